@@ -2,26 +2,45 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"stl-manager/internal/ai"
 	"stl-manager/internal/config"
+	"stl-manager/internal/db"
+	"stl-manager/internal/events"
 	"stl-manager/internal/handlers"
 	"stl-manager/internal/handlers/browse"
 	"stl-manager/internal/handlers/categories"
+	"stl-manager/internal/handlers/collections"
+	eventshandler "stl-manager/internal/handlers/events"
 	"stl-manager/internal/handlers/files"
 	"stl-manager/internal/handlers/folders"
+	jobshandler "stl-manager/internal/handlers/jobs"
+	"stl-manager/internal/handlers/metadata"
+	"stl-manager/internal/handlers/prints"
+	"stl-manager/internal/handlers/proposals"
+	"stl-manager/internal/handlers/reclassify"
+	"stl-manager/internal/handlers/savedsearches"
 	"stl-manager/internal/handlers/scans"
+	"stl-manager/internal/handlers/schedules"
+	"stl-manager/internal/jobs"
+	"stl-manager/internal/media"
 	"stl-manager/internal/scanner"
+	"stl-manager/internal/schedule"
+	"stl-manager/internal/worker"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
@@ -62,16 +81,50 @@ func main() {
 	logger.Info("connected to database")
 
 	// Initialize services
-	classifier := ai.NewOpenAIClassifier(cfg.OpenAIAPIKey)
+	limiter := ai.NewLimiter(ai.LimiterConfig{
+		RequestsPerMinute: cfg.AIRequestsPerMinute,
+		TokensPerMinute:   cfg.AITokensPerMinute,
+		MaxRetries:        cfg.AIMaxRetries,
+		DailyBudgetUSD:    cfg.AIDailyBudgetUSD,
+		ScanBudgetUSD:     cfg.AIScanBudgetUSD,
+	})
+	setupAIUsageTracking(ctx, pool, limiter, logger)
+	classifier := ai.NewLimitedClassifier(ai.NewOpenAIClassifier(cfg.OpenAIAPIKey), limiter)
 	fileScanner := scanner.New(cfg.ScanRootDir, cfg.SupportedExts, logger)
+	workers := worker.NewPool(cfg.Workers)
+	jobManager := jobs.NewManager(pool, logger)
+	broker := events.NewBroker()
 
 	// Initialize modular handlers
 	baseHandler := handlers.New(pool, classifier, fileScanner, cfg, logger)
-	scansHandler := scans.New(pool, classifier, fileScanner, cfg, logger)
-	filesHandler := files.New(pool, classifier, cfg, logger)
-	foldersHandler := folders.New(pool, logger)
-	categoriesHandler := categories.New(pool, logger)
+	scansHandler := scans.New(pool, classifier, fileScanner, workers, jobManager, broker, cfg, logger)
+	foldersHandler := folders.New(pool, jobManager, broker, logger)
+	categoriesHandler := categories.New(pool, broker, logger)
 	browseHandler := browse.New(pool, logger)
+	proposalsHandler := proposals.New(pool, classifier, jobManager, broker, logger)
+	reclassifyHandler := reclassify.New(pool, classifier, workers, jobManager, broker, cfg, logger)
+	filesHandler := files.New(pool, classifier, reclassifyHandler.StartFiles, broker, cfg, logger)
+	jobsHandler := jobshandler.New(pool, jobManager, logger)
+	eventsHandler := eventshandler.New(broker, cfg.APIKey, logger)
+	schedulesHandler := schedules.New(pool, logger)
+	savedSearchesHandler := savedsearches.New(pool, filesHandler.ListFiles, logger)
+	mediaStore := media.NewStore(cfg.MediaDir)
+	collectionsHandler := collections.New(pool, mediaStore, logger)
+	printsHandler := prints.New(pool, mediaStore, logger)
+	metadataHandler := metadata.New(pool, logger)
+
+	// Resume or fail jobs interrupted by the last shutdown (job types are registered above)
+	if err := jobManager.Recover(ctx); err != nil {
+		logger.Error("failed to recover jobs", zap.Error(err))
+	}
+
+	// Start due scan schedules in the background
+	schedulerCtx, stopScheduler := context.WithCancel(ctx)
+	scheduler := schedule.NewScheduler(pool, scansHandler.StartScheduledScan, logger)
+	go scheduler.Run(schedulerCtx)
+
+	// Classify files queued when the AI budget ran out, once it allows
+	go reclassifyHandler.DrainQueue(schedulerCtx, limiter)
 
 	// Setup router
 	r := chi.NewRouter()
@@ -79,9 +132,9 @@ func main() {
 	// Middleware
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
+	r.Use(redactStreamToken)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
 
 	// CORS
 	r.Use(cors.Handler(cors.Options{
@@ -97,7 +150,12 @@ func main() {
 	r.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			apiKey := r.Header.Get("X-API-Key")
-			if apiKey == "" || apiKey != cfg.APIKey {
+			authorized := apiKey != "" && apiKey == cfg.APIKey
+			// EventSource cannot set headers, so event streams pass a short-lived stream token instead
+			if !authorized && strings.HasSuffix(r.URL.Path, "/events") {
+				authorized = eventsHandler.ValidStreamToken(r.URL.Query().Get("token"))
+			}
+			if !authorized {
 				http.Error(w, "Unauthorized", http.StatusUnauthorized)
 				return
 			}
@@ -107,41 +165,123 @@ func main() {
 
 	// Routes
 	r.Route("/v1", func(r chi.Router) {
-		// Health check
-		r.Get("/health", baseHandler.Health)
-
-		// Scans
-		r.Post("/scan", scansHandler.CreateScan)
-		r.Get("/scans/{id}", scansHandler.GetScan)
-		r.Get("/scans", scansHandler.ListScans)
-
-		// Files
-		r.Get("/files", filesHandler.ListFiles)
-		r.Get("/files/{id}", filesHandler.GetFile)
-		r.Post("/files/{id}/reclassify", filesHandler.ReclassifyFile)
-		r.Patch("/files/{id}/categories", filesHandler.UpdateFileCategories)
-
-		// Categories
-		r.Get("/categories", categoriesHandler.ListCategories)
-		r.Post("/categories", categoriesHandler.CreateCategory)
-		r.Get("/categories/{id}", categoriesHandler.GetCategory)
-		r.Put("/categories/{id}", categoriesHandler.UpdateCategory)
-		r.Delete("/categories/{id}", categoriesHandler.SoftDeleteCategory)
-		r.Post("/categories/{id}/restore", categoriesHandler.RestoreCategory)
-
-		// Browse - Mixed view of folders and root files
-		r.Get("/browse", browseHandler.ListBrowse)
-
-		// Mixed - ONLY folders and root-level files (dedicated endpoint)
-		r.Get("/mixed", browseHandler.ListMixed)
-
-		// Folders
-		r.Get("/folders", foldersHandler.ListFolders)
-		r.Get("/folders/{id}", foldersHandler.GetFolder)
-		r.Patch("/folders/{id}/categories", foldersHandler.UpdateFolderCategories)
-
-		// AI
-		r.Get("/ai/status", baseHandler.GetAIStatus)
+		// Event streams stay open for the whole scan, so they skip the request timeout
+		r.Get("/events", eventsHandler.StreamLibrary)
+		r.Get("/scans/{id}/events", scansHandler.ScanEvents)
+		// Collection exports stream every file of the collection
+		r.Get("/collections/{id}/export", collectionsHandler.ExportCollection)
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.Timeout(60 * time.Second))
+
+			// Health check
+			r.Get("/health", baseHandler.Health)
+
+			// Tokens for event streams
+			r.Post("/events/token", eventsHandler.CreateStreamToken)
+
+			// Scans
+			r.Post("/scan", scansHandler.CreateScan)
+			r.Get("/scans/{id}", scansHandler.GetScan)
+			r.Get("/scans/{id}/report", scansHandler.GetScanReport)
+			r.Get("/scans", scansHandler.ListScans)
+
+			// Scan schedules
+			r.Get("/schedules", schedulesHandler.ListSchedules)
+			r.Post("/schedules", schedulesHandler.CreateSchedule)
+			r.Get("/schedules/{id}", schedulesHandler.GetSchedule)
+			r.Put("/schedules/{id}", schedulesHandler.UpdateSchedule)
+			r.Delete("/schedules/{id}", schedulesHandler.DeleteSchedule)
+
+			// Jobs
+			r.Get("/jobs", jobsHandler.ListJobs)
+			r.Get("/jobs/{id}", jobsHandler.GetJob)
+			r.Get("/jobs/{id}/logs", jobsHandler.ListJobLogs)
+			r.Post("/jobs/{id}/cancel", jobsHandler.CancelJob)
+
+			// Files
+			r.Get("/files", filesHandler.ListFiles)
+			r.Post("/files/bulk", filesHandler.BulkUpdateFiles)
+			r.Get("/files/{id}", filesHandler.GetFile)
+			r.Patch("/files/{id}", filesHandler.UpdateFile)
+			r.Post("/files/{id}/reclassify", filesHandler.ReclassifyFile)
+			r.Patch("/files/{id}/categories", filesHandler.UpdateFileCategories)
+
+			// Print history
+			r.Get("/files/{id}/prints", printsHandler.ListPrints)
+			r.Post("/files/{id}/prints", printsHandler.CreatePrint)
+			r.Get("/files/{id}/prints/{printId}", printsHandler.GetPrint)
+			r.Put("/files/{id}/prints/{printId}", printsHandler.UpdatePrint)
+			r.Delete("/files/{id}/prints/{printId}", printsHandler.DeletePrint)
+			r.Put("/files/{id}/prints/{printId}/photo", printsHandler.UploadPhoto)
+			r.Get("/files/{id}/prints/{printId}/photo", printsHandler.GetPhoto)
+			r.Delete("/files/{id}/prints/{printId}/photo", printsHandler.DeletePhoto)
+
+			// Custom fields
+			r.Get("/custom-fields", metadataHandler.ListFields)
+			r.Post("/custom-fields", metadataHandler.CreateField)
+			r.Put("/custom-fields/{id}", metadataHandler.UpdateField)
+			r.Delete("/custom-fields/{id}", metadataHandler.DeleteField)
+
+			// Saved searches
+			r.Get("/saved-searches", savedSearchesHandler.ListSavedSearches)
+			r.Post("/saved-searches", savedSearchesHandler.CreateSavedSearch)
+			r.Get("/saved-searches/{id}", savedSearchesHandler.GetSavedSearch)
+			r.Put("/saved-searches/{id}", savedSearchesHandler.UpdateSavedSearch)
+			r.Delete("/saved-searches/{id}", savedSearchesHandler.DeleteSavedSearch)
+			r.Get("/saved-searches/{id}/files", savedSearchesHandler.ListSavedSearchFiles)
+
+			// Collections
+			r.Get("/collections", collectionsHandler.ListCollections)
+			r.Post("/collections", collectionsHandler.CreateCollection)
+			r.Get("/collections/{id}", collectionsHandler.GetCollection)
+			r.Put("/collections/{id}", collectionsHandler.UpdateCollection)
+			r.Delete("/collections/{id}", collectionsHandler.DeleteCollection)
+			r.Post("/collections/{id}/items", collectionsHandler.AddCollectionItem)
+			r.Patch("/collections/{id}/items/{itemId}", collectionsHandler.UpdateCollectionItem)
+			r.Delete("/collections/{id}/items/{itemId}", collectionsHandler.DeleteCollectionItem)
+			r.Put("/collections/{id}/cover", collectionsHandler.UploadCover)
+			r.Get("/collections/{id}/cover", collectionsHandler.GetCover)
+			r.Delete("/collections/{id}/cover", collectionsHandler.DeleteCover)
+
+			// Bulk reclassification
+			r.Post("/reclassify", reclassifyHandler.CreateReclassify)
+			r.Get("/reclassify", reclassifyHandler.ListReclassify)
+			r.Get("/reclassify/{id}", reclassifyHandler.GetReclassify)
+
+			// Categories
+			r.Get("/categories", categoriesHandler.ListCategories)
+			r.Post("/categories/proposals/analyze", proposalsHandler.AnalyzeUncategorized)
+			r.Get("/categories/proposals", proposalsHandler.ListProposals)
+			r.Get("/categories/proposals/{id}", proposalsHandler.GetProposal)
+			r.Post("/categories/proposals/{id}/accept", proposalsHandler.AcceptProposal)
+			r.Post("/categories/proposals/{id}/reject", proposalsHandler.RejectProposal)
+			r.Post("/categories", categoriesHandler.CreateCategory)
+			r.Get("/categories/{id}", categoriesHandler.GetCategory)
+			r.Put("/categories/{id}", categoriesHandler.UpdateCategory)
+			r.Delete("/categories/{id}", categoriesHandler.SoftDeleteCategory)
+			r.Post("/categories/{id}/restore", categoriesHandler.RestoreCategory)
+			r.Post("/categories/{id}/merge", categoriesHandler.MergeCategories)
+
+			// Browse - Mixed view of folders and root files
+			r.Get("/browse", browseHandler.ListBrowse)
+
+			// Mixed - ONLY folders and root-level files (dedicated endpoint)
+			r.Get("/mixed", browseHandler.ListMixed)
+
+			// Folders
+			r.Get("/folders", foldersHandler.ListFolders)
+			r.Get("/folders/{id}", foldersHandler.GetFolder)
+			r.Patch("/folders/{id}", foldersHandler.UpdateFolder)
+			r.Patch("/folders/{id}/categories", foldersHandler.UpdateFolderCategories)
+			r.Get("/folders/{id}/models", foldersHandler.ListModels)
+			r.Get("/folders/{id}/images", foldersHandler.ListImages)
+			r.Get("/folders/{id}/images/{imageId}", foldersHandler.GetImage)
+
+			// AI
+			r.Get("/ai/status", baseHandler.GetAIStatus)
+			r.Get("/ai/usage", baseHandler.GetAIUsage)
+		})
 	})
 
 	// Start server
@@ -152,14 +292,19 @@ func main() {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// End open event streams so graceful shutdown does not wait for them
+	srv.RegisterOnShutdown(broker.Close)
 
 	// Graceful shutdown
+	stopped := make(chan struct{})
 	go func() {
+		defer close(stopped)
 		sigint := make(chan os.Signal, 1)
 		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
 		<-sigint
 
 		logger.Info("shutting down server...")
+		stopScheduler()
 
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
@@ -167,12 +312,60 @@ func main() {
 		if err := srv.Shutdown(ctx); err != nil {
 			logger.Error("server shutdown error", zap.Error(err))
 		}
+
+		// Stop running jobs; they are resumed or failed on the next start
+		jobManager.Shutdown(ctx)
 	}()
 
 	logger.Info("server started", zap.String("addr", srv.Addr))
 	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		logger.Fatal("server failed", zap.Error(err))
 	}
+	<-stopped
 
 	logger.Info("server stopped")
 }
+
+// setupAIUsageTracking restores today's AI spend from the database and persists
+// usage after every request so the daily cap survives restarts
+func setupAIUsageTracking(ctx context.Context, pool *pgxpool.Pool, limiter *ai.Limiter, logger *zap.Logger) {
+	queries := db.New(pool)
+	now := time.Now().UTC()
+
+	usage, err := queries.GetAIUsage(ctx, pgtype.Date{Time: now, Valid: true})
+	if err == nil {
+		limiter.Seed(ai.UsageStats{
+			Day:              now.Format("2006-01-02"),
+			Requests:         int64(usage.Requests),
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			CostUSD:          usage.CostUsd,
+		})
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		logger.Warn("failed to load AI usage", zap.Error(err))
+	}
+
+	limiter.SetRecorder(func(ctx context.Context, day time.Time, usage ai.Usage, costUSD float64) {
+		err := queries.AddAIUsage(ctx, db.AddAIUsageParams{
+			Day:              pgtype.Date{Time: day.UTC(), Valid: true},
+			PromptTokens:     int64(usage.PromptTokens),
+			CompletionTokens: int64(usage.CompletionTokens),
+			CostUsd:          costUSD,
+		})
+		if err != nil {
+			logger.Warn("failed to record AI usage", zap.Error(err))
+		}
+	})
+}
+
+// redactStreamToken hides the stream token of event stream URLs from the
+// access log, which prints the request URI
+func redactStreamToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if query := r.URL.Query(); query.Has("token") {
+			query.Set("token", "REDACTED")
+			r.RequestURI = r.URL.EscapedPath() + "?" + query.Encode()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
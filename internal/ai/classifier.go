@@ -14,6 +14,22 @@ type Classifier interface {
 	IsEnabled() bool
 }
 
+// Usage is the token usage reported by the model for a single request
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// Total returns the combined prompt and completion tokens
+func (u Usage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// usageClassifier is implemented by classifiers that can report token usage
+type usageClassifier interface {
+	ClassifyWithUsage(ctx context.Context, fileName string, allowedCategories []string) ([]string, Usage, error)
+}
+
 type OpenAIClassifier struct {
 	client  *openai.Client
 	model   string
@@ -39,9 +55,15 @@ func (c *OpenAIClassifier) IsEnabled() bool {
 }
 
 func (c *OpenAIClassifier) Classify(ctx context.Context, fileName string, allowedCategories []string) ([]string, error) {
+	categories, _, err := c.ClassifyWithUsage(ctx, fileName, allowedCategories)
+	return categories, err
+}
+
+// ClassifyWithUsage classifies a file and also returns the tokens consumed by the request
+func (c *OpenAIClassifier) ClassifyWithUsage(ctx context.Context, fileName string, allowedCategories []string) ([]string, Usage, error) {
 	// If not enabled, return empty (no classification)
 	if !c.enabled || c.client == nil {
-		return []string{}, nil
+		return []string{}, Usage{}, nil
 	}
 
 	systemPrompt := `You are a classifier. You receive a filename and a catalog of categories.
@@ -53,6 +75,7 @@ allowed_categories: %s
 
 Instructions:
 - Choose 0-3 categories from the catalog that describe the file by its NAME.
+- Nested categories are written as paths (parent/child). Prefer the most specific path that fits and return it exactly as written.
 - If it doesn't fit, return [].
 - Respond ONLY with JSON: ["cat1","cat2"]
 
@@ -64,6 +87,7 @@ nozzle_holder_4010_fan.stl      -> ["tool_holder","printer_upgrade"]
 gt2_pulley_adapter_v3.stl       -> ["adapter","mechanical_part"]
 porsche_911_body.zip            -> ["vehicle","rc_part"]
 gojo_figure.stl                 -> ["figurine","anime"]
+red_dragon_28mm.stl             -> ["miniature/fantasy/dragons"]
 `, fileName, string(categoriesJSON))
 
 	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
@@ -83,11 +107,16 @@ gojo_figure.stl                 -> ["figurine","anime"]
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("openai request failed: %w", err)
+		return nil, Usage{}, fmt.Errorf("openai request failed: %w", err)
+	}
+
+	usage := Usage{
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
 	}
 
 	if len(resp.Choices) == 0 {
-		return []string{}, nil
+		return []string{}, usage, nil
 	}
 
 	content := strings.TrimSpace(resp.Choices[0].Message.Content)
@@ -101,10 +130,10 @@ gojo_figure.stl                 -> ["figurine","anime"]
 		if start >= 0 && end > start {
 			jsonStr := content[start : end+1]
 			if err := json.Unmarshal([]byte(jsonStr), &categories); err != nil {
-				return []string{}, nil
+				return []string{}, usage, nil
 			}
 		} else {
-			return []string{}, nil
+			return []string{}, usage, nil
 		}
 	}
 
@@ -121,5 +150,5 @@ gojo_figure.stl                 -> ["figurine","anime"]
 		}
 	}
 
-	return validCategories, nil
+	return validCategories, usage, nil
 }
@@ -0,0 +1,462 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// ErrBudgetExceeded is returned when a request would go over the daily or per-scan spend cap
+var ErrBudgetExceeded = errors.New("ai spend budget exceeded")
+
+// Default gpt-4o-mini pricing in USD per 1M tokens
+const (
+	DefaultInputCostPerMillion  = 0.15
+	DefaultOutputCostPerMillion = 0.60
+)
+
+// Rough token counts used to reserve capacity before the real usage is known
+const (
+	basePromptTokens    = 350
+	maxCompletionTokens = 100
+)
+
+// LimiterConfig controls rate limits, retries and spend caps for AI calls.
+// Zero values disable the corresponding limit.
+type LimiterConfig struct {
+	RequestsPerMinute    int     `json:"requests_per_minute"`
+	TokensPerMinute      int     `json:"tokens_per_minute"`
+	MaxRetries           int     `json:"max_retries"`
+	DailyBudgetUSD       float64 `json:"daily_budget_usd"`
+	ScanBudgetUSD        float64 `json:"scan_budget_usd"`
+	InputCostPerMillion  float64 `json:"input_cost_per_million"`
+	OutputCostPerMillion float64 `json:"output_cost_per_million"`
+}
+
+// UsageStats accumulates usage for a single day
+type UsageStats struct {
+	Day              string  `json:"day"`
+	Requests         int64   `json:"requests"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+	Retries          int64   `json:"retries"`
+	BudgetSkips      int64   `json:"budget_skips"`
+}
+
+// UsageSnapshot is the current state of the limiter
+type UsageSnapshot struct {
+	Today  UsageStats    `json:"today"`
+	Limits LimiterConfig `json:"limits"`
+}
+
+// UsageRecorder persists usage after every successful request
+type UsageRecorder func(ctx context.Context, day time.Time, usage Usage, costUSD float64)
+
+// Limiter is shared by every AI caller so that scans, reclassifications and
+// other jobs stay inside the same rate limits and spend caps
+type Limiter struct {
+	cfg      LimiterConfig
+	mu       sync.Mutex
+	requests *bucket
+	tokens   *bucket
+	today    UsageStats
+	recorder UsageRecorder
+}
+
+// NewLimiter creates a Limiter from the given config
+func NewLimiter(cfg LimiterConfig) *Limiter {
+	if cfg.InputCostPerMillion == 0 && cfg.OutputCostPerMillion == 0 {
+		cfg.InputCostPerMillion = DefaultInputCostPerMillion
+		cfg.OutputCostPerMillion = DefaultOutputCostPerMillion
+	}
+	return &Limiter{
+		cfg:      cfg,
+		requests: newBucket(cfg.RequestsPerMinute),
+		tokens:   newBucket(cfg.TokensPerMinute),
+		today:    UsageStats{Day: dayKey(time.Now())},
+	}
+}
+
+// Config returns the limiter configuration
+func (l *Limiter) Config() LimiterConfig {
+	return l.cfg
+}
+
+// SetRecorder sets the function used to persist usage
+func (l *Limiter) SetRecorder(recorder UsageRecorder) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.recorder = recorder
+}
+
+// Seed restores today's usage (e.g. from the database after a restart)
+func (l *Limiter) Seed(stats UsageStats) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if stats.Day == dayKey(time.Now()) {
+		l.today = stats
+	}
+}
+
+// Snapshot returns today's usage and the configured limits
+func (l *Limiter) Snapshot() UsageSnapshot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollDay()
+	return UsageSnapshot{Today: l.today, Limits: l.cfg}
+}
+
+// Cost returns the USD cost of the given usage
+func (l *Limiter) Cost(usage Usage) float64 {
+	return float64(usage.PromptTokens)*l.cfg.InputCostPerMillion/1e6 +
+		float64(usage.CompletionTokens)*l.cfg.OutputCostPerMillion/1e6
+}
+
+// EstimateCost returns the expected USD cost of classifying one file
+func (l *Limiter) EstimateCost(fileName string, allowedCategories []string) float64 {
+	return l.Cost(estimateUsage(fileName, allowedCategories))
+}
+
+// CanSpend reports whether estimate USD still fits in today's budget
+func (l *Limiter) CanSpend(estimate float64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollDay()
+	return l.cfg.DailyBudgetUSD <= 0 || l.today.CostUSD+estimate <= l.cfg.DailyBudgetUSD
+}
+
+// checkBudget fails with ErrBudgetExceeded if the estimated cost does not
+// fit. Otherwise it reserves the estimate in today's spend and in the
+// per-scan budget, so concurrent callers cannot all pass the check before any
+// of them records its cost. It returns the day the reservation was made on.
+func (l *Limiter) checkBudget(ctx context.Context, estimate float64) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollDay()
+
+	if l.cfg.DailyBudgetUSD > 0 && l.today.CostUSD+estimate > l.cfg.DailyBudgetUSD {
+		l.today.BudgetSkips++
+		return "", ErrBudgetExceeded
+	}
+	if budget := budgetFromContext(ctx); budget != nil && !budget.reserve(estimate) {
+		l.today.BudgetSkips++
+		return "", ErrBudgetExceeded
+	}
+	l.today.CostUSD += estimate
+	return l.today.Day, nil
+}
+
+// release gives back the estimate reserved by checkBudget for a request that
+// did not complete
+func (l *Limiter) release(ctx context.Context, reserved float64, day string) {
+	l.mu.Lock()
+	if l.today.Day == day {
+		l.today.CostUSD -= reserved
+	}
+	l.mu.Unlock()
+
+	if budget := budgetFromContext(ctx); budget != nil {
+		budget.add(-reserved)
+	}
+}
+
+// wait blocks until a request using n tokens fits in the per-minute limits
+func (l *Limiter) wait(ctx context.Context, n int) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		delay := l.requests.delay(now, 1)
+		if d := l.tokens.delay(now, float64(n)); d > delay {
+			delay = d
+		}
+		if delay == 0 {
+			l.requests.take(now, 1)
+			l.tokens.take(now, float64(n))
+		}
+		l.mu.Unlock()
+
+		if delay == 0 {
+			return nil
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// record adds the real usage of a finished request. The estimate reserved
+// by checkBudget is already counted, so only the difference is added.
+func (l *Limiter) record(ctx context.Context, usage Usage, estimate Usage, day string) {
+	cost := l.Cost(usage)
+	reserved := l.Cost(estimate)
+
+	l.mu.Lock()
+	l.rollDay()
+	l.today.Requests++
+	l.today.PromptTokens += int64(usage.PromptTokens)
+	l.today.CompletionTokens += int64(usage.CompletionTokens)
+	if l.today.Day == day {
+		l.today.CostUSD += cost - reserved
+	} else {
+		// The reservation went away with the previous day
+		l.today.CostUSD += cost
+	}
+	// Give back (or take more of) the tokens reserved before the call
+	l.tokens.take(time.Now(), float64(usage.Total()-estimate.Total()))
+	recorder := l.recorder
+	l.mu.Unlock()
+
+	if budget := budgetFromContext(ctx); budget != nil {
+		budget.add(cost - reserved)
+	}
+	if recorder != nil {
+		recorder(ctx, time.Now(), usage, cost)
+	}
+}
+
+func (l *Limiter) noteRetry() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.today.Retries++
+}
+
+// rollDay resets the daily counters when the date changes. Caller must hold mu.
+func (l *Limiter) rollDay() {
+	if today := dayKey(time.Now()); l.today.Day != today {
+		l.today = UsageStats{Day: today}
+	}
+}
+
+// LimitedClassifier wraps a Classifier with the shared Limiter
+type LimitedClassifier struct {
+	inner   Classifier
+	limiter *Limiter
+}
+
+// NewLimitedClassifier wraps inner so every call goes through limiter
+func NewLimitedClassifier(inner Classifier, limiter *Limiter) *LimitedClassifier {
+	return &LimitedClassifier{inner: inner, limiter: limiter}
+}
+
+func (c *LimitedClassifier) IsEnabled() bool {
+	return c.inner.IsEnabled()
+}
+
+// Limiter returns the limiter used by this classifier
+func (c *LimitedClassifier) Limiter() *Limiter {
+	return c.limiter
+}
+
+func (c *LimitedClassifier) Classify(ctx context.Context, fileName string, allowedCategories []string) ([]string, error) {
+	if !c.inner.IsEnabled() {
+		return c.inner.Classify(ctx, fileName, allowedCategories)
+	}
+
+	estimate := estimateUsage(fileName, allowedCategories)
+
+	var result []string
+	err := c.limiter.Do(ctx, estimate, func() (Usage, error) {
+		if uc, ok := c.inner.(usageClassifier); ok {
+			categories, usage, err := uc.ClassifyWithUsage(ctx, fileName, allowedCategories)
+			result = categories
+			return usage, err
+		}
+		categories, err := c.inner.Classify(ctx, fileName, allowedCategories)
+		result = categories
+		return estimate, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Do runs call after checking the spend caps and waiting for rate-limit capacity.
+// Rate-limit and server errors are retried with exponential backoff.
+func (l *Limiter) Do(ctx context.Context, estimate Usage, call func() (Usage, error)) error {
+	day, err := l.checkBudget(ctx, l.Cost(estimate))
+	if err != nil {
+		return err
+	}
+	usage, err := l.retry(ctx, estimate.Total(), call)
+	if err != nil {
+		l.release(ctx, l.Cost(estimate), day)
+		return err
+	}
+	l.record(ctx, usage, estimate, day)
+	return nil
+}
+
+// retry runs call inside the rate limits, retrying rate-limit and server errors
+// with exponential backoff
+func (l *Limiter) retry(ctx context.Context, estimated int, call func() (Usage, error)) (Usage, error) {
+	for attempt := 0; ; attempt++ {
+		if err := l.wait(ctx, estimated); err != nil {
+			return Usage{}, err
+		}
+
+		usage, err := call()
+		if err == nil {
+			return usage, nil
+		}
+
+		if !isRetryable(err) || attempt >= l.cfg.MaxRetries {
+			return Usage{}, err
+		}
+
+		l.noteRetry()
+		if err := sleep(ctx, backoff(attempt)); err != nil {
+			return Usage{}, err
+		}
+	}
+}
+
+// UsageReporter is implemented by classifiers that track usage
+type UsageReporter interface {
+	Limiter() *Limiter
+}
+
+// Budget caps the spend of a single scan or job
+type Budget struct {
+	mu    sync.Mutex
+	limit float64
+	spent float64
+}
+
+// NewBudget creates a Budget. A limit <= 0 means unlimited.
+func NewBudget(limitUSD float64) *Budget {
+	return &Budget{limit: limitUSD}
+}
+
+// Spent returns the USD spent so far
+func (b *Budget) Spent() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.spent
+}
+
+// reserve adds estimate to the spend if it fits in the limit
+func (b *Budget) reserve(estimate float64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.limit > 0 && b.spent+estimate > b.limit {
+		return false
+	}
+	b.spent += estimate
+	return true
+}
+
+func (b *Budget) add(cost float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.spent += cost
+}
+
+type budgetKey struct{}
+
+// WithBudget attaches a per-scan budget to the context
+func WithBudget(ctx context.Context, budget *Budget) context.Context {
+	return context.WithValue(ctx, budgetKey{}, budget)
+}
+
+func budgetFromContext(ctx context.Context) *Budget {
+	budget, _ := ctx.Value(budgetKey{}).(*Budget)
+	return budget
+}
+
+// bucket is a token bucket refilled continuously over one minute
+type bucket struct {
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newBucket(perMinute int) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &bucket{capacity: float64(perMinute), tokens: float64(perMinute), last: time.Now()}
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Minutes()
+	b.tokens = math.Min(b.capacity, b.tokens+elapsed*b.capacity)
+	b.last = now
+}
+
+// delay returns how long to wait until n tokens are available
+func (b *bucket) delay(now time.Time, n float64) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.refill(now)
+	// Requests larger than the bucket only wait for a full bucket
+	n = math.Min(n, b.capacity)
+	if b.tokens >= n {
+		return 0
+	}
+	return time.Duration((n - b.tokens) / b.capacity * float64(time.Minute))
+}
+
+func (b *bucket) take(now time.Time, n float64) {
+	if b == nil {
+		return
+	}
+	b.refill(now)
+	b.tokens = math.Min(b.capacity, b.tokens-n)
+}
+
+func estimateUsage(fileName string, allowedCategories []string) Usage {
+	chars := len(fileName)
+	for _, cat := range allowedCategories {
+		chars += len(cat) + 3
+	}
+	return Usage{
+		PromptTokens:     basePromptTokens + chars/4,
+		CompletionTokens: maxCompletionTokens,
+	}
+}
+
+// isRetryable reports whether err is a rate-limit or server-side error
+func isRetryable(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == http.StatusTooManyRequests || apiErr.HTTPStatusCode >= 500
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode == http.StatusTooManyRequests || reqErr.HTTPStatusCode >= 500
+	}
+	return false
+}
+
+// backoff returns 1s, 2s, 4s... capped at 30s, plus up to 1s of jitter
+func backoff(attempt int) time.Duration {
+	d := time.Second << attempt
+	if d <= 0 || d > 30*time.Second {
+		d = 30 * time.Second
+	}
+	return d + time.Duration(rand.Int63n(int64(time.Second)))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func dayKey(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
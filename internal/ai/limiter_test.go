@@ -0,0 +1,331 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// testConfig prices every prompt token at one dollar so costs are whole numbers
+func testConfig() LimiterConfig {
+	return LimiterConfig{InputCostPerMillion: 1e6, OutputCostPerMillion: 1e6}
+}
+
+func TestBucket(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		perMinute int
+		// taken is how many tokens are used at start
+		taken float64
+		after time.Duration
+		n     float64
+		want  time.Duration
+	}{
+		{
+			name:      "full bucket",
+			perMinute: 60,
+			n:         1,
+			want:      0,
+		},
+		{
+			name:      "empty bucket waits for one token",
+			perMinute: 60,
+			taken:     60,
+			n:         1,
+			want:      time.Second,
+		},
+		{
+			name:      "refills over the minute",
+			perMinute: 60,
+			taken:     60,
+			after:     30 * time.Second,
+			n:         30,
+			want:      0,
+		},
+		{
+			name:      "partial refill",
+			perMinute: 60,
+			taken:     60,
+			after:     10 * time.Second,
+			n:         20,
+			want:      10 * time.Second,
+		},
+		{
+			name:      "larger than the bucket waits for a full bucket",
+			perMinute: 60,
+			taken:     30,
+			n:         1000,
+			want:      30 * time.Second,
+		},
+		{
+			name: "no limit",
+			n:    1e9,
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBucket(tt.perMinute)
+			if b != nil {
+				b.last = start
+			}
+			b.take(start, tt.taken)
+			if got := b.delay(start.Add(tt.after), tt.n); got != tt.want {
+				t.Errorf("delay = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limited", &openai.APIError{HTTPStatusCode: http.StatusTooManyRequests}, true},
+		{"server error", &openai.APIError{HTTPStatusCode: http.StatusInternalServerError}, true},
+		{"unavailable", &openai.RequestError{HTTPStatusCode: http.StatusServiceUnavailable}, true},
+		{"bad request", &openai.APIError{HTTPStatusCode: http.StatusBadRequest}, false},
+		{"unauthorized", &openai.RequestError{HTTPStatusCode: http.StatusUnauthorized}, false},
+		{"other error", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		min     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{5, 30 * time.Second},
+		{100, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		got := backoff(tt.attempt)
+		if got < tt.min || got >= tt.min+time.Second {
+			t.Errorf("backoff(%d) = %v, want between %v and %v", tt.attempt, got, tt.min, tt.min+time.Second)
+		}
+	}
+}
+
+func TestDoRetries(t *testing.T) {
+	rateLimited := &openai.APIError{HTTPStatusCode: http.StatusTooManyRequests}
+	unavailable := &openai.APIError{HTTPStatusCode: http.StatusBadGateway}
+	badRequest := &openai.APIError{HTTPStatusCode: http.StatusBadRequest}
+
+	tests := []struct {
+		name       string
+		maxRetries int
+		errs       []error
+		wantErr    error
+		wantCalls  int
+	}{
+		{
+			name:      "success",
+			errs:      []error{nil},
+			wantCalls: 1,
+		},
+		{
+			name:       "retries a 5xx",
+			maxRetries: 1,
+			errs:       []error{unavailable, nil},
+			wantCalls:  2,
+		},
+		{
+			name:      "no retries left",
+			errs:      []error{rateLimited},
+			wantErr:   rateLimited,
+			wantCalls: 1,
+		},
+		{
+			name:       "does not retry a 4xx",
+			maxRetries: 3,
+			errs:       []error{badRequest},
+			wantErr:    badRequest,
+			wantCalls:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.MaxRetries = tt.maxRetries
+			l := NewLimiter(cfg)
+
+			calls := 0
+			err := l.Do(context.Background(), Usage{PromptTokens: 2}, func() (Usage, error) {
+				err := tt.errs[calls]
+				calls++
+				return Usage{PromptTokens: 1}, err
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+
+			today := l.Snapshot().Today
+			if want := int64(tt.wantCalls - 1); today.Retries != want {
+				t.Errorf("retries = %d, want %d", today.Retries, want)
+			}
+			// Failed requests give back their reservation; successful ones
+			// count their real cost, not the estimate
+			wantCost := 0.0
+			if tt.wantErr == nil {
+				wantCost = 1
+			}
+			if today.CostUSD != wantCost {
+				t.Errorf("cost = %v, want %v", today.CostUSD, wantCost)
+			}
+		})
+	}
+}
+
+func TestDoBackoffCancelled(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxRetries = 5
+	l := NewLimiter(cfg)
+	budget := NewBudget(10)
+
+	ctx, cancel := context.WithTimeout(WithBudget(context.Background(), budget), 50*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	err := l.Do(ctx, Usage{PromptTokens: 2}, func() (Usage, error) {
+		calls++
+		return Usage{}, &openai.APIError{HTTPStatusCode: http.StatusTooManyRequests}
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 before the first backoff ends", calls)
+	}
+	if cost := l.Snapshot().Today.CostUSD; cost != 0 {
+		t.Errorf("cost = %v, want the reservation released", cost)
+	}
+	if spent := budget.Spent(); spent != 0 {
+		t.Errorf("budget spent = %v, want the reservation released", spent)
+	}
+}
+
+func TestDoBudget(t *testing.T) {
+	estimate := Usage{PromptTokens: 2}
+
+	tests := []struct {
+		name      string
+		dailyUSD  float64
+		scanUSD   float64
+		requests  int
+		wantOK    int
+		wantSpent float64
+	}{
+		{
+			name:      "unlimited",
+			requests:  5,
+			wantOK:    5,
+			wantSpent: 5,
+		},
+		{
+			name:      "daily budget",
+			dailyUSD:  5,
+			requests:  5,
+			wantOK:    2,
+			wantSpent: 2,
+		},
+		{
+			name:      "scan budget",
+			scanUSD:   4,
+			requests:  5,
+			wantOK:    2,
+			wantSpent: 2,
+		},
+		{
+			name:     "estimate over the budget",
+			dailyUSD: 1,
+			requests: 1,
+			wantOK:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.DailyBudgetUSD = tt.dailyUSD
+			l := NewLimiter(cfg)
+			ctx := WithBudget(context.Background(), NewBudget(tt.scanUSD))
+
+			// Every request passes the budget check before any of them
+			// records its cost, so only reserved estimates keep them inside
+			// the budget
+			release := make(chan struct{})
+			var wg sync.WaitGroup
+			var mu sync.Mutex
+			ok, exceeded := 0, 0
+			for i := 0; i < tt.requests; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					err := l.Do(ctx, estimate, func() (Usage, error) {
+						<-release
+						return Usage{PromptTokens: 1}, nil
+					})
+					mu.Lock()
+					defer mu.Unlock()
+					switch {
+					case err == nil:
+						ok++
+					case errors.Is(err, ErrBudgetExceeded):
+						exceeded++
+					default:
+						t.Errorf("unexpected error: %v", err)
+					}
+				}()
+			}
+
+			// Rejected requests return right away; wait for them before
+			// letting the accepted ones finish
+			deadline := time.Now().Add(time.Second)
+			for l.Snapshot().Today.BudgetSkips < int64(tt.requests-tt.wantOK) && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			close(release)
+			wg.Wait()
+
+			if ok != tt.wantOK {
+				t.Errorf("accepted = %d, want %d", ok, tt.wantOK)
+			}
+			today := l.Snapshot().Today
+			if today.BudgetSkips != int64(exceeded) {
+				t.Errorf("budget skips = %d, want %d", today.BudgetSkips, exceeded)
+			}
+			if today.CostUSD != tt.wantSpent {
+				t.Errorf("cost = %v, want %v", today.CostUSD, tt.wantSpent)
+			}
+			if spent := budgetFromContext(ctx).Spent(); spent != tt.wantSpent {
+				t.Errorf("budget spent = %v, want %v", spent, tt.wantSpent)
+			}
+		})
+	}
+}
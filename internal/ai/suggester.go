@@ -0,0 +1,176 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// CategorySuggestion is a new category proposed by the model
+type CategorySuggestion struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Examples    []string `json:"examples"`
+}
+
+// Suggester proposes new categories for files that don't fit the existing ones
+type Suggester interface {
+	SuggestCategories(ctx context.Context, fileNames []string, existingCategories []string) ([]CategorySuggestion, error)
+}
+
+type usageSuggester interface {
+	SuggestCategoriesWithUsage(ctx context.Context, fileNames []string, existingCategories []string) ([]CategorySuggestion, Usage, error)
+}
+
+const maxSuggestionTokens = 1500
+
+var categoryNamePattern = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
+// SuggestCategories asks the model to group uncategorized file names into new categories
+func (c *OpenAIClassifier) SuggestCategories(ctx context.Context, fileNames []string, existingCategories []string) ([]CategorySuggestion, error) {
+	suggestions, _, err := c.SuggestCategoriesWithUsage(ctx, fileNames, existingCategories)
+	return suggestions, err
+}
+
+// SuggestCategoriesWithUsage is SuggestCategories that also returns the tokens consumed
+func (c *OpenAIClassifier) SuggestCategoriesWithUsage(ctx context.Context, fileNames []string, existingCategories []string) ([]CategorySuggestion, Usage, error) {
+	if !c.enabled || c.client == nil || len(fileNames) == 0 {
+		return []CategorySuggestion{}, Usage{}, nil
+	}
+
+	systemPrompt := `You organize a library of 3D printing files into categories.
+You receive file names that did not fit any existing category.
+Return ONLY a JSON array of objects. No extra text.`
+
+	filesJSON, _ := json.Marshal(fileNames)
+	categoriesJSON, _ := json.Marshal(existingCategories)
+	userPrompt := fmt.Sprintf(`existing_categories: %s
+uncategorized_files: %s
+
+Instructions:
+- Propose 1-10 NEW categories that group several of the files.
+- Do not propose a category that already exists or is a synonym of one.
+- Names must be lowercase snake_case, e.g. "wargame_terrain".
+- For each category list the file names (copied exactly) that belong to it.
+- Respond ONLY with JSON:
+  [{"name":"cat","description":"short description","examples":["file1.stl","file2.zip"]}]
+`, string(categoriesJSON), string(filesJSON))
+
+	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: c.model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: systemPrompt,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: userPrompt,
+			},
+		},
+		Temperature: 0.3,
+		MaxTokens:   maxSuggestionTokens,
+	})
+	if err != nil {
+		return nil, Usage{}, fmt.Errorf("openai request failed: %w", err)
+	}
+
+	usage := Usage{
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+	}
+
+	if len(resp.Choices) == 0 {
+		return []CategorySuggestion{}, usage, nil
+	}
+
+	content := strings.TrimSpace(resp.Choices[0].Message.Content)
+	start := strings.Index(content, "[")
+	end := strings.LastIndex(content, "]")
+	if start < 0 || end <= start {
+		return []CategorySuggestion{}, usage, nil
+	}
+
+	var suggestions []CategorySuggestion
+	if err := json.Unmarshal([]byte(content[start:end+1]), &suggestions); err != nil {
+		return []CategorySuggestion{}, usage, nil
+	}
+
+	return filterSuggestions(suggestions, fileNames, existingCategories), usage, nil
+}
+
+// filterSuggestions drops invalid or existing names and examples that were not in the sample
+func filterSuggestions(suggestions []CategorySuggestion, fileNames []string, existingCategories []string) []CategorySuggestion {
+	existing := make(map[string]bool, len(existingCategories))
+	for _, cat := range existingCategories {
+		existing[strings.ToLower(cat)] = true
+	}
+	sampled := make(map[string]bool, len(fileNames))
+	for _, name := range fileNames {
+		sampled[name] = true
+	}
+
+	valid := make([]CategorySuggestion, 0, len(suggestions))
+	seen := make(map[string]bool)
+	for _, suggestion := range suggestions {
+		name := strings.ToLower(strings.TrimSpace(suggestion.Name))
+		if !categoryNamePattern.MatchString(name) || existing[name] || seen[name] {
+			continue
+		}
+
+		examples := make([]string, 0, len(suggestion.Examples))
+		for _, example := range suggestion.Examples {
+			if sampled[example] {
+				examples = append(examples, example)
+			}
+		}
+		if len(examples) == 0 {
+			continue
+		}
+
+		seen[name] = true
+		valid = append(valid, CategorySuggestion{
+			Name:        name,
+			Description: strings.TrimSpace(suggestion.Description),
+			Examples:    examples,
+		})
+	}
+	return valid
+}
+
+// SuggestCategories runs the wrapped suggester through the shared limiter
+func (c *LimitedClassifier) SuggestCategories(ctx context.Context, fileNames []string, existingCategories []string) ([]CategorySuggestion, error) {
+	suggester, ok := c.inner.(Suggester)
+	if !ok || !c.inner.IsEnabled() {
+		return []CategorySuggestion{}, nil
+	}
+
+	chars := 0
+	for _, name := range append(append([]string{}, fileNames...), existingCategories...) {
+		chars += len(name) + 3
+	}
+	estimate := Usage{
+		PromptTokens:     basePromptTokens + chars/4,
+		CompletionTokens: maxSuggestionTokens,
+	}
+
+	var result []CategorySuggestion
+	err := c.limiter.Do(ctx, estimate, func() (Usage, error) {
+		if us, ok := c.inner.(usageSuggester); ok {
+			suggestions, usage, err := us.SuggestCategoriesWithUsage(ctx, fileNames, existingCategories)
+			result = suggestions
+			return usage, err
+		}
+		suggestions, err := suggester.SuggestCategories(ctx, fileNames, existingCategories)
+		result = suggestions
+		return estimate, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -20,8 +20,27 @@ type Config struct {
 	RedisDB         int
 	ScanRootDir     string
 	SupportedExts   []string
+	Workers         int
 	APIKey          string
 	Port            string
+
+	// AI rate limiting and spend caps (0 disables a limit)
+	AIRequestsPerMinute int
+	AITokensPerMinute   int
+	AIMaxRetries        int
+	AIDailyBudgetUSD    float64
+	AIScanBudgetUSD     float64
+
+	// Scan pipeline: workers per stage, files per database batch and the
+	// channel buffer between stages (0 uses the defaults)
+	ScanStatWorkers   int
+	ScanHashWorkers   int
+	ScanUpsertWorkers int
+	ScanBatchSize     int
+	ScanQueueSize     int
+
+	// MediaDir holds uploaded images, such as collection covers
+	MediaDir string
 }
 
 func Load() (*Config, error) {
@@ -29,6 +48,17 @@ func Load() (*Config, error) {
 	_ = godotenv.Load()
 
 	redisDB, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
+	workers, _ := strconv.Atoi(getEnv("WORKERS", "20"))
+	aiRPM, _ := strconv.Atoi(getEnv("AI_REQUESTS_PER_MINUTE", "500"))
+	aiTPM, _ := strconv.Atoi(getEnv("AI_TOKENS_PER_MINUTE", "200000"))
+	aiMaxRetries, _ := strconv.Atoi(getEnv("AI_MAX_RETRIES", "5"))
+	aiDailyBudget, _ := strconv.ParseFloat(getEnv("AI_DAILY_BUDGET_USD", "0"), 64)
+	aiScanBudget, _ := strconv.ParseFloat(getEnv("AI_SCAN_BUDGET_USD", "0"), 64)
+	scanStatWorkers, _ := strconv.Atoi(getEnv("SCAN_STAT_WORKERS", "8"))
+	scanHashWorkers, _ := strconv.Atoi(getEnv("SCAN_HASH_WORKERS", "4"))
+	scanUpsertWorkers, _ := strconv.Atoi(getEnv("SCAN_UPSERT_WORKERS", "2"))
+	scanBatchSize, _ := strconv.Atoi(getEnv("SCAN_BATCH_SIZE", "500"))
+	scanQueueSize, _ := strconv.Atoi(getEnv("SCAN_QUEUE_SIZE", "1000"))
 
 	cfg := &Config{
 		DatabaseURL:     getEnv("DATABASE_URL", ""),
@@ -41,8 +71,23 @@ func Load() (*Config, error) {
 		RedisDB:         redisDB,
 		ScanRootDir:     getEnv("SCAN_ROOT_DIR", "E:\\Impresion3D"),
 		SupportedExts:   parseExts(getEnv("SUPPORTED_EXTS", ".stl,.zip,.rar")),
+		Workers:         workers,
 		APIKey:          getEnv("API_KEY", "dev-secret-key"),
 		Port:            getEnv("PORT", "8080"),
+
+		AIRequestsPerMinute: aiRPM,
+		AITokensPerMinute:   aiTPM,
+		AIMaxRetries:        aiMaxRetries,
+		AIDailyBudgetUSD:    aiDailyBudget,
+		AIScanBudgetUSD:     aiScanBudget,
+
+		ScanStatWorkers:   scanStatWorkers,
+		ScanHashWorkers:   scanHashWorkers,
+		ScanUpsertWorkers: scanUpsertWorkers,
+		ScanBatchSize:     scanBatchSize,
+		ScanQueueSize:     scanQueueSize,
+
+		MediaDir: getEnv("MEDIA_DIR", "media"),
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -37,15 +37,25 @@ func (q *Queries) CountSearchCategories(ctx context.Context, search string) (int
 }
 
 const createCategory = `-- name: CreateCategory :one
-INSERT INTO categories (name)
-VALUES ($1)
-RETURNING id, name, created_at
+INSERT INTO categories (name, parent_id)
+VALUES ($1, $2)
+RETURNING id, name, created_at, parent_id
 `
 
-func (q *Queries) CreateCategory(ctx context.Context, name string) (Category, error) {
-	row := q.db.QueryRow(ctx, createCategory, name)
+type CreateCategoryParams struct {
+	Name     string      `json:"name"`
+	ParentID pgtype.UUID `json:"parent_id"`
+}
+
+func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error) {
+	row := q.db.QueryRow(ctx, createCategory, arg.Name, arg.ParentID)
 	var i Category
-	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.ParentID,
+	)
 	return i, err
 }
 
@@ -59,7 +69,7 @@ func (q *Queries) DeleteCategory(ctx context.Context, id pgtype.UUID) error {
 }
 
 const getCategory = `-- name: GetCategory :one
-SELECT id, name, created_at FROM categories
+SELECT id, name, created_at, parent_id FROM categories
 WHERE id = $1 AND deleted_at IS NULL
 LIMIT 1
 `
@@ -67,12 +77,17 @@ LIMIT 1
 func (q *Queries) GetCategory(ctx context.Context, id pgtype.UUID) (Category, error) {
 	row := q.db.QueryRow(ctx, getCategory, id)
 	var i Category
-	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.ParentID,
+	)
 	return i, err
 }
 
 const getCategoryByName = `-- name: GetCategoryByName :one
-SELECT id, name, created_at FROM categories
+SELECT id, name, created_at, parent_id FROM categories
 WHERE name = $1 AND deleted_at IS NULL
 LIMIT 1
 `
@@ -80,12 +95,39 @@ LIMIT 1
 func (q *Queries) GetCategoryByName(ctx context.Context, name string) (Category, error) {
 	row := q.db.QueryRow(ctx, getCategoryByName, name)
 	var i Category
-	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.ParentID,
+	)
 	return i, err
 }
 
+const isCategoryDescendant = `-- name: IsCategoryDescendant :one
+WITH RECURSIVE subtree AS (
+  SELECT id FROM categories WHERE id = $1::uuid
+  UNION ALL
+  SELECT c.id FROM categories c
+  INNER JOIN subtree ON c.parent_id = subtree.id
+)
+SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $2::uuid) AS is_descendant
+`
+
+type IsCategoryDescendantParams struct {
+	AncestorID pgtype.UUID `json:"ancestor_id"`
+	CategoryID pgtype.UUID `json:"category_id"`
+}
+
+func (q *Queries) IsCategoryDescendant(ctx context.Context, arg IsCategoryDescendantParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isCategoryDescendant, arg.AncestorID, arg.CategoryID)
+	var isDescendant bool
+	err := row.Scan(&isDescendant)
+	return isDescendant, err
+}
+
 const listCategories = `-- name: ListCategories :many
-SELECT id, name, created_at FROM categories
+SELECT id, name, created_at, parent_id FROM categories
 WHERE deleted_at IS NULL
 ORDER BY name ASC
 `
@@ -99,7 +141,43 @@ func (q *Queries) ListCategories(ctx context.Context) ([]Category, error) {
 	items := []Category{}
 	for rows.Next() {
 		var i Category
-		if err := rows.Scan(&i.ID, &i.Name, &i.CreatedAt); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.ParentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategoriesByID = `-- name: ListCategoriesByID :many
+SELECT id, name, created_at, parent_id FROM categories
+WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+ORDER BY name ASC
+`
+
+func (q *Queries) ListCategoriesByID(ctx context.Context, ids []pgtype.UUID) ([]Category, error) {
+	rows, err := q.db.Query(ctx, listCategoriesByID, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.ParentID,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -111,19 +189,27 @@ func (q *Queries) ListCategories(ctx context.Context) ([]Category, error) {
 }
 
 const listCategoriesPaginated = `-- name: ListCategoriesPaginated :many
-SELECT id, name, created_at FROM categories
+SELECT id, name, created_at, parent_id FROM categories
 WHERE deleted_at IS NULL
-ORDER BY name ASC
+  AND ($3::uuid IS NULL OR (name, id) > ($4::citext, $3::uuid))
+ORDER BY name ASC, id ASC
 LIMIT $1 OFFSET $2
 `
 
 type ListCategoriesPaginatedParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	Limit     int32       `json:"limit"`
+	Offset    int32       `json:"offset"`
+	AfterID   pgtype.UUID `json:"after_id"`
+	AfterName string      `json:"after_name"`
 }
 
 func (q *Queries) ListCategoriesPaginated(ctx context.Context, arg ListCategoriesPaginatedParams) ([]Category, error) {
-	rows, err := q.db.Query(ctx, listCategoriesPaginated, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listCategoriesPaginated,
+		arg.Limit,
+		arg.Offset,
+		arg.AfterID,
+		arg.AfterName,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +217,12 @@ func (q *Queries) ListCategoriesPaginated(ctx context.Context, arg ListCategorie
 	items := []Category{}
 	for rows.Next() {
 		var i Category
-		if err := rows.Scan(&i.ID, &i.Name, &i.CreatedAt); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.ParentID,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -142,6 +233,74 @@ func (q *Queries) ListCategoriesPaginated(ctx context.Context, arg ListCategorie
 	return items, nil
 }
 
+const listCategorySubtreeNames = `-- name: ListCategorySubtreeNames :many
+WITH RECURSIVE subtree AS (
+  SELECT id, name FROM categories WHERE categories.name = $1::text AND deleted_at IS NULL
+  UNION ALL
+  SELECT c.id, c.name FROM categories c
+  INNER JOIN subtree ON c.parent_id = subtree.id
+  WHERE c.deleted_at IS NULL
+)
+SELECT name FROM subtree
+`
+
+func (q *Queries) ListCategorySubtreeNames(ctx context.Context, name string) ([]string, error) {
+	rows, err := q.db.Query(ctx, listCategorySubtreeNames, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reparentCategories = `-- name: ReparentCategories :exec
+UPDATE categories SET parent_id = $1::uuid
+WHERE parent_id = ANY($2::uuid[])
+`
+
+type ReparentCategoriesParams struct {
+	ParentID  pgtype.UUID   `json:"parent_id"`
+	SourceIds []pgtype.UUID `json:"source_ids"`
+}
+
+func (q *Queries) ReparentCategories(ctx context.Context, arg ReparentCategoriesParams) error {
+	_, err := q.db.Exec(ctx, reparentCategories, arg.ParentID, arg.SourceIds)
+	return err
+}
+
+const resolveCategoryName = `-- name: ResolveCategoryName :one
+SELECT c.id, c.name, c.created_at, c.parent_id FROM categories c
+WHERE c.deleted_at IS NULL
+  AND (c.name = $1::text OR c.id = (
+    SELECT a.category_id FROM category_aliases a WHERE a.alias = $1::text
+  ))
+ORDER BY (c.name = $1::text) DESC
+LIMIT 1
+`
+
+func (q *Queries) ResolveCategoryName(ctx context.Context, name string) (Category, error) {
+	row := q.db.QueryRow(ctx, resolveCategoryName, name)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.ParentID,
+	)
+	return i, err
+}
+
 const restoreCategory = `-- name: RestoreCategory :exec
 UPDATE categories
 SET deleted_at = NULL
@@ -154,21 +313,30 @@ func (q *Queries) RestoreCategory(ctx context.Context, id pgtype.UUID) error {
 }
 
 const searchCategoriesPaginated = `-- name: SearchCategoriesPaginated :many
-SELECT id, name, created_at FROM categories
+SELECT id, name, created_at, parent_id FROM categories
 WHERE deleted_at IS NULL
   AND name ILIKE '%' || $3::text || '%'
-ORDER BY name ASC
+  AND ($4::uuid IS NULL OR (name, id) > ($5::citext, $4::uuid))
+ORDER BY name ASC, id ASC
 LIMIT $1 OFFSET $2
 `
 
 type SearchCategoriesPaginatedParams struct {
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
-	Search string `json:"search"`
+	Limit     int32       `json:"limit"`
+	Offset    int32       `json:"offset"`
+	Search    string      `json:"search"`
+	AfterID   pgtype.UUID `json:"after_id"`
+	AfterName string      `json:"after_name"`
 }
 
 func (q *Queries) SearchCategoriesPaginated(ctx context.Context, arg SearchCategoriesPaginatedParams) ([]Category, error) {
-	rows, err := q.db.Query(ctx, searchCategoriesPaginated, arg.Limit, arg.Offset, arg.Search)
+	rows, err := q.db.Query(ctx, searchCategoriesPaginated,
+		arg.Limit,
+		arg.Offset,
+		arg.Search,
+		arg.AfterID,
+		arg.AfterName,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +344,12 @@ func (q *Queries) SearchCategoriesPaginated(ctx context.Context, arg SearchCateg
 	items := []Category{}
 	for rows.Next() {
 		var i Category
-		if err := rows.Scan(&i.ID, &i.Name, &i.CreatedAt); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.ParentID,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -187,6 +360,17 @@ func (q *Queries) SearchCategoriesPaginated(ctx context.Context, arg SearchCateg
 	return items, nil
 }
 
+const softDeleteCategories = `-- name: SoftDeleteCategories :exec
+UPDATE categories
+SET deleted_at = NOW()
+WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteCategories(ctx context.Context, ids []pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, softDeleteCategories, ids)
+	return err
+}
+
 const softDeleteCategory = `-- name: SoftDeleteCategory :exec
 UPDATE categories
 SET deleted_at = NOW()
@@ -200,19 +384,25 @@ func (q *Queries) SoftDeleteCategory(ctx context.Context, id pgtype.UUID) error
 
 const updateCategory = `-- name: UpdateCategory :one
 UPDATE categories
-SET name = $2
+SET name = $2, parent_id = $3
 WHERE id = $1 AND deleted_at IS NULL
-RETURNING id, name, created_at
+RETURNING id, name, created_at, parent_id
 `
 
 type UpdateCategoryParams struct {
-	ID   pgtype.UUID `json:"id"`
-	Name string      `json:"name"`
+	ID       pgtype.UUID `json:"id"`
+	Name     string      `json:"name"`
+	ParentID pgtype.UUID `json:"parent_id"`
 }
 
 func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error) {
-	row := q.db.QueryRow(ctx, updateCategory, arg.ID, arg.Name)
+	row := q.db.QueryRow(ctx, updateCategory, arg.ID, arg.Name, arg.ParentID)
 	var i Category
-	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.ParentID,
+	)
 	return i, err
 }
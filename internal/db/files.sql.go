@@ -47,7 +47,7 @@ func (q *Queries) CountRootFiles(ctx context.Context) (int64, error) {
 const createFile = `-- name: CreateFile :one
 INSERT INTO files (path, file_name, type, size, modified_at, sha256)
 VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at
+RETURNING id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at, classified_at, favorite, rating, note
 `
 
 type CreateFileParams struct {
@@ -80,6 +80,10 @@ func (q *Queries) CreateFile(ctx context.Context, arg CreateFileParams) (File, e
 		&i.FolderID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ClassifiedAt,
+		&i.Favorite,
+		&i.Rating,
+		&i.Note,
 	)
 	return i, err
 }
@@ -93,8 +97,20 @@ func (q *Queries) DeleteFile(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const deleteFilesByID = `-- name: DeleteFilesByID :execrows
+DELETE FROM files WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) DeleteFilesByID(ctx context.Context, ids []pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteFilesByID, ids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getFile = `-- name: GetFile :one
-SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at FROM files WHERE id = $1 LIMIT 1
+SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at, classified_at, favorite, rating, note FROM files WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetFile(ctx context.Context, id pgtype.UUID) (File, error) {
@@ -111,12 +127,16 @@ func (q *Queries) GetFile(ctx context.Context, id pgtype.UUID) (File, error) {
 		&i.FolderID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ClassifiedAt,
+		&i.Favorite,
+		&i.Rating,
+		&i.Note,
 	)
 	return i, err
 }
 
 const getFileByPath = `-- name: GetFileByPath :one
-SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at FROM files WHERE path = $1 LIMIT 1
+SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at, classified_at, favorite, rating, note FROM files WHERE path = $1 LIMIT 1
 `
 
 func (q *Queries) GetFileByPath(ctx context.Context, path string) (File, error) {
@@ -133,12 +153,68 @@ func (q *Queries) GetFileByPath(ctx context.Context, path string) (File, error)
 		&i.FolderID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ClassifiedAt,
+		&i.Favorite,
+		&i.Rating,
+		&i.Note,
 	)
 	return i, err
 }
 
+const getFilesByIDs = `-- name: GetFilesByIDs :many
+SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at, classified_at, favorite, rating, note FROM files
+WHERE id = ANY($1::uuid[])
+ORDER BY path
+`
+
+func (q *Queries) GetFilesByIDs(ctx context.Context, ids []pgtype.UUID) ([]File, error) {
+	rows, err := q.db.Query(ctx, getFilesByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []File{}
+	for rows.Next() {
+		var i File
+		if err := rows.Scan(
+			&i.ID,
+			&i.Path,
+			&i.FileName,
+			&i.Type,
+			&i.Size,
+			&i.ModifiedAt,
+			&i.Sha256,
+			&i.FolderID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClassifiedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const hasFilesUnder = `-- name: HasFilesUnder :one
+SELECT EXISTS (SELECT 1 FROM files WHERE starts_with(path, $1::text)) AS has_files
+`
+
+func (q *Queries) HasFilesUnder(ctx context.Context, prefix string) (bool, error) {
+	row := q.db.QueryRow(ctx, hasFilesUnder, prefix)
+	var hasFiles bool
+	err := row.Scan(&hasFiles)
+	return hasFiles, err
+}
+
 const listAllFiles = `-- name: ListAllFiles :many
-SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at FROM files
+SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at, classified_at, favorite, rating, note FROM files
 ORDER BY file_name ASC
 `
 
@@ -162,6 +238,10 @@ func (q *Queries) ListAllFiles(ctx context.Context) ([]File, error) {
 			&i.FolderID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ClassifiedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
 		); err != nil {
 			return nil, err
 		}
@@ -174,7 +254,7 @@ func (q *Queries) ListAllFiles(ctx context.Context) ([]File, error) {
 }
 
 const listAllFilesPaginated = `-- name: ListAllFilesPaginated :many
-SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at FROM files
+SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at, classified_at, favorite, rating, note FROM files
 ORDER BY file_name ASC
 LIMIT $1 OFFSET $2
 `
@@ -204,6 +284,10 @@ func (q *Queries) ListAllFilesPaginated(ctx context.Context, arg ListAllFilesPag
 			&i.FolderID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ClassifiedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
 		); err != nil {
 			return nil, err
 		}
@@ -216,7 +300,7 @@ func (q *Queries) ListAllFilesPaginated(ctx context.Context, arg ListAllFilesPag
 }
 
 const listFiles = `-- name: ListFiles :many
-SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at FROM files
+SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at, classified_at, favorite, rating, note FROM files
 ORDER BY file_name ASC
 LIMIT $1 OFFSET $2
 `
@@ -246,6 +330,10 @@ func (q *Queries) ListFiles(ctx context.Context, arg ListFilesParams) ([]File, e
 			&i.FolderID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ClassifiedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
 		); err != nil {
 			return nil, err
 		}
@@ -257,14 +345,13 @@ func (q *Queries) ListFiles(ctx context.Context, arg ListFilesParams) ([]File, e
 	return items, nil
 }
 
-const listRootFiles = `-- name: ListRootFiles :many
-SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at FROM files
-WHERE folder_id IS NULL
-ORDER BY file_name ASC
+const listFilesInDir = `-- name: ListFilesInDir :many
+SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at, classified_at, favorite, rating, note FROM files
+WHERE left(path, length(path) - length(file_name) - 1) = $1::text
 `
 
-func (q *Queries) ListRootFiles(ctx context.Context) ([]File, error) {
-	rows, err := q.db.Query(ctx, listRootFiles)
+func (q *Queries) ListFilesInDir(ctx context.Context, dir string) ([]File, error) {
+	rows, err := q.db.Query(ctx, listFilesInDir, dir)
 	if err != nil {
 		return nil, err
 	}
@@ -283,6 +370,10 @@ func (q *Queries) ListRootFiles(ctx context.Context) ([]File, error) {
 			&i.FolderID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ClassifiedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
 		); err != nil {
 			return nil, err
 		}
@@ -294,20 +385,19 @@ func (q *Queries) ListRootFiles(ctx context.Context) ([]File, error) {
 	return items, nil
 }
 
-const listRootFilesPaginated = `-- name: ListRootFilesPaginated :many
-SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at FROM files
-WHERE folder_id IS NULL
-ORDER BY file_name ASC
-LIMIT $1 OFFSET $2
+const listFilesOutsideDirs = `-- name: ListFilesOutsideDirs :many
+SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at, classified_at, favorite, rating, note FROM files
+WHERE starts_with(path, $1::text)
+  AND NOT (left(path, length(path) - length(file_name) - 1) = ANY($2::text[]))
 `
 
-type ListRootFilesPaginatedParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+type ListFilesOutsideDirsParams struct {
+	Prefix string   `json:"prefix"`
+	Dirs   []string `json:"dirs"`
 }
 
-func (q *Queries) ListRootFilesPaginated(ctx context.Context, arg ListRootFilesPaginatedParams) ([]File, error) {
-	rows, err := q.db.Query(ctx, listRootFilesPaginated, arg.Limit, arg.Offset)
+func (q *Queries) ListFilesOutsideDirs(ctx context.Context, arg ListFilesOutsideDirsParams) ([]File, error) {
+	rows, err := q.db.Query(ctx, listFilesOutsideDirs, arg.Prefix, arg.Dirs)
 	if err != nil {
 		return nil, err
 	}
@@ -326,6 +416,10 @@ func (q *Queries) ListRootFilesPaginated(ctx context.Context, arg ListRootFilesP
 			&i.FolderID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ClassifiedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
 		); err != nil {
 			return nil, err
 		}
@@ -337,53 +431,222 @@ func (q *Queries) ListRootFilesPaginated(ctx context.Context, arg ListRootFilesP
 	return items, nil
 }
 
-const searchFiles = `-- name: SearchFiles :many
-SELECT
-  f.id, f.path, f.file_name, f.type, f.size, f.modified_at, f.sha256, f.folder_id, f.created_at, f.updated_at,
-  similarity(f.file_name, $1) as sim
-FROM files f
-WHERE
-  ($1 = '' OR f.file_name % $1 OR f.path % $1)
-  AND ($2 = '' OR f.type = $2)
-ORDER BY sim DESC, f.file_name ASC
-LIMIT $3 OFFSET $4
+const listMoveCandidates = `-- name: ListMoveCandidates :many
+SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at, classified_at, favorite, rating, note FROM files
+WHERE starts_with(path, $1::text)
+  AND (($2::text <> '' AND sha256 = $2::text)
+    OR (file_name = $3::text AND size = $4::bigint AND modified_at = $5::timestamptz))
+ORDER BY ($2::text <> '' AND sha256 IS NOT DISTINCT FROM $2::text) DESC, path
 `
 
-type SearchFilesParams struct {
-	Similarity string      `json:"similarity"`
-	Column2    interface{} `json:"column_2"`
-	Limit      int32       `json:"limit"`
-	Offset     int32       `json:"offset"`
-}
-
-type SearchFilesRow struct {
-	ID         pgtype.UUID        `json:"id"`
-	Path       string             `json:"path"`
+type ListMoveCandidatesParams struct {
+	Prefix     string             `json:"prefix"`
+	Sha256     string             `json:"sha256"`
 	FileName   string             `json:"file_name"`
-	Type       string             `json:"type"`
 	Size       int64              `json:"size"`
 	ModifiedAt pgtype.Timestamptz `json:"modified_at"`
-	Sha256     pgtype.Text        `json:"sha256"`
-	FolderID   pgtype.UUID        `json:"folder_id"`
-	CreatedAt  pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
-	Sim        float32            `json:"sim"`
 }
 
-func (q *Queries) SearchFiles(ctx context.Context, arg SearchFilesParams) ([]SearchFilesRow, error) {
-	rows, err := q.db.Query(ctx, searchFiles,
-		arg.Similarity,
-		arg.Column2,
+func (q *Queries) ListMoveCandidates(ctx context.Context, arg ListMoveCandidatesParams) ([]File, error) {
+	rows, err := q.db.Query(ctx, listMoveCandidates,
+		arg.Prefix,
+		arg.Sha256,
+		arg.FileName,
+		arg.Size,
+		arg.ModifiedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []File{}
+	for rows.Next() {
+		var i File
+		if err := rows.Scan(
+			&i.ID,
+			&i.Path,
+			&i.FileName,
+			&i.Type,
+			&i.Size,
+			&i.ModifiedAt,
+			&i.Sha256,
+			&i.FolderID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClassifiedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReclassifyCandidates = `-- name: ListReclassifyCandidates :many
+SELECT f.id, f.path, f.file_name, f.type, f.size, f.modified_at, f.sha256, f.folder_id, f.created_at, f.updated_at, f.classified_at, f.favorite, f.rating, f.note FROM files f
+WHERE ($1::text = '' OR EXISTS (
+    SELECT 1 FROM files_categories fc
+    WHERE fc.file_id = f.id AND fc.category_id IN (
+      WITH RECURSIVE subtree AS (
+        SELECT id FROM categories WHERE name = $1::text AND deleted_at IS NULL
+        UNION ALL
+        SELECT c.id FROM categories c
+        INNER JOIN subtree ON c.parent_id = subtree.id
+        WHERE c.deleted_at IS NULL
+      )
+      SELECT id FROM subtree
+    )
+  ) OR ($1::text = 'uncategorized' AND NOT EXISTS (
+    SELECT 1 FROM files_categories fc WHERE fc.file_id = f.id
+  )))
+  AND ($2::uuid IS NULL OR f.folder_id IN (
+    WITH RECURSIVE subtree AS (
+      SELECT id FROM folders WHERE id = $2::uuid
+      UNION ALL
+      SELECT sub.id FROM folders sub
+      INNER JOIN subtree ON sub.parent_folder_id = subtree.id
+    )
+    SELECT id FROM subtree
+  ))
+  AND ($3::text = '' OR f.type = $3::text)
+  AND ($4::timestamptz IS NULL OR f.classified_at IS NULL OR f.classified_at < $4::timestamptz)
+  AND (cardinality($5::uuid[]) = 0 OR f.id = ANY($5::uuid[]))
+  AND (NOT $6::boolean OR EXISTS (
+    SELECT 1 FROM classification_queue q WHERE q.file_id = f.id
+  ))
+  AND NOT EXISTS (
+    SELECT 1 FROM files_categories fc WHERE fc.file_id = f.id AND fc.source = 'manual'
+  )
+ORDER BY f.path
+`
+
+type ListReclassifyCandidatesParams struct {
+	Category         string             `json:"category"`
+	FolderID         pgtype.UUID        `json:"folder_id"`
+	Type             string             `json:"type"`
+	ClassifiedBefore pgtype.Timestamptz `json:"classified_before"`
+	FileIds          []pgtype.UUID      `json:"file_ids"`
+	Queued           bool               `json:"queued"`
+}
+
+func (q *Queries) ListReclassifyCandidates(ctx context.Context, arg ListReclassifyCandidatesParams) ([]File, error) {
+	rows, err := q.db.Query(ctx, listReclassifyCandidates,
+		arg.Category,
+		arg.FolderID,
+		arg.Type,
+		arg.ClassifiedBefore,
+		arg.FileIds,
+		arg.Queued,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []File{}
+	for rows.Next() {
+		var i File
+		if err := rows.Scan(
+			&i.ID,
+			&i.Path,
+			&i.FileName,
+			&i.Type,
+			&i.Size,
+			&i.ModifiedAt,
+			&i.Sha256,
+			&i.FolderID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClassifiedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRootFiles = `-- name: ListRootFiles :many
+SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at, classified_at, favorite, rating, note FROM files
+WHERE folder_id IS NULL
+ORDER BY file_name ASC
+`
+
+func (q *Queries) ListRootFiles(ctx context.Context) ([]File, error) {
+	rows, err := q.db.Query(ctx, listRootFiles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []File{}
+	for rows.Next() {
+		var i File
+		if err := rows.Scan(
+			&i.ID,
+			&i.Path,
+			&i.FileName,
+			&i.Type,
+			&i.Size,
+			&i.ModifiedAt,
+			&i.Sha256,
+			&i.FolderID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClassifiedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRootFilesPaginated = `-- name: ListRootFilesPaginated :many
+SELECT id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at, classified_at, favorite, rating, note FROM files
+WHERE folder_id IS NULL
+  AND ($3::uuid IS NULL OR (file_name, id) > ($4::text, $3::uuid))
+ORDER BY file_name ASC, id ASC
+LIMIT $1 OFFSET $2
+`
+
+type ListRootFilesPaginatedParams struct {
+	Limit     int32       `json:"limit"`
+	Offset    int32       `json:"offset"`
+	AfterID   pgtype.UUID `json:"after_id"`
+	AfterName string      `json:"after_name"`
+}
+
+func (q *Queries) ListRootFilesPaginated(ctx context.Context, arg ListRootFilesPaginatedParams) ([]File, error) {
+	rows, err := q.db.Query(ctx, listRootFilesPaginated,
 		arg.Limit,
 		arg.Offset,
+		arg.AfterID,
+		arg.AfterName,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []SearchFilesRow{}
+	items := []File{}
 	for rows.Next() {
-		var i SearchFilesRow
+		var i File
 		if err := rows.Scan(
 			&i.ID,
 			&i.Path,
@@ -395,7 +658,10 @@ func (q *Queries) SearchFiles(ctx context.Context, arg SearchFilesParams) ([]Sea
 			&i.FolderID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.Sim,
+			&i.ClassifiedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
 		); err != nil {
 			return nil, err
 		}
@@ -407,11 +673,53 @@ func (q *Queries) SearchFiles(ctx context.Context, arg SearchFilesParams) ([]Sea
 	return items, nil
 }
 
+const markFileClassified = `-- name: MarkFileClassified :exec
+UPDATE files SET classified_at = now() WHERE id = $1
+`
+
+func (q *Queries) MarkFileClassified(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, markFileClassified, id)
+	return err
+}
+
+const moveFile = `-- name: MoveFile :exec
+UPDATE files SET path = $2, folder_id = $3, updated_at = now() WHERE id = $1
+`
+
+type MoveFileParams struct {
+	ID       pgtype.UUID `json:"id"`
+	Path     string      `json:"path"`
+	FolderID pgtype.UUID `json:"folder_id"`
+}
+
+func (q *Queries) MoveFile(ctx context.Context, arg MoveFileParams) error {
+	_, err := q.db.Exec(ctx, moveFile, arg.ID, arg.Path, arg.FolderID)
+	return err
+}
+
+const setFilesFavorite = `-- name: SetFilesFavorite :execrows
+UPDATE files SET favorite = $1, updated_at = now()
+WHERE id = ANY($2::uuid[])
+`
+
+type SetFilesFavoriteParams struct {
+	Favorite bool          `json:"favorite"`
+	Ids      []pgtype.UUID `json:"ids"`
+}
+
+func (q *Queries) SetFilesFavorite(ctx context.Context, arg SetFilesFavoriteParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setFilesFavorite, arg.Favorite, arg.Ids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateFile = `-- name: UpdateFile :one
 UPDATE files
 SET file_name = $2, type = $3, size = $4, modified_at = $5, sha256 = $6, updated_at = now()
 WHERE path = $1
-RETURNING id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at
+RETURNING id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at, classified_at, favorite, rating, note
 `
 
 type UpdateFileParams struct {
@@ -444,6 +752,50 @@ func (q *Queries) UpdateFile(ctx context.Context, arg UpdateFileParams) (File, e
 		&i.FolderID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ClassifiedAt,
+		&i.Favorite,
+		&i.Rating,
+		&i.Note,
+	)
+	return i, err
+}
+
+const updateFileMetadata = `-- name: UpdateFileMetadata :one
+UPDATE files SET favorite = $1, rating = $2, note = $3, updated_at = now()
+WHERE id = $4
+RETURNING id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at, classified_at, favorite, rating, note
+`
+
+type UpdateFileMetadataParams struct {
+	Favorite bool        `json:"favorite"`
+	Rating   pgtype.Int2 `json:"rating"`
+	Note     string      `json:"note"`
+	ID       pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateFileMetadata(ctx context.Context, arg UpdateFileMetadataParams) (File, error) {
+	row := q.db.QueryRow(ctx, updateFileMetadata,
+		arg.Favorite,
+		arg.Rating,
+		arg.Note,
+		arg.ID,
+	)
+	var i File
+	err := row.Scan(
+		&i.ID,
+		&i.Path,
+		&i.FileName,
+		&i.Type,
+		&i.Size,
+		&i.ModifiedAt,
+		&i.Sha256,
+		&i.FolderID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClassifiedAt,
+		&i.Favorite,
+		&i.Rating,
+		&i.Note,
 	)
 	return i, err
 }
@@ -457,10 +809,14 @@ DO UPDATE SET
   type = EXCLUDED.type,
   size = EXCLUDED.size,
   modified_at = EXCLUDED.modified_at,
-  sha256 = EXCLUDED.sha256,
+  -- Keep the stored hash when the scan did not hash and the file did not change
+  sha256 = CASE
+    WHEN EXCLUDED.sha256 IS NOT NULL THEN EXCLUDED.sha256
+    WHEN files.size = EXCLUDED.size AND files.modified_at = EXCLUDED.modified_at THEN files.sha256
+  END,
   folder_id = EXCLUDED.folder_id,
   updated_at = now()
-RETURNING id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at
+RETURNING id, path, file_name, type, size, modified_at, sha256, folder_id, created_at, updated_at, classified_at, favorite, rating, note
 `
 
 type UpsertFileParams struct {
@@ -495,6 +851,75 @@ func (q *Queries) UpsertFile(ctx context.Context, arg UpsertFileParams) (File, e
 		&i.FolderID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ClassifiedAt,
+		&i.Favorite,
+		&i.Rating,
+		&i.Note,
 	)
 	return i, err
 }
+
+const upsertFiles = `-- name: UpsertFiles :many
+INSERT INTO files (path, file_name, type, size, modified_at, sha256, folder_id)
+SELECT t.path, t.file_name, t.type, t.size, t.modified_at, NULLIF(t.sha256, ''), t.folder_id
+FROM unnest(
+  $1::text[], $2::text[], $3::text[], $4::bigint[],
+  $5::timestamptz[], $6::text[], $7::uuid[]
+) AS t(path, file_name, type, size, modified_at, sha256, folder_id)
+ON CONFLICT (path)
+DO UPDATE SET
+  file_name = EXCLUDED.file_name,
+  type = EXCLUDED.type,
+  size = EXCLUDED.size,
+  modified_at = EXCLUDED.modified_at,
+  sha256 = CASE
+    WHEN EXCLUDED.sha256 IS NOT NULL THEN EXCLUDED.sha256
+    WHEN files.size = EXCLUDED.size AND files.modified_at = EXCLUDED.modified_at THEN files.sha256
+  END,
+  folder_id = EXCLUDED.folder_id,
+  updated_at = now()
+RETURNING id, path
+`
+
+type UpsertFilesParams struct {
+	Paths       []string             `json:"paths"`
+	FileNames   []string             `json:"file_names"`
+	Types       []string             `json:"types"`
+	Sizes       []int64              `json:"sizes"`
+	ModifiedAts []pgtype.Timestamptz `json:"modified_ats"`
+	Sha256s     []string             `json:"sha256s"`
+	FolderIds   []pgtype.UUID        `json:"folder_ids"`
+}
+
+type UpsertFilesRow struct {
+	ID   pgtype.UUID `json:"id"`
+	Path string      `json:"path"`
+}
+
+func (q *Queries) UpsertFiles(ctx context.Context, arg UpsertFilesParams) ([]UpsertFilesRow, error) {
+	rows, err := q.db.Query(ctx, upsertFiles,
+		arg.Paths,
+		arg.FileNames,
+		arg.Types,
+		arg.Sizes,
+		arg.ModifiedAts,
+		arg.Sha256s,
+		arg.FolderIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UpsertFilesRow{}
+	for rows.Next() {
+		var i UpsertFilesRow
+		if err := rows.Scan(&i.ID, &i.Path); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
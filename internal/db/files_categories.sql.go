@@ -27,6 +27,22 @@ func (q *Queries) AddFileCategory(ctx context.Context, arg AddFileCategoryParams
 	return err
 }
 
+const addManualFileCategory = `-- name: AddManualFileCategory :exec
+INSERT INTO files_categories (file_id, category_id, source)
+VALUES ($1, $2, 'manual')
+ON CONFLICT (file_id, category_id) DO UPDATE SET source = 'manual'
+`
+
+type AddManualFileCategoryParams struct {
+	FileID     pgtype.UUID `json:"file_id"`
+	CategoryID pgtype.UUID `json:"category_id"`
+}
+
+func (q *Queries) AddManualFileCategory(ctx context.Context, arg AddManualFileCategoryParams) error {
+	_, err := q.db.Exec(ctx, addManualFileCategory, arg.FileID, arg.CategoryID)
+	return err
+}
+
 const bulkAddFileCategories = `-- name: BulkAddFileCategories :exec
 INSERT INTO files_categories (file_id, category_id)
 SELECT UNNEST($1::uuid[]), UNNEST($2::uuid[])
@@ -43,6 +59,22 @@ func (q *Queries) BulkAddFileCategories(ctx context.Context, arg BulkAddFileCate
 	return err
 }
 
+const bulkAddManualFileCategories = `-- name: BulkAddManualFileCategories :exec
+INSERT INTO files_categories (file_id, category_id, source)
+SELECT UNNEST($1::uuid[]), UNNEST($2::uuid[]), 'manual'
+ON CONFLICT (file_id, category_id) DO UPDATE SET source = 'manual'
+`
+
+type BulkAddManualFileCategoriesParams struct {
+	FileIds     []pgtype.UUID `json:"file_ids"`
+	CategoryIds []pgtype.UUID `json:"category_ids"`
+}
+
+func (q *Queries) BulkAddManualFileCategories(ctx context.Context, arg BulkAddManualFileCategoriesParams) error {
+	_, err := q.db.Exec(ctx, bulkAddManualFileCategories, arg.FileIds, arg.CategoryIds)
+	return err
+}
+
 const bulkRemoveFileCategories = `-- name: BulkRemoveFileCategories :exec
 DELETE FROM files_categories WHERE file_id = ANY($1::uuid[])
 `
@@ -52,8 +84,47 @@ func (q *Queries) BulkRemoveFileCategories(ctx context.Context, fileIds []pgtype
 	return err
 }
 
+const bulkRemoveSelectedFileCategories = `-- name: BulkRemoveSelectedFileCategories :exec
+DELETE FROM files_categories
+WHERE file_id = ANY($1::uuid[]) AND category_id = ANY($2::uuid[])
+`
+
+type BulkRemoveSelectedFileCategoriesParams struct {
+	FileIds     []pgtype.UUID `json:"file_ids"`
+	CategoryIds []pgtype.UUID `json:"category_ids"`
+}
+
+func (q *Queries) BulkRemoveSelectedFileCategories(ctx context.Context, arg BulkRemoveSelectedFileCategoriesParams) error {
+	_, err := q.db.Exec(ctx, bulkRemoveSelectedFileCategories, arg.FileIds, arg.CategoryIds)
+	return err
+}
+
+const countManualFileCategories = `-- name: CountManualFileCategories :one
+SELECT COUNT(*) FROM files_categories
+WHERE file_id = $1 AND source = 'manual'
+`
+
+func (q *Queries) CountManualFileCategories(ctx context.Context, fileID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countManualFileCategories, fileID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteCategoryFileLinks = `-- name: DeleteCategoryFileLinks :execrows
+DELETE FROM files_categories WHERE category_id = ANY($1::uuid[])
+`
+
+func (q *Queries) DeleteCategoryFileLinks(ctx context.Context, categoryIds []pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCategoryFileLinks, categoryIds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getCategoriesBatch = `-- name: GetCategoriesBatch :many
-SELECT fc.file_id, c.id, c.name, c.created_at
+SELECT fc.file_id, c.id, c.name, c.created_at, c.parent_id
 FROM files_categories fc
 INNER JOIN categories c ON c.id = fc.category_id
 WHERE fc.file_id = ANY($1::uuid[])
@@ -65,6 +136,7 @@ type GetCategoriesBatchRow struct {
 	ID        pgtype.UUID        `json:"id"`
 	Name      string             `json:"name"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ParentID  pgtype.UUID        `json:"parent_id"`
 }
 
 func (q *Queries) GetCategoriesBatch(ctx context.Context, fileIds []pgtype.UUID) ([]GetCategoriesBatchRow, error) {
@@ -81,6 +153,7 @@ func (q *Queries) GetCategoriesBatch(ctx context.Context, fileIds []pgtype.UUID)
 			&i.ID,
 			&i.Name,
 			&i.CreatedAt,
+			&i.ParentID,
 		); err != nil {
 			return nil, err
 		}
@@ -93,7 +166,7 @@ func (q *Queries) GetCategoriesBatch(ctx context.Context, fileIds []pgtype.UUID)
 }
 
 const getFileCategories = `-- name: GetFileCategories :many
-SELECT c.id, c.name, c.created_at FROM categories c
+SELECT c.id, c.name, c.created_at, c.parent_id FROM categories c
 INNER JOIN files_categories fc ON fc.category_id = c.id
 WHERE fc.file_id = $1
 ORDER BY c.name ASC
@@ -108,7 +181,12 @@ func (q *Queries) GetFileCategories(ctx context.Context, fileID pgtype.UUID) ([]
 	items := []Category{}
 	for rows.Next() {
 		var i Category
-		if err := rows.Scan(&i.ID, &i.Name, &i.CreatedAt); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.ParentID,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -119,50 +197,35 @@ func (q *Queries) GetFileCategories(ctx context.Context, fileID pgtype.UUID) ([]
 	return items, nil
 }
 
-const getFilesByCategory = `-- name: GetFilesByCategory :many
-SELECT f.id, f.path, f.file_name, f.type, f.size, f.modified_at, f.sha256, f.folder_id, f.created_at, f.updated_at FROM files f
-INNER JOIN files_categories fc ON fc.file_id = f.id
-INNER JOIN categories c ON c.id = fc.category_id
-WHERE c.name = $1
-ORDER BY f.file_name ASC
-LIMIT $2 OFFSET $3
+const mergeFileCategories = `-- name: MergeFileCategories :exec
+INSERT INTO files_categories (file_id, category_id, source)
+SELECT DISTINCT ON (fc.file_id) fc.file_id, $1::uuid, fc.source
+FROM files_categories fc
+WHERE fc.category_id = ANY($2::uuid[])
+ORDER BY fc.file_id, (fc.source = 'manual') DESC
+ON CONFLICT (file_id, category_id) DO UPDATE SET source = CASE
+  WHEN EXCLUDED.source = 'manual' THEN 'manual'
+  ELSE files_categories.source
+END
 `
 
-type GetFilesByCategoryParams struct {
-	Name   string `json:"name"`
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
+type MergeFileCategoriesParams struct {
+	CategoryID pgtype.UUID   `json:"category_id"`
+	SourceIds  []pgtype.UUID `json:"source_ids"`
 }
 
-func (q *Queries) GetFilesByCategory(ctx context.Context, arg GetFilesByCategoryParams) ([]File, error) {
-	rows, err := q.db.Query(ctx, getFilesByCategory, arg.Name, arg.Limit, arg.Offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	items := []File{}
-	for rows.Next() {
-		var i File
-		if err := rows.Scan(
-			&i.ID,
-			&i.Path,
-			&i.FileName,
-			&i.Type,
-			&i.Size,
-			&i.ModifiedAt,
-			&i.Sha256,
-			&i.FolderID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) MergeFileCategories(ctx context.Context, arg MergeFileCategoriesParams) error {
+	_, err := q.db.Exec(ctx, mergeFileCategories, arg.CategoryID, arg.SourceIds)
+	return err
+}
+
+const removeAIFileCategories = `-- name: RemoveAIFileCategories :exec
+DELETE FROM files_categories WHERE file_id = $1 AND source = 'ai'
+`
+
+func (q *Queries) RemoveAIFileCategories(ctx context.Context, fileID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, removeAIFileCategories, fileID)
+	return err
 }
 
 const removeAllFileCategories = `-- name: RemoveAllFileCategories :exec
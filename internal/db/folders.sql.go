@@ -27,6 +27,73 @@ func (q *Queries) AddFolderCategory(ctx context.Context, arg AddFolderCategoryPa
 	return err
 }
 
+const addSubtreeFileCategories = `-- name: AddSubtreeFileCategories :execrows
+WITH RECURSIVE subtree AS (
+    SELECT folders.id, 0 AS depth FROM folders WHERE folders.id = $1
+    UNION ALL
+    SELECT f.id, s.depth + 1 FROM folders f
+    INNER JOIN subtree s ON f.parent_folder_id = s.id
+    WHERE s.depth < $2::int
+)
+INSERT INTO files_categories (file_id, category_id, source)
+SELECT files.id, category_id, 'manual'
+FROM files
+INNER JOIN subtree ON files.folder_id = subtree.id
+CROSS JOIN UNNEST($3::uuid[]) AS category_id
+WHERE lower(files.type) = ANY($4::text[])
+ON CONFLICT (file_id, category_id) DO UPDATE SET source = 'manual'
+`
+
+type AddSubtreeFileCategoriesParams struct {
+	FolderID    pgtype.UUID   `json:"folder_id"`
+	MaxDepth    int32         `json:"max_depth"`
+	CategoryIds []pgtype.UUID `json:"category_ids"`
+	Types       []string      `json:"types"`
+}
+
+func (q *Queries) AddSubtreeFileCategories(ctx context.Context, arg AddSubtreeFileCategoriesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addSubtreeFileCategories,
+		arg.FolderID,
+		arg.MaxDepth,
+		arg.CategoryIds,
+		arg.Types,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const addSubtreeFolderCategories = `-- name: AddSubtreeFolderCategories :execrows
+WITH RECURSIVE subtree AS (
+    SELECT folders.id, 0 AS depth FROM folders WHERE folders.id = $1
+    UNION ALL
+    SELECT f.id, s.depth + 1 FROM folders f
+    INNER JOIN subtree s ON f.parent_folder_id = s.id
+    WHERE s.depth < $2::int
+)
+INSERT INTO folders_categories (folder_id, category_id)
+SELECT subtree.id, category_id
+FROM subtree
+CROSS JOIN UNNEST($3::uuid[]) AS category_id
+WHERE subtree.depth > 0
+ON CONFLICT DO NOTHING
+`
+
+type AddSubtreeFolderCategoriesParams struct {
+	FolderID    pgtype.UUID   `json:"folder_id"`
+	MaxDepth    int32         `json:"max_depth"`
+	CategoryIds []pgtype.UUID `json:"category_ids"`
+}
+
+func (q *Queries) AddSubtreeFolderCategories(ctx context.Context, arg AddSubtreeFolderCategoriesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addSubtreeFolderCategories, arg.FolderID, arg.MaxDepth, arg.CategoryIds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const bulkAddFolderCategories = `-- name: BulkAddFolderCategories :exec
 INSERT INTO folders_categories (folder_id, category_id)
 SELECT UNNEST($1::uuid[]), UNNEST($2::uuid[])
@@ -64,12 +131,103 @@ func (q *Queries) CountFolderFiles(ctx context.Context, folderID pgtype.UUID) (i
 	return count, err
 }
 
+const countFolderPropagation = `-- name: CountFolderPropagation :one
+WITH RECURSIVE subtree AS (
+    SELECT folders.id, 0 AS depth FROM folders WHERE folders.id = $1
+    UNION ALL
+    SELECT f.id, s.depth + 1 FROM folders f
+    INNER JOIN subtree s ON f.parent_folder_id = s.id
+    WHERE s.depth < $2::int
+)
+SELECT
+    (SELECT COUNT(*) FROM subtree
+     WHERE subtree.depth > 0
+       AND CASE $3::text
+         WHEN 'remove' THEN EXISTS (
+           SELECT 1 FROM folders_categories fc
+           WHERE fc.folder_id = subtree.id AND fc.category_id = ANY($4::uuid[])
+         )
+         WHEN 'add' THEN EXISTS (
+           SELECT 1 FROM UNNEST($4::uuid[]) AS wanted(id)
+           WHERE NOT EXISTS (
+             SELECT 1 FROM folders_categories fc
+             WHERE fc.folder_id = subtree.id AND fc.category_id = wanted.id
+           )
+         )
+         ELSE EXISTS (
+           SELECT 1 FROM UNNEST($4::uuid[]) AS wanted(id)
+           WHERE NOT EXISTS (
+             SELECT 1 FROM folders_categories fc
+             WHERE fc.folder_id = subtree.id AND fc.category_id = wanted.id
+           )
+         ) OR EXISTS (
+           SELECT 1 FROM folders_categories fc
+           WHERE fc.folder_id = subtree.id AND NOT fc.category_id = ANY($4::uuid[])
+         )
+       END) AS folders,
+    (SELECT COUNT(*) FROM files
+     INNER JOIN subtree ON files.folder_id = subtree.id
+     WHERE lower(files.type) = ANY($5::text[])
+       AND CASE $3::text
+         WHEN 'remove' THEN EXISTS (
+           SELECT 1 FROM files_categories fc
+           WHERE fc.file_id = files.id AND fc.category_id = ANY($4::uuid[])
+         )
+         WHEN 'add' THEN EXISTS (
+           SELECT 1 FROM UNNEST($4::uuid[]) AS wanted(id)
+           WHERE NOT EXISTS (
+             SELECT 1 FROM files_categories fc
+             WHERE fc.file_id = files.id AND fc.category_id = wanted.id AND fc.source = 'manual'
+           )
+         )
+         ELSE EXISTS (
+           SELECT 1 FROM UNNEST($4::uuid[]) AS wanted(id)
+           WHERE NOT EXISTS (
+             SELECT 1 FROM files_categories fc
+             WHERE fc.file_id = files.id AND fc.category_id = wanted.id AND fc.source = 'manual'
+           )
+         ) OR EXISTS (
+           SELECT 1 FROM files_categories fc
+           WHERE fc.file_id = files.id AND NOT fc.category_id = ANY($4::uuid[])
+         )
+       END) AS files,
+    (SELECT COALESCE(MAX(subtree.depth), 0) FROM subtree)::int AS depth
+`
+
+type CountFolderPropagationParams struct {
+	FolderID    pgtype.UUID   `json:"folder_id"`
+	MaxDepth    int32         `json:"max_depth"`
+	Mode        string        `json:"mode"`
+	CategoryIds []pgtype.UUID `json:"category_ids"`
+	Types       []string      `json:"types"`
+}
+
+type CountFolderPropagationRow struct {
+	Folders int64 `json:"folders"`
+	Files   int64 `json:"files"`
+	Depth   int32 `json:"depth"`
+}
+
+func (q *Queries) CountFolderPropagation(ctx context.Context, arg CountFolderPropagationParams) (CountFolderPropagationRow, error) {
+	row := q.db.QueryRow(ctx, countFolderPropagation,
+		arg.FolderID,
+		arg.MaxDepth,
+		arg.Mode,
+		arg.CategoryIds,
+		arg.Types,
+	)
+	var i CountFolderPropagationRow
+	err := row.Scan(&i.Folders, &i.Files, &i.Depth)
+	return i, err
+}
+
 const countFolders = `-- name: CountFolders :one
 SELECT COUNT(*) FROM folders
+WHERE COALESCE(cardinality($1::text[]), 0) = 0 OR source_license = ANY($1::text[])
 `
 
-func (q *Queries) CountFolders(ctx context.Context) (int64, error) {
-	row := q.db.QueryRow(ctx, countFolders)
+func (q *Queries) CountFolders(ctx context.Context, licenses []string) (int64, error) {
+	row := q.db.QueryRow(ctx, countFolders, licenses)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -89,11 +247,19 @@ func (q *Queries) CountRootFolders(ctx context.Context) (int64, error) {
 
 const countSearchFolders = `-- name: CountSearchFolders :one
 SELECT COUNT(*) FROM folders
-WHERE name ILIKE '%' || $1::text || '%'
+WHERE (name ILIKE '%' || $1::text || '%'
+    OR to_tsvector('simple', search_tokens(name)) @@ search_query($1::text)
+    OR note ILIKE '%' || $1::text || '%')
+  AND (COALESCE(cardinality($2::text[]), 0) = 0 OR source_license = ANY($2::text[]))
 `
 
-func (q *Queries) CountSearchFolders(ctx context.Context, search string) (int64, error) {
-	row := q.db.QueryRow(ctx, countSearchFolders, search)
+type CountSearchFoldersParams struct {
+	Search   string   `json:"search"`
+	Licenses []string `json:"licenses"`
+}
+
+func (q *Queries) CountSearchFolders(ctx context.Context, arg CountSearchFoldersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchFolders, arg.Search, arg.Licenses)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -102,7 +268,9 @@ func (q *Queries) CountSearchFolders(ctx context.Context, search string) (int64,
 const countSearchRootFolders = `-- name: CountSearchRootFolders :one
 SELECT COUNT(*) FROM folders
 WHERE parent_folder_id IS NULL
-  AND name ILIKE '%' || $1::text || '%'
+  AND (name ILIKE '%' || $1::text || '%'
+    OR to_tsvector('simple', search_tokens(name)) @@ search_query($1::text)
+    OR note ILIKE '%' || $1::text || '%')
 `
 
 func (q *Queries) CountSearchRootFolders(ctx context.Context, search string) (int64, error) {
@@ -127,7 +295,7 @@ func (q *Queries) CountSubfolders(ctx context.Context, parentFolderID pgtype.UUI
 const createFolder = `-- name: CreateFolder :one
 INSERT INTO folders (name, path)
 VALUES ($1, $2)
-RETURNING id, name, path, parent_folder_id, created_at, updated_at
+RETURNING id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at
 `
 
 type CreateFolderParams struct {
@@ -145,6 +313,16 @@ func (q *Queries) CreateFolder(ctx context.Context, arg CreateFolderParams) (Fol
 		&i.ParentFolderID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Favorite,
+		&i.Rating,
+		&i.Note,
+		&i.SourceDesigner,
+		&i.SourceUrl,
+		&i.SourceLicense,
+		&i.SourceDescription,
+		&i.SourceFiles,
+		&i.PrimaryImageID,
+		&i.ContentsModifiedAt,
 	)
 	return i, err
 }
@@ -152,7 +330,7 @@ func (q *Queries) CreateFolder(ctx context.Context, arg CreateFolderParams) (Fol
 const createFolderWithParent = `-- name: CreateFolderWithParent :one
 INSERT INTO folders (name, path, parent_folder_id)
 VALUES ($1, $2, $3)
-RETURNING id, name, path, parent_folder_id, created_at, updated_at
+RETURNING id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at
 `
 
 type CreateFolderWithParentParams struct {
@@ -171,10 +349,50 @@ func (q *Queries) CreateFolderWithParent(ctx context.Context, arg CreateFolderWi
 		&i.ParentFolderID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Favorite,
+		&i.Rating,
+		&i.Note,
+		&i.SourceDesigner,
+		&i.SourceUrl,
+		&i.SourceLicense,
+		&i.SourceDescription,
+		&i.SourceFiles,
+		&i.PrimaryImageID,
+		&i.ContentsModifiedAt,
 	)
 	return i, err
 }
 
+const deleteCategoryFolderLinks = `-- name: DeleteCategoryFolderLinks :execrows
+DELETE FROM folders_categories WHERE category_id = ANY($1::uuid[])
+`
+
+func (q *Queries) DeleteCategoryFolderLinks(ctx context.Context, categoryIds []pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCategoryFolderLinks, categoryIds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteEmptyFolders = `-- name: DeleteEmptyFolders :execrows
+DELETE FROM folders f
+WHERE (f.path = $1::text OR starts_with(f.path, $1::text || '/'))
+  AND NOT EXISTS (
+    SELECT 1 FROM files fi
+    JOIN folders d ON d.id = fi.folder_id
+    WHERE d.path = f.path OR starts_with(d.path, f.path || '/')
+  )
+`
+
+func (q *Queries) DeleteEmptyFolders(ctx context.Context, dir string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteEmptyFolders, dir)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteFolder = `-- name: DeleteFolder :exec
 DELETE FROM folders
 WHERE id = $1
@@ -185,8 +403,63 @@ func (q *Queries) DeleteFolder(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const deleteSubtreeFileCategories = `-- name: DeleteSubtreeFileCategories :execrows
+WITH RECURSIVE subtree AS (
+    SELECT folders.id, 0 AS depth FROM folders WHERE folders.id = $1
+    UNION ALL
+    SELECT f.id, s.depth + 1 FROM folders f
+    INNER JOIN subtree s ON f.parent_folder_id = s.id
+    WHERE s.depth < $2::int
+)
+DELETE FROM files_categories
+WHERE file_id IN (
+    SELECT files.id FROM files
+    INNER JOIN subtree ON files.folder_id = subtree.id
+    WHERE lower(files.type) = ANY($3::text[])
+)
+`
+
+type DeleteSubtreeFileCategoriesParams struct {
+	FolderID pgtype.UUID `json:"folder_id"`
+	MaxDepth int32       `json:"max_depth"`
+	Types    []string    `json:"types"`
+}
+
+func (q *Queries) DeleteSubtreeFileCategories(ctx context.Context, arg DeleteSubtreeFileCategoriesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSubtreeFileCategories, arg.FolderID, arg.MaxDepth, arg.Types)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteSubtreeFolderCategories = `-- name: DeleteSubtreeFolderCategories :execrows
+WITH RECURSIVE subtree AS (
+    SELECT folders.id, 0 AS depth FROM folders WHERE folders.id = $1
+    UNION ALL
+    SELECT f.id, s.depth + 1 FROM folders f
+    INNER JOIN subtree s ON f.parent_folder_id = s.id
+    WHERE s.depth < $2::int
+)
+DELETE FROM folders_categories
+WHERE folder_id IN (SELECT subtree.id FROM subtree WHERE subtree.depth > 0)
+`
+
+type DeleteSubtreeFolderCategoriesParams struct {
+	FolderID pgtype.UUID `json:"folder_id"`
+	MaxDepth int32       `json:"max_depth"`
+}
+
+func (q *Queries) DeleteSubtreeFolderCategories(ctx context.Context, arg DeleteSubtreeFolderCategoriesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSubtreeFolderCategories, arg.FolderID, arg.MaxDepth)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getFolder = `-- name: GetFolder :one
-SELECT id, name, path, parent_folder_id, created_at, updated_at FROM folders
+SELECT id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at FROM folders
 WHERE id = $1
 `
 
@@ -200,12 +473,22 @@ func (q *Queries) GetFolder(ctx context.Context, id pgtype.UUID) (Folder, error)
 		&i.ParentFolderID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Favorite,
+		&i.Rating,
+		&i.Note,
+		&i.SourceDesigner,
+		&i.SourceUrl,
+		&i.SourceLicense,
+		&i.SourceDescription,
+		&i.SourceFiles,
+		&i.PrimaryImageID,
+		&i.ContentsModifiedAt,
 	)
 	return i, err
 }
 
 const getFolderByPath = `-- name: GetFolderByPath :one
-SELECT id, name, path, parent_folder_id, created_at, updated_at FROM folders
+SELECT id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at FROM folders
 WHERE path = $1
 `
 
@@ -219,12 +502,22 @@ func (q *Queries) GetFolderByPath(ctx context.Context, path string) (Folder, err
 		&i.ParentFolderID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Favorite,
+		&i.Rating,
+		&i.Note,
+		&i.SourceDesigner,
+		&i.SourceUrl,
+		&i.SourceLicense,
+		&i.SourceDescription,
+		&i.SourceFiles,
+		&i.PrimaryImageID,
+		&i.ContentsModifiedAt,
 	)
 	return i, err
 }
 
 const getFolderCategories = `-- name: GetFolderCategories :many
-SELECT c.id, c.name, c.created_at FROM categories c
+SELECT c.id, c.name, c.created_at, c.parent_id FROM categories c
 INNER JOIN folders_categories fc ON c.id = fc.category_id
 WHERE fc.folder_id = $1
 ORDER BY c.name
@@ -239,7 +532,12 @@ func (q *Queries) GetFolderCategories(ctx context.Context, folderID pgtype.UUID)
 	items := []Category{}
 	for rows.Next() {
 		var i Category
-		if err := rows.Scan(&i.ID, &i.Name, &i.CreatedAt); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.ParentID,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -251,7 +549,7 @@ func (q *Queries) GetFolderCategories(ctx context.Context, folderID pgtype.UUID)
 }
 
 const getFolderCategoriesBatch = `-- name: GetFolderCategoriesBatch :many
-SELECT fc.folder_id, c.id, c.name, c.created_at
+SELECT fc.folder_id, c.id, c.name, c.created_at, c.parent_id
 FROM folders_categories fc
 INNER JOIN categories c ON c.id = fc.category_id
 WHERE fc.folder_id = ANY($1::uuid[])
@@ -263,6 +561,7 @@ type GetFolderCategoriesBatchRow struct {
 	ID        pgtype.UUID        `json:"id"`
 	Name      string             `json:"name"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ParentID  pgtype.UUID        `json:"parent_id"`
 }
 
 func (q *Queries) GetFolderCategoriesBatch(ctx context.Context, folderIds []pgtype.UUID) ([]GetFolderCategoriesBatchRow, error) {
@@ -279,6 +578,7 @@ func (q *Queries) GetFolderCategoriesBatch(ctx context.Context, folderIds []pgty
 			&i.ID,
 			&i.Name,
 			&i.CreatedAt,
+			&i.ParentID,
 		); err != nil {
 			return nil, err
 		}
@@ -291,7 +591,7 @@ func (q *Queries) GetFolderCategoriesBatch(ctx context.Context, folderIds []pgty
 }
 
 const getFolderFiles = `-- name: GetFolderFiles :many
-SELECT f.id, f.path, f.file_name, f.type, f.size, f.modified_at, f.sha256, f.folder_id, f.created_at, f.updated_at FROM files f
+SELECT f.id, f.path, f.file_name, f.type, f.size, f.modified_at, f.sha256, f.folder_id, f.created_at, f.updated_at, f.classified_at, f.favorite, f.rating, f.note FROM files f
 WHERE f.folder_id = $1
 ORDER BY f.file_name
 `
@@ -316,6 +616,10 @@ func (q *Queries) GetFolderFiles(ctx context.Context, folderID pgtype.UUID) ([]F
 			&i.FolderID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ClassifiedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
 		); err != nil {
 			return nil, err
 		}
@@ -328,20 +632,162 @@ func (q *Queries) GetFolderFiles(ctx context.Context, folderID pgtype.UUID) ([]F
 }
 
 const getFolderFilesPaginated = `-- name: GetFolderFilesPaginated :many
-SELECT f.id, f.path, f.file_name, f.type, f.size, f.modified_at, f.sha256, f.folder_id, f.created_at, f.updated_at FROM files f
+SELECT f.id, f.path, f.file_name, f.type, f.size, f.modified_at, f.sha256, f.folder_id, f.created_at, f.updated_at, f.classified_at, f.favorite, f.rating, f.note FROM files f
 WHERE f.folder_id = $1
-ORDER BY f.file_name
+  AND ($4::uuid IS NULL OR (f.file_name, f.id) > ($5::text, $4::uuid))
+ORDER BY f.file_name, f.id
 LIMIT $2 OFFSET $3
 `
 
 type GetFolderFilesPaginatedParams struct {
-	FolderID pgtype.UUID `json:"folder_id"`
-	Limit    int32       `json:"limit"`
-	Offset   int32       `json:"offset"`
+	FolderID  pgtype.UUID `json:"folder_id"`
+	Limit     int32       `json:"limit"`
+	Offset    int32       `json:"offset"`
+	AfterID   pgtype.UUID `json:"after_id"`
+	AfterName string      `json:"after_name"`
 }
 
 func (q *Queries) GetFolderFilesPaginated(ctx context.Context, arg GetFolderFilesPaginatedParams) ([]File, error) {
-	rows, err := q.db.Query(ctx, getFolderFilesPaginated, arg.FolderID, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, getFolderFilesPaginated,
+		arg.FolderID,
+		arg.Limit,
+		arg.Offset,
+		arg.AfterID,
+		arg.AfterName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []File{}
+	for rows.Next() {
+		var i File
+		if err := rows.Scan(
+			&i.ID,
+			&i.Path,
+			&i.FileName,
+			&i.Type,
+			&i.Size,
+			&i.ModifiedAt,
+			&i.Sha256,
+			&i.FolderID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClassifiedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFoldersByIDs = `-- name: GetFoldersByIDs :many
+SELECT id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at FROM folders
+WHERE id = ANY($1::uuid[])
+ORDER BY path
+`
+
+func (q *Queries) GetFoldersByIDs(ctx context.Context, ids []pgtype.UUID) ([]Folder, error) {
+	rows, err := q.db.Query(ctx, getFoldersByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Folder{}
+	for rows.Next() {
+		var i Folder
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Path,
+			&i.ParentFolderID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
+			&i.SourceDesigner,
+			&i.SourceUrl,
+			&i.SourceLicense,
+			&i.SourceDescription,
+			&i.SourceFiles,
+			&i.PrimaryImageID,
+			&i.ContentsModifiedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFoldersByPaths = `-- name: GetFoldersByPaths :many
+SELECT id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at FROM folders
+WHERE path = ANY($1::text[])
+ORDER BY path
+`
+
+func (q *Queries) GetFoldersByPaths(ctx context.Context, paths []string) ([]Folder, error) {
+	rows, err := q.db.Query(ctx, getFoldersByPaths, paths)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Folder{}
+	for rows.Next() {
+		var i Folder
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Path,
+			&i.ParentFolderID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
+			&i.SourceDesigner,
+			&i.SourceUrl,
+			&i.SourceLicense,
+			&i.SourceDescription,
+			&i.SourceFiles,
+			&i.PrimaryImageID,
+			&i.ContentsModifiedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFolderTreeFiles = `-- name: ListFolderTreeFiles :many
+WITH RECURSIVE subtree AS (
+  SELECT folders.id FROM folders WHERE folders.id = $1
+  UNION ALL
+  SELECT sub.id FROM folders sub
+  INNER JOIN subtree ON sub.parent_folder_id = subtree.id
+)
+SELECT f.id, f.path, f.file_name, f.type, f.size, f.modified_at, f.sha256, f.folder_id, f.created_at, f.updated_at, f.classified_at, f.favorite, f.rating, f.note FROM files f
+WHERE f.folder_id IN (SELECT subtree.id FROM subtree)
+ORDER BY f.path
+`
+
+func (q *Queries) ListFolderTreeFiles(ctx context.Context, id pgtype.UUID) ([]File, error) {
+	rows, err := q.db.Query(ctx, listFolderTreeFiles, id)
 	if err != nil {
 		return nil, err
 	}
@@ -360,6 +806,10 @@ func (q *Queries) GetFolderFilesPaginated(ctx context.Context, arg GetFolderFile
 			&i.FolderID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ClassifiedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
 		); err != nil {
 			return nil, err
 		}
@@ -372,7 +822,7 @@ func (q *Queries) GetFolderFilesPaginated(ctx context.Context, arg GetFolderFile
 }
 
 const listFolders = `-- name: ListFolders :many
-SELECT id, name, path, parent_folder_id, created_at, updated_at FROM folders
+SELECT id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at FROM folders
 ORDER BY name
 `
 
@@ -392,6 +842,16 @@ func (q *Queries) ListFolders(ctx context.Context) ([]Folder, error) {
 			&i.ParentFolderID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
+			&i.SourceDesigner,
+			&i.SourceUrl,
+			&i.SourceLicense,
+			&i.SourceDescription,
+			&i.SourceFiles,
+			&i.PrimaryImageID,
+			&i.ContentsModifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -404,18 +864,29 @@ func (q *Queries) ListFolders(ctx context.Context) ([]Folder, error) {
 }
 
 const listFoldersPaginated = `-- name: ListFoldersPaginated :many
-SELECT id, name, path, parent_folder_id, created_at, updated_at FROM folders
-ORDER BY name
+SELECT id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at FROM folders
+WHERE (COALESCE(cardinality($3::text[]), 0) = 0 OR source_license = ANY($3::text[]))
+  AND ($4::uuid IS NULL OR (name, id) > ($5::text, $4::uuid))
+ORDER BY name, id
 LIMIT $1 OFFSET $2
 `
 
 type ListFoldersPaginatedParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	Limit     int32       `json:"limit"`
+	Offset    int32       `json:"offset"`
+	Licenses  []string    `json:"licenses"`
+	AfterID   pgtype.UUID `json:"after_id"`
+	AfterName string      `json:"after_name"`
 }
 
 func (q *Queries) ListFoldersPaginated(ctx context.Context, arg ListFoldersPaginatedParams) ([]Folder, error) {
-	rows, err := q.db.Query(ctx, listFoldersPaginated, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listFoldersPaginated,
+		arg.Limit,
+		arg.Offset,
+		arg.Licenses,
+		arg.AfterID,
+		arg.AfterName,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -430,6 +901,16 @@ func (q *Queries) ListFoldersPaginated(ctx context.Context, arg ListFoldersPagin
 			&i.ParentFolderID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
+			&i.SourceDesigner,
+			&i.SourceUrl,
+			&i.SourceLicense,
+			&i.SourceDescription,
+			&i.SourceFiles,
+			&i.PrimaryImageID,
+			&i.ContentsModifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -442,7 +923,7 @@ func (q *Queries) ListFoldersPaginated(ctx context.Context, arg ListFoldersPagin
 }
 
 const listRootFolders = `-- name: ListRootFolders :many
-SELECT id, name, path, parent_folder_id, created_at, updated_at FROM folders
+SELECT id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at FROM folders
 WHERE parent_folder_id IS NULL
 ORDER BY name
 `
@@ -463,6 +944,16 @@ func (q *Queries) ListRootFolders(ctx context.Context) ([]Folder, error) {
 			&i.ParentFolderID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
+			&i.SourceDesigner,
+			&i.SourceUrl,
+			&i.SourceLicense,
+			&i.SourceDescription,
+			&i.SourceFiles,
+			&i.PrimaryImageID,
+			&i.ContentsModifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -475,19 +966,27 @@ func (q *Queries) ListRootFolders(ctx context.Context) ([]Folder, error) {
 }
 
 const listRootFoldersPaginated = `-- name: ListRootFoldersPaginated :many
-SELECT id, name, path, parent_folder_id, created_at, updated_at FROM folders
+SELECT id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at FROM folders
 WHERE parent_folder_id IS NULL
-ORDER BY name
+  AND ($3::uuid IS NULL OR (name, id) > ($4::text, $3::uuid))
+ORDER BY name, id
 LIMIT $1 OFFSET $2
 `
 
 type ListRootFoldersPaginatedParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	Limit     int32       `json:"limit"`
+	Offset    int32       `json:"offset"`
+	AfterID   pgtype.UUID `json:"after_id"`
+	AfterName string      `json:"after_name"`
 }
 
 func (q *Queries) ListRootFoldersPaginated(ctx context.Context, arg ListRootFoldersPaginatedParams) ([]Folder, error) {
-	rows, err := q.db.Query(ctx, listRootFoldersPaginated, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listRootFoldersPaginated,
+		arg.Limit,
+		arg.Offset,
+		arg.AfterID,
+		arg.AfterName,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -502,6 +1001,16 @@ func (q *Queries) ListRootFoldersPaginated(ctx context.Context, arg ListRootFold
 			&i.ParentFolderID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
+			&i.SourceDesigner,
+			&i.SourceUrl,
+			&i.SourceLicense,
+			&i.SourceDescription,
+			&i.SourceFiles,
+			&i.PrimaryImageID,
+			&i.ContentsModifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -514,7 +1023,7 @@ func (q *Queries) ListRootFoldersPaginated(ctx context.Context, arg ListRootFold
 }
 
 const listSubfolders = `-- name: ListSubfolders :many
-SELECT id, name, path, parent_folder_id, created_at, updated_at FROM folders
+SELECT id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at FROM folders
 WHERE parent_folder_id = $1
 ORDER BY name
 `
@@ -535,6 +1044,16 @@ func (q *Queries) ListSubfolders(ctx context.Context, parentFolderID pgtype.UUID
 			&i.ParentFolderID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
+			&i.SourceDesigner,
+			&i.SourceUrl,
+			&i.SourceLicense,
+			&i.SourceDescription,
+			&i.SourceFiles,
+			&i.PrimaryImageID,
+			&i.ContentsModifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -547,9 +1066,10 @@ func (q *Queries) ListSubfolders(ctx context.Context, parentFolderID pgtype.UUID
 }
 
 const listSubfoldersPaginated = `-- name: ListSubfoldersPaginated :many
-SELECT id, name, path, parent_folder_id, created_at, updated_at FROM folders
+SELECT id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at FROM folders
 WHERE parent_folder_id = $1
-ORDER BY name
+  AND ($4::uuid IS NULL OR (name, id) > ($5::text, $4::uuid))
+ORDER BY name, id
 LIMIT $2 OFFSET $3
 `
 
@@ -557,10 +1077,18 @@ type ListSubfoldersPaginatedParams struct {
 	ParentFolderID pgtype.UUID `json:"parent_folder_id"`
 	Limit          int32       `json:"limit"`
 	Offset         int32       `json:"offset"`
+	AfterID        pgtype.UUID `json:"after_id"`
+	AfterName      string      `json:"after_name"`
 }
 
 func (q *Queries) ListSubfoldersPaginated(ctx context.Context, arg ListSubfoldersPaginatedParams) ([]Folder, error) {
-	rows, err := q.db.Query(ctx, listSubfoldersPaginated, arg.ParentFolderID, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listSubfoldersPaginated,
+		arg.ParentFolderID,
+		arg.Limit,
+		arg.Offset,
+		arg.AfterID,
+		arg.AfterName,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -575,6 +1103,16 @@ func (q *Queries) ListSubfoldersPaginated(ctx context.Context, arg ListSubfolder
 			&i.ParentFolderID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
+			&i.SourceDesigner,
+			&i.SourceUrl,
+			&i.SourceLicense,
+			&i.SourceDescription,
+			&i.SourceFiles,
+			&i.PrimaryImageID,
+			&i.ContentsModifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -586,6 +1124,24 @@ func (q *Queries) ListSubfoldersPaginated(ctx context.Context, arg ListSubfolder
 	return items, nil
 }
 
+const mergeFolderCategories = `-- name: MergeFolderCategories :exec
+INSERT INTO folders_categories (folder_id, category_id)
+SELECT DISTINCT fc.folder_id, $1::uuid
+FROM folders_categories fc
+WHERE fc.category_id = ANY($2::uuid[])
+ON CONFLICT DO NOTHING
+`
+
+type MergeFolderCategoriesParams struct {
+	CategoryID pgtype.UUID   `json:"category_id"`
+	SourceIds  []pgtype.UUID `json:"source_ids"`
+}
+
+func (q *Queries) MergeFolderCategories(ctx context.Context, arg MergeFolderCategoriesParams) error {
+	_, err := q.db.Exec(ctx, mergeFolderCategories, arg.CategoryID, arg.SourceIds)
+	return err
+}
+
 const removeFolderCategory = `-- name: RemoveFolderCategory :exec
 DELETE FROM folders_categories
 WHERE folder_id = $1 AND category_id = $2
@@ -601,21 +1157,99 @@ func (q *Queries) RemoveFolderCategory(ctx context.Context, arg RemoveFolderCate
 	return err
 }
 
+const removeSubtreeFileCategories = `-- name: RemoveSubtreeFileCategories :execrows
+WITH RECURSIVE subtree AS (
+    SELECT folders.id, 0 AS depth FROM folders WHERE folders.id = $1
+    UNION ALL
+    SELECT f.id, s.depth + 1 FROM folders f
+    INNER JOIN subtree s ON f.parent_folder_id = s.id
+    WHERE s.depth < $2::int
+)
+DELETE FROM files_categories
+WHERE file_id IN (
+    SELECT files.id FROM files
+    INNER JOIN subtree ON files.folder_id = subtree.id
+    WHERE lower(files.type) = ANY($3::text[])
+)
+  AND category_id = ANY($4::uuid[])
+`
+
+type RemoveSubtreeFileCategoriesParams struct {
+	FolderID    pgtype.UUID   `json:"folder_id"`
+	MaxDepth    int32         `json:"max_depth"`
+	Types       []string      `json:"types"`
+	CategoryIds []pgtype.UUID `json:"category_ids"`
+}
+
+func (q *Queries) RemoveSubtreeFileCategories(ctx context.Context, arg RemoveSubtreeFileCategoriesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeSubtreeFileCategories,
+		arg.FolderID,
+		arg.MaxDepth,
+		arg.Types,
+		arg.CategoryIds,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const removeSubtreeFolderCategories = `-- name: RemoveSubtreeFolderCategories :execrows
+WITH RECURSIVE subtree AS (
+    SELECT folders.id, 0 AS depth FROM folders WHERE folders.id = $1
+    UNION ALL
+    SELECT f.id, s.depth + 1 FROM folders f
+    INNER JOIN subtree s ON f.parent_folder_id = s.id
+    WHERE s.depth < $2::int
+)
+DELETE FROM folders_categories
+WHERE folder_id IN (SELECT subtree.id FROM subtree WHERE subtree.depth > 0)
+  AND category_id = ANY($3::uuid[])
+`
+
+type RemoveSubtreeFolderCategoriesParams struct {
+	FolderID    pgtype.UUID   `json:"folder_id"`
+	MaxDepth    int32         `json:"max_depth"`
+	CategoryIds []pgtype.UUID `json:"category_ids"`
+}
+
+func (q *Queries) RemoveSubtreeFolderCategories(ctx context.Context, arg RemoveSubtreeFolderCategoriesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeSubtreeFolderCategories, arg.FolderID, arg.MaxDepth, arg.CategoryIds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const searchFoldersPaginated = `-- name: SearchFoldersPaginated :many
-SELECT id, name, path, parent_folder_id, created_at, updated_at FROM folders
-WHERE name ILIKE '%' || $3::text || '%'
-ORDER BY name
+SELECT id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at FROM folders
+WHERE (name ILIKE '%' || $3::text || '%'
+    OR to_tsvector('simple', search_tokens(name)) @@ search_query($3::text)
+    OR note ILIKE '%' || $3::text || '%')
+  AND (COALESCE(cardinality($4::text[]), 0) = 0 OR source_license = ANY($4::text[]))
+  AND ($5::uuid IS NULL OR (name, id) > ($6::text, $5::uuid))
+ORDER BY name, id
 LIMIT $1 OFFSET $2
 `
 
 type SearchFoldersPaginatedParams struct {
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
-	Search string `json:"search"`
+	Limit     int32       `json:"limit"`
+	Offset    int32       `json:"offset"`
+	Search    string      `json:"search"`
+	Licenses  []string    `json:"licenses"`
+	AfterID   pgtype.UUID `json:"after_id"`
+	AfterName string      `json:"after_name"`
 }
 
 func (q *Queries) SearchFoldersPaginated(ctx context.Context, arg SearchFoldersPaginatedParams) ([]Folder, error) {
-	rows, err := q.db.Query(ctx, searchFoldersPaginated, arg.Limit, arg.Offset, arg.Search)
+	rows, err := q.db.Query(ctx, searchFoldersPaginated,
+		arg.Limit,
+		arg.Offset,
+		arg.Search,
+		arg.Licenses,
+		arg.AfterID,
+		arg.AfterName,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -630,6 +1264,16 @@ func (q *Queries) SearchFoldersPaginated(ctx context.Context, arg SearchFoldersP
 			&i.ParentFolderID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
+			&i.SourceDesigner,
+			&i.SourceUrl,
+			&i.SourceLicense,
+			&i.SourceDescription,
+			&i.SourceFiles,
+			&i.PrimaryImageID,
+			&i.ContentsModifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -642,21 +1286,32 @@ func (q *Queries) SearchFoldersPaginated(ctx context.Context, arg SearchFoldersP
 }
 
 const searchRootFoldersPaginated = `-- name: SearchRootFoldersPaginated :many
-SELECT id, name, path, parent_folder_id, created_at, updated_at FROM folders
+SELECT id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at FROM folders
 WHERE parent_folder_id IS NULL
-  AND name ILIKE '%' || $3::text || '%'
-ORDER BY name
+  AND (name ILIKE '%' || $3::text || '%'
+    OR to_tsvector('simple', search_tokens(name)) @@ search_query($3::text)
+    OR note ILIKE '%' || $3::text || '%')
+  AND ($4::uuid IS NULL OR (name, id) > ($5::text, $4::uuid))
+ORDER BY name, id
 LIMIT $1 OFFSET $2
 `
 
 type SearchRootFoldersPaginatedParams struct {
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
-	Search string `json:"search"`
+	Limit     int32       `json:"limit"`
+	Offset    int32       `json:"offset"`
+	Search    string      `json:"search"`
+	AfterID   pgtype.UUID `json:"after_id"`
+	AfterName string      `json:"after_name"`
 }
 
 func (q *Queries) SearchRootFoldersPaginated(ctx context.Context, arg SearchRootFoldersPaginatedParams) ([]Folder, error) {
-	rows, err := q.db.Query(ctx, searchRootFoldersPaginated, arg.Limit, arg.Offset, arg.Search)
+	rows, err := q.db.Query(ctx, searchRootFoldersPaginated,
+		arg.Limit,
+		arg.Offset,
+		arg.Search,
+		arg.AfterID,
+		arg.AfterName,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -671,6 +1326,16 @@ func (q *Queries) SearchRootFoldersPaginated(ctx context.Context, arg SearchRoot
 			&i.ParentFolderID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Favorite,
+			&i.Rating,
+			&i.Note,
+			&i.SourceDesigner,
+			&i.SourceUrl,
+			&i.SourceLicense,
+			&i.SourceDescription,
+			&i.SourceFiles,
+			&i.PrimaryImageID,
+			&i.ContentsModifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -691,6 +1356,22 @@ func (q *Queries) SetFolderCategories(ctx context.Context, folderID pgtype.UUID)
 	return err
 }
 
+const setFolderContentsModifiedAt = `-- name: SetFolderContentsModifiedAt :exec
+UPDATE folders
+SET contents_modified_at = $1
+WHERE id = $2
+`
+
+type SetFolderContentsModifiedAtParams struct {
+	ContentsModifiedAt pgtype.Timestamptz `json:"contents_modified_at"`
+	ID                 pgtype.UUID        `json:"id"`
+}
+
+func (q *Queries) SetFolderContentsModifiedAt(ctx context.Context, arg SetFolderContentsModifiedAtParams) error {
+	_, err := q.db.Exec(ctx, setFolderContentsModifiedAt, arg.ContentsModifiedAt, arg.ID)
+	return err
+}
+
 const updateFileFolderID = `-- name: UpdateFileFolderID :exec
 UPDATE files
 SET folder_id = $2
@@ -711,7 +1392,7 @@ const updateFolder = `-- name: UpdateFolder :one
 UPDATE folders
 SET name = $2, path = $3, updated_at = NOW()
 WHERE id = $1
-RETURNING id, name, path, parent_folder_id, created_at, updated_at
+RETURNING id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at
 `
 
 type UpdateFolderParams struct {
@@ -730,6 +1411,59 @@ func (q *Queries) UpdateFolder(ctx context.Context, arg UpdateFolderParams) (Fol
 		&i.ParentFolderID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Favorite,
+		&i.Rating,
+		&i.Note,
+		&i.SourceDesigner,
+		&i.SourceUrl,
+		&i.SourceLicense,
+		&i.SourceDescription,
+		&i.SourceFiles,
+		&i.PrimaryImageID,
+		&i.ContentsModifiedAt,
+	)
+	return i, err
+}
+
+const updateFolderMetadata = `-- name: UpdateFolderMetadata :one
+UPDATE folders
+SET favorite = $1, rating = $2, note = $3, updated_at = NOW()
+WHERE id = $4
+RETURNING id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at
+`
+
+type UpdateFolderMetadataParams struct {
+	Favorite bool        `json:"favorite"`
+	Rating   pgtype.Int2 `json:"rating"`
+	Note     string      `json:"note"`
+	ID       pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateFolderMetadata(ctx context.Context, arg UpdateFolderMetadataParams) (Folder, error) {
+	row := q.db.QueryRow(ctx, updateFolderMetadata,
+		arg.Favorite,
+		arg.Rating,
+		arg.Note,
+		arg.ID,
+	)
+	var i Folder
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Path,
+		&i.ParentFolderID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Favorite,
+		&i.Rating,
+		&i.Note,
+		&i.SourceDesigner,
+		&i.SourceUrl,
+		&i.SourceLicense,
+		&i.SourceDescription,
+		&i.SourceFiles,
+		&i.PrimaryImageID,
+		&i.ContentsModifiedAt,
 	)
 	return i, err
 }
@@ -738,7 +1472,7 @@ const updateFolderParent = `-- name: UpdateFolderParent :one
 UPDATE folders
 SET parent_folder_id = $2, updated_at = NOW()
 WHERE id = $1
-RETURNING id, name, path, parent_folder_id, created_at, updated_at
+RETURNING id, name, path, parent_folder_id, created_at, updated_at, favorite, rating, note, source_designer, source_url, source_license, source_description, source_files, primary_image_id, contents_modified_at
 `
 
 type UpdateFolderParentParams struct {
@@ -756,6 +1490,46 @@ func (q *Queries) UpdateFolderParent(ctx context.Context, arg UpdateFolderParent
 		&i.ParentFolderID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Favorite,
+		&i.Rating,
+		&i.Note,
+		&i.SourceDesigner,
+		&i.SourceUrl,
+		&i.SourceLicense,
+		&i.SourceDescription,
+		&i.SourceFiles,
+		&i.PrimaryImageID,
+		&i.ContentsModifiedAt,
 	)
 	return i, err
 }
+
+const updateFolderSource = `-- name: UpdateFolderSource :exec
+UPDATE folders
+SET source_designer = $1, source_url = $2, source_license = $3,
+    source_description = $4, source_files = $5, updated_at = NOW()
+WHERE id = $6
+  AND (source_designer, source_url, source_license, source_description, source_files)
+    IS DISTINCT FROM ($1::text, $2::text, $3::text, $4::text, $5::text[])
+`
+
+type UpdateFolderSourceParams struct {
+	SourceDesigner    string      `json:"source_designer"`
+	SourceUrl         string      `json:"source_url"`
+	SourceLicense     string      `json:"source_license"`
+	SourceDescription string      `json:"source_description"`
+	SourceFiles       []string    `json:"source_files"`
+	ID                pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateFolderSource(ctx context.Context, arg UpdateFolderSourceParams) error {
+	_, err := q.db.Exec(ctx, updateFolderSource,
+		arg.SourceDesigner,
+		arg.SourceUrl,
+		arg.SourceLicense,
+		arg.SourceDescription,
+		arg.SourceFiles,
+		arg.ID,
+	)
+	return err
+}
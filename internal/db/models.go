@@ -8,37 +8,142 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AiUsageDaily struct {
+	Day              pgtype.Date        `json:"day"`
+	Requests         int32              `json:"requests"`
+	PromptTokens     int64              `json:"prompt_tokens"`
+	CompletionTokens int64              `json:"completion_tokens"`
+	CostUsd          float64            `json:"cost_usd"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+}
+
 type Category struct {
 	ID        pgtype.UUID        `json:"id"`
 	Name      string             `json:"name"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ParentID  pgtype.UUID        `json:"parent_id"`
 }
 
-type File struct {
-	ID         pgtype.UUID        `json:"id"`
-	Path       string             `json:"path"`
-	FileName   string             `json:"file_name"`
-	Type       string             `json:"type"`
-	Size       int64              `json:"size"`
-	ModifiedAt pgtype.Timestamptz `json:"modified_at"`
-	Sha256     pgtype.Text        `json:"sha256"`
-	FolderID   pgtype.UUID        `json:"folder_id"`
+type CategoryAlias struct {
+	Alias      string             `json:"alias"`
+	CategoryID pgtype.UUID        `json:"category_id"`
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+type CategoryProposal struct {
+	ID          pgtype.UUID        `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Status      string             `json:"status"`
+	CategoryID  pgtype.UUID        `json:"category_id"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type CategoryProposalFile struct {
+	ProposalID pgtype.UUID `json:"proposal_id"`
+	FileID     pgtype.UUID `json:"file_id"`
+}
+
+type ClassificationQueue struct {
+	FileID   pgtype.UUID        `json:"file_id"`
+	ScanID   pgtype.UUID        `json:"scan_id"`
+	Reason   string             `json:"reason"`
+	QueuedAt pgtype.Timestamptz `json:"queued_at"`
+}
+
+type Collection struct {
+	ID               pgtype.UUID        `json:"id"`
+	Name             string             `json:"name"`
+	Description      string             `json:"description"`
+	CoverPath        pgtype.Text        `json:"cover_path"`
+	CoverContentType pgtype.Text        `json:"cover_content_type"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+}
+
+type CollectionItem struct {
+	ID           pgtype.UUID        `json:"id"`
+	CollectionID pgtype.UUID        `json:"collection_id"`
+	FileID       pgtype.UUID        `json:"file_id"`
+	FolderID     pgtype.UUID        `json:"folder_id"`
+	Position     int32              `json:"position"`
+	Note         string             `json:"note"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type CustomField struct {
+	ID        pgtype.UUID        `json:"id"`
+	Key       string             `json:"key"`
+	Label     string             `json:"label"`
+	Type      string             `json:"type"`
+	Options   []string           `json:"options"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type CustomFieldValue struct {
+	FieldID  pgtype.UUID `json:"field_id"`
+	FileID   pgtype.UUID `json:"file_id"`
+	FolderID pgtype.UUID `json:"folder_id"`
+	Value    []byte      `json:"value"`
+}
+
+type File struct {
+	ID           pgtype.UUID        `json:"id"`
+	Path         string             `json:"path"`
+	FileName     string             `json:"file_name"`
+	Type         string             `json:"type"`
+	Size         int64              `json:"size"`
+	ModifiedAt   pgtype.Timestamptz `json:"modified_at"`
+	Sha256       pgtype.Text        `json:"sha256"`
+	FolderID     pgtype.UUID        `json:"folder_id"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	ClassifiedAt pgtype.Timestamptz `json:"classified_at"`
+	Favorite     bool               `json:"favorite"`
+	Rating       pgtype.Int2        `json:"rating"`
+	Note         string             `json:"note"`
+}
+
+type FileSearch struct {
+	FileID   pgtype.UUID `json:"file_id"`
+	Document interface{} `json:"document"`
 }
 
 type FilesCategory struct {
 	FileID     pgtype.UUID `json:"file_id"`
 	CategoryID pgtype.UUID `json:"category_id"`
+	Source     string      `json:"source"`
 }
 
 type Folder struct {
-	ID             pgtype.UUID        `json:"id"`
-	Name           string             `json:"name"`
-	Path           string             `json:"path"`
-	ParentFolderID pgtype.UUID        `json:"parent_folder_id"`
-	CreatedAt      pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	ID                 pgtype.UUID        `json:"id"`
+	Name               string             `json:"name"`
+	Path               string             `json:"path"`
+	ParentFolderID     pgtype.UUID        `json:"parent_folder_id"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
+	Favorite           bool               `json:"favorite"`
+	Rating             pgtype.Int2        `json:"rating"`
+	Note               string             `json:"note"`
+	SourceDesigner     string             `json:"source_designer"`
+	SourceUrl          string             `json:"source_url"`
+	SourceLicense      string             `json:"source_license"`
+	SourceDescription  string             `json:"source_description"`
+	SourceFiles        []string           `json:"source_files"`
+	PrimaryImageID     pgtype.UUID        `json:"primary_image_id"`
+	ContentsModifiedAt pgtype.Timestamptz `json:"contents_modified_at"`
+}
+
+type FolderImage struct {
+	ID         pgtype.UUID        `json:"id"`
+	FolderID   pgtype.UUID        `json:"folder_id"`
+	Name       string             `json:"name"`
+	Size       int64              `json:"size"`
+	ModifiedAt pgtype.Timestamptz `json:"modified_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
 }
 
 type FoldersCategory struct {
@@ -46,13 +151,109 @@ type FoldersCategory struct {
 	CategoryID pgtype.UUID `json:"category_id"`
 }
 
-type Scan struct {
+type Job struct {
+	ID           pgtype.UUID        `json:"id"`
+	Type         string             `json:"type"`
+	Status       string             `json:"status"`
+	ExclusiveKey pgtype.Text        `json:"exclusive_key"`
+	Payload      []byte             `json:"payload"`
+	Result       []byte             `json:"result"`
+	Progress     int32              `json:"progress"`
+	Error        pgtype.Text        `json:"error"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	StartedAt    pgtype.Timestamptz `json:"started_at"`
+	FinishedAt   pgtype.Timestamptz `json:"finished_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+type JobLog struct {
+	ID        int64              `json:"id"`
+	JobID     pgtype.UUID        `json:"job_id"`
+	Level     string             `json:"level"`
+	Message   string             `json:"message"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type Print struct {
+	ID               pgtype.UUID        `json:"id"`
+	FileID           pgtype.UUID        `json:"file_id"`
+	PrintedAt        pgtype.Timestamptz `json:"printed_at"`
+	Printer          string             `json:"printer"`
+	Material         string             `json:"material"`
+	LayerHeight      pgtype.Float8      `json:"layer_height"`
+	Nozzle           pgtype.Float8      `json:"nozzle"`
+	DurationSeconds  pgtype.Int4        `json:"duration_seconds"`
+	Result           string             `json:"result"`
+	Note             string             `json:"note"`
+	PhotoPath        pgtype.Text        `json:"photo_path"`
+	PhotoContentType pgtype.Text        `json:"photo_content_type"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+}
+
+type ReclassifyRun struct {
 	ID        pgtype.UUID        `json:"id"`
 	Status    string             `json:"status"`
-	Found     pgtype.Int4        `json:"found"`
-	Processed pgtype.Int4        `json:"processed"`
-	Progress  pgtype.Int4        `json:"progress"`
+	DryRun    bool               `json:"dry_run"`
+	Filter    []byte             `json:"filter"`
+	Total     int32              `json:"total"`
+	Processed int32              `json:"processed"`
+	Changed   int32              `json:"changed"`
+	Queued    int32              `json:"queued"`
+	Progress  int32              `json:"progress"`
 	Error     pgtype.Text        `json:"error"`
+	Report    []byte             `json:"report"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	JobID     pgtype.UUID        `json:"job_id"`
+}
+
+type SavedSearch struct {
+	ID        pgtype.UUID        `json:"id"`
+	Name      string             `json:"name"`
+	Filters   []byte             `json:"filters"`
+	Pinned    bool               `json:"pinned"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Scan struct {
+	ID         pgtype.UUID        `json:"id"`
+	Status     string             `json:"status"`
+	Found      pgtype.Int4        `json:"found"`
+	Processed  pgtype.Int4        `json:"processed"`
+	Progress   pgtype.Int4        `json:"progress"`
+	Error      pgtype.Text        `json:"error"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+	JobID      pgtype.UUID        `json:"job_id"`
+	Options    []byte             `json:"options"`
+	ScheduleID pgtype.UUID        `json:"schedule_id"`
+	Preview    []byte             `json:"preview"`
+}
+
+type ScanEvent struct {
+	ID           int64              `json:"id"`
+	ScanID       pgtype.UUID        `json:"scan_id"`
+	FileID       pgtype.UUID        `json:"file_id"`
+	Event        string             `json:"event"`
+	Path         string             `json:"path"`
+	PreviousPath pgtype.Text        `json:"previous_path"`
+	Error        pgtype.Text        `json:"error"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type ScanSchedule struct {
+	ID         pgtype.UUID        `json:"id"`
+	Name       string             `json:"name"`
+	Cron       string             `json:"cron"`
+	Timezone   string             `json:"timezone"`
+	Options    []byte             `json:"options"`
+	Enabled    bool               `json:"enabled"`
+	NextRun    pgtype.Timestamptz `json:"next_run"`
+	LastRunAt  pgtype.Timestamptz `json:"last_run_at"`
+	LastStatus pgtype.Text        `json:"last_status"`
+	LastError  pgtype.Text        `json:"last_error"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
 }
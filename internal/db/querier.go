@@ -11,78 +11,228 @@ import (
 )
 
 type Querier interface {
+	AddAIUsage(ctx context.Context, arg AddAIUsageParams) error
+	AddCategoryAliases(ctx context.Context, arg AddCategoryAliasesParams) error
+	AddCategoryProposalFiles(ctx context.Context, arg AddCategoryProposalFilesParams) error
+	AddCollectionItem(ctx context.Context, arg AddCollectionItemParams) (CollectionItem, error)
 	AddFileCategory(ctx context.Context, arg AddFileCategoryParams) error
 	AddFolderCategory(ctx context.Context, arg AddFolderCategoryParams) error
+	AddJobLog(ctx context.Context, arg AddJobLogParams) error
+	AddManualFileCategory(ctx context.Context, arg AddManualFileCategoryParams) error
+	AddRemovedScanEvents(ctx context.Context, arg AddRemovedScanEventsParams) error
+	AddScanEvent(ctx context.Context, arg AddScanEventParams) error
+	AddScanEvents(ctx context.Context, arg AddScanEventsParams) error
+	AddSubtreeFileCategories(ctx context.Context, arg AddSubtreeFileCategoriesParams) (int64, error)
+	AddSubtreeFolderCategories(ctx context.Context, arg AddSubtreeFolderCategoriesParams) (int64, error)
 	BulkAddFileCategories(ctx context.Context, arg BulkAddFileCategoriesParams) error
 	BulkAddFolderCategories(ctx context.Context, arg BulkAddFolderCategoriesParams) error
+	BulkAddManualFileCategories(ctx context.Context, arg BulkAddManualFileCategoriesParams) error
 	BulkRemoveFileCategories(ctx context.Context, fileIds []pgtype.UUID) error
 	BulkRemoveFolderCategories(ctx context.Context, folderIds []pgtype.UUID) error
+	BulkRemoveSelectedFileCategories(ctx context.Context, arg BulkRemoveSelectedFileCategoriesParams) error
+	ClaimScanSchedule(ctx context.Context, arg ClaimScanScheduleParams) (int64, error)
 	CountCategories(ctx context.Context) (int64, error)
+	CountCategoryProposals(ctx context.Context, status string) (int64, error)
+	CountClassificationQueue(ctx context.Context) (int64, error)
+	CountCollectionItems(ctx context.Context, collectionID pgtype.UUID) (int64, error)
+	CountCollections(ctx context.Context) (int64, error)
+	CountFilePrints(ctx context.Context, fileID pgtype.UUID) (int64, error)
 	CountFiles(ctx context.Context) (int64, error)
 	CountFilesByType(ctx context.Context, type_ string) (int64, error)
 	CountFolderFiles(ctx context.Context, folderID pgtype.UUID) (int64, error)
-	CountFolders(ctx context.Context) (int64, error)
+	CountFolderPropagation(ctx context.Context, arg CountFolderPropagationParams) (CountFolderPropagationRow, error)
+	CountFolders(ctx context.Context, licenses []string) (int64, error)
+	CountJobLogs(ctx context.Context, jobID pgtype.UUID) (int64, error)
+	CountJobs(ctx context.Context, arg CountJobsParams) (int64, error)
+	CountManualFileCategories(ctx context.Context, fileID pgtype.UUID) (int64, error)
+	CountReclassifyRuns(ctx context.Context) (int64, error)
 	CountRootFiles(ctx context.Context) (int64, error)
 	CountRootFolders(ctx context.Context) (int64, error)
-	CountScans(ctx context.Context) (int64, error)
+	CountSavedSearches(ctx context.Context) (int64, error)
+	CountScanEvents(ctx context.Context, arg CountScanEventsParams) (int64, error)
+	CountScanSchedules(ctx context.Context) (int64, error)
+	CountScans(ctx context.Context, scheduleID pgtype.UUID) (int64, error)
 	CountSearchCategories(ctx context.Context, search string) (int64, error)
-	CountSearchFolders(ctx context.Context, search string) (int64, error)
+	CountSearchFolders(ctx context.Context, arg CountSearchFoldersParams) (int64, error)
 	CountSearchRootFolders(ctx context.Context, search string) (int64, error)
 	CountSubfolders(ctx context.Context, parentFolderID pgtype.UUID) (int64, error)
-	CreateCategory(ctx context.Context, name string) (Category, error)
+	CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error)
+	CreateCollection(ctx context.Context, arg CreateCollectionParams) (Collection, error)
+	CreateCustomField(ctx context.Context, arg CreateCustomFieldParams) (CustomField, error)
 	CreateFile(ctx context.Context, arg CreateFileParams) (File, error)
 	CreateFolder(ctx context.Context, arg CreateFolderParams) (Folder, error)
 	CreateFolderWithParent(ctx context.Context, arg CreateFolderWithParentParams) (Folder, error)
+	CreateJob(ctx context.Context, arg CreateJobParams) (Job, error)
+	CreatePrint(ctx context.Context, arg CreatePrintParams) (Print, error)
+	CreateReclassifyRun(ctx context.Context, arg CreateReclassifyRunParams) (ReclassifyRun, error)
+	CreateSavedSearch(ctx context.Context, arg CreateSavedSearchParams) (SavedSearch, error)
 	CreateScan(ctx context.Context, arg CreateScanParams) (Scan, error)
+	CreateScanSchedule(ctx context.Context, arg CreateScanScheduleParams) (ScanSchedule, error)
 	DeleteCategory(ctx context.Context, id pgtype.UUID) error
+	DeleteCategoryFileLinks(ctx context.Context, categoryIds []pgtype.UUID) (int64, error)
+	DeleteCategoryFolderLinks(ctx context.Context, categoryIds []pgtype.UUID) (int64, error)
+	DeleteCategoryNameAlias(ctx context.Context, id pgtype.UUID) error
+	DeleteCategoryProposal(ctx context.Context, id pgtype.UUID) error
+	DeleteCollection(ctx context.Context, id pgtype.UUID) (int64, error)
+	DeleteCollectionItem(ctx context.Context, arg DeleteCollectionItemParams) (int64, error)
+	DeleteCustomField(ctx context.Context, id pgtype.UUID) (int64, error)
+	DeleteEmptyFolders(ctx context.Context, dir string) (int64, error)
 	DeleteFile(ctx context.Context, id pgtype.UUID) error
+	DeleteFileFieldValue(ctx context.Context, arg DeleteFileFieldValueParams) error
+	DeleteFilesByID(ctx context.Context, ids []pgtype.UUID) (int64, error)
 	DeleteFolder(ctx context.Context, id pgtype.UUID) error
+	DeleteFolderFieldValue(ctx context.Context, arg DeleteFolderFieldValueParams) error
+	DeleteJob(ctx context.Context, id pgtype.UUID) error
+	DeleteMissingFolderImages(ctx context.Context, arg DeleteMissingFolderImagesParams) (int64, error)
+	DeletePrint(ctx context.Context, arg DeletePrintParams) (int64, error)
+	DeleteReclassifyRun(ctx context.Context, id pgtype.UUID) error
+	DeleteSavedSearch(ctx context.Context, id pgtype.UUID) (int64, error)
 	DeleteScan(ctx context.Context, id pgtype.UUID) error
+	DeleteScanSchedule(ctx context.Context, id pgtype.UUID) error
+	DeleteSubtreeFileCategories(ctx context.Context, arg DeleteSubtreeFileCategoriesParams) (int64, error)
+	DeleteSubtreeFolderCategories(ctx context.Context, arg DeleteSubtreeFolderCategoriesParams) (int64, error)
+	DequeueClassification(ctx context.Context, fileID pgtype.UUID) error
+	DequeueManualClassifications(ctx context.Context) (int64, error)
+	EnqueueClassification(ctx context.Context, arg EnqueueClassificationParams) error
+	FailInterruptedReclassifyRuns(ctx context.Context) (int64, error)
+	FailInterruptedScans(ctx context.Context) (int64, error)
+	FinishJob(ctx context.Context, arg FinishJobParams) error
+	FinishReclassifyRun(ctx context.Context, arg FinishReclassifyRunParams) (ReclassifyRun, error)
+	GetAIUsage(ctx context.Context, day pgtype.Date) (AiUsageDaily, error)
 	GetCategoriesBatch(ctx context.Context, fileIds []pgtype.UUID) ([]GetCategoriesBatchRow, error)
 	GetCategory(ctx context.Context, id pgtype.UUID) (Category, error)
 	GetCategoryByName(ctx context.Context, name string) (Category, error)
+	GetCategoryProposal(ctx context.Context, id pgtype.UUID) (CategoryProposal, error)
+	GetCategoryProposalFiles(ctx context.Context, proposalID pgtype.UUID) ([]File, error)
+	GetCategoryProposalFilesBatch(ctx context.Context, proposalIds []pgtype.UUID) ([]GetCategoryProposalFilesBatchRow, error)
+	GetCollection(ctx context.Context, id pgtype.UUID) (Collection, error)
+	GetCollectionItem(ctx context.Context, arg GetCollectionItemParams) (CollectionItem, error)
+	GetCustomField(ctx context.Context, id pgtype.UUID) (CustomField, error)
+	GetCustomFieldsByKeys(ctx context.Context, keys []string) ([]CustomField, error)
 	GetFile(ctx context.Context, id pgtype.UUID) (File, error)
 	GetFileByPath(ctx context.Context, path string) (File, error)
 	GetFileCategories(ctx context.Context, fileID pgtype.UUID) ([]Category, error)
-	GetFilesByCategory(ctx context.Context, arg GetFilesByCategoryParams) ([]File, error)
+	GetFilePrintStats(ctx context.Context, fileIds []pgtype.UUID) ([]GetFilePrintStatsRow, error)
+	GetFilesByIDs(ctx context.Context, ids []pgtype.UUID) ([]File, error)
 	GetFolder(ctx context.Context, id pgtype.UUID) (Folder, error)
 	GetFolderByPath(ctx context.Context, path string) (Folder, error)
 	GetFolderCategories(ctx context.Context, folderID pgtype.UUID) ([]Category, error)
 	GetFolderCategoriesBatch(ctx context.Context, folderIds []pgtype.UUID) ([]GetFolderCategoriesBatchRow, error)
 	GetFolderFiles(ctx context.Context, folderID pgtype.UUID) ([]File, error)
 	GetFolderFilesPaginated(ctx context.Context, arg GetFolderFilesPaginatedParams) ([]File, error)
+	GetFolderImage(ctx context.Context, arg GetFolderImageParams) (FolderImage, error)
+	GetFolderPrintStats(ctx context.Context, folderIds []pgtype.UUID) ([]GetFolderPrintStatsRow, error)
+	GetFoldersByIDs(ctx context.Context, ids []pgtype.UUID) ([]Folder, error)
+	GetFoldersByPaths(ctx context.Context, paths []string) ([]Folder, error)
+	GetJob(ctx context.Context, id pgtype.UUID) (Job, error)
+	GetPrint(ctx context.Context, arg GetPrintParams) (Print, error)
+	GetReclassifyRun(ctx context.Context, id pgtype.UUID) (ReclassifyRun, error)
+	GetSavedSearch(ctx context.Context, id pgtype.UUID) (SavedSearch, error)
 	GetScan(ctx context.Context, id pgtype.UUID) (Scan, error)
+	GetScanSchedule(ctx context.Context, id pgtype.UUID) (ScanSchedule, error)
+	HasFilesUnder(ctx context.Context, prefix string) (bool, error)
+	IsCategoryDescendant(ctx context.Context, arg IsCategoryDescendantParams) (bool, error)
+	ListAIUsage(ctx context.Context, limit int32) ([]AiUsageDaily, error)
+	ListAliasesForCategory(ctx context.Context, categoryID pgtype.UUID) ([]CategoryAlias, error)
 	ListAllFiles(ctx context.Context) ([]File, error)
 	ListAllFilesPaginated(ctx context.Context, arg ListAllFilesPaginatedParams) ([]File, error)
 	ListCategories(ctx context.Context) ([]Category, error)
+	ListCategoriesByID(ctx context.Context, ids []pgtype.UUID) ([]Category, error)
 	ListCategoriesPaginated(ctx context.Context, arg ListCategoriesPaginatedParams) ([]Category, error)
+	ListCategoryAliases(ctx context.Context) ([]CategoryAlias, error)
+	ListCategoryProposals(ctx context.Context, arg ListCategoryProposalsParams) ([]CategoryProposal, error)
+	ListCategorySubtreeNames(ctx context.Context, name string) ([]string, error)
+	ListCollectionExportFiles(ctx context.Context, collectionID pgtype.UUID) ([]ListCollectionExportFilesRow, error)
+	ListCollectionItems(ctx context.Context, collectionID pgtype.UUID) ([]CollectionItem, error)
+	ListCollectionSummaries(ctx context.Context) ([]ListCollectionSummariesRow, error)
+	ListCollections(ctx context.Context, arg ListCollectionsParams) ([]ListCollectionsRow, error)
+	ListCustomFields(ctx context.Context) ([]CustomField, error)
+	ListDueScanSchedules(ctx context.Context) ([]ScanSchedule, error)
+	ListFileFieldValues(ctx context.Context, fileIds []pgtype.UUID) ([]ListFileFieldValuesRow, error)
+	ListFilePrints(ctx context.Context, arg ListFilePrintsParams) ([]Print, error)
 	ListFiles(ctx context.Context, arg ListFilesParams) ([]File, error)
+	ListFilesInDir(ctx context.Context, dir string) ([]File, error)
+	ListFilesOutsideDirs(ctx context.Context, arg ListFilesOutsideDirsParams) ([]File, error)
+	ListFolderFieldValues(ctx context.Context, folderIds []pgtype.UUID) ([]ListFolderFieldValuesRow, error)
+	ListFolderImages(ctx context.Context, folderID pgtype.UUID) ([]FolderImage, error)
+	ListFolderTreeFiles(ctx context.Context, id pgtype.UUID) ([]File, error)
 	ListFolders(ctx context.Context) ([]Folder, error)
 	ListFoldersPaginated(ctx context.Context, arg ListFoldersPaginatedParams) ([]Folder, error)
+	ListJobLogs(ctx context.Context, arg ListJobLogsParams) ([]JobLog, error)
+	ListJobs(ctx context.Context, arg ListJobsParams) ([]Job, error)
+	ListMoveCandidates(ctx context.Context, arg ListMoveCandidatesParams) ([]File, error)
+	ListPinnedSavedSearches(ctx context.Context) ([]SavedSearch, error)
+	ListReclassifyCandidates(ctx context.Context, arg ListReclassifyCandidatesParams) ([]File, error)
+	ListReclassifyRuns(ctx context.Context, arg ListReclassifyRunsParams) ([]ReclassifyRun, error)
 	ListRootFiles(ctx context.Context) ([]File, error)
 	ListRootFilesPaginated(ctx context.Context, arg ListRootFilesPaginatedParams) ([]File, error)
 	ListRootFolders(ctx context.Context) ([]Folder, error)
 	ListRootFoldersPaginated(ctx context.Context, arg ListRootFoldersPaginatedParams) ([]Folder, error)
+	ListSavedSearches(ctx context.Context, arg ListSavedSearchesParams) ([]SavedSearch, error)
+	ListScanEvents(ctx context.Context, arg ListScanEventsParams) ([]ScanEvent, error)
+	ListScanSchedules(ctx context.Context, arg ListScanSchedulesParams) ([]ScanSchedule, error)
 	ListScans(ctx context.Context, arg ListScansParams) ([]Scan, error)
 	ListSubfolders(ctx context.Context, parentFolderID pgtype.UUID) ([]Folder, error)
 	ListSubfoldersPaginated(ctx context.Context, arg ListSubfoldersPaginatedParams) ([]Folder, error)
+	ListUnfinishedJobs(ctx context.Context) ([]Job, error)
+	MarkFileClassified(ctx context.Context, id pgtype.UUID) error
+	MergeFileCategories(ctx context.Context, arg MergeFileCategoriesParams) error
+	MergeFolderCategories(ctx context.Context, arg MergeFolderCategoriesParams) error
+	MoveCategoryAliases(ctx context.Context, arg MoveCategoryAliasesParams) error
+	MoveFile(ctx context.Context, arg MoveFileParams) error
+	RemoveAIFileCategories(ctx context.Context, fileID pgtype.UUID) error
 	RemoveAllFileCategories(ctx context.Context, fileID pgtype.UUID) error
 	RemoveFileCategory(ctx context.Context, arg RemoveFileCategoryParams) error
 	RemoveFolderCategory(ctx context.Context, arg RemoveFolderCategoryParams) error
+	RemoveSubtreeFileCategories(ctx context.Context, arg RemoveSubtreeFileCategoriesParams) (int64, error)
+	RemoveSubtreeFolderCategories(ctx context.Context, arg RemoveSubtreeFolderCategoriesParams) (int64, error)
+	ReparentCategories(ctx context.Context, arg ReparentCategoriesParams) error
+	ResolveCategoryName(ctx context.Context, name string) (Category, error)
 	RestoreCategory(ctx context.Context, id pgtype.UUID) error
+	SampleUncategorizedFiles(ctx context.Context, limit int32) ([]File, error)
 	SearchCategoriesPaginated(ctx context.Context, arg SearchCategoriesPaginatedParams) ([]Category, error)
-	SearchFiles(ctx context.Context, arg SearchFilesParams) ([]SearchFilesRow, error)
 	SearchFoldersPaginated(ctx context.Context, arg SearchFoldersPaginatedParams) ([]Folder, error)
 	SearchRootFoldersPaginated(ctx context.Context, arg SearchRootFoldersPaginatedParams) ([]Folder, error)
+	SetCollectionCover(ctx context.Context, arg SetCollectionCoverParams) (Collection, error)
+	SetCollectionItemPositions(ctx context.Context, ids []pgtype.UUID) error
+	SetFileFieldValue(ctx context.Context, arg SetFileFieldValueParams) error
+	SetFilesFavorite(ctx context.Context, arg SetFilesFavoriteParams) (int64, error)
 	SetFolderCategories(ctx context.Context, folderID pgtype.UUID) error
+	SetFolderContentsModifiedAt(ctx context.Context, arg SetFolderContentsModifiedAtParams) error
+	SetFolderFieldValue(ctx context.Context, arg SetFolderFieldValueParams) error
+	SetFolderPrimaryImage(ctx context.Context, arg SetFolderPrimaryImageParams) error
+	SetPrintPhoto(ctx context.Context, arg SetPrintPhotoParams) (Print, error)
+	SetReclassifyRunJob(ctx context.Context, arg SetReclassifyRunJobParams) error
+	SetScanJob(ctx context.Context, arg SetScanJobParams) error
+	SetScanPreview(ctx context.Context, arg SetScanPreviewParams) error
+	SetScanScheduleResult(ctx context.Context, arg SetScanScheduleResultParams) error
+	SoftDeleteCategories(ctx context.Context, ids []pgtype.UUID) error
 	SoftDeleteCategory(ctx context.Context, id pgtype.UUID) error
+	StartJob(ctx context.Context, id pgtype.UUID) error
+	SummarizeScanEvents(ctx context.Context, scanID pgtype.UUID) ([]SummarizeScanEventsRow, error)
+	TouchCollection(ctx context.Context, id pgtype.UUID) error
 	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error)
+	UpdateCategoryProposalStatus(ctx context.Context, arg UpdateCategoryProposalStatusParams) (CategoryProposal, error)
+	UpdateCollection(ctx context.Context, arg UpdateCollectionParams) (Collection, error)
+	UpdateCollectionItemNote(ctx context.Context, arg UpdateCollectionItemNoteParams) (CollectionItem, error)
+	UpdateCustomField(ctx context.Context, arg UpdateCustomFieldParams) (CustomField, error)
 	UpdateFile(ctx context.Context, arg UpdateFileParams) (File, error)
 	UpdateFileFolderID(ctx context.Context, arg UpdateFileFolderIDParams) error
+	UpdateFileMetadata(ctx context.Context, arg UpdateFileMetadataParams) (File, error)
 	UpdateFolder(ctx context.Context, arg UpdateFolderParams) (Folder, error)
+	UpdateFolderMetadata(ctx context.Context, arg UpdateFolderMetadataParams) (Folder, error)
 	UpdateFolderParent(ctx context.Context, arg UpdateFolderParentParams) (Folder, error)
+	UpdateFolderSource(ctx context.Context, arg UpdateFolderSourceParams) error
+	UpdateJobProgress(ctx context.Context, arg UpdateJobProgressParams) error
+	UpdatePrint(ctx context.Context, arg UpdatePrintParams) (Print, error)
+	UpdateReclassifyRunProgress(ctx context.Context, arg UpdateReclassifyRunProgressParams) error
+	UpdateSavedSearch(ctx context.Context, arg UpdateSavedSearchParams) (SavedSearch, error)
 	UpdateScan(ctx context.Context, arg UpdateScanParams) (Scan, error)
+	UpdateScanSchedule(ctx context.Context, arg UpdateScanScheduleParams) (ScanSchedule, error)
+	UpsertCategoryProposal(ctx context.Context, arg UpsertCategoryProposalParams) (CategoryProposal, error)
 	UpsertFile(ctx context.Context, arg UpsertFileParams) (File, error)
+	UpsertFiles(ctx context.Context, arg UpsertFilesParams) ([]UpsertFilesRow, error)
+	UpsertFolderImages(ctx context.Context, arg UpsertFolderImagesParams) error
 }
 
 var _ Querier = (*Queries)(nil)
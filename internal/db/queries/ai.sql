@@ -0,0 +1,40 @@
+-- name: AddAIUsage :exec
+INSERT INTO ai_usage_daily (day, requests, prompt_tokens, completion_tokens, cost_usd)
+VALUES (@day, 1, @prompt_tokens, @completion_tokens, @cost_usd)
+ON CONFLICT (day)
+DO UPDATE SET
+  requests = ai_usage_daily.requests + 1,
+  prompt_tokens = ai_usage_daily.prompt_tokens + EXCLUDED.prompt_tokens,
+  completion_tokens = ai_usage_daily.completion_tokens + EXCLUDED.completion_tokens,
+  cost_usd = ai_usage_daily.cost_usd + EXCLUDED.cost_usd,
+  updated_at = now();
+
+-- name: GetAIUsage :one
+SELECT * FROM ai_usage_daily
+WHERE day = $1;
+
+-- name: ListAIUsage :many
+SELECT * FROM ai_usage_daily
+ORDER BY day DESC
+LIMIT $1;
+
+-- name: EnqueueClassification :exec
+INSERT INTO classification_queue (file_id, scan_id, reason)
+VALUES ($1, $2, $3)
+ON CONFLICT (file_id)
+DO UPDATE SET
+  scan_id = EXCLUDED.scan_id,
+  reason = EXCLUDED.reason,
+  queued_at = now();
+
+-- name: DequeueClassification :exec
+DELETE FROM classification_queue WHERE file_id = $1;
+
+-- name: CountClassificationQueue :one
+SELECT COUNT(*) FROM classification_queue;
+
+-- name: DequeueManualClassifications :execrows
+DELETE FROM classification_queue q
+WHERE EXISTS (
+  SELECT 1 FROM files_categories fc WHERE fc.file_id = q.file_id AND fc.source = 'manual'
+);
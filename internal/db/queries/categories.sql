@@ -16,7 +16,8 @@ ORDER BY name ASC;
 -- name: ListCategoriesPaginated :many
 SELECT * FROM categories
 WHERE deleted_at IS NULL
-ORDER BY name ASC
+  AND (@after_id::uuid IS NULL OR (name, id) > (@after_name::citext, @after_id::uuid))
+ORDER BY name ASC, id ASC
 LIMIT $1 OFFSET $2;
 
 -- name: CountCategories :one
@@ -27,7 +28,8 @@ WHERE deleted_at IS NULL;
 SELECT * FROM categories
 WHERE deleted_at IS NULL
   AND name ILIKE '%' || @search::text || '%'
-ORDER BY name ASC
+  AND (@after_id::uuid IS NULL OR (name, id) > (@after_name::citext, @after_id::uuid))
+ORDER BY name ASC, id ASC
 LIMIT $1 OFFSET $2;
 
 -- name: CountSearchCategories :one
@@ -36,13 +38,13 @@ WHERE deleted_at IS NULL
   AND name ILIKE '%' || @search::text || '%';
 
 -- name: CreateCategory :one
-INSERT INTO categories (name)
-VALUES ($1)
+INSERT INTO categories (name, parent_id)
+VALUES ($1, $2)
 RETURNING *;
 
 -- name: UpdateCategory :one
 UPDATE categories
-SET name = $2
+SET name = $2, parent_id = $3
 WHERE id = $1 AND deleted_at IS NULL
 RETURNING *;
 
@@ -58,3 +60,45 @@ WHERE id = $1;
 
 -- name: DeleteCategory :exec
 DELETE FROM categories WHERE id = $1;
+
+-- name: ListCategoriesByID :many
+SELECT * FROM categories
+WHERE id = ANY(@ids::uuid[]) AND deleted_at IS NULL
+ORDER BY name ASC;
+
+-- name: IsCategoryDescendant :one
+WITH RECURSIVE subtree AS (
+  SELECT id FROM categories WHERE id = @ancestor_id::uuid
+  UNION ALL
+  SELECT c.id FROM categories c
+  INNER JOIN subtree ON c.parent_id = subtree.id
+)
+SELECT EXISTS (SELECT 1 FROM subtree WHERE id = @category_id::uuid) AS is_descendant;
+
+-- name: ListCategorySubtreeNames :many
+WITH RECURSIVE subtree AS (
+  SELECT id, name FROM categories WHERE categories.name = @name::text AND deleted_at IS NULL
+  UNION ALL
+  SELECT c.id, c.name FROM categories c
+  INNER JOIN subtree ON c.parent_id = subtree.id
+  WHERE c.deleted_at IS NULL
+)
+SELECT name FROM subtree;
+
+-- name: ResolveCategoryName :one
+SELECT c.* FROM categories c
+WHERE c.deleted_at IS NULL
+  AND (c.name = @name::text OR c.id = (
+    SELECT a.category_id FROM category_aliases a WHERE a.alias = @name::text
+  ))
+ORDER BY (c.name = @name::text) DESC
+LIMIT 1;
+
+-- name: ReparentCategories :exec
+UPDATE categories SET parent_id = @parent_id::uuid
+WHERE parent_id = ANY(@source_ids::uuid[]);
+
+-- name: SoftDeleteCategories :exec
+UPDATE categories
+SET deleted_at = NOW()
+WHERE id = ANY(@ids::uuid[]) AND deleted_at IS NULL;
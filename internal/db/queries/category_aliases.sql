@@ -0,0 +1,23 @@
+-- name: ListCategoryAliases :many
+SELECT a.* FROM category_aliases a
+INNER JOIN categories c ON c.id = a.category_id
+WHERE c.deleted_at IS NULL
+ORDER BY a.alias ASC;
+
+-- name: ListAliasesForCategory :many
+SELECT * FROM category_aliases
+WHERE category_id = $1
+ORDER BY alias ASC;
+
+-- name: AddCategoryAliases :exec
+INSERT INTO category_aliases (alias, category_id)
+SELECT name, @category_id::uuid FROM categories WHERE id = ANY(@source_ids::uuid[])
+ON CONFLICT (alias) DO UPDATE SET category_id = EXCLUDED.category_id;
+
+-- name: MoveCategoryAliases :exec
+UPDATE category_aliases SET category_id = @category_id::uuid
+WHERE category_id = ANY(@source_ids::uuid[]);
+
+-- name: DeleteCategoryNameAlias :exec
+DELETE FROM category_aliases
+WHERE alias = (SELECT name FROM categories WHERE id = $1);
@@ -0,0 +1,60 @@
+-- name: SampleUncategorizedFiles :many
+SELECT f.* FROM files f
+WHERE NOT EXISTS (
+  SELECT 1 FROM files_categories fc
+  INNER JOIN categories c ON c.id = fc.category_id
+  WHERE fc.file_id = f.id AND c.name <> 'uncategorized'
+)
+ORDER BY random()
+LIMIT $1;
+
+-- name: UpsertCategoryProposal :one
+INSERT INTO category_proposals (name, description)
+VALUES ($1, $2)
+ON CONFLICT (name) WHERE status = 'pending'
+DO UPDATE SET
+  description = EXCLUDED.description,
+  updated_at = now()
+RETURNING *;
+
+-- name: AddCategoryProposalFiles :exec
+INSERT INTO category_proposal_files (proposal_id, file_id)
+SELECT @proposal_id::uuid, UNNEST(@file_ids::uuid[])
+ON CONFLICT DO NOTHING;
+
+-- name: GetCategoryProposal :one
+SELECT * FROM category_proposals
+WHERE id = $1;
+
+-- name: ListCategoryProposals :many
+SELECT * FROM category_proposals
+WHERE (@status::text = '' OR status = @status::text)
+  AND (@after_id::uuid IS NULL OR (created_at, id) < (@after_created_at::timestamptz, @after_id::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountCategoryProposals :one
+SELECT COUNT(*) FROM category_proposals
+WHERE (@status::text = '' OR status = @status::text);
+
+-- name: GetCategoryProposalFiles :many
+SELECT f.* FROM files f
+INNER JOIN category_proposal_files cpf ON cpf.file_id = f.id
+WHERE cpf.proposal_id = $1
+ORDER BY f.file_name;
+
+-- name: GetCategoryProposalFilesBatch :many
+SELECT cpf.proposal_id, f.*
+FROM category_proposal_files cpf
+INNER JOIN files f ON f.id = cpf.file_id
+WHERE cpf.proposal_id = ANY(@proposal_ids::uuid[])
+ORDER BY cpf.proposal_id, f.file_name;
+
+-- name: UpdateCategoryProposalStatus :one
+UPDATE category_proposals
+SET status = $2, category_id = $3, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteCategoryProposal :exec
+DELETE FROM category_proposals WHERE id = $1;
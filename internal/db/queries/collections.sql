@@ -0,0 +1,99 @@
+-- name: CreateCollection :one
+INSERT INTO collections (name, description)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetCollection :one
+SELECT * FROM collections
+WHERE id = $1;
+
+-- name: ListCollections :many
+SELECT c.id, c.name, c.description, c.cover_path, c.cover_content_type, c.created_at, c.updated_at,
+  (SELECT COUNT(*) FROM collection_items ci WHERE ci.collection_id = c.id) AS item_count
+FROM collections c
+ORDER BY c.name ASC
+LIMIT $1 OFFSET $2;
+
+-- name: ListCollectionSummaries :many
+SELECT c.id, c.name,
+  (SELECT COUNT(*) FROM collection_items ci WHERE ci.collection_id = c.id) AS item_count
+FROM collections c
+ORDER BY c.name ASC;
+
+-- name: CountCollections :one
+SELECT COUNT(*) FROM collections;
+
+-- name: UpdateCollection :one
+UPDATE collections
+SET name = $2, description = $3, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetCollectionCover :one
+UPDATE collections
+SET cover_path = $2, cover_content_type = $3, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteCollection :execrows
+DELETE FROM collections WHERE id = $1;
+
+-- name: ListCollectionItems :many
+SELECT * FROM collection_items
+WHERE collection_id = $1
+ORDER BY position ASC, created_at ASC;
+
+-- name: CountCollectionItems :one
+SELECT COUNT(*) FROM collection_items
+WHERE collection_id = $1;
+
+-- name: GetCollectionItem :one
+SELECT * FROM collection_items
+WHERE collection_id = $1 AND id = $2;
+
+-- name: AddCollectionItem :one
+INSERT INTO collection_items (collection_id, file_id, folder_id, note, position)
+VALUES (@collection_id, @file_id, @folder_id, @note, (
+  SELECT COALESCE(MAX(position) + 1, 0) FROM collection_items WHERE collection_id = @collection_id
+))
+RETURNING *;
+
+-- name: UpdateCollectionItemNote :one
+UPDATE collection_items
+SET note = $3
+WHERE collection_id = $1 AND id = $2
+RETURNING *;
+
+-- name: DeleteCollectionItem :execrows
+DELETE FROM collection_items
+WHERE collection_id = $1 AND id = $2;
+
+-- name: SetCollectionItemPositions :exec
+UPDATE collection_items ci
+SET position = o.position - 1
+FROM unnest(@ids::uuid[]) WITH ORDINALITY AS o(id, position)
+WHERE ci.id = o.id;
+
+-- name: TouchCollection :exec
+UPDATE collections SET updated_at = now() WHERE id = $1;
+
+-- name: ListCollectionExportFiles :many
+WITH RECURSIVE tree AS (
+  SELECT ci.position, fo.id, fo.name AS dir
+  FROM collection_items ci
+  INNER JOIN folders fo ON fo.id = ci.folder_id
+  WHERE ci.collection_id = @collection_id
+  UNION ALL
+  SELECT tree.position, sub.id, tree.dir || '/' || sub.name
+  FROM folders sub
+  INNER JOIN tree ON sub.parent_folder_id = tree.id
+)
+SELECT f.id, f.path, f.file_name, tree.dir::text AS dir, tree.position
+FROM files f
+INNER JOIN tree ON f.folder_id = tree.id
+UNION ALL
+SELECT f.id, f.path, f.file_name, ''::text AS dir, ci.position
+FROM collection_items ci
+INNER JOIN files f ON f.id = ci.file_id
+WHERE ci.collection_id = @collection_id
+ORDER BY position, dir, file_name;
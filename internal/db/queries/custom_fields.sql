@@ -0,0 +1,57 @@
+-- name: ListCustomFields :many
+SELECT * FROM custom_fields
+ORDER BY key ASC;
+
+-- name: GetCustomField :one
+SELECT * FROM custom_fields
+WHERE id = $1;
+
+-- name: GetCustomFieldsByKeys :many
+SELECT * FROM custom_fields
+WHERE key = ANY(@keys::text[]);
+
+-- name: CreateCustomField :one
+INSERT INTO custom_fields (key, label, type, options)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: UpdateCustomField :one
+UPDATE custom_fields
+SET label = $2, options = $3, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteCustomField :execrows
+DELETE FROM custom_fields WHERE id = $1;
+
+-- name: SetFileFieldValue :exec
+INSERT INTO custom_field_values (field_id, file_id, value)
+VALUES ($1, $2, $3)
+ON CONFLICT (field_id, file_id) DO UPDATE SET value = EXCLUDED.value;
+
+-- name: DeleteFileFieldValue :exec
+DELETE FROM custom_field_values
+WHERE field_id = $1 AND file_id = $2;
+
+-- name: SetFolderFieldValue :exec
+INSERT INTO custom_field_values (field_id, folder_id, value)
+VALUES ($1, $2, $3)
+ON CONFLICT (field_id, folder_id) DO UPDATE SET value = EXCLUDED.value;
+
+-- name: DeleteFolderFieldValue :exec
+DELETE FROM custom_field_values
+WHERE field_id = $1 AND folder_id = $2;
+
+-- name: ListFileFieldValues :many
+SELECT v.file_id, cf.key, v.value
+FROM custom_field_values v
+INNER JOIN custom_fields cf ON cf.id = v.field_id
+WHERE v.file_id = ANY(@file_ids::uuid[])
+ORDER BY cf.key;
+
+-- name: ListFolderFieldValues :many
+SELECT v.folder_id, cf.key, v.value
+FROM custom_field_values v
+INNER JOIN custom_fields cf ON cf.id = v.field_id
+WHERE v.folder_id = ANY(@folder_ids::uuid[])
+ORDER BY cf.key;
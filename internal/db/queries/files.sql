@@ -9,17 +9,6 @@ SELECT * FROM files
 ORDER BY file_name ASC
 LIMIT $1 OFFSET $2;
 
--- name: SearchFiles :many
-SELECT
-  f.*,
-  similarity(f.file_name, $1) as sim
-FROM files f
-WHERE
-  ($1 = '' OR f.file_name % $1 OR f.path % $1)
-  AND ($2 = '' OR f.type = $2)
-ORDER BY sim DESC, f.file_name ASC
-LIMIT $3 OFFSET $4;
-
 -- name: CreateFile :one
 INSERT INTO files (path, file_name, type, size, modified_at, sha256)
 VALUES ($1, $2, $3, $4, $5, $6)
@@ -40,11 +29,36 @@ DO UPDATE SET
   type = EXCLUDED.type,
   size = EXCLUDED.size,
   modified_at = EXCLUDED.modified_at,
-  sha256 = EXCLUDED.sha256,
+  -- Keep the stored hash when the scan did not hash and the file did not change
+  sha256 = CASE
+    WHEN EXCLUDED.sha256 IS NOT NULL THEN EXCLUDED.sha256
+    WHEN files.size = EXCLUDED.size AND files.modified_at = EXCLUDED.modified_at THEN files.sha256
+  END,
   folder_id = EXCLUDED.folder_id,
   updated_at = now()
 RETURNING *;
 
+-- name: UpsertFiles :many
+INSERT INTO files (path, file_name, type, size, modified_at, sha256, folder_id)
+SELECT t.path, t.file_name, t.type, t.size, t.modified_at, NULLIF(t.sha256, ''), t.folder_id
+FROM unnest(
+  @paths::text[], @file_names::text[], @types::text[], @sizes::bigint[],
+  @modified_ats::timestamptz[], @sha256s::text[], @folder_ids::uuid[]
+) AS t(path, file_name, type, size, modified_at, sha256, folder_id)
+ON CONFLICT (path)
+DO UPDATE SET
+  file_name = EXCLUDED.file_name,
+  type = EXCLUDED.type,
+  size = EXCLUDED.size,
+  modified_at = EXCLUDED.modified_at,
+  sha256 = CASE
+    WHEN EXCLUDED.sha256 IS NOT NULL THEN EXCLUDED.sha256
+    WHEN files.size = EXCLUDED.size AND files.modified_at = EXCLUDED.modified_at THEN files.sha256
+  END,
+  folder_id = EXCLUDED.folder_id,
+  updated_at = now()
+RETURNING id, path;
+
 -- name: DeleteFile :exec
 DELETE FROM files WHERE id = $1;
 
@@ -71,8 +85,88 @@ LIMIT $1 OFFSET $2;
 -- name: ListRootFilesPaginated :many
 SELECT * FROM files
 WHERE folder_id IS NULL
-ORDER BY file_name ASC
+  AND (@after_id::uuid IS NULL OR (file_name, id) > (@after_name::text, @after_id::uuid))
+ORDER BY file_name ASC, id ASC
 LIMIT $1 OFFSET $2;
 
 -- name: CountRootFiles :one
 SELECT COUNT(*) FROM files WHERE folder_id IS NULL;
+
+-- name: MarkFileClassified :exec
+UPDATE files SET classified_at = now() WHERE id = $1;
+
+-- name: ListReclassifyCandidates :many
+SELECT f.* FROM files f
+WHERE (@category::text = '' OR EXISTS (
+    SELECT 1 FROM files_categories fc
+    WHERE fc.file_id = f.id AND fc.category_id IN (
+      WITH RECURSIVE subtree AS (
+        SELECT id FROM categories WHERE name = @category::text AND deleted_at IS NULL
+        UNION ALL
+        SELECT c.id FROM categories c
+        INNER JOIN subtree ON c.parent_id = subtree.id
+        WHERE c.deleted_at IS NULL
+      )
+      SELECT id FROM subtree
+    )
+  ) OR (@category::text = 'uncategorized' AND NOT EXISTS (
+    SELECT 1 FROM files_categories fc WHERE fc.file_id = f.id
+  )))
+  AND (@folder_id::uuid IS NULL OR f.folder_id IN (
+    WITH RECURSIVE subtree AS (
+      SELECT id FROM folders WHERE id = @folder_id::uuid
+      UNION ALL
+      SELECT sub.id FROM folders sub
+      INNER JOIN subtree ON sub.parent_folder_id = subtree.id
+    )
+    SELECT id FROM subtree
+  ))
+  AND (@type::text = '' OR f.type = @type::text)
+  AND (@classified_before::timestamptz IS NULL OR f.classified_at IS NULL OR f.classified_at < @classified_before::timestamptz)
+  AND (cardinality(@file_ids::uuid[]) = 0 OR f.id = ANY(@file_ids::uuid[]))
+  AND (NOT @queued::boolean OR EXISTS (
+    SELECT 1 FROM classification_queue q WHERE q.file_id = f.id
+  ))
+  AND NOT EXISTS (
+    SELECT 1 FROM files_categories fc WHERE fc.file_id = f.id AND fc.source = 'manual'
+  )
+ORDER BY f.path;
+
+-- name: HasFilesUnder :one
+SELECT EXISTS (SELECT 1 FROM files WHERE starts_with(path, @prefix::text)) AS has_files;
+
+-- name: ListFilesInDir :many
+SELECT * FROM files
+WHERE left(path, length(path) - length(file_name) - 1) = @dir::text;
+
+-- name: ListFilesOutsideDirs :many
+SELECT * FROM files
+WHERE starts_with(path, @prefix::text)
+  AND NOT (left(path, length(path) - length(file_name) - 1) = ANY(@dirs::text[]));
+
+-- name: ListMoveCandidates :many
+SELECT * FROM files
+WHERE starts_with(path, @prefix::text)
+  AND ((@sha256::text <> '' AND sha256 = @sha256::text)
+    OR (file_name = @file_name::text AND size = @size::bigint AND modified_at = @modified_at::timestamptz))
+ORDER BY (@sha256::text <> '' AND sha256 IS NOT DISTINCT FROM @sha256::text) DESC, path;
+
+-- name: MoveFile :exec
+UPDATE files SET path = $2, folder_id = $3, updated_at = now() WHERE id = $1;
+
+-- name: DeleteFilesByID :execrows
+DELETE FROM files WHERE id = ANY(@ids::uuid[]);
+
+-- name: GetFilesByIDs :many
+SELECT * FROM files
+WHERE id = ANY(@ids::uuid[])
+ORDER BY path;
+
+-- name: UpdateFileMetadata :one
+UPDATE files SET favorite = @favorite, rating = @rating, note = @note, updated_at = now()
+WHERE id = @id
+RETURNING *;
+
+-- name: SetFilesFavorite :execrows
+UPDATE files SET favorite = @favorite, updated_at = now()
+WHERE id = ANY(@ids::uuid[]);
@@ -31,10 +31,37 @@ INSERT INTO files_categories (file_id, category_id)
 SELECT UNNEST(@file_ids::uuid[]), UNNEST(@category_ids::uuid[])
 ON CONFLICT DO NOTHING;
 
--- name: GetFilesByCategory :many
-SELECT f.* FROM files f
-INNER JOIN files_categories fc ON fc.file_id = f.id
-INNER JOIN categories c ON c.id = fc.category_id
-WHERE c.name = $1
-ORDER BY f.file_name ASC
-LIMIT $2 OFFSET $3;
+-- name: AddManualFileCategory :exec
+INSERT INTO files_categories (file_id, category_id, source)
+VALUES ($1, $2, 'manual')
+ON CONFLICT (file_id, category_id) DO UPDATE SET source = 'manual';
+
+-- name: BulkAddManualFileCategories :exec
+INSERT INTO files_categories (file_id, category_id, source)
+SELECT UNNEST(@file_ids::uuid[]), UNNEST(@category_ids::uuid[]), 'manual'
+ON CONFLICT (file_id, category_id) DO UPDATE SET source = 'manual';
+
+-- name: RemoveAIFileCategories :exec
+DELETE FROM files_categories WHERE file_id = $1 AND source = 'ai';
+
+-- name: CountManualFileCategories :one
+SELECT COUNT(*) FROM files_categories
+WHERE file_id = $1 AND source = 'manual';
+
+-- name: BulkRemoveSelectedFileCategories :exec
+DELETE FROM files_categories
+WHERE file_id = ANY(@file_ids::uuid[]) AND category_id = ANY(@category_ids::uuid[]);
+
+-- name: MergeFileCategories :exec
+INSERT INTO files_categories (file_id, category_id, source)
+SELECT DISTINCT ON (fc.file_id) fc.file_id, @category_id::uuid, fc.source
+FROM files_categories fc
+WHERE fc.category_id = ANY(@source_ids::uuid[])
+ORDER BY fc.file_id, (fc.source = 'manual') DESC
+ON CONFLICT (file_id, category_id) DO UPDATE SET source = CASE
+  WHEN EXCLUDED.source = 'manual' THEN 'manual'
+  ELSE files_categories.source
+END;
+
+-- name: DeleteCategoryFileLinks :execrows
+DELETE FROM files_categories WHERE category_id = ANY(@category_ids::uuid[]);
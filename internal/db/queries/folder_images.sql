@@ -0,0 +1,27 @@
+-- name: UpsertFolderImages :exec
+INSERT INTO folder_images (folder_id, name, size, modified_at)
+SELECT @folder_id::uuid, t.name, t.size, t.modified_at
+FROM unnest(@names::text[], @sizes::bigint[], @modified_ats::timestamptz[]) AS t(name, size, modified_at)
+ON CONFLICT (folder_id, name)
+DO UPDATE SET size = EXCLUDED.size, modified_at = EXCLUDED.modified_at, updated_at = NOW()
+WHERE (folder_images.size, folder_images.modified_at) IS DISTINCT FROM (EXCLUDED.size, EXCLUDED.modified_at);
+
+-- name: DeleteMissingFolderImages :execrows
+DELETE FROM folder_images
+WHERE folder_id = @folder_id AND NOT (name = ANY(@names::text[]));
+
+-- name: ListFolderImages :many
+SELECT * FROM folder_images
+WHERE folder_id = $1
+ORDER BY name;
+
+-- name: GetFolderImage :one
+SELECT * FROM folder_images
+WHERE folder_id = $1 AND id = $2;
+
+-- name: SetFolderPrimaryImage :exec
+UPDATE folders
+SET primary_image_id = (SELECT i.id FROM folder_images i WHERE i.folder_id = @id AND i.name = @name::text),
+    updated_at = NOW()
+WHERE id = @id
+  AND primary_image_id IS DISTINCT FROM (SELECT i.id FROM folder_images i WHERE i.folder_id = @id AND i.name = @name::text);
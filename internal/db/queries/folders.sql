@@ -22,11 +22,14 @@ ORDER BY name;
 
 -- name: ListFoldersPaginated :many
 SELECT * FROM folders
-ORDER BY name
+WHERE (COALESCE(cardinality(@licenses::text[]), 0) = 0 OR source_license = ANY(@licenses::text[]))
+  AND (@after_id::uuid IS NULL OR (name, id) > (@after_name::text, @after_id::uuid))
+ORDER BY name, id
 LIMIT $1 OFFSET $2;
 
 -- name: CountFolders :one
-SELECT COUNT(*) FROM folders;
+SELECT COUNT(*) FROM folders
+WHERE COALESCE(cardinality(@licenses::text[]), 0) = 0 OR source_license = ANY(@licenses::text[]);
 
 -- name: ListRootFolders :many
 SELECT * FROM folders
@@ -36,7 +39,8 @@ ORDER BY name;
 -- name: ListRootFoldersPaginated :many
 SELECT * FROM folders
 WHERE parent_folder_id IS NULL
-ORDER BY name
+  AND (@after_id::uuid IS NULL OR (name, id) > (@after_name::text, @after_id::uuid))
+ORDER BY name, id
 LIMIT $1 OFFSET $2;
 
 -- name: CountRootFolders :one
@@ -45,25 +49,37 @@ WHERE parent_folder_id IS NULL;
 
 -- name: SearchFoldersPaginated :many
 SELECT * FROM folders
-WHERE name ILIKE '%' || @search::text || '%'
-ORDER BY name
+WHERE (name ILIKE '%' || @search::text || '%'
+    OR to_tsvector('simple', search_tokens(name)) @@ search_query(@search::text)
+    OR note ILIKE '%' || @search::text || '%')
+  AND (COALESCE(cardinality(@licenses::text[]), 0) = 0 OR source_license = ANY(@licenses::text[]))
+  AND (@after_id::uuid IS NULL OR (name, id) > (@after_name::text, @after_id::uuid))
+ORDER BY name, id
 LIMIT $1 OFFSET $2;
 
 -- name: CountSearchFolders :one
 SELECT COUNT(*) FROM folders
-WHERE name ILIKE '%' || @search::text || '%';
+WHERE (name ILIKE '%' || @search::text || '%'
+    OR to_tsvector('simple', search_tokens(name)) @@ search_query(@search::text)
+    OR note ILIKE '%' || @search::text || '%')
+  AND (COALESCE(cardinality(@licenses::text[]), 0) = 0 OR source_license = ANY(@licenses::text[]));
 
 -- name: SearchRootFoldersPaginated :many
 SELECT * FROM folders
 WHERE parent_folder_id IS NULL
-  AND name ILIKE '%' || @search::text || '%'
-ORDER BY name
+  AND (name ILIKE '%' || @search::text || '%'
+    OR to_tsvector('simple', search_tokens(name)) @@ search_query(@search::text)
+    OR note ILIKE '%' || @search::text || '%')
+  AND (@after_id::uuid IS NULL OR (name, id) > (@after_name::text, @after_id::uuid))
+ORDER BY name, id
 LIMIT $1 OFFSET $2;
 
 -- name: CountSearchRootFolders :one
 SELECT COUNT(*) FROM folders
 WHERE parent_folder_id IS NULL
-  AND name ILIKE '%' || @search::text || '%';
+  AND (name ILIKE '%' || @search::text || '%'
+    OR to_tsvector('simple', search_tokens(name)) @@ search_query(@search::text)
+    OR note ILIKE '%' || @search::text || '%');
 
 -- name: ListSubfolders :many
 SELECT * FROM folders
@@ -73,7 +89,8 @@ ORDER BY name;
 -- name: ListSubfoldersPaginated :many
 SELECT * FROM folders
 WHERE parent_folder_id = $1
-ORDER BY name
+  AND (@after_id::uuid IS NULL OR (name, id) > (@after_name::text, @after_id::uuid))
+ORDER BY name, id
 LIMIT $2 OFFSET $3;
 
 -- name: CountSubfolders :one
@@ -86,6 +103,25 @@ SET name = $2, path = $3, updated_at = NOW()
 WHERE id = $1
 RETURNING *;
 
+-- name: UpdateFolderMetadata :one
+UPDATE folders
+SET favorite = @favorite, rating = @rating, note = @note, updated_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: UpdateFolderSource :exec
+UPDATE folders
+SET source_designer = @source_designer, source_url = @source_url, source_license = @source_license,
+    source_description = @source_description, source_files = @source_files, updated_at = NOW()
+WHERE id = @id
+  AND (source_designer, source_url, source_license, source_description, source_files)
+    IS DISTINCT FROM (@source_designer::text, @source_url::text, @source_license::text, @source_description::text, @source_files::text[]);
+
+-- name: SetFolderContentsModifiedAt :exec
+UPDATE folders
+SET contents_modified_at = @contents_modified_at
+WHERE id = @id;
+
 -- name: UpdateFolderParent :one
 UPDATE folders
 SET parent_folder_id = $2, updated_at = NOW()
@@ -104,7 +140,8 @@ ORDER BY f.file_name;
 -- name: GetFolderFilesPaginated :many
 SELECT f.* FROM files f
 WHERE f.folder_id = $1
-ORDER BY f.file_name
+  AND (@after_id::uuid IS NULL OR (f.file_name, f.id) > (@after_name::text, @after_id::uuid))
+ORDER BY f.file_name, f.id
 LIMIT $2 OFFSET $3;
 
 -- name: CountFolderFiles :one
@@ -148,3 +185,190 @@ ON CONFLICT DO NOTHING;
 UPDATE files
 SET folder_id = $2
 WHERE id = $1;
+
+-- name: DeleteEmptyFolders :execrows
+DELETE FROM folders f
+WHERE (f.path = @dir::text OR starts_with(f.path, @dir::text || '/'))
+  AND NOT EXISTS (
+    SELECT 1 FROM files fi
+    JOIN folders d ON d.id = fi.folder_id
+    WHERE d.path = f.path OR starts_with(d.path, f.path || '/')
+  );
+
+-- name: CountFolderPropagation :one
+WITH RECURSIVE subtree AS (
+    SELECT folders.id, 0 AS depth FROM folders WHERE folders.id = @folder_id
+    UNION ALL
+    SELECT f.id, s.depth + 1 FROM folders f
+    INNER JOIN subtree s ON f.parent_folder_id = s.id
+    WHERE s.depth < @max_depth::int
+)
+SELECT
+    (SELECT COUNT(*) FROM subtree
+     WHERE subtree.depth > 0
+       AND CASE @mode::text
+         WHEN 'remove' THEN EXISTS (
+           SELECT 1 FROM folders_categories fc
+           WHERE fc.folder_id = subtree.id AND fc.category_id = ANY(@category_ids::uuid[])
+         )
+         WHEN 'add' THEN EXISTS (
+           SELECT 1 FROM UNNEST(@category_ids::uuid[]) AS wanted(id)
+           WHERE NOT EXISTS (
+             SELECT 1 FROM folders_categories fc
+             WHERE fc.folder_id = subtree.id AND fc.category_id = wanted.id
+           )
+         )
+         ELSE EXISTS (
+           SELECT 1 FROM UNNEST(@category_ids::uuid[]) AS wanted(id)
+           WHERE NOT EXISTS (
+             SELECT 1 FROM folders_categories fc
+             WHERE fc.folder_id = subtree.id AND fc.category_id = wanted.id
+           )
+         ) OR EXISTS (
+           SELECT 1 FROM folders_categories fc
+           WHERE fc.folder_id = subtree.id AND NOT fc.category_id = ANY(@category_ids::uuid[])
+         )
+       END) AS folders,
+    (SELECT COUNT(*) FROM files
+     INNER JOIN subtree ON files.folder_id = subtree.id
+     WHERE lower(files.type) = ANY(@types::text[])
+       AND CASE @mode::text
+         WHEN 'remove' THEN EXISTS (
+           SELECT 1 FROM files_categories fc
+           WHERE fc.file_id = files.id AND fc.category_id = ANY(@category_ids::uuid[])
+         )
+         WHEN 'add' THEN EXISTS (
+           SELECT 1 FROM UNNEST(@category_ids::uuid[]) AS wanted(id)
+           WHERE NOT EXISTS (
+             SELECT 1 FROM files_categories fc
+             WHERE fc.file_id = files.id AND fc.category_id = wanted.id AND fc.source = 'manual'
+           )
+         )
+         ELSE EXISTS (
+           SELECT 1 FROM UNNEST(@category_ids::uuid[]) AS wanted(id)
+           WHERE NOT EXISTS (
+             SELECT 1 FROM files_categories fc
+             WHERE fc.file_id = files.id AND fc.category_id = wanted.id AND fc.source = 'manual'
+           )
+         ) OR EXISTS (
+           SELECT 1 FROM files_categories fc
+           WHERE fc.file_id = files.id AND NOT fc.category_id = ANY(@category_ids::uuid[])
+         )
+       END) AS files,
+    (SELECT COALESCE(MAX(subtree.depth), 0) FROM subtree)::int AS depth;
+
+-- name: DeleteSubtreeFolderCategories :execrows
+WITH RECURSIVE subtree AS (
+    SELECT folders.id, 0 AS depth FROM folders WHERE folders.id = @folder_id
+    UNION ALL
+    SELECT f.id, s.depth + 1 FROM folders f
+    INNER JOIN subtree s ON f.parent_folder_id = s.id
+    WHERE s.depth < @max_depth::int
+)
+DELETE FROM folders_categories
+WHERE folder_id IN (SELECT subtree.id FROM subtree WHERE subtree.depth > 0);
+
+-- name: AddSubtreeFolderCategories :execrows
+WITH RECURSIVE subtree AS (
+    SELECT folders.id, 0 AS depth FROM folders WHERE folders.id = @folder_id
+    UNION ALL
+    SELECT f.id, s.depth + 1 FROM folders f
+    INNER JOIN subtree s ON f.parent_folder_id = s.id
+    WHERE s.depth < @max_depth::int
+)
+INSERT INTO folders_categories (folder_id, category_id)
+SELECT subtree.id, category_id
+FROM subtree
+CROSS JOIN UNNEST(@category_ids::uuid[]) AS category_id
+WHERE subtree.depth > 0
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteSubtreeFileCategories :execrows
+WITH RECURSIVE subtree AS (
+    SELECT folders.id, 0 AS depth FROM folders WHERE folders.id = @folder_id
+    UNION ALL
+    SELECT f.id, s.depth + 1 FROM folders f
+    INNER JOIN subtree s ON f.parent_folder_id = s.id
+    WHERE s.depth < @max_depth::int
+)
+DELETE FROM files_categories
+WHERE file_id IN (
+    SELECT files.id FROM files
+    INNER JOIN subtree ON files.folder_id = subtree.id
+    WHERE lower(files.type) = ANY(@types::text[])
+);
+
+-- name: AddSubtreeFileCategories :execrows
+WITH RECURSIVE subtree AS (
+    SELECT folders.id, 0 AS depth FROM folders WHERE folders.id = @folder_id
+    UNION ALL
+    SELECT f.id, s.depth + 1 FROM folders f
+    INNER JOIN subtree s ON f.parent_folder_id = s.id
+    WHERE s.depth < @max_depth::int
+)
+INSERT INTO files_categories (file_id, category_id, source)
+SELECT files.id, category_id, 'manual'
+FROM files
+INNER JOIN subtree ON files.folder_id = subtree.id
+CROSS JOIN UNNEST(@category_ids::uuid[]) AS category_id
+WHERE lower(files.type) = ANY(@types::text[])
+ON CONFLICT (file_id, category_id) DO UPDATE SET source = 'manual';
+
+-- name: RemoveSubtreeFolderCategories :execrows
+WITH RECURSIVE subtree AS (
+    SELECT folders.id, 0 AS depth FROM folders WHERE folders.id = @folder_id
+    UNION ALL
+    SELECT f.id, s.depth + 1 FROM folders f
+    INNER JOIN subtree s ON f.parent_folder_id = s.id
+    WHERE s.depth < @max_depth::int
+)
+DELETE FROM folders_categories
+WHERE folder_id IN (SELECT subtree.id FROM subtree WHERE subtree.depth > 0)
+  AND category_id = ANY(@category_ids::uuid[]);
+
+-- name: RemoveSubtreeFileCategories :execrows
+WITH RECURSIVE subtree AS (
+    SELECT folders.id, 0 AS depth FROM folders WHERE folders.id = @folder_id
+    UNION ALL
+    SELECT f.id, s.depth + 1 FROM folders f
+    INNER JOIN subtree s ON f.parent_folder_id = s.id
+    WHERE s.depth < @max_depth::int
+)
+DELETE FROM files_categories
+WHERE file_id IN (
+    SELECT files.id FROM files
+    INNER JOIN subtree ON files.folder_id = subtree.id
+    WHERE lower(files.type) = ANY(@types::text[])
+)
+  AND category_id = ANY(@category_ids::uuid[]);
+
+-- name: MergeFolderCategories :exec
+INSERT INTO folders_categories (folder_id, category_id)
+SELECT DISTINCT fc.folder_id, @category_id::uuid
+FROM folders_categories fc
+WHERE fc.category_id = ANY(@source_ids::uuid[])
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteCategoryFolderLinks :execrows
+DELETE FROM folders_categories WHERE category_id = ANY(@category_ids::uuid[]);
+
+-- name: GetFoldersByIDs :many
+SELECT * FROM folders
+WHERE id = ANY(@ids::uuid[])
+ORDER BY path;
+
+-- name: GetFoldersByPaths :many
+SELECT * FROM folders
+WHERE path = ANY(@paths::text[])
+ORDER BY path;
+
+-- name: ListFolderTreeFiles :many
+WITH RECURSIVE subtree AS (
+  SELECT folders.id FROM folders WHERE folders.id = $1
+  UNION ALL
+  SELECT sub.id FROM folders sub
+  INNER JOIN subtree ON sub.parent_folder_id = subtree.id
+)
+SELECT f.* FROM files f
+WHERE f.folder_id IN (SELECT subtree.id FROM subtree)
+ORDER BY f.path;
@@ -0,0 +1,59 @@
+-- name: CreateJob :one
+INSERT INTO jobs (type, exclusive_key, payload)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetJob :one
+SELECT * FROM jobs
+WHERE id = $1;
+
+-- name: ListJobs :many
+SELECT * FROM jobs
+WHERE (@type::text = '' OR type = @type::text)
+  AND (@status::text = '' OR status = @status::text)
+  AND (@after_id::uuid IS NULL OR (created_at, id) < (@after_created_at::timestamptz, @after_id::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountJobs :one
+SELECT COUNT(*) FROM jobs
+WHERE (@type::text = '' OR type = @type::text)
+  AND (@status::text = '' OR status = @status::text);
+
+-- name: ListUnfinishedJobs :many
+SELECT * FROM jobs
+WHERE status IN ('queued', 'running')
+ORDER BY created_at ASC;
+
+-- name: StartJob :exec
+UPDATE jobs
+SET status = 'running', started_at = COALESCE(started_at, now()), updated_at = now()
+WHERE id = $1;
+
+-- name: UpdateJobProgress :exec
+UPDATE jobs
+SET progress = $2, updated_at = now()
+WHERE id = $1;
+
+-- name: FinishJob :exec
+UPDATE jobs
+SET status = $2, error = $3, result = $4, finished_at = now(), updated_at = now()
+WHERE id = $1;
+
+-- name: DeleteJob :exec
+DELETE FROM jobs WHERE id = $1;
+
+-- name: AddJobLog :exec
+INSERT INTO job_logs (job_id, level, message)
+VALUES ($1, $2, $3);
+
+-- name: ListJobLogs :many
+SELECT * FROM job_logs
+WHERE job_id = $1
+  AND id > @after_id::bigint
+ORDER BY id ASC
+LIMIT $2 OFFSET $3;
+
+-- name: CountJobLogs :one
+SELECT COUNT(*) FROM job_logs
+WHERE job_id = $1;
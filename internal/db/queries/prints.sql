@@ -0,0 +1,63 @@
+-- name: CreatePrint :one
+INSERT INTO prints (file_id, printed_at, printer, material, layer_height, nozzle, duration_seconds, result, note)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetPrint :one
+SELECT * FROM prints
+WHERE file_id = $1 AND id = $2;
+
+-- name: ListFilePrints :many
+SELECT * FROM prints
+WHERE file_id = $1
+ORDER BY printed_at DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountFilePrints :one
+SELECT COUNT(*) FROM prints
+WHERE file_id = $1;
+
+-- name: UpdatePrint :one
+UPDATE prints
+SET printed_at = $3, printer = $4, material = $5, layer_height = $6, nozzle = $7,
+  duration_seconds = $8, result = $9, note = $10, updated_at = now()
+WHERE file_id = $1 AND id = $2
+RETURNING *;
+
+-- name: SetPrintPhoto :one
+UPDATE prints
+SET photo_path = $3, photo_content_type = $4, updated_at = now()
+WHERE file_id = $1 AND id = $2
+RETURNING *;
+
+-- name: DeletePrint :execrows
+DELETE FROM prints
+WHERE file_id = $1 AND id = $2;
+
+-- name: GetFilePrintStats :many
+SELECT file_id,
+  COUNT(*) AS print_count,
+  COUNT(*) FILTER (WHERE result = 'success') AS success_count,
+  MAX(printed_at) AS last_printed_at
+FROM prints
+WHERE file_id = ANY(@file_ids::uuid[])
+GROUP BY file_id;
+
+-- name: GetFolderPrintStats :many
+WITH RECURSIVE tree AS (
+  SELECT id AS root_id, id
+  FROM folders
+  WHERE id = ANY(@folder_ids::uuid[])
+  UNION ALL
+  SELECT tree.root_id, sub.id
+  FROM folders sub
+  INNER JOIN tree ON sub.parent_folder_id = tree.id
+)
+SELECT tree.root_id AS folder_id,
+  COUNT(*) AS print_count,
+  COUNT(*) FILTER (WHERE p.result = 'success') AS success_count,
+  MAX(p.printed_at) AS last_printed_at
+FROM tree
+INNER JOIN files f ON f.folder_id = tree.id
+INNER JOIN prints p ON p.file_id = f.id
+GROUP BY tree.root_id;
@@ -0,0 +1,42 @@
+-- name: CreateReclassifyRun :one
+INSERT INTO reclassify_runs (dry_run, filter)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetReclassifyRun :one
+SELECT * FROM reclassify_runs
+WHERE id = $1;
+
+-- name: ListReclassifyRuns :many
+SELECT * FROM reclassify_runs
+WHERE (@after_id::uuid IS NULL OR (created_at, id) < (@after_created_at::timestamptz, @after_id::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountReclassifyRuns :one
+SELECT COUNT(*) FROM reclassify_runs;
+
+-- name: UpdateReclassifyRunProgress :exec
+UPDATE reclassify_runs
+SET status = $2, total = $3, processed = $4, changed = $5, queued = $6, progress = $7, updated_at = now()
+WHERE id = $1;
+
+-- name: FinishReclassifyRun :one
+UPDATE reclassify_runs
+SET status = $2, error = $3, report = $4, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteReclassifyRun :exec
+DELETE FROM reclassify_runs WHERE id = $1;
+
+-- name: SetReclassifyRunJob :exec
+UPDATE reclassify_runs SET job_id = $2 WHERE id = $1;
+
+-- name: FailInterruptedReclassifyRuns :execrows
+UPDATE reclassify_runs
+SET status = 'failed', error = 'interrupted by server restart', updated_at = now()
+WHERE status = 'running'
+  AND (job_id IS NULL OR job_id NOT IN (
+    SELECT id FROM jobs WHERE status IN ('queued', 'running')
+  ));
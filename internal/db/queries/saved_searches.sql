@@ -0,0 +1,30 @@
+-- name: CreateSavedSearch :one
+INSERT INTO saved_searches (name, filters, pinned)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetSavedSearch :one
+SELECT * FROM saved_searches
+WHERE id = $1;
+
+-- name: ListSavedSearches :many
+SELECT * FROM saved_searches
+ORDER BY pinned DESC, name ASC
+LIMIT $1 OFFSET $2;
+
+-- name: CountSavedSearches :one
+SELECT COUNT(*) FROM saved_searches;
+
+-- name: ListPinnedSavedSearches :many
+SELECT * FROM saved_searches
+WHERE pinned
+ORDER BY name ASC;
+
+-- name: UpdateSavedSearch :one
+UPDATE saved_searches
+SET name = $2, filters = $3, pinned = $4, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteSavedSearch :execrows
+DELETE FROM saved_searches WHERE id = $1;
@@ -0,0 +1,32 @@
+-- name: AddScanEvent :exec
+INSERT INTO scan_events (scan_id, file_id, event, path, previous_path, error)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: AddScanEvents :exec
+INSERT INTO scan_events (scan_id, file_id, event, path, previous_path, error)
+SELECT @scan_id::uuid, t.file_id, t.event, t.path, NULLIF(t.previous_path, ''), NULLIF(t.error, '')
+FROM unnest(
+  @file_ids::uuid[], @events::text[], @paths::text[], @previous_paths::text[], @errors::text[]
+) AS t(file_id, event, path, previous_path, error);
+
+-- name: AddRemovedScanEvents :exec
+INSERT INTO scan_events (scan_id, file_id, event, path)
+SELECT @scan_id::uuid, UNNEST(@file_ids::uuid[]), 'removed', UNNEST(@paths::text[]);
+
+-- name: ListScanEvents :many
+SELECT * FROM scan_events
+WHERE scan_id = @scan_id
+  AND (@event::text = '' OR event = @event::text)
+  AND id > @after_id::bigint
+ORDER BY id
+LIMIT $1 OFFSET $2;
+
+-- name: CountScanEvents :one
+SELECT COUNT(*) FROM scan_events
+WHERE scan_id = @scan_id
+  AND (@event::text = '' OR event = @event::text);
+
+-- name: SummarizeScanEvents :many
+SELECT event, COUNT(*) AS count FROM scan_events
+WHERE scan_id = $1
+GROUP BY event;
@@ -0,0 +1,41 @@
+-- name: CreateScanSchedule :one
+INSERT INTO scan_schedules (name, cron, timezone, options, enabled, next_run)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetScanSchedule :one
+SELECT * FROM scan_schedules
+WHERE id = $1;
+
+-- name: ListScanSchedules :many
+SELECT * FROM scan_schedules
+WHERE (@after_id::uuid IS NULL OR (name, id) > (@after_name::text, @after_id::uuid))
+ORDER BY name ASC, id ASC
+LIMIT $1 OFFSET $2;
+
+-- name: CountScanSchedules :one
+SELECT COUNT(*) FROM scan_schedules;
+
+-- name: UpdateScanSchedule :one
+UPDATE scan_schedules
+SET name = $2, cron = $3, timezone = $4, options = $5, enabled = $6, next_run = $7, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteScanSchedule :exec
+DELETE FROM scan_schedules WHERE id = $1;
+
+-- name: ListDueScanSchedules :many
+SELECT * FROM scan_schedules
+WHERE enabled AND next_run IS NOT NULL AND next_run <= now()
+ORDER BY next_run ASC;
+
+-- name: ClaimScanSchedule :execrows
+UPDATE scan_schedules
+SET next_run = @next_run, last_run_at = now(), updated_at = now()
+WHERE id = @id AND next_run = @due_at;
+
+-- name: SetScanScheduleResult :exec
+UPDATE scan_schedules
+SET last_status = $2, last_error = $3, updated_at = now()
+WHERE id = $1;
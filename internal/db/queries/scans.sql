@@ -3,15 +3,18 @@ SELECT * FROM scans WHERE id = $1 LIMIT 1;
 
 -- name: ListScans :many
 SELECT * FROM scans
-ORDER BY created_at DESC
+WHERE (@schedule_id::uuid IS NULL OR schedule_id = @schedule_id::uuid)
+  AND (@after_id::uuid IS NULL OR (created_at, id) < (@after_created_at::timestamptz, @after_id::uuid))
+ORDER BY created_at DESC, id DESC
 LIMIT $1 OFFSET $2;
 
 -- name: CountScans :one
-SELECT COUNT(*) FROM scans;
+SELECT COUNT(*) FROM scans
+WHERE (@schedule_id::uuid IS NULL OR schedule_id = @schedule_id::uuid);
 
 -- name: CreateScan :one
-INSERT INTO scans (status, found, processed, progress)
-VALUES ($1, $2, $3, $4)
+INSERT INTO scans (status, found, processed, progress, options, schedule_id)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: UpdateScan :one
@@ -22,3 +25,17 @@ RETURNING *;
 
 -- name: DeleteScan :exec
 DELETE FROM scans WHERE id = $1;
+
+-- name: SetScanJob :exec
+UPDATE scans SET job_id = $2 WHERE id = $1;
+
+-- name: SetScanPreview :exec
+UPDATE scans SET preview = $2 WHERE id = $1;
+
+-- name: FailInterruptedScans :execrows
+UPDATE scans
+SET status = 'failed', error = 'interrupted by server restart', updated_at = now()
+WHERE status = 'running'
+  AND (job_id IS NULL OR job_id NOT IN (
+    SELECT id FROM jobs WHERE status IN ('queued', 'running')
+  ));
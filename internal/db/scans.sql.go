@@ -13,26 +13,29 @@ import (
 
 const countScans = `-- name: CountScans :one
 SELECT COUNT(*) FROM scans
+WHERE ($1::uuid IS NULL OR schedule_id = $1::uuid)
 `
 
-func (q *Queries) CountScans(ctx context.Context) (int64, error) {
-	row := q.db.QueryRow(ctx, countScans)
+func (q *Queries) CountScans(ctx context.Context, scheduleID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countScans, scheduleID)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
 const createScan = `-- name: CreateScan :one
-INSERT INTO scans (status, found, processed, progress)
-VALUES ($1, $2, $3, $4)
-RETURNING id, status, found, processed, progress, error, created_at, updated_at
+INSERT INTO scans (status, found, processed, progress, options, schedule_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, status, found, processed, progress, error, created_at, updated_at, job_id, options, schedule_id, preview
 `
 
 type CreateScanParams struct {
-	Status    string      `json:"status"`
-	Found     pgtype.Int4 `json:"found"`
-	Processed pgtype.Int4 `json:"processed"`
-	Progress  pgtype.Int4 `json:"progress"`
+	Status     string      `json:"status"`
+	Found      pgtype.Int4 `json:"found"`
+	Processed  pgtype.Int4 `json:"processed"`
+	Progress   pgtype.Int4 `json:"progress"`
+	Options    []byte      `json:"options"`
+	ScheduleID pgtype.UUID `json:"schedule_id"`
 }
 
 func (q *Queries) CreateScan(ctx context.Context, arg CreateScanParams) (Scan, error) {
@@ -41,6 +44,8 @@ func (q *Queries) CreateScan(ctx context.Context, arg CreateScanParams) (Scan, e
 		arg.Found,
 		arg.Processed,
 		arg.Progress,
+		arg.Options,
+		arg.ScheduleID,
 	)
 	var i Scan
 	err := row.Scan(
@@ -52,6 +57,10 @@ func (q *Queries) CreateScan(ctx context.Context, arg CreateScanParams) (Scan, e
 		&i.Error,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.JobID,
+		&i.Options,
+		&i.ScheduleID,
+		&i.Preview,
 	)
 	return i, err
 }
@@ -65,8 +74,25 @@ func (q *Queries) DeleteScan(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const failInterruptedScans = `-- name: FailInterruptedScans :execrows
+UPDATE scans
+SET status = 'failed', error = 'interrupted by server restart', updated_at = now()
+WHERE status = 'running'
+  AND (job_id IS NULL OR job_id NOT IN (
+    SELECT id FROM jobs WHERE status IN ('queued', 'running')
+  ))
+`
+
+func (q *Queries) FailInterruptedScans(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, failInterruptedScans)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getScan = `-- name: GetScan :one
-SELECT id, status, found, processed, progress, error, created_at, updated_at FROM scans WHERE id = $1 LIMIT 1
+SELECT id, status, found, processed, progress, error, created_at, updated_at, job_id, options, schedule_id, preview FROM scans WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetScan(ctx context.Context, id pgtype.UUID) (Scan, error) {
@@ -81,23 +107,38 @@ func (q *Queries) GetScan(ctx context.Context, id pgtype.UUID) (Scan, error) {
 		&i.Error,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.JobID,
+		&i.Options,
+		&i.ScheduleID,
+		&i.Preview,
 	)
 	return i, err
 }
 
 const listScans = `-- name: ListScans :many
-SELECT id, status, found, processed, progress, error, created_at, updated_at FROM scans
-ORDER BY created_at DESC
+SELECT id, status, found, processed, progress, error, created_at, updated_at, job_id, options, schedule_id, preview FROM scans
+WHERE ($3::uuid IS NULL OR schedule_id = $3::uuid)
+  AND ($4::uuid IS NULL OR (created_at, id) < ($5::timestamptz, $4::uuid))
+ORDER BY created_at DESC, id DESC
 LIMIT $1 OFFSET $2
 `
 
 type ListScansParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	Limit          int32              `json:"limit"`
+	Offset         int32              `json:"offset"`
+	ScheduleID     pgtype.UUID        `json:"schedule_id"`
+	AfterID        pgtype.UUID        `json:"after_id"`
+	AfterCreatedAt pgtype.Timestamptz `json:"after_created_at"`
 }
 
 func (q *Queries) ListScans(ctx context.Context, arg ListScansParams) ([]Scan, error) {
-	rows, err := q.db.Query(ctx, listScans, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listScans,
+		arg.Limit,
+		arg.Offset,
+		arg.ScheduleID,
+		arg.AfterID,
+		arg.AfterCreatedAt,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -114,6 +155,10 @@ func (q *Queries) ListScans(ctx context.Context, arg ListScansParams) ([]Scan, e
 			&i.Error,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.JobID,
+			&i.Options,
+			&i.ScheduleID,
+			&i.Preview,
 		); err != nil {
 			return nil, err
 		}
@@ -125,11 +170,39 @@ func (q *Queries) ListScans(ctx context.Context, arg ListScansParams) ([]Scan, e
 	return items, nil
 }
 
+const setScanJob = `-- name: SetScanJob :exec
+UPDATE scans SET job_id = $2 WHERE id = $1
+`
+
+type SetScanJobParams struct {
+	ID    pgtype.UUID `json:"id"`
+	JobID pgtype.UUID `json:"job_id"`
+}
+
+func (q *Queries) SetScanJob(ctx context.Context, arg SetScanJobParams) error {
+	_, err := q.db.Exec(ctx, setScanJob, arg.ID, arg.JobID)
+	return err
+}
+
+const setScanPreview = `-- name: SetScanPreview :exec
+UPDATE scans SET preview = $2 WHERE id = $1
+`
+
+type SetScanPreviewParams struct {
+	ID      pgtype.UUID `json:"id"`
+	Preview []byte      `json:"preview"`
+}
+
+func (q *Queries) SetScanPreview(ctx context.Context, arg SetScanPreviewParams) error {
+	_, err := q.db.Exec(ctx, setScanPreview, arg.ID, arg.Preview)
+	return err
+}
+
 const updateScan = `-- name: UpdateScan :one
 UPDATE scans
 SET status = $2, found = $3, processed = $4, progress = $5, error = $6, updated_at = now()
 WHERE id = $1
-RETURNING id, status, found, processed, progress, error, created_at, updated_at
+RETURNING id, status, found, processed, progress, error, created_at, updated_at, job_id, options, schedule_id, preview
 `
 
 type UpdateScanParams struct {
@@ -160,6 +233,10 @@ func (q *Queries) UpdateScan(ctx context.Context, arg UpdateScanParams) (Scan, e
 		&i.Error,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.JobID,
+		&i.Options,
+		&i.ScheduleID,
+		&i.Preview,
 	)
 	return i, err
 }
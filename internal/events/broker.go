@@ -0,0 +1,143 @@
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// TopicLibrary carries library-wide changes so open clients can refresh
+const TopicLibrary = "library"
+
+// Library event types
+const (
+	TypeScanStarted        = "scan.started"
+	TypeScanFinished       = "scan.finished"
+	TypeFilesUpdated       = "files.updated"
+	TypeFoldersUpdated     = "folders.updated"
+	TypeCategoriesUpdated  = "categories.updated"
+	TypeReclassifyFinished = "reclassify.finished"
+)
+
+// subscriberBuffer is how many undelivered events a subscriber can hold before
+// the oldest ones are dropped
+const subscriberBuffer = 256
+
+// ScanTopic returns the topic that carries the progress of a single scan
+func ScanTopic(scanID uuid.UUID) string {
+	return "scan:" + scanID.String()
+}
+
+// Event is a message published on a topic
+type Event struct {
+	ID    uint64
+	Topic string
+	Type  string
+	Data  any
+}
+
+// Broker fans out events to in-process subscribers. Publishing never blocks:
+// a subscriber that falls behind loses its oldest events, so the latest state
+// (such as a final scan event) is always delivered.
+type Broker struct {
+	nextID atomic.Uint64
+
+	mu     sync.RWMutex
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// NewBroker creates an event broker
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscription receives the events of one topic until it is closed
+type Subscription struct {
+	topic  string
+	ch     chan Event
+	broker *Broker
+	once   sync.Once
+}
+
+// Subscribe starts receiving events published on topic. Subscribing to a closed
+// broker returns a subscription whose channel is already closed.
+func (b *Broker) Subscribe(topic string) *Subscription {
+	sub := &Subscription{
+		topic:  topic,
+		ch:     make(chan Event, subscriberBuffer),
+		broker: b,
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub.ch)
+		return sub
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// Close closes every subscription channel so open streams end, e.g. on shutdown
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subs {
+		close(sub.ch)
+		delete(b.subs, sub)
+	}
+}
+
+// Events returns the channel events are delivered on. It is closed when the
+// broker closes.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.broker.mu.Lock()
+		delete(s.broker.subs, s)
+		s.broker.mu.Unlock()
+	})
+}
+
+// Publish sends an event to every subscriber of topic. A nil broker discards it.
+func (b *Broker) Publish(topic, eventType string, data any) {
+	if b == nil {
+		return
+	}
+	event := Event{
+		ID:    b.nextID.Add(1),
+		Topic: topic,
+		Type:  eventType,
+		Data:  data,
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		if sub.topic != topic {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			// Drop the oldest event to make room
+			select {
+			case <-sub.ch:
+			default:
+			}
+			select {
+			case sub.ch <- event:
+			default:
+			}
+		}
+	}
+}
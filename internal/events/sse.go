@@ -0,0 +1,91 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// heartbeatInterval keeps idle connections open through proxies
+const heartbeatInterval = 15 * time.Second
+
+// Stream writes events to w as Server-Sent Events. initial events are sent first,
+// then events from sub until the client disconnects, the broker closes or stop
+// returns true for a delivered event. stop may be nil.
+func Stream(w http.ResponseWriter, r *http.Request, sub *Subscription, initial []Event, stop func(Event) bool) error {
+	rc := http.NewResponseController(w)
+
+	// The stream outlives the server write timeout
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event Event) error {
+		if err := writeEvent(w, event); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	for _, event := range initial {
+		if err := send(event); err != nil {
+			return err
+		}
+		if stop != nil && stop(event) {
+			return nil
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return err
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return err
+			}
+			if err := rc.Flush(); err != nil {
+				return err
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				return nil
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+			if stop != nil && stop(event) {
+				return nil
+			}
+		}
+	}
+}
+
+// writeEvent formats one event in the text/event-stream format
+func writeEvent(w http.ResponseWriter, event Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	if event.ID > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", event.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
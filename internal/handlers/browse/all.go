@@ -1,13 +1,17 @@
 package browse
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
 	"stl-manager/internal/db"
+	"stl-manager/internal/handlers/metadata"
+	"stl-manager/internal/pagination"
+	"stl-manager/internal/search"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -43,30 +47,38 @@ func (h *Handler) ListBrowse(w http.ResponseWriter, r *http.Request) {
 
 	query := r.URL.Query()
 	searchQuery := strings.TrimSpace(query.Get("q"))
-	page := 1
-	pageSize := 20
-	if p := query.Get("page"); p != "" {
-		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
-			page = parsed
-		}
+	wantFacets, err := search.WantFacets(query)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	if ps := query.Get("page_size"); ps != "" {
-		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
-			pageSize = parsed
-		}
+	p, err := pagination.Parse(query, pagination.DefaultPageSize)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	after, err := p.After(sortFolder)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var afterID pgtype.UUID
+	var afterName string
+	if after != nil {
+		afterID, afterName = after.ID, after.Key
 	}
-	offset := (page - 1) * pageSize
 
 	var folders []db.Folder
 	var totalFolders int64
-	var err error
 
 	// Use search queries if search parameter is provided
 	if searchQuery != "" {
 		folders, err = queries.SearchRootFoldersPaginated(ctx, db.SearchRootFoldersPaginatedParams{
-			Search: searchQuery,
-			Limit:  int32(pageSize),
-			Offset: int32(offset),
+			Search:    searchQuery,
+			Limit:     int32(p.Limit()),
+			Offset:    int32(p.Offset()),
+			AfterID:   afterID,
+			AfterName: afterName,
 		})
 		if err != nil {
 			h.logger.Error("failed to search root folders", zap.Error(err))
@@ -81,8 +93,10 @@ func (h *Handler) ListBrowse(w http.ResponseWriter, r *http.Request) {
 		}
 	} else {
 		folders, err = queries.ListRootFoldersPaginated(ctx, db.ListRootFoldersPaginatedParams{
-			Limit:  int32(pageSize),
-			Offset: int32(offset),
+			Limit:     int32(p.Limit()),
+			Offset:    int32(p.Offset()),
+			AfterID:   afterID,
+			AfterName: afterName,
 		})
 		if err != nil {
 			h.logger.Error("failed to list root folders", zap.Error(err))
@@ -96,14 +110,20 @@ func (h *Handler) ListBrowse(w http.ResponseWriter, r *http.Request) {
 			totalFolders = 0
 		}
 	}
+	folders, next := pagination.Trim(folders, p, folderCursor)
 
 	type BrowseItem struct {
-		ID         string        `json:"id"`
-		Name       string        `json:"name"`
-		Type       string        `json:"type"`
-		FileCount  *int          `json:"file_count,omitempty"`
-		Categories []db.Category `json:"categories"`
-		CreatedAt  string        `json:"created_at"`
+		ID             string          `json:"id"`
+		Name           string          `json:"name"`
+		Type           string          `json:"type"`
+		FileCount      *int            `json:"file_count,omitempty"`
+		Categories     []db.Category   `json:"categories"`
+		CreatedAt      string          `json:"created_at"`
+		Favorite       bool            `json:"favorite"`
+		Rating         pgtype.Int2     `json:"rating"`
+		Note           string          `json:"note,omitempty"`
+		CustomFields   metadata.Values `json:"custom_fields"`
+		PrimaryImageID pgtype.UUID     `json:"primary_image_id"`
 	}
 
 	// Collect folder IDs for batch query
@@ -129,6 +149,11 @@ func (h *Handler) ListBrowse(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	folderValues, err := metadata.FolderValues(ctx, queries, folderIDs)
+	if err != nil {
+		h.logger.Warn("failed to get folder custom fields", zap.Error(err))
+	}
+
 	items := make([]BrowseItem, 0, len(folders))
 
 	for _, folder := range folders {
@@ -146,117 +171,170 @@ func (h *Handler) ListBrowse(w http.ResponseWriter, r *http.Request) {
 		}
 
 		items = append(items, BrowseItem{
-			ID:         uuid.UUID(folder.ID.Bytes).String(),
-			Name:       folder.Name,
-			Type:       "folder",
-			FileCount:  &count,
-			Categories: categories,
-			CreatedAt:  folder.CreatedAt.Time.Format(time.RFC3339),
+			ID:             uuid.UUID(folder.ID.Bytes).String(),
+			Name:           folder.Name,
+			Type:           "folder",
+			FileCount:      &count,
+			Categories:     categories,
+			CreatedAt:      folder.CreatedAt.Time.Format(time.RFC3339),
+			Favorite:       folder.Favorite,
+			Rating:         folder.Rating,
+			Note:           folder.Note,
+			CustomFields:   folderValues.Get(folder.ID),
+			PrimaryImageID: folder.PrimaryImageID,
 		})
 	}
 
-	h.RespondJSON(w, http.StatusOK, map[string]interface{}{
-		"items":       items,
-		"total":       totalFolders,
-		"page":        page,
-		"page_size":   pageSize,
-		"total_pages": int((totalFolders + int64(pageSize) - 1) / int64(pageSize)),
-	})
-}
-
-// ListMixed returns folders + files respecting hierarchy
-func (h *Handler) ListMixed(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	queries := db.New(h.pool)
-
-	query := r.URL.Query()
-	page := 1
-	pageSize := 20
-	if p := query.Get("page"); p != "" {
-		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
-			page = parsed
-		}
+	response := p.Response(items, totalFolders, next)
+	collections, err := h.smartCollections(ctx, queries)
+	if err != nil {
+		h.logger.Error("failed to count smart collections", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "Failed to count smart collections")
+		return
 	}
-	if ps := query.Get("page_size"); ps != "" {
-		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
-			pageSize = parsed
+	response["smart_collections"] = collections
+	// Manual collections, for filtering GET /v1/files by collection_id
+	manual, err := queries.ListCollectionSummaries(ctx)
+	if err != nil {
+		h.logger.Error("failed to list collections", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "Failed to list collections")
+		return
+	}
+	response["collections"] = manual
+	if wantFacets {
+		// Facets cover the files under every matching root folder, not only this page
+		facets, err := search.FileFacets(ctx, h.pool, search.Filter{RootFolderName: searchQuery})
+		if err != nil {
+			h.logger.Error("failed to count browse facets", zap.Error(err))
+			h.RespondError(w, http.StatusInternalServerError, "Failed to count facets")
+			return
 		}
+		response["facets"] = facets
 	}
-	offset := (page - 1) * pageSize
 
-	folderIDStr := query.Get("folder_id")
+	h.RespondJSON(w, http.StatusOK, response)
+}
 
-	var folders []db.Folder
-	var files []db.File
-	var totalFolders int64
-	var totalFiles int64
-	var err error
+// SmartCollection is a pinned saved search with its current number of files
+type SmartCollection struct {
+	ID    pgtype.UUID `json:"id"`
+	Name  string      `json:"name"`
+	Count int64       `json:"count"`
+}
 
-	if folderIDStr == "" {
-		folders, err = queries.ListRootFoldersPaginated(ctx, db.ListRootFoldersPaginatedParams{
-			Limit:  int32(pageSize),
-			Offset: int32(offset),
-		})
+// smartCollections counts the files of every pinned saved search. A search
+// whose stored filters no longer parse is skipped.
+func (h *Handler) smartCollections(ctx context.Context, queries *db.Queries) ([]SmartCollection, error) {
+	pinned, err := queries.ListPinnedSavedSearches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	collections := make([]SmartCollection, 0, len(pinned))
+	for _, saved := range pinned {
+		filter, err := savedFilter(saved.Filters)
 		if err != nil {
-			h.logger.Error("failed to list root folders", zap.Error(err))
-			h.RespondError(w, http.StatusInternalServerError, "Failed to list folders")
-			return
+			h.logger.Warn("skipping saved search with invalid filters", zap.String("name", saved.Name), zap.Error(err))
+			continue
 		}
 
-		files, err = queries.ListRootFilesPaginated(ctx, db.ListRootFilesPaginatedParams{
-			Limit:  int32(pageSize),
-			Offset: int32(offset),
-		})
+		count, err := search.Count(ctx, h.pool, filter)
 		if err != nil {
-			h.logger.Error("failed to list root files", zap.Error(err))
-			h.RespondError(w, http.StatusInternalServerError, "Failed to list files")
-			return
+			return nil, err
 		}
+		collections = append(collections, SmartCollection{ID: saved.ID, Name: saved.Name, Count: count})
+	}
+	return collections, nil
+}
 
-		totalFolders, _ = queries.CountRootFolders(ctx)
-		totalFiles, _ = queries.CountRootFiles(ctx)
-	} else {
+// savedFilter parses the filters stored by a saved search
+func savedFilter(data []byte) (search.Filter, error) {
+	var filters map[string]string
+	if err := json.Unmarshal(data, &filters); err != nil {
+		return search.Filter{}, err
+	}
+	values, err := search.Values(filters)
+	if err != nil {
+		return search.Filter{}, err
+	}
+	filter, _, err := search.Parse(values)
+	return filter, err
+}
+
+// ListMixed returns folders + files respecting hierarchy
+func (h *Handler) ListMixed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	query := r.URL.Query()
+	p, err := pagination.Parse(query, pagination.DefaultPageSize)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Without folder_id the listing is the root: folders without parent and
+	// files outside any folder
+	var parentID pgtype.UUID
+	if folderIDStr := query.Get("folder_id"); folderIDStr != "" {
 		folderUUID, err := uuid.Parse(folderIDStr)
 		if err != nil {
 			h.RespondError(w, http.StatusBadRequest, "Invalid folder_id")
 			return
 		}
+		parentID = pgtype.UUID{Bytes: folderUUID, Valid: true}
+	}
 
-		folders, err = queries.ListSubfolders(ctx, pgtype.UUID{Bytes: folderUUID, Valid: true})
-		if err != nil {
-			h.logger.Error("failed to list subfolders", zap.Error(err))
-			h.RespondError(w, http.StatusInternalServerError, "Failed to list subfolders")
-			return
+	var totalFolders, totalFiles int64
+	if parentID.Valid {
+		totalFolders, err = queries.CountSubfolders(ctx, parentID)
+		if err == nil {
+			totalFiles, err = queries.CountFolderFiles(ctx, parentID)
 		}
-
-		files, err = queries.GetFolderFiles(ctx, pgtype.UUID{Bytes: folderUUID, Valid: true})
-		if err != nil {
-			h.logger.Error("failed to list folder files", zap.Error(err))
-			h.RespondError(w, http.StatusInternalServerError, "Failed to list files")
-			return
+	} else {
+		totalFolders, err = queries.CountRootFolders(ctx)
+		if err == nil {
+			totalFiles, err = queries.CountRootFiles(ctx)
 		}
+	}
+	if err != nil {
+		h.logger.Error("failed to count mixed items", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "Failed to list items")
+		return
+	}
 
-		totalFiles = int64(len(files))
-		totalFolders = int64(len(folders))
+	folders, files, next, err := listMixedPage(ctx, queries, parentID, p, totalFolders)
+	if errors.Is(err, pagination.ErrCursorMismatch) {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to list mixed items", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "Failed to list items")
+		return
 	}
 
 	type MixedItem struct {
-		ID         string        `json:"id"`
-		Name       string        `json:"name"`
-		Type       string        `json:"type"`
-		Size       *int64        `json:"size,omitempty"`
-		FileCount  *int          `json:"file_count,omitempty"`
-		Categories []db.Category `json:"categories"`
-		CreatedAt  string        `json:"created_at"`
+		ID           string          `json:"id"`
+		Name         string          `json:"name"`
+		Type         string          `json:"type"`
+		Size         *int64          `json:"size,omitempty"`
+		FileCount    *int            `json:"file_count,omitempty"`
+		Categories   []db.Category   `json:"categories"`
+		CreatedAt    string          `json:"created_at"`
+		Favorite     bool            `json:"favorite"`
+		Rating       pgtype.Int2     `json:"rating"`
+		Note         string          `json:"note,omitempty"`
+		CustomFields metadata.Values `json:"custom_fields"`
 	}
 
 	// Batch query for folder categories (1 query instead of N)
+	folderIDs := make([]pgtype.UUID, len(folders))
+	for i, folder := range folders {
+		folderIDs[i] = folder.ID
+	}
 	folderCategoriesMap := make(map[pgtype.UUID][]db.Category)
 	if len(folders) > 0 {
-		folderIDs := make([]pgtype.UUID, len(folders))
-		for i, folder := range folders {
-			folderIDs[i] = folder.ID
-		}
 		batchResults, err := queries.GetFolderCategoriesBatch(ctx, folderIDs)
 		if err != nil {
 			h.logger.Warn("failed to get folder categories batch", zap.Error(err))
@@ -272,12 +350,12 @@ func (h *Handler) ListMixed(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Batch query for file categories (1 query instead of M)
+	fileIDs := make([]pgtype.UUID, len(files))
+	for i, file := range files {
+		fileIDs[i] = file.ID
+	}
 	fileCategoriesMap := make(map[pgtype.UUID][]db.Category)
 	if len(files) > 0 {
-		fileIDs := make([]pgtype.UUID, len(files))
-		for i, file := range files {
-			fileIDs[i] = file.ID
-		}
 		batchResults, err := queries.GetCategoriesBatch(ctx, fileIDs)
 		if err != nil {
 			h.logger.Warn("failed to get file categories batch", zap.Error(err))
@@ -292,6 +370,15 @@ func (h *Handler) ListMixed(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	folderValues, err := metadata.FolderValues(ctx, queries, folderIDs)
+	if err != nil {
+		h.logger.Warn("failed to get folder custom fields", zap.Error(err))
+	}
+	fileValues, err := metadata.FileValues(ctx, queries, fileIDs)
+	if err != nil {
+		h.logger.Warn("failed to get file custom fields", zap.Error(err))
+	}
+
 	items := make([]MixedItem, 0, len(folders)+len(files))
 
 	for _, folder := range folders {
@@ -309,12 +396,16 @@ func (h *Handler) ListMixed(w http.ResponseWriter, r *http.Request) {
 		}
 
 		items = append(items, MixedItem{
-			ID:         uuid.UUID(folder.ID.Bytes).String(),
-			Name:       folder.Name,
-			Type:       "folder",
-			FileCount:  &count,
-			Categories: categories,
-			CreatedAt:  folder.CreatedAt.Time.Format(time.RFC3339),
+			ID:           uuid.UUID(folder.ID.Bytes).String(),
+			Name:         folder.Name,
+			Type:         "folder",
+			FileCount:    &count,
+			Categories:   categories,
+			CreatedAt:    folder.CreatedAt.Time.Format(time.RFC3339),
+			Favorite:     folder.Favorite,
+			Rating:       folder.Rating,
+			Note:         folder.Note,
+			CustomFields: folderValues.Get(folder.ID),
 		})
 	}
 
@@ -325,20 +416,122 @@ func (h *Handler) ListMixed(w http.ResponseWriter, r *http.Request) {
 		}
 
 		items = append(items, MixedItem{
-			ID:         uuid.UUID(file.ID.Bytes).String(),
-			Name:       file.FileName,
-			Type:       file.Type,
-			Size:       &file.Size,
-			Categories: categories,
-			CreatedAt:  file.CreatedAt.Time.Format(time.RFC3339),
+			ID:           uuid.UUID(file.ID.Bytes).String(),
+			Name:         file.FileName,
+			Type:         file.Type,
+			Size:         &file.Size,
+			Categories:   categories,
+			CreatedAt:    file.CreatedAt.Time.Format(time.RFC3339),
+			Favorite:     file.Favorite,
+			Rating:       file.Rating,
+			Note:         file.Note,
+			CustomFields: fileValues.Get(file.ID),
+		})
+	}
+
+	h.RespondJSON(w, http.StatusOK, p.Response(items, totalFolders+totalFiles, next))
+}
+
+// Cursor sorts of the browse listings. Mixed listings page through folders
+// first and then files, so their cursors also record which of the two they
+// stopped in.
+const (
+	sortFolder = "folder"
+	sortFile   = "file"
+)
+
+func folderCursor(f db.Folder) pagination.Cursor {
+	return pagination.NewCursor(sortFolder, f.Name, f.ID)
+}
+
+func fileCursor(f db.File) pagination.Cursor {
+	return pagination.NewCursor(sortFile, f.FileName, f.ID)
+}
+
+// listMixedPage returns one page of a folder's subfolders followed by its
+// files, both ordered by name, or of the root when parentID is null. Files
+// fill whatever the folders leave of the page.
+func listMixedPage(ctx context.Context, queries *db.Queries, parentID pgtype.UUID, p pagination.Params, totalFolders int64) ([]db.Folder, []db.File, string, error) {
+	after := p.Cursor
+	if after != nil && after.Sort != sortFolder && after.Sort != sortFile {
+		return nil, nil, "", pagination.ErrCursorMismatch
+	}
+
+	folders := []db.Folder{}
+	if after == nil || after.Sort == sortFolder {
+		var afterID pgtype.UUID
+		var afterName string
+		if after != nil {
+			afterID, afterName = after.ID, after.Key
+		}
+		var err error
+		if parentID.Valid {
+			folders, err = queries.ListSubfoldersPaginated(ctx, db.ListSubfoldersPaginatedParams{
+				ParentFolderID: parentID,
+				Limit:          int32(p.Limit()),
+				Offset:         int32(p.Offset()),
+				AfterID:        afterID,
+				AfterName:      afterName,
+			})
+		} else {
+			folders, err = queries.ListRootFoldersPaginated(ctx, db.ListRootFoldersPaginatedParams{
+				Limit:     int32(p.Limit()),
+				Offset:    int32(p.Offset()),
+				AfterID:   afterID,
+				AfterName: afterName,
+			})
+		}
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if len(folders) > p.PageSize {
+			folders, next := pagination.Trim(folders, p, folderCursor)
+			return folders, []db.File{}, next, nil
+		}
+	}
+
+	// Files start where the folders end: right after the cursor, at the
+	// start of the files for a folder cursor, or at the page offset minus
+	// the folders that precede them
+	files := pagination.Params{PageSize: p.PageSize - len(folders)}
+	var afterID pgtype.UUID
+	var afterName string
+	offset := 0
+	if after != nil && after.Sort == sortFile {
+		afterID, afterName = after.ID, after.Key
+	} else if after == nil {
+		offset = max(0, p.Offset()-int(totalFolders))
+	}
+	rows, err := func() ([]db.File, error) {
+		if parentID.Valid {
+			return queries.GetFolderFilesPaginated(ctx, db.GetFolderFilesPaginatedParams{
+				FolderID:  parentID,
+				Limit:     int32(files.Limit()),
+				Offset:    int32(offset),
+				AfterID:   afterID,
+				AfterName: afterName,
+			})
+		}
+		return queries.ListRootFilesPaginated(ctx, db.ListRootFilesPaginatedParams{
+			Limit:     int32(files.Limit()),
+			Offset:    int32(offset),
+			AfterID:   afterID,
+			AfterName: afterName,
 		})
+	}()
+	if err != nil {
+		return nil, nil, "", err
 	}
 
-	h.RespondJSON(w, http.StatusOK, map[string]interface{}{
-		"items":       items,
-		"total":       totalFolders + totalFiles,
-		"page":        page,
-		"page_size":   pageSize,
-		"total_pages": int((totalFolders + totalFiles + int64(pageSize) - 1) / int64(pageSize)),
-	})
+	// A page filled by folders only continues from its last folder when
+	// files follow
+	if files.PageSize == 0 {
+		next := ""
+		if len(rows) > 0 {
+			next = folderCursor(folders[len(folders)-1]).Encode()
+		}
+		return folders, []db.File{}, next, nil
+	}
+	rows, next := pagination.Trim(rows, files, fileCursor)
+	return folders, rows, next, nil
 }
@@ -1,16 +1,81 @@
 package categories
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"stl-manager/internal/db"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
 )
 
+// ErrNameRequired is returned when creating a category without a name
+var ErrNameRequired = errors.New("name is required")
+
+var (
+	// ErrInvalidParent is returned when parent_id is not a UUID
+	ErrInvalidParent = errors.New("invalid parent category ID")
+	// ErrParentNotFound is returned when the parent category does not exist
+	ErrParentNotFound = errors.New("parent category not found")
+	// ErrParentCycle is returned when a category would become its own ancestor
+	ErrParentCycle = errors.New("a category cannot be placed under itself or one of its descendants")
+)
+
 type CreateCategoryRequest struct {
-	Name string `json:"name"`
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+// Create validates and inserts a category. It is the single create path used by
+// the HTTP handler and by other features that add categories (e.g. accepted AI proposals).
+// A null parentID creates a root category.
+func Create(ctx context.Context, queries *db.Queries, name string, parentID pgtype.UUID) (db.Category, error) {
+	if name == "" {
+		return db.Category{}, ErrNameRequired
+	}
+	return queries.CreateCategory(ctx, db.CreateCategoryParams{Name: name, ParentID: parentID})
+}
+
+// resolveParent parses and looks up a parent category ID. An empty ID means
+// a root category.
+func resolveParent(ctx context.Context, queries *db.Queries, id string) (pgtype.UUID, error) {
+	if id == "" {
+		return pgtype.UUID{}, nil
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return pgtype.UUID{}, ErrInvalidParent
+	}
+	parent, err := queries.GetCategory(ctx, pgtype.UUID{Bytes: parsed, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return pgtype.UUID{}, ErrParentNotFound
+	}
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	return parent.ID, nil
+}
+
+// respondParentError writes the response for an error returned by resolveParent
+// and reports whether it did
+func (h *Handler) respondParentError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, ErrInvalidParent), errors.Is(err, ErrParentCycle):
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrParentNotFound):
+		h.RespondError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		h.logger.Error("failed to get parent category", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to get parent category")
+	default:
+		return false
+	}
+	return true
 }
 
 func (h *Handler) CreateCategory(w http.ResponseWriter, r *http.Request) {
@@ -23,19 +88,23 @@ func (h *Handler) CreateCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate
-	if req.Name == "" {
-		h.RespondError(w, http.StatusBadRequest, "name is required")
+	parentID, err := resolveParent(ctx, queries, req.ParentID)
+	if h.respondParentError(w, err) {
 		return
 	}
 
 	// Create category
-	category, err := queries.CreateCategory(ctx, req.Name)
+	category, err := Create(ctx, queries, req.Name, parentID)
+	if errors.Is(err, ErrNameRequired) {
+		h.RespondError(w, http.StatusBadRequest, "name is required")
+		return
+	}
 	if err != nil {
 		h.logger.Error("failed to create category", zap.Error(err))
 		h.RespondError(w, http.StatusInternalServerError, "failed to create category")
 		return
 	}
 
+	h.publishCategoryChange(category.ID, "created")
 	h.RespondJSON(w, http.StatusCreated, category)
 }
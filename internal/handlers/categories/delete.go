@@ -31,6 +31,7 @@ func (h *Handler) SoftDeleteCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.publishCategoryChange(pgtype.UUID{Bytes: categoryID, Valid: true}, "deleted")
 	h.RespondJSON(w, http.StatusOK, map[string]string{"message": "category deleted successfully"})
 }
 
@@ -54,5 +55,11 @@ func (h *Handler) RestoreCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A restored category answers to its own name again, not as an alias of a merge target
+	if err := queries.DeleteCategoryNameAlias(ctx, pgtype.UUID{Bytes: categoryID, Valid: true}); err != nil {
+		h.logger.Warn("failed to remove category alias", zap.Error(err))
+	}
+
+	h.publishCategoryChange(pgtype.UUID{Bytes: categoryID, Valid: true}, "restored")
 	h.RespondJSON(w, http.StatusOK, map[string]string{"message": "category restored successfully"})
 }
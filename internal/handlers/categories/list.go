@@ -3,21 +3,24 @@ package categories
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"stl-manager/internal/db"
+	"stl-manager/internal/events"
+	"stl-manager/internal/pagination"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
 
 type Handler struct {
 	pool   *pgxpool.Pool
+	events *events.Broker
 	logger *zap.Logger
 }
 
-func New(pool *pgxpool.Pool, logger *zap.Logger) *Handler {
-	return &Handler{pool: pool, logger: logger}
+func New(pool *pgxpool.Pool, broker *events.Broker, logger *zap.Logger) *Handler {
+	return &Handler{pool: pool, events: broker, logger: logger}
 }
 
 func (h *Handler) RespondJSON(w http.ResponseWriter, status int, data interface{}) {
@@ -28,40 +31,70 @@ func (h *Handler) RespondJSON(w http.ResponseWriter, status int, data interface{
 	}
 }
 
+// publishCategoryChange notifies library event subscribers
+func (h *Handler) publishCategoryChange(categoryID pgtype.UUID, action string) {
+	h.events.Publish(events.TopicLibrary, events.TypeCategoriesUpdated, map[string]any{
+		"category_id": categoryID,
+		"action":      action,
+	})
+}
+
 func (h *Handler) RespondError(w http.ResponseWriter, status int, message string) {
 	h.RespondJSON(w, status, map[string]string{"error": message})
 }
 
+// sortByName tags cursors of the category list, ordered by name
+const sortByName = "name"
+
 func (h *Handler) ListCategories(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	queries := db.New(h.pool)
 
 	query := r.URL.Query()
-	searchQuery := query.Get("q")
-	page := 1
-	pageSize := 20
-	if p := query.Get("page"); p != "" {
-		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
-			page = parsed
+
+	// The tree view returns every active category nested under its parent
+	if query.Get("tree") == "true" {
+		categories, err := queries.ListCategories(ctx)
+		if err != nil {
+			h.logger.Error("failed to list categories", zap.Error(err))
+			h.RespondError(w, http.StatusInternalServerError, "failed to list categories")
+			return
 		}
+		h.RespondJSON(w, http.StatusOK, map[string]any{
+			"items": BuildTree(categories),
+			"total": len(categories),
+		})
+		return
 	}
-	if ps := query.Get("page_size"); ps != "" {
-		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
-			pageSize = parsed
-		}
+
+	searchQuery := query.Get("q")
+	p, err := pagination.Parse(query, pagination.DefaultPageSize)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	after, err := p.After(sortByName)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var afterID pgtype.UUID
+	var afterName string
+	if after != nil {
+		afterID, afterName = after.ID, after.Key
 	}
-	offset := (page - 1) * pageSize
 
 	var categories []db.Category
 	var total int64
-	var err error
 
 	// Use search if query parameter provided
 	if searchQuery != "" {
 		categories, err = queries.SearchCategoriesPaginated(ctx, db.SearchCategoriesPaginatedParams{
-			Search: searchQuery,
-			Limit:  int32(pageSize),
-			Offset: int32(offset),
+			Search:    searchQuery,
+			Limit:     int32(p.Limit()),
+			Offset:    int32(p.Offset()),
+			AfterID:   afterID,
+			AfterName: afterName,
 		})
 		if err != nil {
 			h.logger.Error("failed to search categories", zap.Error(err))
@@ -76,8 +109,10 @@ func (h *Handler) ListCategories(w http.ResponseWriter, r *http.Request) {
 		}
 	} else {
 		categories, err = queries.ListCategoriesPaginated(ctx, db.ListCategoriesPaginatedParams{
-			Limit:  int32(pageSize),
-			Offset: int32(offset),
+			Limit:     int32(p.Limit()),
+			Offset:    int32(p.Offset()),
+			AfterID:   afterID,
+			AfterName: afterName,
 		})
 		if err != nil {
 			h.logger.Error("failed to list categories", zap.Error(err))
@@ -92,11 +127,8 @@ func (h *Handler) ListCategories(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	h.RespondJSON(w, http.StatusOK, map[string]any{
-		"items":       categories,
-		"total":       total,
-		"page":        page,
-		"page_size":   pageSize,
-		"total_pages": int((total + int64(pageSize) - 1) / int64(pageSize)),
+	categories, next := pagination.Trim(categories, p, func(c db.Category) pagination.Cursor {
+		return pagination.NewCursor(sortByName, c.Name, c.ID)
 	})
+	h.RespondJSON(w, http.StatusOK, p.Response(categories, total, next))
 }
@@ -0,0 +1,200 @@
+package categories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"stl-manager/internal/db"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+type MergeRequest struct {
+	SourceIDs []string `json:"source_ids"`
+}
+
+// MergeCategories merges the source categories into the category in the URL.
+// File and folder links move to the target, sources are soft deleted and their
+// names become aliases of the target so AI output using them still resolves.
+func (h *Handler) MergeCategories(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	idStr := chi.URLParam(r, "id")
+	targetID, err := uuid.Parse(idStr)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+
+	var req MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if len(req.SourceIDs) == 0 {
+		h.RespondError(w, http.StatusBadRequest, "source_ids is required")
+		return
+	}
+
+	sourceIDs := make([]pgtype.UUID, 0, len(req.SourceIDs))
+	seen := make(map[uuid.UUID]bool, len(req.SourceIDs))
+	for _, id := range req.SourceIDs {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			h.RespondError(w, http.StatusBadRequest, "Invalid source category ID: "+id)
+			return
+		}
+		if parsed == targetID {
+			h.RespondError(w, http.StatusBadRequest, "a category cannot be merged into itself")
+			return
+		}
+		if !seen[parsed] {
+			seen[parsed] = true
+			sourceIDs = append(sourceIDs, pgtype.UUID{Bytes: parsed, Valid: true})
+		}
+	}
+
+	target, err := queries.GetCategory(ctx, pgtype.UUID{Bytes: targetID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.RespondError(w, http.StatusNotFound, "category not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to get category", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to merge categories")
+		return
+	}
+
+	sources, err := queries.ListCategoriesByID(ctx, sourceIDs)
+	if err != nil {
+		h.logger.Error("failed to get source categories", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to merge categories")
+		return
+	}
+	if len(sources) != len(sourceIDs) {
+		h.RespondError(w, http.StatusNotFound, "source category not found")
+		return
+	}
+
+	// Child categories of the sources move under the target, which would
+	// create a cycle if the target sits inside a source's subtree
+	for _, source := range sources {
+		inside, err := queries.IsCategoryDescendant(ctx, db.IsCategoryDescendantParams{
+			AncestorID: source.ID,
+			CategoryID: target.ID,
+		})
+		if err != nil {
+			h.logger.Error("failed to check category hierarchy", zap.Error(err))
+			h.RespondError(w, http.StatusInternalServerError, "failed to merge categories")
+			return
+		}
+		if inside {
+			h.RespondError(w, http.StatusBadRequest, "a category cannot be merged into one of its descendants")
+			return
+		}
+	}
+
+	files, folders, err := h.merge(ctx, target.ID, sourceIDs)
+	if err != nil {
+		h.logger.Error("failed to merge categories", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to merge categories")
+		return
+	}
+
+	aliases, err := queries.ListAliasesForCategory(ctx, target.ID)
+	if err != nil {
+		h.logger.Warn("failed to list category aliases", zap.Error(err))
+		aliases = []db.CategoryAlias{}
+	}
+	aliasNames := make([]string, len(aliases))
+	for i, alias := range aliases {
+		aliasNames[i] = alias.Alias
+	}
+	merged := make([]string, len(sources))
+	for i, source := range sources {
+		merged[i] = source.Name
+	}
+
+	h.logger.Info("categories merged",
+		zap.String("target", target.Name),
+		zap.Strings("sources", merged),
+		zap.Int64("files", files),
+		zap.Int64("folders", folders))
+
+	h.publishCategoryChange(target.ID, "merged")
+	h.RespondJSON(w, http.StatusOK, map[string]any{
+		"category": target,
+		"merged":   merged,
+		"files":    files,
+		"folders":  folders,
+		"aliases":  aliasNames,
+	})
+}
+
+// merge moves links and aliases from the sources to the target and soft
+// deletes the sources in one transaction. It returns the number of file and
+// folder links taken from the sources.
+func (h *Handler) merge(ctx context.Context, targetID pgtype.UUID, sourceIDs []pgtype.UUID) (int64, int64, error) {
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+	queries := db.New(h.pool).WithTx(tx)
+
+	// Duplicate pairs collapse into the target link, keeping manual over ai
+	if err := queries.MergeFileCategories(ctx, db.MergeFileCategoriesParams{
+		CategoryID: targetID,
+		SourceIds:  sourceIDs,
+	}); err != nil {
+		return 0, 0, err
+	}
+	files, err := queries.DeleteCategoryFileLinks(ctx, sourceIDs)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := queries.MergeFolderCategories(ctx, db.MergeFolderCategoriesParams{
+		CategoryID: targetID,
+		SourceIds:  sourceIDs,
+	}); err != nil {
+		return 0, 0, err
+	}
+	folders, err := queries.DeleteCategoryFolderLinks(ctx, sourceIDs)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := queries.ReparentCategories(ctx, db.ReparentCategoriesParams{
+		ParentID:  targetID,
+		SourceIds: sourceIDs,
+	}); err != nil {
+		return 0, 0, err
+	}
+
+	// Aliases of earlier merges follow the sources to the new target
+	if err := queries.MoveCategoryAliases(ctx, db.MoveCategoryAliasesParams{
+		CategoryID: targetID,
+		SourceIds:  sourceIDs,
+	}); err != nil {
+		return 0, 0, err
+	}
+	if err := queries.AddCategoryAliases(ctx, db.AddCategoryAliasesParams{
+		CategoryID: targetID,
+		SourceIds:  sourceIDs,
+	}); err != nil {
+		return 0, 0, err
+	}
+
+	if err := queries.SoftDeleteCategories(ctx, sourceIDs); err != nil {
+		return 0, 0, err
+	}
+	return files, folders, tx.Commit(ctx)
+}
@@ -0,0 +1,108 @@
+package categories
+
+import (
+	"context"
+	"strings"
+
+	"stl-manager/internal/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// pathSeparator joins category names into the path shown to the classifier
+const pathSeparator = "/"
+
+// Node is a category with its child categories, as returned by GET /v1/categories?tree=true
+type Node struct {
+	db.Category
+	Children []*Node `json:"children"`
+}
+
+// BuildTree nests categories under their parents. Categories whose parent is
+// missing from the list (e.g. soft deleted) are returned as roots. Children
+// keep the order of the input list.
+func BuildTree(categories []db.Category) []*Node {
+	nodes := make(map[pgtype.UUID]*Node, len(categories))
+	for _, cat := range categories {
+		nodes[cat.ID] = &Node{Category: cat, Children: []*Node{}}
+	}
+
+	roots := []*Node{}
+	for _, cat := range categories {
+		node := nodes[cat.ID]
+		if parent, ok := nodes[cat.ParentID]; ok && cat.ParentID.Valid {
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// Catalog is the category list offered to the classifier. Each category is
+// listed by its path from the root (e.g. "miniature/fantasy/dragons") so the
+// model sees the hierarchy and can pick the most specific leaf. Root
+// categories keep their plain name.
+type Catalog struct {
+	Paths      []string
+	Categories []db.Category
+	byPath     map[string]db.Category
+	aliases    map[string]db.Category
+}
+
+// LoadCatalog builds the classifier catalog from the active categories and
+// the aliases left by merges
+func LoadCatalog(ctx context.Context, queries *db.Queries) (*Catalog, error) {
+	categories, err := queries.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := queries.ListCategoryAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewCatalog(categories, aliases), nil
+}
+
+// NewCatalog builds the classifier catalog from active categories and their aliases
+func NewCatalog(categories []db.Category, aliases []db.CategoryAlias) *Catalog {
+	c := &Catalog{
+		Paths:      []string{},
+		Categories: categories,
+		byPath:     make(map[string]db.Category, len(categories)),
+		aliases:    make(map[string]db.Category, len(aliases)),
+	}
+
+	byID := make(map[pgtype.UUID]db.Category, len(categories))
+	for _, cat := range categories {
+		byID[cat.ID] = cat
+	}
+	for _, alias := range aliases {
+		if cat, ok := byID[alias.CategoryID]; ok {
+			c.aliases[strings.ToLower(alias.Alias)] = cat
+		}
+	}
+
+	var walk func(nodes []*Node, prefix string)
+	walk = func(nodes []*Node, prefix string) {
+		for _, node := range nodes {
+			path := prefix + node.Name
+			c.Paths = append(c.Paths, path)
+			c.byPath[strings.ToLower(path)] = node.Category
+			walk(node.Children, path+pathSeparator)
+		}
+	}
+	walk(BuildTree(categories), "")
+	return c
+}
+
+// Lookup returns the category for a path returned by the classifier. Paths
+// are matched case-insensitively, like category names. A merged category's
+// name resolves to the category it was merged into.
+func (c *Catalog) Lookup(path string) (db.Category, bool) {
+	if cat, ok := c.byPath[strings.ToLower(path)]; ok {
+		return cat, true
+	}
+	cat, ok := c.aliases[strings.ToLower(path)]
+	return cat, ok
+}
@@ -2,18 +2,23 @@ package categories
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"stl-manager/internal/db"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
 )
 
 type UpdateCategoryRequest struct {
 	Name string `json:"name"`
+	// ParentID moves the category; omit it to keep the current parent and
+	// send "" to make it a root category
+	ParentID *string `json:"parent_id,omitempty"`
 }
 
 func (h *Handler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
@@ -41,10 +46,41 @@ func (h *Handler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	current, err := queries.GetCategory(ctx, pgtype.UUID{Bytes: categoryID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.RespondError(w, http.StatusNotFound, "category not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to get category", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to update category")
+		return
+	}
+
+	parentID := current.ParentID
+	if req.ParentID != nil {
+		parentID, err = resolveParent(ctx, queries, *req.ParentID)
+		if err == nil && parentID.Valid {
+			// The new parent must not sit inside this category's own subtree
+			var cycle bool
+			cycle, err = queries.IsCategoryDescendant(ctx, db.IsCategoryDescendantParams{
+				AncestorID: current.ID,
+				CategoryID: parentID,
+			})
+			if err == nil && cycle {
+				err = ErrParentCycle
+			}
+		}
+		if h.respondParentError(w, err) {
+			return
+		}
+	}
+
 	// Update category
 	category, err := queries.UpdateCategory(ctx, db.UpdateCategoryParams{
-		ID:   pgtype.UUID{Bytes: categoryID, Valid: true},
-		Name: req.Name,
+		ID:       current.ID,
+		Name:     req.Name,
+		ParentID: parentID,
 	})
 	if err != nil {
 		h.logger.Error("failed to update category", zap.Error(err))
@@ -52,5 +88,6 @@ func (h *Handler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.publishCategoryChange(category.ID, "updated")
 	h.RespondJSON(w, http.StatusOK, category)
 }
@@ -0,0 +1,119 @@
+package collections
+
+import (
+	"errors"
+	"net/http"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/media"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// coverKind is the media directory covers are stored in
+const coverKind = "covers"
+
+// UploadCover sets the cover image from a multipart "image" field, replacing
+// any previous cover
+func (h *Handler) UploadCover(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	collection, ok := h.loadCollection(w, r)
+	if !ok {
+		return
+	}
+
+	image, err := media.FormImage(w, r, "image")
+	if errors.Is(err, media.ErrTooLarge) {
+		h.RespondError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer image.Close()
+
+	path, contentType, err := h.media.SaveImage(coverKind, uuid.UUID(collection.ID.Bytes).String(), image)
+	if errors.Is(err, media.ErrTooLarge) {
+		h.RespondError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	if errors.Is(err, media.ErrNotImage) {
+		h.RespondError(w, http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to save collection cover", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to save cover")
+		return
+	}
+
+	updated, err := db.New(h.pool).SetCollectionCover(ctx, db.SetCollectionCoverParams{
+		ID:               collection.ID,
+		CoverPath:        pgtype.Text{String: path, Valid: true},
+		CoverContentType: pgtype.Text{String: contentType, Valid: true},
+	})
+	if err != nil {
+		h.logger.Error("failed to set collection cover", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to save cover")
+		return
+	}
+
+	// A cover of another type was stored under a different extension
+	if collection.CoverPath.Valid && collection.CoverPath.String != path {
+		if err := h.media.Remove(collection.CoverPath.String); err != nil {
+			h.logger.Warn("failed to remove previous collection cover", zap.Error(err))
+		}
+	}
+
+	itemCount, err := db.New(h.pool).CountCollectionItems(ctx, collection.ID)
+	if err != nil {
+		h.logger.Warn("failed to count collection items", zap.Error(err))
+	}
+	h.RespondJSON(w, http.StatusOK, newCollectionResponse(updated, itemCount))
+}
+
+// GetCover serves the cover image
+func (h *Handler) GetCover(w http.ResponseWriter, r *http.Request) {
+	collection, ok := h.loadCollection(w, r)
+	if !ok {
+		return
+	}
+	if !collection.CoverPath.Valid {
+		h.RespondError(w, http.StatusNotFound, "collection has no cover")
+		return
+	}
+
+	if err := h.media.Serve(w, r, collection.CoverPath.String, collection.CoverContentType.String); err != nil {
+		h.logger.Warn("collection cover missing from media directory", zap.String("path", collection.CoverPath.String), zap.Error(err))
+		h.RespondError(w, http.StatusNotFound, "cover image not found")
+	}
+}
+
+// DeleteCover removes the cover image
+func (h *Handler) DeleteCover(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	collection, ok := h.loadCollection(w, r)
+	if !ok {
+		return
+	}
+	if !collection.CoverPath.Valid {
+		h.RespondError(w, http.StatusNotFound, "collection has no cover")
+		return
+	}
+
+	if _, err := db.New(h.pool).SetCollectionCover(ctx, db.SetCollectionCoverParams{ID: collection.ID}); err != nil {
+		h.logger.Error("failed to clear collection cover", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to remove cover")
+		return
+	}
+	if err := h.media.Remove(collection.CoverPath.String); err != nil {
+		h.logger.Warn("failed to remove collection cover", zap.Error(err))
+	}
+
+	h.RespondJSON(w, http.StatusOK, map[string]string{"message": "cover removed"})
+}
@@ -0,0 +1,97 @@
+package collections
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"stl-manager/internal/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// CollectionRequest is the body of create and update requests
+type CollectionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// CollectionResponse hides where the cover is stored; cover_url is null
+// without a cover
+type CollectionResponse struct {
+	ID          pgtype.UUID        `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	CoverURL    *string            `json:"cover_url"`
+	ItemCount   int64              `json:"item_count"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+func newCollectionResponse(c db.Collection, itemCount int64) CollectionResponse {
+	resp := CollectionResponse{
+		ID:          c.ID,
+		Name:        c.Name,
+		Description: c.Description,
+		ItemCount:   itemCount,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+	if c.CoverPath.Valid {
+		url := "/v1/collections/" + uuid.UUID(c.ID.Bytes).String() + "/cover"
+		resp.CoverURL = &url
+	}
+	return resp
+}
+
+// validate trims the request. The returned message is empty when the request
+// is valid.
+func (req *CollectionRequest) validate() string {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Description = strings.TrimSpace(req.Description)
+	if req.Name == "" {
+		return "name is required"
+	}
+	return ""
+}
+
+// isUniqueViolation reports whether err is a conflict on a unique constraint
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func (h *Handler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	var req CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		h.RespondError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	created, err := queries.CreateCollection(ctx, db.CreateCollectionParams{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if isUniqueViolation(err) {
+		h.RespondError(w, http.StatusConflict, "a collection with this name already exists")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to create collection", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to create collection")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusCreated, newCollectionResponse(created, 0))
+}
@@ -0,0 +1,32 @@
+package collections
+
+import (
+	"net/http"
+
+	"stl-manager/internal/db"
+
+	"go.uber.org/zap"
+)
+
+// DeleteCollection removes a collection, its items and its cover. The files
+// and folders it grouped are not touched.
+func (h *Handler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	collection, ok := h.loadCollection(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := db.New(h.pool).DeleteCollection(r.Context(), collection.ID); err != nil {
+		h.logger.Error("failed to delete collection", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to delete collection")
+		return
+	}
+
+	if collection.CoverPath.Valid {
+		if err := h.media.Remove(collection.CoverPath.String); err != nil {
+			h.logger.Warn("failed to remove collection cover", zap.String("path", collection.CoverPath.String), zap.Error(err))
+		}
+	}
+
+	h.RespondJSON(w, http.StatusOK, map[string]string{"message": "collection deleted successfully"})
+}
@@ -0,0 +1,196 @@
+package collections
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"stl-manager/internal/db"
+
+	"go.uber.org/zap"
+)
+
+// manifestName is the file describing the collection inside an export
+const manifestName = "collection.json"
+
+// Manifest is written into every export. Missing lists library files that
+// could not be read from disk and are not in the bundle.
+type Manifest struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	ExportedAt  time.Time      `json:"exported_at"`
+	Cover       string         `json:"cover,omitempty"`
+	Items       []ManifestItem `json:"items"`
+	Missing     []string       `json:"missing"`
+}
+
+// ManifestItem is an item of the collection and where it is in the bundle
+type ManifestItem struct {
+	Position int32  `json:"position"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Note     string `json:"note"`
+}
+
+// unsafeFilename matches characters kept out of the download file name
+var unsafeFilename = regexp.MustCompile(`[^\pL\pN._-]+`)
+
+// ExportCollection streams the collection as a ZIP: file items at the root,
+// folder items as directories with their whole subtree, the cover and a
+// collection.json manifest with the order and notes. The response is
+// streamed, so files missing on disk are listed in the manifest instead of
+// failing the request.
+func (h *Handler) ExportCollection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	collection, ok := h.loadCollection(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.collectionItems(ctx, queries, collection.ID)
+	if err != nil {
+		h.logger.Error("failed to list collection items", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to export collection")
+		return
+	}
+	files, err := queries.ListCollectionExportFiles(ctx, collection.ID)
+	if err != nil {
+		h.logger.Error("failed to list collection files", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to export collection")
+		return
+	}
+
+	// Large collections take longer than the server write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.Error("failed to clear write deadline", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to export collection")
+		return
+	}
+
+	filename := strings.Trim(unsafeFilename.ReplaceAllString(collection.Name, "_"), "_")
+	if filename == "" {
+		filename = "collection"
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+	w.WriteHeader(http.StatusOK)
+
+	zw := zip.NewWriter(w)
+	manifest := Manifest{
+		Name:        collection.Name,
+		Description: collection.Description,
+		ExportedAt:  time.Now().UTC(),
+		Items:       make([]ManifestItem, 0, len(items)),
+		Missing:     []string{},
+	}
+	names := map[string]bool{manifestName: true}
+
+	for _, item := range items {
+		entry := ManifestItem{Position: item.Position, Type: item.Type, Note: item.Note}
+		switch {
+		case item.File != nil:
+			entry.Name = item.File.FileName
+		case item.Folder != nil:
+			entry.Name = item.Folder.Name
+			entry.Path = item.Folder.Name + "/"
+		}
+		manifest.Items = append(manifest.Items, entry)
+	}
+
+	for _, f := range files {
+		name := uniqueName(names, path.Join(f.Dir, f.FileName))
+		if err := addFile(zw, name, f.Path); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			h.logger.Warn("skipping collection file", zap.String("path", f.Path), zap.Error(err))
+			manifest.Missing = append(manifest.Missing, f.Path)
+			continue
+		}
+		// File items point at their entry; the first entry wins for duplicates
+		for i := range manifest.Items {
+			if f.Dir == "" && items[i].File != nil && items[i].File.ID == f.ID {
+				manifest.Items[i].Path = name
+			}
+		}
+	}
+
+	if collection.CoverPath.Valid {
+		name := uniqueName(names, "cover"+path.Ext(collection.CoverPath.String))
+		if err := addFile(zw, name, h.media.Path(collection.CoverPath.String)); err != nil {
+			h.logger.Warn("skipping collection cover", zap.Error(err))
+		} else {
+			manifest.Cover = name
+		}
+	}
+
+	mw, err := zw.Create(manifestName)
+	if err == nil {
+		enc := json.NewEncoder(mw)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(manifest)
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		h.logger.Error("failed to write collection export", zap.Error(err))
+	}
+}
+
+// uniqueName returns name, or name with a " (2)", " (3)"... suffix when an
+// entry with that name is already in the bundle
+func uniqueName(names map[string]bool, name string) string {
+	candidate := name
+	ext := path.Ext(name)
+	for i := 2; names[candidate]; i++ {
+		candidate = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), i, ext)
+	}
+	names[candidate] = true
+	return candidate
+}
+
+// addFile copies a file from disk into the bundle. Archives and images are
+// already compressed and are stored as they are.
+func addFile(zw *zip.Writer, name, diskPath string) error {
+	file, err := os.Open(diskPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+	switch strings.ToLower(filepath.Ext(diskPath)) {
+	case ".zip", ".rar", ".7z", ".jpg", ".jpeg", ".png", ".webp", ".gif":
+		header.Method = zip.Store
+	}
+
+	dst, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, file)
+	return err
+}
@@ -0,0 +1,193 @@
+package collections
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/pagination"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// Item types
+const (
+	ItemFile   = "file"
+	ItemFolder = "folder"
+)
+
+// ItemResponse is a member of a collection with the file or folder it points at
+type ItemResponse struct {
+	ID        pgtype.UUID        `json:"id"`
+	Type      string             `json:"type"`
+	Position  int32              `json:"position"`
+	Note      string             `json:"note"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	File      *db.File           `json:"file,omitempty"`
+	Folder    *db.Folder         `json:"folder,omitempty"`
+}
+
+// CollectionDetail is a single collection with its items in order
+type CollectionDetail struct {
+	CollectionResponse
+	Items []ItemResponse `json:"items"`
+}
+
+// ListCollections lists collections by name with their number of items
+func (h *Handler) ListCollections(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	p, err := pagination.Parse(r.URL.Query(), pagination.DefaultPageSize)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if p.Cursor != nil {
+		h.RespondError(w, http.StatusBadRequest, "cursor is not supported; use page")
+		return
+	}
+
+	rows, err := queries.ListCollections(ctx, db.ListCollectionsParams{
+		Limit:  int32(p.PageSize),
+		Offset: int32(p.Offset()),
+	})
+	if err != nil {
+		h.logger.Error("failed to list collections", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to list collections")
+		return
+	}
+
+	total, err := queries.CountCollections(ctx)
+	if err != nil {
+		h.logger.Error("failed to count collections", zap.Error(err))
+		total = 0
+	}
+
+	items := make([]CollectionResponse, len(rows))
+	for i, row := range rows {
+		items[i] = newCollectionResponse(db.Collection{
+			ID:               row.ID,
+			Name:             row.Name,
+			Description:      row.Description,
+			CoverPath:        row.CoverPath,
+			CoverContentType: row.CoverContentType,
+			CreatedAt:        row.CreatedAt,
+			UpdatedAt:        row.UpdatedAt,
+		}, row.ItemCount)
+	}
+
+	h.RespondJSON(w, http.StatusOK, p.Response(items, total, ""))
+}
+
+// GetCollection returns a collection with its items in order
+func (h *Handler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	collection, ok := h.loadCollection(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.collectionItems(r.Context(), db.New(h.pool), collection.ID)
+	if err != nil {
+		h.logger.Error("failed to list collection items", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to get collection")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusOK, CollectionDetail{
+		CollectionResponse: newCollectionResponse(collection, int64(len(items))),
+		Items:              items,
+	})
+}
+
+// loadCollection reads the collection named by the id URL parameter. On
+// failure it has already written the error response.
+func (h *Handler) loadCollection(w http.ResponseWriter, r *http.Request) (db.Collection, bool) {
+	collectionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid collection ID")
+		return db.Collection{}, false
+	}
+
+	collection, err := db.New(h.pool).GetCollection(r.Context(), pgtype.UUID{Bytes: collectionID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.RespondError(w, http.StatusNotFound, "collection not found")
+		return db.Collection{}, false
+	}
+	if err != nil {
+		h.logger.Error("failed to get collection", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to get collection")
+		return db.Collection{}, false
+	}
+	return collection, true
+}
+
+// collectionItems loads the items of a collection in order, with their files
+// and folders fetched in one query each
+func (h *Handler) collectionItems(ctx context.Context, queries *db.Queries, collectionID pgtype.UUID) ([]ItemResponse, error) {
+	rows, err := queries.ListCollectionItems(ctx, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileIDs, folderIDs []pgtype.UUID
+	for _, row := range rows {
+		if row.FileID.Valid {
+			fileIDs = append(fileIDs, row.FileID)
+		} else {
+			folderIDs = append(folderIDs, row.FolderID)
+		}
+	}
+
+	files := make(map[pgtype.UUID]db.File, len(fileIDs))
+	if len(fileIDs) > 0 {
+		found, err := queries.GetFilesByIDs(ctx, fileIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range found {
+			files[f.ID] = f
+		}
+	}
+	folders := make(map[pgtype.UUID]db.Folder, len(folderIDs))
+	if len(folderIDs) > 0 {
+		found, err := queries.GetFoldersByIDs(ctx, folderIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range found {
+			folders[f.ID] = f
+		}
+	}
+
+	items := make([]ItemResponse, len(rows))
+	for i, row := range rows {
+		items[i] = newItemResponse(row)
+		if file, ok := files[row.FileID]; ok {
+			items[i].File = &file
+		}
+		if folder, ok := folders[row.FolderID]; ok {
+			items[i].Folder = &folder
+		}
+	}
+	return items, nil
+}
+
+func newItemResponse(item db.CollectionItem) ItemResponse {
+	resp := ItemResponse{
+		ID:        item.ID,
+		Type:      ItemFile,
+		Position:  item.Position,
+		Note:      item.Note,
+		CreatedAt: item.CreatedAt,
+	}
+	if item.FolderID.Valid {
+		resp.Type = ItemFolder
+	}
+	return resp
+}
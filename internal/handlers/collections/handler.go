@@ -0,0 +1,33 @@
+package collections
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"stl-manager/internal/media"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	pool   *pgxpool.Pool
+	media  *media.Store
+	logger *zap.Logger
+}
+
+func New(pool *pgxpool.Pool, store *media.Store, logger *zap.Logger) *Handler {
+	return &Handler{pool: pool, media: store, logger: logger}
+}
+
+func (h *Handler) RespondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if data != nil {
+		json.NewEncoder(w).Encode(data)
+	}
+}
+
+func (h *Handler) RespondError(w http.ResponseWriter, status int, message string) {
+	h.RespondJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,293 @@
+package collections
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+
+	"stl-manager/internal/db"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// AddItemRequest adds a file or a folder (exactly one of them). Without a
+// position the item goes last.
+type AddItemRequest struct {
+	FileID   string `json:"file_id"`
+	FolderID string `json:"folder_id"`
+	Note     string `json:"note"`
+	Position *int   `json:"position"`
+}
+
+// UpdateItemRequest changes the note and/or moves an item
+type UpdateItemRequest struct {
+	Note     *string `json:"note"`
+	Position *int    `json:"position"`
+}
+
+// AddCollectionItem adds a file or folder to a collection
+func (h *Handler) AddCollectionItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	collection, ok := h.loadCollection(w, r)
+	if !ok {
+		return
+	}
+
+	var req AddItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if (req.FileID == "") == (req.FolderID == "") {
+		h.RespondError(w, http.StatusBadRequest, "exactly one of file_id or folder_id is required")
+		return
+	}
+	if req.Position != nil && *req.Position < 0 {
+		h.RespondError(w, http.StatusBadRequest, "position must not be negative")
+		return
+	}
+
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		h.logger.Error("failed to begin transaction", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to add item")
+		return
+	}
+	defer tx.Rollback(ctx)
+	queries := db.New(h.pool).WithTx(tx)
+
+	params := db.AddCollectionItemParams{CollectionID: collection.ID, Note: req.Note}
+	if req.FileID != "" {
+		fileID, err := uuid.Parse(req.FileID)
+		if err != nil {
+			h.RespondError(w, http.StatusBadRequest, "invalid file_id")
+			return
+		}
+		params.FileID = pgtype.UUID{Bytes: fileID, Valid: true}
+		if _, err := queries.GetFile(ctx, params.FileID); errors.Is(err, pgx.ErrNoRows) {
+			h.RespondError(w, http.StatusNotFound, "file not found")
+			return
+		}
+	} else {
+		folderID, err := uuid.Parse(req.FolderID)
+		if err != nil {
+			h.RespondError(w, http.StatusBadRequest, "invalid folder_id")
+			return
+		}
+		params.FolderID = pgtype.UUID{Bytes: folderID, Valid: true}
+		if _, err := queries.GetFolder(ctx, params.FolderID); errors.Is(err, pgx.ErrNoRows) {
+			h.RespondError(w, http.StatusNotFound, "folder not found")
+			return
+		}
+	}
+
+	item, err := queries.AddCollectionItem(ctx, params)
+	if isUniqueViolation(err) {
+		h.RespondError(w, http.StatusConflict, "already in the collection")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to add collection item", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to add item")
+		return
+	}
+
+	if req.Position != nil {
+		if item.Position, err = moveItem(ctx, queries, collection.ID, item.ID, *req.Position); err != nil {
+			h.logger.Error("failed to move collection item", zap.Error(err))
+			h.RespondError(w, http.StatusInternalServerError, "failed to add item")
+			return
+		}
+	}
+	if err := queries.TouchCollection(ctx, collection.ID); err != nil {
+		h.logger.Error("failed to touch collection", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to add item")
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		h.logger.Error("failed to commit transaction", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to add item")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusCreated, newItemResponse(item))
+}
+
+// UpdateCollectionItem changes the note of an item and/or moves it
+func (h *Handler) UpdateCollectionItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	collection, ok := h.loadCollection(w, r)
+	if !ok {
+		return
+	}
+	itemID, err := uuid.Parse(chi.URLParam(r, "itemId"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid item ID")
+		return
+	}
+
+	var req UpdateItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Note == nil && req.Position == nil {
+		h.RespondError(w, http.StatusBadRequest, "note or position is required")
+		return
+	}
+	if req.Position != nil && *req.Position < 0 {
+		h.RespondError(w, http.StatusBadRequest, "position must not be negative")
+		return
+	}
+
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		h.logger.Error("failed to begin transaction", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to update item")
+		return
+	}
+	defer tx.Rollback(ctx)
+	queries := db.New(h.pool).WithTx(tx)
+
+	item, err := queries.GetCollectionItem(ctx, db.GetCollectionItemParams{
+		CollectionID: collection.ID,
+		ID:           pgtype.UUID{Bytes: itemID, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.RespondError(w, http.StatusNotFound, "item not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to get collection item", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to update item")
+		return
+	}
+
+	if req.Note != nil {
+		item, err = queries.UpdateCollectionItemNote(ctx, db.UpdateCollectionItemNoteParams{
+			CollectionID: collection.ID,
+			ID:           item.ID,
+			Note:         *req.Note,
+		})
+		if err != nil {
+			h.logger.Error("failed to update collection item note", zap.Error(err))
+			h.RespondError(w, http.StatusInternalServerError, "failed to update item")
+			return
+		}
+	}
+	if req.Position != nil {
+		if item.Position, err = moveItem(ctx, queries, collection.ID, item.ID, *req.Position); err != nil {
+			h.logger.Error("failed to move collection item", zap.Error(err))
+			h.RespondError(w, http.StatusInternalServerError, "failed to update item")
+			return
+		}
+	}
+	if err := queries.TouchCollection(ctx, collection.ID); err != nil {
+		h.logger.Error("failed to touch collection", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to update item")
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		h.logger.Error("failed to commit transaction", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to update item")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusOK, newItemResponse(item))
+}
+
+// DeleteCollectionItem removes an item and closes the gap it leaves
+func (h *Handler) DeleteCollectionItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	collection, ok := h.loadCollection(w, r)
+	if !ok {
+		return
+	}
+	itemID, err := uuid.Parse(chi.URLParam(r, "itemId"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid item ID")
+		return
+	}
+
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		h.logger.Error("failed to begin transaction", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to remove item")
+		return
+	}
+	defer tx.Rollback(ctx)
+	queries := db.New(h.pool).WithTx(tx)
+
+	deleted, err := queries.DeleteCollectionItem(ctx, db.DeleteCollectionItemParams{
+		CollectionID: collection.ID,
+		ID:           pgtype.UUID{Bytes: itemID, Valid: true},
+	})
+	if err != nil {
+		h.logger.Error("failed to delete collection item", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to remove item")
+		return
+	}
+	if deleted == 0 {
+		h.RespondError(w, http.StatusNotFound, "item not found")
+		return
+	}
+
+	if err := renumber(ctx, queries, collection.ID, nil); err != nil {
+		h.logger.Error("failed to renumber collection items", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to remove item")
+		return
+	}
+	if err := queries.TouchCollection(ctx, collection.ID); err != nil {
+		h.logger.Error("failed to touch collection", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to remove item")
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		h.logger.Error("failed to commit transaction", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to remove item")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusOK, map[string]string{"message": "item removed from collection"})
+}
+
+// moveItem puts an item at a position, shifting the items after it. A
+// position past the end moves the item last. It returns the final position.
+func moveItem(ctx context.Context, queries *db.Queries, collectionID, itemID pgtype.UUID, position int) (int32, error) {
+	err := renumber(ctx, queries, collectionID, func(ids []pgtype.UUID) []pgtype.UUID {
+		i := slices.Index(ids, itemID)
+		ids = slices.Delete(ids, i, i+1)
+		position = min(position, len(ids))
+		return slices.Insert(ids, position, itemID)
+	})
+	return int32(position), err
+}
+
+// renumber rewrites the positions of a collection's items from 0 in their
+// current order, optionally rearranged first
+func renumber(ctx context.Context, queries *db.Queries, collectionID pgtype.UUID, rearrange func([]pgtype.UUID) []pgtype.UUID) error {
+	items, err := queries.ListCollectionItems(ctx, collectionID)
+	if err != nil {
+		return err
+	}
+	ids := make([]pgtype.UUID, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	if rearrange != nil {
+		ids = rearrange(ids)
+	}
+	return queries.SetCollectionItemPositions(ctx, ids)
+}
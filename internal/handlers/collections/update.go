@@ -0,0 +1,64 @@
+package collections
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"stl-manager/internal/db"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// UpdateCollection replaces the name and description of a collection
+func (h *Handler) UpdateCollection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	collectionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid collection ID")
+		return
+	}
+
+	var req CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		h.RespondError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	id := pgtype.UUID{Bytes: collectionID, Valid: true}
+	updated, err := queries.UpdateCollection(ctx, db.UpdateCollectionParams{
+		ID:          id,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.RespondError(w, http.StatusNotFound, "collection not found")
+		return
+	}
+	if isUniqueViolation(err) {
+		h.RespondError(w, http.StatusConflict, "a collection with this name already exists")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to update collection", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to update collection")
+		return
+	}
+
+	itemCount, err := queries.CountCollectionItems(ctx, id)
+	if err != nil {
+		h.logger.Warn("failed to count collection items", zap.Error(err))
+	}
+
+	h.RespondJSON(w, http.StatusOK, newCollectionResponse(updated, itemCount))
+}
@@ -0,0 +1,45 @@
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+
+	eventbus "stl-manager/internal/events"
+
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	broker *eventbus.Broker
+	secret []byte
+	logger *zap.Logger
+}
+
+// New creates an events Handler. apiKey signs stream tokens.
+func New(broker *eventbus.Broker, apiKey string, logger *zap.Logger) *Handler {
+	return &Handler{
+		broker: broker,
+		secret: []byte(apiKey),
+		logger: logger,
+	}
+}
+
+func (h *Handler) RespondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if data != nil {
+		json.NewEncoder(w).Encode(data)
+	}
+}
+
+// StreamLibrary streams library changes (scans, files, folders, categories) as
+// Server-Sent Events so open clients can refresh without polling
+func (h *Handler) StreamLibrary(w http.ResponseWriter, r *http.Request) {
+	sub := h.broker.Subscribe(eventbus.TopicLibrary)
+	defer sub.Close()
+
+	initial := []eventbus.Event{{Type: "connected", Data: map[string]string{"topic": eventbus.TopicLibrary}}}
+	if err := eventbus.Stream(w, r, sub, initial, nil); err != nil {
+		h.logger.Debug("library event stream closed", zap.Error(err))
+	}
+}
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StreamTokenTTL is how long a stream token can open event streams
+const StreamTokenTTL = 10 * time.Minute
+
+// streamScope is signed into every token so it cannot stand for anything but
+// opening event streams
+const streamScope = "events"
+
+type StreamTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CreateStreamToken issues a short-lived token for event streams. EventSource
+// cannot send the X-API-Key header, and putting the API key itself in the URL
+// would leave it in access and proxy logs.
+func (h *Handler) CreateStreamToken(w http.ResponseWriter, r *http.Request) {
+	expiresAt := time.Now().Add(StreamTokenTTL).Truncate(time.Second)
+	h.RespondJSON(w, http.StatusOK, StreamTokenResponse{
+		Token:     h.signStreamToken(expiresAt),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// ValidStreamToken reports whether token was issued by CreateStreamToken and
+// has not expired. Tokens are only checked when a stream opens, so open
+// streams outlive them.
+func (h *Handler) ValidStreamToken(token string) bool {
+	expiry, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return false
+	}
+	expiresAt := time.Unix(unix, 0)
+	if !time.Now().Before(expiresAt) {
+		return false
+	}
+	return hmac.Equal([]byte(token), []byte(h.signStreamToken(expiresAt)))
+}
+
+// signStreamToken returns "<expiry>.<signature>", signed with the API key so
+// tokens survive restarts and stop working when the key changes
+func (h *Handler) signStreamToken(expiresAt time.Time) string {
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write([]byte(streamScope + ":" + expiry))
+	return expiry + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
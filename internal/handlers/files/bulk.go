@@ -0,0 +1,457 @@
+package files
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/events"
+	"stl-manager/internal/jobs"
+	"stl-manager/internal/search"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// maxBulkFiles caps how many files one bulk request can touch
+const maxBulkFiles = 5000
+
+// Bulk actions
+const (
+	BulkAddCategories    = "add_categories"
+	BulkRemoveCategories = "remove_categories"
+	BulkMove             = "move"
+	BulkReclassify       = "reclassify"
+	BulkFavorite         = "favorite"
+	BulkUnfavorite       = "unfavorite"
+	BulkDelete           = "delete"
+)
+
+// Bulk item statuses
+const (
+	BulkStatusOK       = "ok"
+	BulkStatusNotFound = "not_found"
+	BulkStatusFailed   = "failed"
+	// BulkStatusSkipped marks items rolled back because another item failed
+	BulkStatusSkipped = "skipped"
+)
+
+// ReclassifyFunc starts a reclassification job for the given files
+type ReclassifyFunc func(ctx context.Context, fileIDs []string) (db.ReclassifyRun, db.Job, error)
+
+// BulkRequest is the body of POST /v1/files/bulk. Files are selected either by
+// file_ids or by filter, which takes the query parameters of GET /v1/files
+// (see search.Parse) so a bulk action applies to exactly the files listed.
+type BulkRequest struct {
+	FileIDs []string          `json:"file_ids,omitempty"`
+	Filter  map[string]string `json:"filter,omitempty"`
+	Action  string            `json:"action"`
+	// CategoryIDs are the categories to add or remove
+	CategoryIDs []string `json:"category_ids,omitempty"`
+	// FolderID is the destination of a move
+	FolderID string `json:"folder_id,omitempty"`
+}
+
+// BulkItemResult is the outcome for one selected file
+type BulkItemResult struct {
+	FileID string `json:"file_id"`
+	Path   string `json:"path,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BulkResponse struct {
+	Action    string           `json:"action"`
+	Total     int              `json:"total"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Committed bool             `json:"committed"`
+	RunID     string           `json:"run_id,omitempty"`
+	JobID     string           `json:"job_id,omitempty"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// bulkTarget holds the validated arguments of an action
+type bulkTarget struct {
+	categoryIDs []pgtype.UUID
+	folder      db.Folder
+}
+
+// BulkUpdateFiles applies one action to a selection of files in a single
+// transaction: either every file is updated or none is.
+func (h *Handler) BulkUpdateFiles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	var req BulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	switch req.Action {
+	case BulkAddCategories, BulkRemoveCategories, BulkMove, BulkReclassify, BulkFavorite, BulkUnfavorite, BulkDelete:
+	case "":
+		h.RespondError(w, http.StatusBadRequest, "action is required")
+		return
+	default:
+		h.RespondError(w, http.StatusBadRequest, "unknown action: "+req.Action)
+		return
+	}
+	if (len(req.FileIDs) == 0) == (req.Filter == nil) {
+		h.RespondError(w, http.StatusBadRequest, "either file_ids or filter is required")
+		return
+	}
+	if len(req.FileIDs) > maxBulkFiles {
+		h.RespondError(w, http.StatusBadRequest, fmt.Sprintf("at most %d files per request", maxBulkFiles))
+		return
+	}
+
+	target, status, msg := h.bulkTarget(ctx, queries, req)
+	if status != 0 {
+		h.RespondError(w, status, msg)
+		return
+	}
+
+	// Select the files
+	var (
+		files    []db.File
+		results  []BulkItemResult
+		notFound int
+	)
+	if req.Filter != nil {
+		values, err := search.Values(req.Filter)
+		if err != nil {
+			h.RespondError(w, http.StatusBadRequest, "invalid filter: "+err.Error())
+			return
+		}
+		filter, _, err := search.Parse(values)
+		if err != nil {
+			h.RespondError(w, http.StatusBadRequest, "invalid filter: "+err.Error())
+			return
+		}
+		files, err = search.Select(ctx, h.pool, filter, maxBulkFiles+1)
+		if err != nil {
+			h.logger.Error("failed to select files", zap.Error(err))
+			h.RespondError(w, http.StatusInternalServerError, "failed to select files")
+			return
+		}
+		if len(files) > maxBulkFiles {
+			h.RespondError(w, http.StatusBadRequest, fmt.Sprintf("filter matches more than %d files", maxBulkFiles))
+			return
+		}
+	} else {
+		ids := make([]pgtype.UUID, 0, len(req.FileIDs))
+		seen := make(map[uuid.UUID]bool, len(req.FileIDs))
+		for _, idStr := range req.FileIDs {
+			id, err := uuid.Parse(idStr)
+			if err != nil {
+				h.RespondError(w, http.StatusBadRequest, "invalid file ID: "+idStr)
+				return
+			}
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, pgtype.UUID{Bytes: id, Valid: true})
+			}
+		}
+		var err error
+		files, err = queries.GetFilesByIDs(ctx, ids)
+		if err != nil {
+			h.logger.Error("failed to get files", zap.Error(err))
+			h.RespondError(w, http.StatusInternalServerError, "failed to select files")
+			return
+		}
+		found := make(map[pgtype.UUID]bool, len(files))
+		for _, file := range files {
+			found[file.ID] = true
+		}
+		for _, id := range ids {
+			if !found[id] {
+				notFound++
+				results = append(results, BulkItemResult{
+					FileID: uuid.UUID(id.Bytes).String(),
+					Status: BulkStatusNotFound,
+					Error:  "file not found",
+				})
+			}
+		}
+	}
+
+	resp := BulkResponse{Action: req.Action, Total: len(files) + notFound}
+	if notFound > 0 {
+		resp.Failed = notFound
+		resp.Results = append(results, bulkResults(files, nil, BulkStatusSkipped)...)
+		h.RespondJSON(w, http.StatusUnprocessableEntity, resp)
+		return
+	}
+
+	fileIDs := make([]pgtype.UUID, len(files))
+	for i, file := range files {
+		fileIDs[i] = file.ID
+	}
+
+	itemErrors, err := h.applyBulk(ctx, req.Action, files, fileIDs, target)
+	if err != nil {
+		h.logger.Error("bulk update failed", zap.String("action", req.Action), zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "bulk update failed")
+		return
+	}
+	if len(itemErrors) > 0 {
+		resp.Failed = len(itemErrors)
+		resp.Results = bulkResults(files, itemErrors, BulkStatusSkipped)
+		h.RespondJSON(w, http.StatusUnprocessableEntity, resp)
+		return
+	}
+
+	// Reclassification reads the committed selection in a background job
+	if req.Action == BulkReclassify && len(files) > 0 {
+		ids := make([]string, len(files))
+		for i, file := range files {
+			ids[i] = uuid.UUID(file.ID.Bytes).String()
+		}
+		run, job, err := h.reclassify(ctx, ids)
+		if errors.Is(err, jobs.ErrAlreadyRunning) {
+			h.RespondError(w, http.StatusConflict, "a reclassification is already running")
+			return
+		}
+		if err != nil {
+			h.logger.Error("failed to start reclassification", zap.Error(err))
+			h.RespondError(w, http.StatusInternalServerError, "failed to start reclassification")
+			return
+		}
+		resp.RunID = uuid.UUID(run.ID.Bytes).String()
+		resp.JobID = uuid.UUID(job.ID.Bytes).String()
+	}
+
+	resp.Committed = true
+	resp.Succeeded = len(files)
+	resp.Results = bulkResults(files, nil, BulkStatusOK)
+
+	h.logger.Info("bulk file update",
+		zap.String("action", req.Action),
+		zap.Int("files", len(files)))
+	if len(files) > 0 && req.Action != BulkReclassify {
+		ids := make([]string, len(files))
+		for i, file := range files {
+			ids[i] = uuid.UUID(file.ID.Bytes).String()
+		}
+		h.events.Publish(events.TopicLibrary, events.TypeFilesUpdated, map[string]any{"file_ids": ids})
+	}
+	h.RespondJSON(w, http.StatusOK, resp)
+}
+
+// bulkTarget validates the arguments of the action. A non-zero status means
+// the request is rejected with msg.
+func (h *Handler) bulkTarget(ctx context.Context, queries *db.Queries, req BulkRequest) (bulkTarget, int, string) {
+	var target bulkTarget
+
+	switch req.Action {
+	case BulkAddCategories, BulkRemoveCategories:
+		if len(req.CategoryIDs) == 0 {
+			return target, http.StatusBadRequest, "category_ids is required for " + req.Action
+		}
+		for _, idStr := range req.CategoryIDs {
+			id, err := uuid.Parse(idStr)
+			if err != nil {
+				return target, http.StatusBadRequest, "invalid category ID: " + idStr
+			}
+			target.categoryIDs = append(target.categoryIDs, pgtype.UUID{Bytes: id, Valid: true})
+		}
+		categories, err := queries.ListCategoriesByID(ctx, target.categoryIDs)
+		if err != nil {
+			h.logger.Error("failed to get categories", zap.Error(err))
+			return target, http.StatusInternalServerError, "failed to get categories"
+		}
+		if len(categories) != len(target.categoryIDs) {
+			return target, http.StatusNotFound, "category not found"
+		}
+
+	case BulkMove:
+		folderID, err := uuid.Parse(req.FolderID)
+		if err != nil {
+			return target, http.StatusBadRequest, "folder_id is required for move"
+		}
+		target.folder, err = queries.GetFolder(ctx, pgtype.UUID{Bytes: folderID, Valid: true})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return target, http.StatusNotFound, "folder not found"
+		}
+		if err != nil {
+			h.logger.Error("failed to get folder", zap.Error(err))
+			return target, http.StatusInternalServerError, "failed to get folder"
+		}
+		if info, err := os.Stat(target.folder.Path); err != nil || !info.IsDir() {
+			return target, http.StatusConflict, "folder does not exist on disk"
+		}
+
+	case BulkReclassify:
+		if h.reclassify == nil || !h.classifier.IsEnabled() {
+			return target, http.StatusServiceUnavailable, "OpenAI classification is not enabled"
+		}
+	}
+	return target, 0, ""
+}
+
+// applyBulk runs the action in one transaction. Item errors roll the
+// transaction back and are returned per file; err is a database failure.
+func (h *Handler) applyBulk(ctx context.Context, action string, files []db.File, fileIDs []pgtype.UUID, target bulkTarget) (map[pgtype.UUID]string, error) {
+	if len(files) == 0 || action == BulkReclassify {
+		return nil, nil
+	}
+
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	queries := db.New(h.pool).WithTx(tx)
+
+	// Moves rename files on disk; they are undone if the transaction does not commit
+	var moved []diskMove
+	undo := func() {
+		for i := len(moved) - 1; i >= 0; i-- {
+			if err := os.Rename(moved[i].to, moved[i].from); err != nil {
+				h.logger.Error("failed to undo file move",
+					zap.String("from", moved[i].to),
+					zap.String("to", moved[i].from),
+					zap.Error(err))
+			}
+		}
+	}
+
+	switch action {
+	case BulkAddCategories:
+		var ids, cats []pgtype.UUID
+		for _, fileID := range fileIDs {
+			for _, catID := range target.categoryIDs {
+				ids = append(ids, fileID)
+				cats = append(cats, catID)
+			}
+		}
+		err = queries.BulkAddManualFileCategories(ctx, db.BulkAddManualFileCategoriesParams{
+			FileIds:     ids,
+			CategoryIds: cats,
+		})
+
+	case BulkRemoveCategories:
+		err = queries.BulkRemoveSelectedFileCategories(ctx, db.BulkRemoveSelectedFileCategoriesParams{
+			FileIds:     fileIDs,
+			CategoryIds: target.categoryIDs,
+		})
+
+	case BulkFavorite, BulkUnfavorite:
+		_, err = queries.SetFilesFavorite(ctx, db.SetFilesFavoriteParams{
+			Favorite: action == BulkFavorite,
+			Ids:      fileIDs,
+		})
+
+	case BulkDelete:
+		_, err = queries.DeleteFilesByID(ctx, fileIDs)
+
+	case BulkMove:
+		var itemErrors map[pgtype.UUID]string
+		moved, itemErrors, err = h.moveFiles(ctx, queries, files, target.folder)
+		if err != nil || len(itemErrors) > 0 {
+			undo()
+			return itemErrors, err
+		}
+	}
+	if err != nil {
+		undo()
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		undo()
+		return nil, err
+	}
+	return nil, nil
+}
+
+// diskMove is a file renamed on disk by a bulk move
+type diskMove struct {
+	from, to string
+}
+
+// moveFiles points the files at the destination folder, then renames them on
+// disk. Conflicts and failed renames are returned per file.
+func (h *Handler) moveFiles(ctx context.Context, queries *db.Queries, files []db.File, folder db.Folder) ([]diskMove, map[pgtype.UUID]string, error) {
+	itemErrors := make(map[pgtype.UUID]string)
+	var pending []diskMove
+	destinations := make(map[string]bool, len(files))
+
+	for _, file := range files {
+		dest := filepath.Join(folder.Path, filepath.Base(file.Path))
+		if dest == file.Path {
+			pending = append(pending, diskMove{})
+			continue
+		}
+		if destinations[dest] {
+			itemErrors[file.ID] = "another selected file has the same name"
+			pending = append(pending, diskMove{})
+			continue
+		}
+		destinations[dest] = true
+
+		if _, err := os.Lstat(dest); err == nil {
+			itemErrors[file.ID] = "a file already exists at " + dest
+		} else if _, err := queries.GetFileByPath(ctx, dest); err == nil {
+			itemErrors[file.ID] = "the catalogue already has a file at " + dest
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, err
+		}
+		pending = append(pending, diskMove{from: file.Path, to: dest})
+	}
+	if len(itemErrors) > 0 {
+		return nil, itemErrors, nil
+	}
+
+	for i, file := range files {
+		if pending[i].to == "" {
+			continue
+		}
+		if err := queries.MoveFile(ctx, db.MoveFileParams{
+			ID:       file.ID,
+			Path:     pending[i].to,
+			FolderID: folder.ID,
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var moved []diskMove
+	for i, file := range files {
+		if pending[i].to == "" {
+			continue
+		}
+		if err := os.Rename(pending[i].from, pending[i].to); err != nil {
+			itemErrors[file.ID] = err.Error()
+			return moved, itemErrors, nil
+		}
+		moved = append(moved, pending[i])
+	}
+	return moved, nil, nil
+}
+
+// bulkResults lists the files with status, or their error when they have one
+func bulkResults(files []db.File, itemErrors map[pgtype.UUID]string, status string) []BulkItemResult {
+	results := make([]BulkItemResult, len(files))
+	for i, file := range files {
+		results[i] = BulkItemResult{
+			FileID: uuid.UUID(file.ID.Bytes).String(),
+			Path:   file.Path,
+			Status: status,
+		}
+		if msg, ok := itemErrors[file.ID]; ok {
+			results[i].Status = BulkStatusFailed
+			results[i].Error = msg
+		}
+	}
+	return results
+}
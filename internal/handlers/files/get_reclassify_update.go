@@ -2,9 +2,13 @@ package files
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
+	"stl-manager/internal/ai"
 	"stl-manager/internal/db"
+	"stl-manager/internal/events"
+	"stl-manager/internal/handlers/categories"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -34,21 +38,7 @@ func (h *Handler) GetFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	categories, err := queries.GetFileCategories(ctx, file.ID)
-	if err != nil {
-		h.logger.Warn("failed to get file categories", zap.Error(err))
-		categories = []db.Category{}
-	}
-
-	type FileWithCategories struct {
-		db.File
-		Categories []db.Category `json:"categories"`
-	}
-
-	h.RespondJSON(w, http.StatusOK, FileWithCategories{
-		File:       file,
-		Categories: categories,
-	})
+	h.RespondJSON(w, http.StatusOK, h.fileDetail(ctx, queries, file))
 }
 
 func (h *Handler) ReclassifyFile(w http.ResponseWriter, r *http.Request) {
@@ -73,7 +63,7 @@ func (h *Handler) ReclassifyFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	allCategories, err := queries.ListCategories(ctx)
+	catalog, err := categories.LoadCatalog(ctx, queries)
 	if err != nil {
 		h.logger.Error("failed to list categories", zap.Error(err))
 		h.RespondError(w, http.StatusInternalServerError, "failed to fetch categories")
@@ -85,12 +75,17 @@ func (h *Handler) ReclassifyFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	categoryNames := make([]string, len(allCategories))
-	for i, cat := range allCategories {
-		categoryNames[i] = cat.Name
+	classifiedCategories, err := h.classifier.Classify(ctx, file.FileName, catalog.Paths)
+	if errors.Is(err, ai.ErrBudgetExceeded) {
+		if err := queries.EnqueueClassification(ctx, db.EnqueueClassificationParams{
+			FileID: file.ID,
+			Reason: "budget_exceeded",
+		}); err != nil {
+			h.logger.Error("failed to queue file for classification", zap.String("file_id", fileID), zap.Error(err))
+		}
+		h.RespondError(w, http.StatusTooManyRequests, "AI budget exceeded, file queued for classification")
+		return
 	}
-
-	classifiedCategories, err := h.classifier.Classify(ctx, file.FileName, categoryNames)
 	if err != nil {
 		h.logger.Error("classification failed", zap.String("file_id", fileID), zap.Error(err))
 		h.RespondError(w, http.StatusInternalServerError, "classification failed")
@@ -101,14 +96,21 @@ func (h *Handler) ReclassifyFile(w http.ResponseWriter, r *http.Request) {
 		classifiedCategories = []string{"uncategorized"}
 	}
 
-	err = queries.RemoveAllFileCategories(ctx, file.ID)
+	if err := queries.DequeueClassification(ctx, file.ID); err != nil {
+		h.logger.Warn("failed to remove file from classification queue", zap.String("file_id", fileID), zap.Error(err))
+	}
+	if err := queries.MarkFileClassified(ctx, file.ID); err != nil {
+		h.logger.Warn("failed to mark file as classified", zap.String("file_id", fileID), zap.Error(err))
+	}
+
+	err = queries.RemoveAIFileCategories(ctx, file.ID)
 	if err != nil {
 		h.logger.Error("failed to remove existing categories", zap.String("file_id", fileID), zap.Error(err))
 	}
 
 	for _, catName := range classifiedCategories {
-		category, err := queries.GetCategoryByName(ctx, catName)
-		if err != nil {
+		category, ok := catalog.Lookup(catName)
+		if !ok {
 			h.logger.Warn("category not found, skipping", zap.String("category", catName))
 			continue
 		}
@@ -129,14 +131,24 @@ func (h *Handler) ReclassifyFile(w http.ResponseWriter, r *http.Request) {
 		zap.String("file_id", fileID),
 		zap.Strings("categories", classifiedCategories))
 
+	h.events.Publish(events.TopicLibrary, events.TypeFilesUpdated, map[string]any{"file_ids": []string{fileID}})
 	h.RespondJSON(w, http.StatusOK, map[string]any{
 		"file_id":    fileID,
 		"categories": classifiedCategories,
 	})
 }
 
+// Category edit modes for UpdateFileCategories
+const (
+	ModeReplace = "replace"
+	ModeAdd     = "add"
+	ModeRemove  = "remove"
+)
+
 type UpdateCategoriesRequest struct {
 	CategoryIDs []string `json:"category_ids"`
+	// Mode is replace (default), add or remove
+	Mode string `json:"mode"`
 }
 
 func (h *Handler) UpdateFileCategories(w http.ResponseWriter, r *http.Request) {
@@ -166,14 +178,24 @@ func (h *Handler) UpdateFileCategories(w http.ResponseWriter, r *http.Request) {
 		h.RespondError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
-
-	err = queries.RemoveAllFileCategories(ctx, file.ID)
-	if err != nil {
-		h.logger.Error("failed to remove existing categories", zap.String("file_id", fileID), zap.Error(err))
-		h.RespondError(w, http.StatusInternalServerError, "failed to update categories")
+	switch req.Mode {
+	case "":
+		req.Mode = ModeReplace
+	case ModeReplace, ModeAdd, ModeRemove:
+	default:
+		h.RespondError(w, http.StatusBadRequest, "mode must be replace, add or remove")
 		return
 	}
 
+	if req.Mode == ModeReplace {
+		err = queries.RemoveAllFileCategories(ctx, file.ID)
+		if err != nil {
+			h.logger.Error("failed to remove existing categories", zap.String("file_id", fileID), zap.Error(err))
+			h.RespondError(w, http.StatusInternalServerError, "failed to update categories")
+			return
+		}
+	}
+
 	for _, catIDStr := range req.CategoryIDs {
 		catUID, err := uuid.Parse(catIDStr)
 		if err != nil {
@@ -181,12 +203,20 @@ func (h *Handler) UpdateFileCategories(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
-		err = queries.AddFileCategory(ctx, db.AddFileCategoryParams{
-			FileID:     file.ID,
-			CategoryID: pgtype.UUID{Bytes: catUID, Valid: true},
-		})
+		if req.Mode == ModeRemove {
+			err = queries.RemoveFileCategory(ctx, db.RemoveFileCategoryParams{
+				FileID:     file.ID,
+				CategoryID: pgtype.UUID{Bytes: catUID, Valid: true},
+			})
+		} else {
+			err = queries.AddManualFileCategory(ctx, db.AddManualFileCategoryParams{
+				FileID:     file.ID,
+				CategoryID: pgtype.UUID{Bytes: catUID, Valid: true},
+			})
+		}
 		if err != nil {
-			h.logger.Error("failed to add category",
+			h.logger.Error("failed to update category",
+				zap.String("mode", req.Mode),
 				zap.String("file_id", fileID),
 				zap.String("category_id", catIDStr),
 				zap.Error(err))
@@ -201,8 +231,10 @@ func (h *Handler) UpdateFileCategories(w http.ResponseWriter, r *http.Request) {
 
 	h.logger.Info("file categories updated",
 		zap.String("file_id", fileID),
+		zap.String("mode", req.Mode),
 		zap.Int("category_count", len(categories)))
 
+	h.events.Publish(events.TopicLibrary, events.TypeFilesUpdated, map[string]any{"file_ids": []string{fileID}})
 	h.RespondJSON(w, http.StatusOK, map[string]any{
 		"file_id":    fileID,
 		"categories": categories,
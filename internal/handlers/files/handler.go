@@ -6,6 +6,7 @@ import (
 
 	"stl-manager/internal/ai"
 	"stl-manager/internal/config"
+	"stl-manager/internal/events"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
@@ -14,14 +15,18 @@ import (
 type Handler struct {
 	pool       *pgxpool.Pool
 	classifier ai.Classifier
+	reclassify ReclassifyFunc
+	events     *events.Broker
 	config     *config.Config
 	logger     *zap.Logger
 }
 
-func New(pool *pgxpool.Pool, classifier ai.Classifier, cfg *config.Config, logger *zap.Logger) *Handler {
+func New(pool *pgxpool.Pool, classifier ai.Classifier, reclassify ReclassifyFunc, broker *events.Broker, cfg *config.Config, logger *zap.Logger) *Handler {
 	return &Handler{
 		pool:       pool,
 		classifier: classifier,
+		reclassify: reclassify,
+		events:     broker,
 		config:     cfg,
 		logger:     logger,
 	}
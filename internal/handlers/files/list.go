@@ -1,10 +1,13 @@
 package files
 
 import (
+	"errors"
 	"net/http"
-	"strconv"
 
 	"stl-manager/internal/db"
+	"stl-manager/internal/handlers/prints"
+	"stl-manager/internal/pagination"
+	"stl-manager/internal/search"
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
@@ -16,96 +19,41 @@ func (h *Handler) ListFiles(w http.ResponseWriter, r *http.Request) {
 
 	// Parse query parameters
 	query := r.URL.Query()
-	searchQuery := query.Get("q")
-	typeFilter := query.Get("type")
-	categoryFilter := query.Get("category")
-
-	// Parse pagination
-	page := 1
-	pageSize := 20
-	if p := query.Get("page"); p != "" {
-		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
-			page = parsed
-		}
+	filter, sort, err := search.Parse(query)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	if ps := query.Get("page_size"); ps != "" {
-		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
-			pageSize = parsed
-		}
+	wantFacets, err := search.WantFacets(query)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	offset := (page - 1) * pageSize
-
-	var files []db.File
-	var total int64
-	var err error
-
-	// If search query or type filter, use SearchFiles
-	if searchQuery != "" || typeFilter != "" {
-		searchRows, err := queries.SearchFiles(ctx, db.SearchFilesParams{
-			Similarity: searchQuery,
-			Column2:    typeFilter,
-			Limit:      int32(pageSize),
-			Offset:     int32(offset),
-		})
-		if err != nil {
-			h.logger.Error("failed to search files", zap.Error(err))
-			h.RespondError(w, http.StatusInternalServerError, "failed to search files")
-			return
-		}
 
-		// Convert SearchFilesRow to File
-		files = make([]db.File, len(searchRows))
-		for i, row := range searchRows {
-			files[i] = db.File{
-				ID:         row.ID,
-				Path:       row.Path,
-				FileName:   row.FileName,
-				Type:       row.Type,
-				Size:       row.Size,
-				ModifiedAt: row.ModifiedAt,
-				Sha256:     row.Sha256,
-				CreatedAt:  row.CreatedAt,
-				UpdatedAt:  row.UpdatedAt,
-			}
-		}
+	p, err := pagination.Parse(query, pagination.DefaultPageSize)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-		// Count total (approximate for search)
-		if typeFilter != "" {
-			total, _ = queries.CountFilesByType(ctx, typeFilter)
-		} else {
-			total, _ = queries.CountFiles(ctx)
-		}
-	} else if categoryFilter != "" {
-		// Filter by category
-		files, err = queries.GetFilesByCategory(ctx, db.GetFilesByCategoryParams{
-			Name:   categoryFilter,
-			Limit:  int32(pageSize),
-			Offset: int32(offset),
-		})
-		if err != nil {
-			h.logger.Error("failed to get files by category", zap.Error(err))
-			h.RespondError(w, http.StatusInternalServerError, "failed to get files by category")
-			return
-		}
-		total, _ = queries.CountFiles(ctx)
-	} else {
-		// Default: list all files
-		files, err = queries.ListFiles(ctx, db.ListFilesParams{
-			Limit:  int32(pageSize),
-			Offset: int32(offset),
-		})
-		if err != nil {
-			h.logger.Error("failed to list files", zap.Error(err))
-			h.RespondError(w, http.StatusInternalServerError, "failed to list files")
-			return
-		}
-		total, _ = queries.CountFiles(ctx)
+	files, total, next, err := search.Files(ctx, h.pool, filter, sort, p)
+	if errors.Is(err, pagination.ErrCursorMismatch) {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to search files", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to search files")
+		return
 	}
 
 	// Attach categories to each file using batch query (1 query instead of N)
 	type FileWithCategories struct {
 		db.File
 		Categories []db.Category `json:"categories"`
+		prints.Stats
+		// Highlight marks the words of file_name matched by q
+		Highlight []search.Range `json:"highlight,omitempty"`
 	}
 
 	// Collect file IDs
@@ -127,11 +75,17 @@ func (h *Handler) ListFiles(w http.ResponseWriter, r *http.Request) {
 					ID:        row.ID,
 					Name:      row.Name,
 					CreatedAt: row.CreatedAt,
+					ParentID:  row.ParentID,
 				})
 			}
 		}
 	}
 
+	statsMap, err := prints.FileStats(ctx, queries, fileIDs)
+	if err != nil {
+		h.logger.Warn("failed to get file print stats", zap.Error(err))
+	}
+
 	// Build response with categories
 	filesWithCategories := make([]FileWithCategories, len(files))
 	for i, file := range files {
@@ -142,14 +96,23 @@ func (h *Handler) ListFiles(w http.ResponseWriter, r *http.Request) {
 		filesWithCategories[i] = FileWithCategories{
 			File:       file,
 			Categories: categories,
+			Stats:      statsMap[file.ID],
+		}
+		if filter.Query != "" {
+			filesWithCategories[i].Highlight = search.Highlight(file.FileName, filter.Query)
+		}
+	}
+
+	response := p.Response(filesWithCategories, total, next)
+	if wantFacets {
+		facets, err := search.FileFacets(ctx, h.pool, filter)
+		if err != nil {
+			h.logger.Error("failed to count file facets", zap.Error(err))
+			h.RespondError(w, http.StatusInternalServerError, "failed to count facets")
+			return
 		}
+		response["facets"] = facets
 	}
 
-	h.RespondJSON(w, http.StatusOK, map[string]interface{}{
-		"items":       filesWithCategories,
-		"total":       total,
-		"page":        page,
-		"page_size":   pageSize,
-		"total_pages": int((total + int64(pageSize) - 1) / int64(pageSize)),
-	})
+	h.RespondJSON(w, http.StatusOK, response)
 }
@@ -0,0 +1,124 @@
+package files
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/events"
+	"stl-manager/internal/handlers/metadata"
+	"stl-manager/internal/handlers/prints"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// FileDetail is the response of GetFile and UpdateFile
+type FileDetail struct {
+	db.File
+	Categories   []db.Category   `json:"categories"`
+	CustomFields metadata.Values `json:"custom_fields"`
+	prints.Stats
+}
+
+// fileDetail loads the categories, custom fields and print stats of a file.
+// Lookups that fail are logged and left empty.
+func (h *Handler) fileDetail(ctx context.Context, queries *db.Queries, file db.File) FileDetail {
+	categories, err := queries.GetFileCategories(ctx, file.ID)
+	if err != nil {
+		h.logger.Warn("failed to get file categories", zap.Error(err))
+		categories = []db.Category{}
+	}
+
+	values, err := metadata.FileValues(ctx, queries, []pgtype.UUID{file.ID})
+	if err != nil {
+		h.logger.Warn("failed to get file custom fields", zap.Error(err))
+	}
+
+	stats, err := prints.FileStats(ctx, queries, []pgtype.UUID{file.ID})
+	if err != nil {
+		h.logger.Warn("failed to get file print stats", zap.Error(err))
+	}
+
+	return FileDetail{
+		File:         file,
+		Categories:   categories,
+		CustomFields: values.Get(file.ID),
+		Stats:        stats[file.ID],
+	}
+}
+
+// UpdateFile edits the favourite flag, rating, note and custom fields of a file
+func (h *Handler) UpdateFile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	fileID := chi.URLParam(r, "id")
+	uid, err := uuid.Parse(fileID)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "invalid file_id format")
+		return
+	}
+
+	var req metadata.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	queries := db.New(h.pool)
+	file, err := queries.GetFile(ctx, pgtype.UUID{Bytes: uid, Valid: true})
+	if err != nil {
+		h.logger.Error("failed to get file", zap.String("file_id", fileID), zap.Error(err))
+		h.RespondError(w, http.StatusNotFound, "file not found")
+		return
+	}
+
+	msg, err := req.Validate(ctx, queries)
+	if err != nil {
+		h.logger.Error("failed to load custom fields", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to update file")
+		return
+	}
+	if msg != "" {
+		h.RespondError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		h.logger.Error("failed to begin transaction", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to update file")
+		return
+	}
+	defer tx.Rollback(ctx)
+	txQueries := queries.WithTx(tx)
+
+	favorite, rating, note := req.Merge(file.Favorite, file.Rating, file.Note)
+	file, err = txQueries.UpdateFileMetadata(ctx, db.UpdateFileMetadataParams{
+		Favorite: favorite,
+		Rating:   rating,
+		Note:     note,
+		ID:       file.ID,
+	})
+	if err != nil {
+		h.logger.Error("failed to update file metadata", zap.String("file_id", fileID), zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to update file")
+		return
+	}
+	if err := req.SaveFile(ctx, txQueries, file.ID); err != nil {
+		h.logger.Error("failed to save custom fields", zap.String("file_id", fileID), zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to update file")
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		h.logger.Error("failed to commit transaction", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to update file")
+		return
+	}
+
+	h.events.Publish(events.TopicLibrary, events.TypeFilesUpdated, map[string]any{"file_ids": []string{fileID}})
+	h.RespondJSON(w, http.StatusOK, h.fileDetail(ctx, queries, file))
+}
@@ -1,16 +1,23 @@
 package folders
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"stl-manager/internal/db"
+	"stl-manager/internal/events"
+	"stl-manager/internal/handlers/metadata"
+	"stl-manager/internal/handlers/prints"
+	"stl-manager/internal/jobs"
+	"stl-manager/internal/pagination"
+	"stl-manager/internal/search"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
@@ -18,11 +25,15 @@ import (
 
 type Handler struct {
 	pool   *pgxpool.Pool
+	jobs   *jobs.Manager
+	events *events.Broker
 	logger *zap.Logger
 }
 
-func New(pool *pgxpool.Pool, logger *zap.Logger) *Handler {
-	return &Handler{pool: pool, logger: logger}
+func New(pool *pgxpool.Pool, jobManager *jobs.Manager, broker *events.Broker, logger *zap.Logger) *Handler {
+	h := &Handler{pool: pool, jobs: jobManager, events: broker, logger: logger}
+	h.registerJobs()
+	return h
 }
 
 func (h *Handler) RespondJSON(w http.ResponseWriter, status int, data interface{}) {
@@ -37,6 +48,9 @@ func (h *Handler) RespondError(w http.ResponseWriter, status int, message string
 	h.RespondJSON(w, status, map[string]string{"error": message})
 }
 
+// sortByName tags cursors of folder lists, ordered by name
+const sortByName = "name"
+
 // ListFolders lists all folders with their file count
 func (h *Handler) ListFolders(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -44,30 +58,39 @@ func (h *Handler) ListFolders(w http.ResponseWriter, r *http.Request) {
 
 	query := r.URL.Query()
 	searchQuery := strings.TrimSpace(query.Get("q"))
-	page := 1
-	pageSize := 20
-	if p := query.Get("page"); p != "" {
-		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
-			page = parsed
-		}
+	p, err := pagination.Parse(query, pagination.DefaultPageSize)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	if ps := query.Get("page_size"); ps != "" {
-		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
-			pageSize = parsed
-		}
+	licenses, err := search.ParseLicenses(query)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	after, err := p.After(sortByName)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var afterID pgtype.UUID
+	var afterName string
+	if after != nil {
+		afterID, afterName = after.ID, after.Key
 	}
-	offset := (page - 1) * pageSize
 
 	var folders []db.Folder
 	var total int64
-	var err error
 
 	// Use search queries if search parameter is provided
 	if searchQuery != "" {
 		folders, err = queries.SearchFoldersPaginated(ctx, db.SearchFoldersPaginatedParams{
-			Search: searchQuery,
-			Limit:  int32(pageSize),
-			Offset: int32(offset),
+			Search:    searchQuery,
+			Licenses:  licenses,
+			Limit:     int32(p.Limit()),
+			Offset:    int32(p.Offset()),
+			AfterID:   afterID,
+			AfterName: afterName,
 		})
 		if err != nil {
 			h.logger.Error("failed to search folders", zap.Error(err))
@@ -75,15 +98,21 @@ func (h *Handler) ListFolders(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		total, err = queries.CountSearchFolders(ctx, searchQuery)
+		total, err = queries.CountSearchFolders(ctx, db.CountSearchFoldersParams{
+			Search:   searchQuery,
+			Licenses: licenses,
+		})
 		if err != nil {
 			h.logger.Error("failed to count search folders", zap.Error(err))
 			total = 0
 		}
 	} else {
 		folders, err = queries.ListFoldersPaginated(ctx, db.ListFoldersPaginatedParams{
-			Limit:  int32(pageSize),
-			Offset: int32(offset),
+			Licenses:  licenses,
+			Limit:     int32(p.Limit()),
+			Offset:    int32(p.Offset()),
+			AfterID:   afterID,
+			AfterName: afterName,
 		})
 		if err != nil {
 			h.logger.Error("failed to list folders", zap.Error(err))
@@ -91,17 +120,21 @@ func (h *Handler) ListFolders(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		total, err = queries.CountFolders(ctx)
+		total, err = queries.CountFolders(ctx, licenses)
 		if err != nil {
 			h.logger.Error("failed to count folders", zap.Error(err))
 			total = 0
 		}
 	}
+	folders, next := pagination.Trim(folders, p, func(f db.Folder) pagination.Cursor {
+		return pagination.NewCursor(sortByName, f.Name, f.ID)
+	})
 
 	type FolderResponse struct {
 		db.Folder
 		FileCount  int           `json:"file_count"`
 		Categories []db.Category `json:"categories"`
+		prints.Stats
 	}
 
 	// Collect folder IDs for batch queries
@@ -127,6 +160,11 @@ func (h *Handler) ListFolders(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	statsMap, err := prints.FolderStats(ctx, queries, folderIDs)
+	if err != nil {
+		h.logger.Warn("failed to get folder print stats", zap.Error(err))
+	}
+
 	response := make([]FolderResponse, len(folders))
 	for i, folder := range folders {
 		// FIX: Use CountFolderFiles instead of loading all files
@@ -145,16 +183,11 @@ func (h *Handler) ListFolders(w http.ResponseWriter, r *http.Request) {
 			Folder:     folder,
 			FileCount:  int(fileCount),
 			Categories: categories,
+			Stats:      statsMap[folder.ID],
 		}
 	}
 
-	h.RespondJSON(w, http.StatusOK, map[string]interface{}{
-		"items":       response,
-		"total":       total,
-		"page":        page,
-		"page_size":   pageSize,
-		"total_pages": int((total + int64(pageSize) - 1) / int64(pageSize)),
-	})
+	h.RespondJSON(w, http.StatusOK, p.Response(response, total, next))
 }
 
 // GetFolder gets a specific folder with its files (paginated)
@@ -172,6 +205,11 @@ func (h *Handler) GetFolder(w http.ResponseWriter, r *http.Request) {
 	searchQuery := strings.TrimSpace(r.URL.Query().Get("search"))
 	typeFilter := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("type")))
 	categoryFilter := strings.TrimSpace(r.URL.Query().Get("category"))
+	printedFilter, err := search.ParsePrinted(r.URL.Query().Get("printed"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	query := r.URL.Query()
 	page := 1
@@ -202,6 +240,19 @@ func (h *Handler) GetFolder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A category filter also matches its descendant categories
+	categoryNames := map[string]bool{}
+	if categoryFilter != "" {
+		names, err := queries.ListCategorySubtreeNames(ctx, categoryFilter)
+		if err != nil {
+			h.logger.Warn("failed to list category descendants", zap.Error(err))
+			names = []string{categoryFilter}
+		}
+		for _, name := range names {
+			categoryNames[strings.ToLower(name)] = true
+		}
+	}
+
 	if searchQuery != "" || categoryFilter != "" {
 		filtered := []db.Folder{}
 		searchLower := strings.ToLower(searchQuery)
@@ -216,7 +267,7 @@ func (h *Handler) GetFolder(w http.ResponseWriter, r *http.Request) {
 				}
 				hasCategory := false
 				for _, cat := range subfolderCategories {
-					if cat.Name == categoryFilter {
+					if categoryNames[strings.ToLower(cat.Name)] {
 						hasCategory = true
 						break
 					}
@@ -232,7 +283,7 @@ func (h *Handler) GetFolder(w http.ResponseWriter, r *http.Request) {
 
 	var files []db.File
 	var totalFiles int64
-	hasFilters := searchQuery != "" || typeFilter != "" || categoryFilter != ""
+	hasFilters := searchQuery != "" || typeFilter != "" || categoryFilter != "" || printedFilter != ""
 
 	if hasFilters {
 		allFiles, err := queries.GetFolderFiles(ctx, folder.ID)
@@ -242,6 +293,21 @@ func (h *Handler) GetFolder(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// Print history of every file, only needed to filter on it
+		var printStats map[pgtype.UUID]prints.Stats
+		if printedFilter != "" {
+			allFileIDs := make([]pgtype.UUID, len(allFiles))
+			for i, file := range allFiles {
+				allFileIDs[i] = file.ID
+			}
+			printStats, err = prints.FileStats(ctx, queries, allFileIDs)
+			if err != nil {
+				h.logger.Error("failed to get file print stats", zap.Error(err))
+				h.RespondError(w, http.StatusInternalServerError, "Failed to get folder files")
+				return
+			}
+		}
+
 		filtered := []db.File{}
 		searchLower := strings.ToLower(searchQuery)
 
@@ -252,6 +318,9 @@ func (h *Handler) GetFolder(w http.ResponseWriter, r *http.Request) {
 			if typeFilter != "" && strings.ToLower(file.Type) != typeFilter {
 				continue
 			}
+			if printedFilter != "" && !matchesPrinted(printStats[file.ID], printedFilter) {
+				continue
+			}
 			if categoryFilter != "" {
 				fileCategories, err := queries.GetFileCategories(ctx, file.ID)
 				if err != nil || len(fileCategories) == 0 {
@@ -259,7 +328,7 @@ func (h *Handler) GetFolder(w http.ResponseWriter, r *http.Request) {
 				}
 				hasCategory := false
 				for _, cat := range fileCategories {
-					if cat.Name == categoryFilter {
+					if categoryNames[strings.ToLower(cat.Name)] {
 						hasCategory = true
 						break
 					}
@@ -311,6 +380,17 @@ func (h *Handler) GetFolder(w http.ResponseWriter, r *http.Request) {
 		db.Folder
 		FileCount  int           `json:"file_count"`
 		Categories []db.Category `json:"categories"`
+		prints.Stats
+	}
+
+	// Print statistics of the folder and its subfolders cover their whole subtrees
+	folderIDs := []pgtype.UUID{folder.ID}
+	for _, subfolder := range subfolders {
+		folderIDs = append(folderIDs, subfolder.ID)
+	}
+	folderStats, err := prints.FolderStats(ctx, queries, folderIDs)
+	if err != nil {
+		h.logger.Warn("failed to get folder print stats", zap.Error(err))
 	}
 
 	// Batch query for subfolder categories (1 query instead of N)
@@ -352,21 +432,25 @@ func (h *Handler) GetFolder(w http.ResponseWriter, r *http.Request) {
 			Folder:     subfolder,
 			FileCount:  int(fileCount),
 			Categories: subfolderCategories,
+			Stats:      folderStats[subfolder.ID],
 		}
 	}
 
 	type FileWithCategories struct {
 		db.File
-		Categories []db.Category `json:"categories"`
+		Categories   []db.Category   `json:"categories"`
+		CustomFields metadata.Values `json:"custom_fields"`
+		prints.Stats
+	}
+
+	fileIDs := make([]pgtype.UUID, len(files))
+	for i, file := range files {
+		fileIDs[i] = file.ID
 	}
 
 	// Batch query for file categories (1 query instead of M)
 	fileCategoriesMap := make(map[pgtype.UUID][]db.Category)
 	if len(files) > 0 {
-		fileIDs := make([]pgtype.UUID, len(files))
-		for i, file := range files {
-			fileIDs[i] = file.ID
-		}
 		batchResults, err := queries.GetCategoriesBatch(ctx, fileIDs)
 		if err != nil {
 			h.logger.Warn("failed to get file categories batch", zap.Error(err))
@@ -381,6 +465,16 @@ func (h *Handler) GetFolder(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	fileStats, err := prints.FileStats(ctx, queries, fileIDs)
+	if err != nil {
+		h.logger.Warn("failed to get file print stats", zap.Error(err))
+	}
+
+	fileValues, err := metadata.FileValues(ctx, queries, fileIDs)
+	if err != nil {
+		h.logger.Warn("failed to get file custom fields", zap.Error(err))
+	}
+
 	filesWithCategories := make([]FileWithCategories, len(files))
 	for i, file := range files {
 		fileCategories := fileCategoriesMap[file.ID]
@@ -388,13 +482,24 @@ func (h *Handler) GetFolder(w http.ResponseWriter, r *http.Request) {
 			fileCategories = []db.Category{}
 		}
 		filesWithCategories[i] = FileWithCategories{
-			File:       file,
-			Categories: fileCategories,
+			File:         file,
+			Categories:   fileCategories,
+			CustomFields: fileValues.Get(file.ID),
+			Stats:        fileStats[file.ID],
 		}
 	}
 
+	folderValues, err := metadata.FolderValues(ctx, queries, []pgtype.UUID{folder.ID})
+	if err != nil {
+		h.logger.Warn("failed to get folder custom fields", zap.Error(err))
+	}
+
 	h.RespondJSON(w, http.StatusOK, map[string]interface{}{
-		"folder":     folder,
+		"folder": FolderWithStats{
+			Folder:       folder,
+			CustomFields: folderValues.Get(folder.ID),
+			Stats:        folderStats[folder.ID],
+		},
 		"subfolders": subfoldersWithInfo,
 		"files":      filesWithCategories,
 		"categories": categories,
@@ -407,7 +512,23 @@ func (h *Handler) GetFolder(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// UpdateFolderCategories updates the categories assigned to a folder
+// matchesPrinted reports whether a file's print history passes the printed
+// filter (see search.ParsePrinted)
+func matchesPrinted(stats prints.Stats, printed string) bool {
+	switch printed {
+	case search.PrintedNever:
+		return stats.PrintCount == 0
+	case search.PrintedAny:
+		return stats.PrintCount > 0
+	case search.PrintedSuccess:
+		return stats.SuccessRate != nil && *stats.SuccessRate > 0
+	}
+	return true
+}
+
+// UpdateFolderCategories updates the categories assigned to a folder and, on
+// request, copies them to its files and the folders below it. Trees with more
+// than syncPropagationLimit subfolders and files are updated by a background job.
 func (h *Handler) UpdateFolderCategories(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	queries := db.New(h.pool)
@@ -419,187 +540,114 @@ func (h *Handler) UpdateFolderCategories(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var req struct {
-		CategoryIDs       []string `json:"category_ids"`
-		ApplyToSTL        bool     `json:"apply_to_stl"`
-		ApplyToZIP        bool     `json:"apply_to_zip"`
-		ApplyToRAR        bool     `json:"apply_to_rar"`
-		ApplyToSubfolders bool     `json:"apply_to_subfolders"`
-	}
+	var req CategoriesRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
+	if msg := req.validate(); msg != "" {
+		h.RespondError(w, http.StatusBadRequest, msg)
+		return
+	}
 
-	if err := queries.SetFolderCategories(ctx, pgtype.UUID{Bytes: folderID, Valid: true}); err != nil {
-		h.logger.Error("failed to clear folder categories", zap.Error(err))
+	folderUUID := pgtype.UUID{Bytes: folderID, Valid: true}
+	if _, err := queries.GetFolder(ctx, folderUUID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			h.RespondError(w, http.StatusNotFound, "Folder not found")
+			return
+		}
+		h.logger.Error("failed to get folder", zap.Error(err))
 		h.RespondError(w, http.StatusInternalServerError, "Failed to update categories")
 		return
 	}
 
-	categoryUUIDs := []pgtype.UUID{}
+	categoryIDs := []uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
 	for _, catIDStr := range req.CategoryIDs {
 		catID, err := uuid.Parse(catIDStr)
 		if err != nil {
 			h.logger.Warn("invalid category ID", zap.String("id", catIDStr))
 			continue
 		}
-
-		categoryUUID := pgtype.UUID{Bytes: catID, Valid: true}
-		categoryUUIDs = append(categoryUUIDs, categoryUUID)
-
-		err = queries.AddFolderCategory(ctx, db.AddFolderCategoryParams{
-			FolderID:   pgtype.UUID{Bytes: folderID, Valid: true},
-			CategoryID: categoryUUID,
-		})
-		if err != nil {
-			h.logger.Error("failed to add folder category", zap.Error(err))
-			continue
+		if !seen[catID] {
+			seen[catID] = true
+			categoryIDs = append(categoryIDs, catID)
 		}
 	}
 
-	if req.ApplyToSTL || req.ApplyToZIP || req.ApplyToRAR || req.ApplyToSubfolders {
-		h.propagateFolderCategories(ctx, queries, pgtype.UUID{Bytes: folderID, Valid: true}, categoryUUIDs, req)
-	}
-
-	categories, err := queries.GetFolderCategories(ctx, pgtype.UUID{Bytes: folderID, Valid: true})
-	if err != nil {
-		h.logger.Error("failed to get updated categories", zap.Error(err))
-		categories = []db.Category{}
+	p := newPropagation(folderID, categoryIDs, req)
+	// Removing a category that does not exist changes nothing, adding one fails
+	if req.Mode != ModeRemove && len(categoryIDs) > 0 {
+		found, err := queries.ListCategoriesByID(ctx, p.categoryUUIDs())
+		if err != nil {
+			h.logger.Error("failed to get categories", zap.Error(err))
+			h.RespondError(w, http.StatusInternalServerError, "Failed to update categories")
+			return
+		}
+		if len(found) != len(categoryIDs) {
+			h.RespondError(w, http.StatusNotFound, "Category not found")
+			return
+		}
 	}
-
-	h.RespondJSON(w, http.StatusOK, map[string]interface{}{
-		"categories": categories,
-	})
-}
-
-func (h *Handler) propagateFolderCategories(
-	ctx context.Context,
-	queries *db.Queries,
-	folderID pgtype.UUID,
-	categoryUUIDs []pgtype.UUID,
-	req struct {
-		CategoryIDs       []string `json:"category_ids"`
-		ApplyToSTL        bool     `json:"apply_to_stl"`
-		ApplyToZIP        bool     `json:"apply_to_zip"`
-		ApplyToRAR        bool     `json:"apply_to_rar"`
-		ApplyToSubfolders bool     `json:"apply_to_subfolders"`
-	},
-) {
-	// OPTIMIZED: Use batch operations instead of individual queries
-	files, err := queries.GetFolderFiles(ctx, folderID)
+	preview, err := h.previewPropagation(ctx, queries, p)
 	if err != nil {
-		h.logger.Error("failed to get folder files for propagation", zap.Error(err))
+		h.logger.Error("failed to count folder tree", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "Failed to update categories")
 		return
 	}
-
-	// Filter files that need category updates
-	var filesToUpdate []pgtype.UUID
-	for _, file := range files {
-		shouldApply := false
-		switch strings.ToLower(file.Type) {
-		case "stl":
-			shouldApply = req.ApplyToSTL
-		case "zip":
-			shouldApply = req.ApplyToZIP
-		case "rar":
-			shouldApply = req.ApplyToRAR
-		}
-
-		if shouldApply {
-			filesToUpdate = append(filesToUpdate, file.ID)
-		}
+	if req.DryRun {
+		h.RespondJSON(w, http.StatusOK, map[string]interface{}{
+			"preview": preview,
+		})
+		return
 	}
 
-	// Batch delete all categories for affected files (1 query instead of N)
-	if len(filesToUpdate) > 0 {
-		if err := queries.BulkRemoveFileCategories(ctx, filesToUpdate); err != nil {
-			h.logger.Error("failed to bulk remove file categories", zap.Error(err))
+	// Start the job before writing anything so a conflict leaves the folder untouched
+	var job db.Job
+	background := req.ApplyToSubfolders && preview.Folders+preview.Files > syncPropagationLimit
+	if background {
+		job, err = h.jobs.Enqueue(ctx, PropagationJobType, p)
+		if errors.Is(err, jobs.ErrAlreadyRunning) {
+			h.RespondError(w, http.StatusConflict, "a category propagation is already running")
 			return
 		}
-
-		// Prepare bulk insert data
-		var fileIDs []pgtype.UUID
-		var catIDs []pgtype.UUID
-		for _, fileID := range filesToUpdate {
-			for _, catUUID := range categoryUUIDs {
-				fileIDs = append(fileIDs, fileID)
-				catIDs = append(catIDs, catUUID)
-			}
-		}
-
-		// Batch insert all categories (1 query instead of N×M)
-		if len(fileIDs) > 0 {
-			if err := queries.BulkAddFileCategories(ctx, db.BulkAddFileCategoriesParams{
-				FileIds:     fileIDs,
-				CategoryIds: catIDs,
-			}); err != nil {
-				h.logger.Error("failed to bulk add file categories", zap.Error(err))
-			}
+		if err != nil {
+			h.logger.Error("failed to start category propagation", zap.Error(err))
+			h.RespondError(w, http.StatusInternalServerError, "Failed to update categories")
+			return
 		}
-
-		h.logger.Info("bulk updated file categories",
-			zap.Int("files", len(filesToUpdate)),
-			zap.Int("categories", len(categoryUUIDs)))
 	}
 
-	// TODO: FEATURE FUTURA - Procesamiento recursivo en background
-	// Implementar un job/worker que procese folders recursivamente sin bloquear el HTTP request
-	// Esto permitirá aplicar categorías a toda la jerarquía de folders sin causar timeout
-	// Consideraciones:
-	// - Background job queue (Redis/RabbitMQ/Go channels)
-	// - Progress tracking para mostrar al usuario
-	// - Límite de profundidad configurable
-	// - Transacciones por lotes para eficiencia
-	// - Capacidad de cancelar la operación
-	if req.ApplyToSubfolders {
-		subfolders, err := queries.ListSubfolders(ctx, folderID)
-		if err != nil {
-			h.logger.Error("failed to get subfolders for propagation", zap.Error(err))
+	// The background job updates the folder itself along with its tree
+	if !background {
+		if err := h.propagate(ctx, p, func(int) {}); err != nil {
+			h.logger.Error("failed to update folder categories", zap.Error(err))
+			h.RespondError(w, http.StatusInternalServerError, "Failed to update categories")
 			return
 		}
+	}
 
-		// OPTIMIZED: Solo procesa subfolders del nivel actual (sin recursión)
-		// Usar batch operations para actualizar múltiples folders a la vez
-		if len(subfolders) > 0 {
-			var subfolderIDs []pgtype.UUID
-			for _, subfolder := range subfolders {
-				subfolderIDs = append(subfolderIDs, subfolder.ID)
-			}
-
-			// Batch delete all categories for subfolders (1 query instead of N)
-			if err := queries.BulkRemoveFolderCategories(ctx, subfolderIDs); err != nil {
-				h.logger.Error("failed to bulk remove subfolder categories", zap.Error(err))
-				return
-			}
-
-			// Prepare bulk insert data
-			var folderIDs []pgtype.UUID
-			var catIDs []pgtype.UUID
-			for _, subfolderID := range subfolderIDs {
-				for _, catUUID := range categoryUUIDs {
-					folderIDs = append(folderIDs, subfolderID)
-					catIDs = append(catIDs, catUUID)
-				}
-			}
-
-			// Batch insert all categories (1 query instead of N×M)
-			if len(folderIDs) > 0 {
-				if err := queries.BulkAddFolderCategories(ctx, db.BulkAddFolderCategoriesParams{
-					FolderIds:   folderIDs,
-					CategoryIds: catIDs,
-				}); err != nil {
-					h.logger.Error("failed to bulk add subfolder categories", zap.Error(err))
-					return
-				}
-			}
+	categories, err := queries.GetFolderCategories(ctx, folderUUID)
+	if err != nil {
+		h.logger.Error("failed to get updated categories", zap.Error(err))
+		categories = []db.Category{}
+	}
 
-			h.logger.Info("bulk updated subfolder categories",
-				zap.Int("subfolders", len(subfolders)),
-				zap.Int("categories", len(categoryUUIDs)))
+	h.events.Publish(events.TopicLibrary, events.TypeFoldersUpdated, map[string]any{
+		"folder_id":  folderID.String(),
+		"propagated": p.changes() && !background,
+	})
 
-			// REMOVED: Recursive call - causes timeout on large folder structures
-			// h.propagateFolderCategories(ctx, queries, subfolder.ID, categoryUUIDs, req)
-		}
+	if background {
+		h.RespondJSON(w, http.StatusAccepted, map[string]interface{}{
+			"categories": categories,
+			"preview":    preview,
+			"job_id":     uuid.UUID(job.ID.Bytes).String(),
+		})
+		return
 	}
+	h.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"categories": categories,
+		"preview":    preview,
+	})
 }
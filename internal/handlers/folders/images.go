@@ -0,0 +1,120 @@
+package folders
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/scanner"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// ListImages returns the gallery of a folder: the preview images scans found
+// in it, and which of them is its cover
+func (h *Handler) ListImages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	folder, ok := h.loadFolder(w, r, queries)
+	if !ok {
+		return
+	}
+
+	images, err := queries.ListFolderImages(ctx, folder.ID)
+	if err != nil {
+		h.logger.Error("failed to list folder images", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "Failed to list images")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"items":            images,
+		"total":            len(images),
+		"primary_image_id": folder.PrimaryImageID,
+	})
+}
+
+// GetImage serves an image of a folder's gallery from the library on disk
+func (h *Handler) GetImage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	folder, ok := h.loadFolder(w, r, queries)
+	if !ok {
+		return
+	}
+	imageID, err := uuid.Parse(chi.URLParam(r, "imageId"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid image ID")
+		return
+	}
+
+	image, err := queries.GetFolderImage(ctx, db.GetFolderImageParams{
+		FolderID: folder.ID,
+		ID:       pgtype.UUID{Bytes: imageID, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.RespondError(w, http.StatusNotFound, "Image not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to get folder image", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "Failed to get image")
+		return
+	}
+
+	// Names come from scans, but never serve anything outside the folder
+	name := filepath.FromSlash(image.Name)
+	contentType, ok := scanner.ImageContentType(name)
+	if !ok || !filepath.IsLocal(name) {
+		h.RespondError(w, http.StatusNotFound, "Image not found")
+		return
+	}
+
+	path := filepath.Join(folder.Path, name)
+	file, err := os.Open(path)
+	if err != nil {
+		h.logger.Warn("folder image missing from disk", zap.String("path", path), zap.Error(err))
+		h.RespondError(w, http.StatusNotFound, "Image not found")
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		h.logger.Warn("failed to stat folder image", zap.String("path", path), zap.Error(err))
+		h.RespondError(w, http.StatusNotFound, "Image not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
+
+// loadFolder returns the folder named by the id URL param, answering 400 or
+// 404 itself when it cannot
+func (h *Handler) loadFolder(w http.ResponseWriter, r *http.Request, queries *db.Queries) (db.Folder, bool) {
+	folderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid folder ID")
+		return db.Folder{}, false
+	}
+
+	folder, err := queries.GetFolder(r.Context(), pgtype.UUID{Bytes: folderID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.RespondError(w, http.StatusNotFound, "Folder not found")
+		return db.Folder{}, false
+	}
+	if err != nil {
+		h.logger.Error("failed to get folder", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "Failed to get folder")
+		return db.Folder{}, false
+	}
+	return folder, true
+}
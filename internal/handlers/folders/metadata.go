@@ -0,0 +1,103 @@
+package folders
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/events"
+	"stl-manager/internal/handlers/metadata"
+	"stl-manager/internal/handlers/prints"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// FolderWithStats is the folder returned by GetFolder and UpdateFolder
+type FolderWithStats struct {
+	db.Folder
+	CustomFields metadata.Values `json:"custom_fields"`
+	prints.Stats
+}
+
+// UpdateFolder edits the favourite flag, rating, note and custom fields of a
+// folder. Unlike categories they are not propagated to the folder contents.
+func (h *Handler) UpdateFolder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	folderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid folder ID")
+		return
+	}
+
+	var req metadata.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	folder, err := queries.GetFolder(ctx, pgtype.UUID{Bytes: folderID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.RespondError(w, http.StatusNotFound, "Folder not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to get folder", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "Failed to update folder")
+		return
+	}
+
+	msg, err := req.Validate(ctx, queries)
+	if err != nil {
+		h.logger.Error("failed to load custom fields", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "Failed to update folder")
+		return
+	}
+	if msg != "" {
+		h.RespondError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	favorite, rating, note := req.Merge(folder.Favorite, folder.Rating, folder.Note)
+	err = h.inTx(ctx, func(queries *db.Queries) error {
+		folder, err = queries.UpdateFolderMetadata(ctx, db.UpdateFolderMetadataParams{
+			Favorite: favorite,
+			Rating:   rating,
+			Note:     note,
+			ID:       folder.ID,
+		})
+		if err != nil {
+			return err
+		}
+		return req.SaveFolder(ctx, queries, folder.ID)
+	})
+	if err != nil {
+		h.logger.Error("failed to update folder", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "Failed to update folder")
+		return
+	}
+
+	values, err := metadata.FolderValues(ctx, queries, []pgtype.UUID{folder.ID})
+	if err != nil {
+		h.logger.Warn("failed to get folder custom fields", zap.Error(err))
+	}
+	stats, err := prints.FolderStats(ctx, queries, []pgtype.UUID{folder.ID})
+	if err != nil {
+		h.logger.Warn("failed to get folder print stats", zap.Error(err))
+	}
+
+	h.events.Publish(events.TopicLibrary, events.TypeFoldersUpdated, map[string]any{
+		"folder_id": folderID.String(),
+	})
+	h.RespondJSON(w, http.StatusOK, FolderWithStats{
+		Folder:       folder,
+		CustomFields: values.Get(folder.ID),
+		Stats:        stats[folder.ID],
+	})
+}
@@ -0,0 +1,92 @@
+package folders
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/scanner"
+
+	"go.uber.org/zap"
+)
+
+// ModelFile is a file of a model and what sets it apart from the other
+// variants
+type ModelFile struct {
+	db.File
+	Supports string `json:"supports"`
+	Scale    string `json:"scale"`
+}
+
+// Model groups the variants of one model: the same part supported,
+// presupported or scaled
+type Model struct {
+	Name  string      `json:"name"`
+	Files []ModelFile `json:"files"`
+}
+
+// ListModels groups the files of a folder and its subfolders by model, so
+// dragon.stl, dragon_presupported.stl and Supported/dragon_75mm.stl show up
+// as three variants of "dragon"
+func (h *Handler) ListModels(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	folder, ok := h.loadFolder(w, r, queries)
+	if !ok {
+		return
+	}
+
+	files, err := queries.ListFolderTreeFiles(ctx, folder.ID)
+	if err != nil {
+		h.logger.Error("failed to list folder files", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "Failed to list models")
+		return
+	}
+
+	models := groupModels(folder.Path, files)
+	h.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"items": models,
+		"total": len(models),
+	})
+}
+
+// groupModels groups files by the model their path inside folderPath names,
+// ignoring case. Models are sorted by name and their files keep the order of
+// their paths.
+func groupModels(folderPath string, files []db.File) []Model {
+	models := []Model{}
+	index := map[string]int{}
+	for _, file := range files {
+		variant := scanner.ParseVariant(relativePath(folderPath, file))
+		key := strings.ToLower(variant.Model)
+		i, ok := index[key]
+		if !ok {
+			i = len(models)
+			index[key] = i
+			models = append(models, Model{Name: variant.Model})
+		}
+		models[i].Files = append(models[i].Files, ModelFile{
+			File:     file,
+			Supports: variant.Supports,
+			Scale:    variant.Scale,
+		})
+	}
+
+	sort.SliceStable(models, func(i, j int) bool {
+		return strings.ToLower(models[i].Name) < strings.ToLower(models[j].Name)
+	})
+	return models
+}
+
+// relativePath returns the path of a file inside folderPath with forward
+// slashes. Library paths may come from Windows, so both separators count.
+func relativePath(folderPath string, file db.File) string {
+	path := strings.ReplaceAll(file.Path, "\\", "/")
+	prefix := strings.TrimSuffix(strings.ReplaceAll(folderPath, "\\", "/"), "/") + "/"
+	if !strings.HasPrefix(path, prefix) {
+		return file.FileName
+	}
+	return strings.TrimPrefix(path, prefix)
+}
@@ -0,0 +1,331 @@
+package folders
+
+import (
+	"context"
+	"strings"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/events"
+	"stl-manager/internal/jobs"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// PropagationJobType is the job type used for category propagation through a folder tree
+const PropagationJobType = "folder_category_propagation"
+
+const (
+	// maxFolderDepth bounds the walk down the tree when no max_depth is given,
+	// which also stops it on a parent_folder_id cycle
+	maxFolderDepth = 1000
+	// syncPropagationLimit is the most subfolders and files updated during the
+	// request; larger trees are updated by a background job
+	syncPropagationLimit = 500
+)
+
+// Category edit modes. Replace swaps the whole set, add keeps existing
+// categories and remove only takes the listed ones away.
+const (
+	ModeReplace = "replace"
+	ModeAdd     = "add"
+	ModeRemove  = "remove"
+)
+
+// CategoriesRequest is the body of PATCH /v1/folders/{id}/categories
+type CategoriesRequest struct {
+	CategoryIDs       []string `json:"category_ids"`
+	ApplyToSTL        bool     `json:"apply_to_stl"`
+	ApplyToZIP        bool     `json:"apply_to_zip"`
+	ApplyToRAR        bool     `json:"apply_to_rar"`
+	ApplyToSubfolders bool     `json:"apply_to_subfolders"`
+	// Mode is replace (default), add or remove; it applies to the folder and to propagation
+	Mode string `json:"mode"`
+	// MaxDepth limits how many subfolder levels are updated (1 = direct subfolders); 0 is the whole tree
+	MaxDepth int `json:"max_depth"`
+	// DryRun only returns the preview
+	DryRun bool `json:"dry_run"`
+}
+
+// validate defaults the mode and returns a message for invalid requests
+func (r *CategoriesRequest) validate() string {
+	switch r.Mode {
+	case "":
+		r.Mode = ModeReplace
+	case ModeReplace, ModeAdd, ModeRemove:
+	default:
+		return "mode must be replace, add or remove"
+	}
+	if r.MaxDepth < 0 {
+		return "max_depth must be 0 or greater"
+	}
+	return ""
+}
+
+// PropagationPreview counts the subfolders and files whose categories change
+type PropagationPreview struct {
+	Folders int64 `json:"folders"`
+	Files   int64 `json:"files"`
+	// Depth is the deepest subfolder level reached
+	Depth int32 `json:"depth"`
+}
+
+// propagation copies a folder's categories down its tree. It is also the
+// payload of propagation jobs.
+type propagation struct {
+	FolderID    uuid.UUID   `json:"folder_id"`
+	CategoryIDs []uuid.UUID `json:"category_ids"`
+	Mode        string      `json:"mode"`
+	// Types are the file types whose categories change
+	Types []string `json:"types"`
+	// Depth is the number of subfolder levels covered; 0 only updates files in the folder itself
+	Depth int32 `json:"depth"`
+}
+
+func newPropagation(folderID uuid.UUID, categoryIDs []uuid.UUID, req CategoriesRequest) propagation {
+	p := propagation{FolderID: folderID, CategoryIDs: categoryIDs, Mode: req.Mode, Types: []string{}}
+	if req.ApplyToSTL {
+		p.Types = append(p.Types, "stl")
+	}
+	if req.ApplyToZIP {
+		p.Types = append(p.Types, "zip")
+	}
+	if req.ApplyToRAR {
+		p.Types = append(p.Types, "rar")
+	}
+	if req.ApplyToSubfolders {
+		p.Depth = maxFolderDepth
+		if req.MaxDepth > 0 && req.MaxDepth < maxFolderDepth {
+			p.Depth = int32(req.MaxDepth)
+		}
+	}
+	return p
+}
+
+// changes reports whether anything besides the folder itself is updated
+func (p propagation) changes() bool {
+	return p.Depth > 0 || len(p.Types) > 0
+}
+
+// mode returns the edit mode; jobs queued before modes existed replace
+func (p propagation) mode() string {
+	if p.Mode == "" {
+		return ModeReplace
+	}
+	return p.Mode
+}
+
+func (p propagation) folderUUID() pgtype.UUID {
+	return pgtype.UUID{Bytes: p.FolderID, Valid: true}
+}
+
+func (p propagation) categoryUUIDs() []pgtype.UUID {
+	ids := make([]pgtype.UUID, len(p.CategoryIDs))
+	for i, id := range p.CategoryIDs {
+		ids[i] = pgtype.UUID{Bytes: id, Valid: true}
+	}
+	return ids
+}
+
+// registerJobs registers category propagation with the job manager. Only one
+// propagation runs at a time so overlapping trees are not written concurrently.
+func (h *Handler) registerJobs() {
+	h.jobs.Register(PropagationJobType, jobs.Definition{
+		Run: func(ctx context.Context, job *jobs.Job) (any, error) {
+			var p propagation
+			if err := job.Decode(&p); err != nil {
+				return nil, err
+			}
+			return h.runPropagation(ctx, job, p)
+		},
+		Exclusive: true,
+	})
+}
+
+func (h *Handler) runPropagation(ctx context.Context, job *jobs.Job, p propagation) (any, error) {
+	queries := db.New(h.pool)
+
+	preview, err := h.previewPropagation(ctx, queries, p)
+	if err != nil {
+		h.logger.Error("failed to count folder tree", zap.Error(err))
+		return nil, err
+	}
+	job.Info("%s: updating %d subfolders (depth %d) and %d files of folder %s",
+		p.mode(), preview.Folders, preview.Depth, preview.Files, p.FolderID)
+
+	if err := h.propagate(ctx, p, job.SetProgress); err != nil {
+		h.logger.Error("failed to propagate folder categories",
+			zap.String("folder_id", p.FolderID.String()),
+			zap.Error(err))
+		return nil, err
+	}
+
+	h.events.Publish(events.TopicLibrary, events.TypeFoldersUpdated, map[string]any{
+		"folder_id":  p.FolderID.String(),
+		"propagated": true,
+	})
+	return map[string]any{
+		"folder_id": p.FolderID.String(),
+		"folders":   preview.Folders,
+		"files":     preview.Files,
+		"depth":     preview.Depth,
+	}, nil
+}
+
+// previewPropagation counts the subfolders and files whose categories
+// propagate would change under the edit mode; rows that already match are
+// left out
+func (h *Handler) previewPropagation(ctx context.Context, queries *db.Queries, p propagation) (PropagationPreview, error) {
+	if !p.changes() {
+		return PropagationPreview{}, nil
+	}
+	row, err := queries.CountFolderPropagation(ctx, db.CountFolderPropagationParams{
+		FolderID:    p.folderUUID(),
+		MaxDepth:    p.Depth,
+		Mode:        p.mode(),
+		CategoryIds: p.categoryUUIDs(),
+		Types:       p.Types,
+	})
+	if err != nil {
+		return PropagationPreview{}, err
+	}
+	return PropagationPreview{Folders: row.Folders, Files: row.Files, Depth: row.Depth}, nil
+}
+
+// propagate applies the categories to the folder itself, to its subfolders
+// and to the matching files in the tree in one transaction, so a failure
+// leaves the whole tree as it was and a replace never leaves a folder or file
+// without categories halfway through.
+func (h *Handler) propagate(ctx context.Context, p propagation, progress func(int)) error {
+	categoryIDs := p.categoryUUIDs()
+
+	err := h.inTx(ctx, func(queries *db.Queries) error {
+		if err := h.updateOwnCategories(ctx, queries, p); err != nil {
+			return err
+		}
+
+		if p.Depth > 0 {
+			if p.mode() == ModeReplace {
+				if _, err := queries.DeleteSubtreeFolderCategories(ctx, db.DeleteSubtreeFolderCategoriesParams{
+					FolderID: p.folderUUID(),
+					MaxDepth: p.Depth,
+				}); err != nil {
+					return err
+				}
+			}
+
+			var (
+				rows int64
+				err  error
+			)
+			if p.mode() == ModeRemove {
+				rows, err = queries.RemoveSubtreeFolderCategories(ctx, db.RemoveSubtreeFolderCategoriesParams{
+					FolderID:    p.folderUUID(),
+					MaxDepth:    p.Depth,
+					CategoryIds: categoryIDs,
+				})
+			} else {
+				rows, err = queries.AddSubtreeFolderCategories(ctx, db.AddSubtreeFolderCategoriesParams{
+					FolderID:    p.folderUUID(),
+					MaxDepth:    p.Depth,
+					CategoryIds: categoryIDs,
+				})
+			}
+			if err != nil {
+				return err
+			}
+			h.logger.Info("bulk updated subfolder categories",
+				zap.String("mode", p.mode()),
+				zap.Int64("rows", rows),
+				zap.Int("categories", len(categoryIDs)))
+		}
+		progress(50)
+
+		if len(p.Types) > 0 {
+			if p.mode() == ModeReplace {
+				if _, err := queries.DeleteSubtreeFileCategories(ctx, db.DeleteSubtreeFileCategoriesParams{
+					FolderID: p.folderUUID(),
+					MaxDepth: p.Depth,
+					Types:    p.Types,
+				}); err != nil {
+					return err
+				}
+			}
+
+			var (
+				rows int64
+				err  error
+			)
+			if p.mode() == ModeRemove {
+				rows, err = queries.RemoveSubtreeFileCategories(ctx, db.RemoveSubtreeFileCategoriesParams{
+					FolderID:    p.folderUUID(),
+					MaxDepth:    p.Depth,
+					Types:       p.Types,
+					CategoryIds: categoryIDs,
+				})
+			} else {
+				rows, err = queries.AddSubtreeFileCategories(ctx, db.AddSubtreeFileCategoriesParams{
+					FolderID:    p.folderUUID(),
+					MaxDepth:    p.Depth,
+					CategoryIds: categoryIDs,
+					Types:       p.Types,
+				})
+			}
+			if err != nil {
+				return err
+			}
+			h.logger.Info("bulk updated file categories",
+				zap.String("mode", p.mode()),
+				zap.Int64("rows", rows),
+				zap.String("types", strings.Join(p.Types, ",")),
+				zap.Int("categories", len(categoryIDs)))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	progress(100)
+	return nil
+}
+
+// updateOwnCategories applies the edit to the categories of the folder itself
+func (h *Handler) updateOwnCategories(ctx context.Context, queries *db.Queries, p propagation) error {
+	if p.mode() == ModeReplace {
+		if err := queries.SetFolderCategories(ctx, p.folderUUID()); err != nil {
+			return err
+		}
+	}
+
+	for _, categoryUUID := range p.categoryUUIDs() {
+		params := db.AddFolderCategoryParams{
+			FolderID:   p.folderUUID(),
+			CategoryID: categoryUUID,
+		}
+		var err error
+		if p.mode() == ModeRemove {
+			err = queries.RemoveFolderCategory(ctx, db.RemoveFolderCategoryParams(params))
+		} else {
+			err = queries.AddFolderCategory(ctx, params)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inTx runs fn with queries bound to a transaction that is committed when fn succeeds
+func (h *Handler) inTx(ctx context.Context, fn func(queries *db.Queries) error) error {
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(db.New(h.pool).WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
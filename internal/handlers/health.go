@@ -2,6 +2,12 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+
+	"stl-manager/internal/ai"
+	"stl-manager/internal/db"
+
+	"go.uber.org/zap"
 )
 
 // Health check
@@ -26,3 +32,42 @@ func (h *Handler) GetAIStatus(w http.ResponseWriter, r *http.Request) {
 		"enabled": h.classifier.IsEnabled(),
 	})
 }
+
+// GetAIUsage returns today's AI usage, the configured limits and spend caps,
+// the number of files waiting to be classified and the daily history
+func (h *Handler) GetAIUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	days := 30
+	if d := r.URL.Query().Get("days"); d != "" {
+		if parsed, err := strconv.Atoi(d); err == nil && parsed > 0 && parsed <= 365 {
+			days = parsed
+		}
+	}
+
+	queued, err := queries.CountClassificationQueue(ctx)
+	if err != nil {
+		h.logger.Error("failed to count classification queue", zap.Error(err))
+		queued = 0
+	}
+
+	history, err := queries.ListAIUsage(ctx, int32(days))
+	if err != nil {
+		h.logger.Error("failed to list AI usage", zap.Error(err))
+		history = []db.AiUsageDaily{}
+	}
+
+	response := map[string]any{
+		"enabled": h.classifier.IsEnabled(),
+		"queued":  queued,
+		"history": history,
+	}
+	if reporter, ok := h.classifier.(ai.UsageReporter); ok {
+		snapshot := reporter.Limiter().Snapshot()
+		response["today"] = snapshot.Today
+		response["limits"] = snapshot.Limits
+	}
+
+	h.respondJSON(w, http.StatusOK, response)
+}
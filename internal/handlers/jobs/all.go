@@ -0,0 +1,226 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"stl-manager/internal/db"
+	jobqueue "stl-manager/internal/jobs"
+	"stl-manager/internal/pagination"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// JobResponse exposes the JSON columns of a job as JSON instead of base64
+type JobResponse struct {
+	db.Job
+	Payload json.RawMessage `json:"payload"`
+	Result  json.RawMessage `json:"result"`
+}
+
+func newJobResponse(job db.Job) JobResponse {
+	return JobResponse{
+		Job:     job,
+		Payload: job.Payload,
+		Result:  job.Result,
+	}
+}
+
+var validStatuses = map[string]bool{
+	jobqueue.StatusQueued:    true,
+	jobqueue.StatusRunning:   true,
+	jobqueue.StatusCompleted: true,
+	jobqueue.StatusFailed:    true,
+	jobqueue.StatusCancelled: true,
+}
+
+const (
+	// sortByCreated tags cursors of the job list, newest first
+	sortByCreated = "created_at"
+	// sortLogs tags cursors of a job's logs, oldest first
+	sortLogs = "log_id"
+)
+
+func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	query := r.URL.Query()
+	jobType := query.Get("type")
+	status := query.Get("status")
+	if status != "" && !validStatuses[status] {
+		h.RespondError(w, http.StatusBadRequest, "invalid status")
+		return
+	}
+
+	p, err := pagination.Parse(query, pagination.DefaultPageSize)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	after, err := p.After(sortByCreated)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params := db.ListJobsParams{
+		Type:   jobType,
+		Status: status,
+		Limit:  int32(p.Limit()),
+		Offset: int32(p.Offset()),
+	}
+	if after != nil {
+		createdAt, err := time.Parse(time.RFC3339Nano, after.Key)
+		if err != nil {
+			h.RespondError(w, http.StatusBadRequest, pagination.ErrInvalidCursor.Error())
+			return
+		}
+		params.AfterID = after.ID
+		params.AfterCreatedAt = pgtype.Timestamptz{Time: createdAt, Valid: true}
+	}
+
+	jobs, err := queries.ListJobs(ctx, params)
+	if err != nil {
+		h.logger.Error("failed to list jobs", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to list jobs")
+		return
+	}
+
+	total, err := queries.CountJobs(ctx, db.CountJobsParams{
+		Type:   jobType,
+		Status: status,
+	})
+	if err != nil {
+		h.logger.Error("failed to count jobs", zap.Error(err))
+		total = 0
+	}
+
+	jobs, next := pagination.Trim(jobs, p, func(job db.Job) pagination.Cursor {
+		return pagination.NewCursor(sortByCreated, job.CreatedAt.Time.Format(time.RFC3339Nano), job.ID)
+	})
+
+	items := make([]JobResponse, len(jobs))
+	for i, job := range jobs {
+		items[i] = newJobResponse(job)
+	}
+
+	h.RespondJSON(w, http.StatusOK, p.Response(items, total, next))
+}
+
+func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	idStr := chi.URLParam(r, "id")
+	jobID, err := uuid.Parse(idStr)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	job, err := queries.GetJob(ctx, pgtype.UUID{Bytes: jobID, Valid: true})
+	if err != nil {
+		h.RespondError(w, http.StatusNotFound, "job not found")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusOK, newJobResponse(job))
+}
+
+func (h *Handler) ListJobLogs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	idStr := chi.URLParam(r, "id")
+	jobID, err := uuid.Parse(idStr)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	job, err := queries.GetJob(ctx, pgtype.UUID{Bytes: jobID, Valid: true})
+	if err != nil {
+		h.RespondError(w, http.StatusNotFound, "job not found")
+		return
+	}
+
+	p, err := pagination.Parse(r.URL.Query(), pagination.MaxPageSize)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	after, err := p.After(sortLogs)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params := db.ListJobLogsParams{
+		JobID:  job.ID,
+		Limit:  int32(p.Limit()),
+		Offset: int32(p.Offset()),
+	}
+	if after != nil {
+		// Log IDs are not UUIDs, so the cursor carries the log ID as its key
+		// and the job ID as its ID
+		logID, err := strconv.ParseInt(after.Key, 10, 64)
+		if err != nil || after.ID != job.ID {
+			h.RespondError(w, http.StatusBadRequest, pagination.ErrInvalidCursor.Error())
+			return
+		}
+		params.AfterID = logID
+	}
+
+	logs, err := queries.ListJobLogs(ctx, params)
+	if err != nil {
+		h.logger.Error("failed to list job logs", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to list job logs")
+		return
+	}
+
+	total, err := queries.CountJobLogs(ctx, job.ID)
+	if err != nil {
+		h.logger.Error("failed to count job logs", zap.Error(err))
+		total = 0
+	}
+
+	logs, next := pagination.Trim(logs, p, func(log db.JobLog) pagination.Cursor {
+		return pagination.NewCursor(sortLogs, strconv.FormatInt(log.ID, 10), job.ID)
+	})
+
+	h.RespondJSON(w, http.StatusOK, p.Response(logs, total, next))
+}
+
+func (h *Handler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	jobID, err := uuid.Parse(idStr)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	err = h.manager.Cancel(ctx, jobID)
+	switch {
+	case errors.Is(err, jobqueue.ErrNotFound):
+		h.RespondError(w, http.StatusNotFound, "job not found")
+		return
+	case errors.Is(err, jobqueue.ErrFinished):
+		h.RespondError(w, http.StatusConflict, "job already finished")
+		return
+	case err != nil:
+		h.logger.Error("failed to cancel job", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to cancel job")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusAccepted, map[string]string{
+		"message": "job cancellation requested",
+	})
+}
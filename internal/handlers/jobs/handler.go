@@ -0,0 +1,37 @@
+package jobs
+
+import (
+	"encoding/json"
+	"net/http"
+
+	jobqueue "stl-manager/internal/jobs"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	pool    *pgxpool.Pool
+	manager *jobqueue.Manager
+	logger  *zap.Logger
+}
+
+func New(pool *pgxpool.Pool, manager *jobqueue.Manager, logger *zap.Logger) *Handler {
+	return &Handler{
+		pool:    pool,
+		manager: manager,
+		logger:  logger,
+	}
+}
+
+func (h *Handler) RespondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if data != nil {
+		json.NewEncoder(w).Encode(data)
+	}
+}
+
+func (h *Handler) RespondError(w http.ResponseWriter, status int, message string) {
+	h.RespondJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,201 @@
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"stl-manager/internal/db"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// Custom field types
+const (
+	TypeText    = "text"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeSelect  = "select"
+)
+
+// fieldKey matches the keys the custom_fields table accepts
+var fieldKey = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// FieldRequest is the body of create and update requests. Key and type are
+// fixed once the field exists, so values already stored stay valid.
+type FieldRequest struct {
+	Key     string   `json:"key"`
+	Label   string   `json:"label"`
+	Type    string   `json:"type"`
+	Options []string `json:"options"`
+}
+
+// validate trims the request. The returned message is empty when the request
+// is valid.
+func (req *FieldRequest) validate() string {
+	req.Key = strings.TrimSpace(req.Key)
+	req.Label = strings.TrimSpace(req.Label)
+	if !fieldKey.MatchString(req.Key) {
+		return "key must start with a lowercase letter and contain only lowercase letters, digits and underscores"
+	}
+	if req.Label == "" {
+		req.Label = req.Key
+	}
+
+	options := []string{}
+	for _, option := range req.Options {
+		if option = strings.TrimSpace(option); option != "" && !slices.Contains(options, option) {
+			options = append(options, option)
+		}
+	}
+	req.Options = options
+
+	switch req.Type {
+	case TypeSelect:
+		if len(req.Options) == 0 {
+			return "select fields need at least one option"
+		}
+	case TypeText, TypeNumber, TypeBoolean:
+		if len(req.Options) > 0 {
+			return "only select fields have options"
+		}
+	case "":
+		return "type is required"
+	default:
+		return "type must be text, number, boolean or select"
+	}
+	return ""
+}
+
+// ListFields lists the custom field definitions by key
+func (h *Handler) ListFields(w http.ResponseWriter, r *http.Request) {
+	fields, err := db.New(h.pool).ListCustomFields(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list custom fields", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to list custom fields")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusOK, map[string]any{
+		"items": fields,
+		"total": len(fields),
+	})
+}
+
+func (h *Handler) CreateField(w http.ResponseWriter, r *http.Request) {
+	var req FieldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		h.RespondError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	created, err := db.New(h.pool).CreateCustomField(r.Context(), db.CreateCustomFieldParams{
+		Key:     req.Key,
+		Label:   req.Label,
+		Type:    req.Type,
+		Options: req.Options,
+	})
+	if isUniqueViolation(err) {
+		h.RespondError(w, http.StatusConflict, "a custom field with this key already exists")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to create custom field", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to create custom field")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusCreated, created)
+}
+
+// UpdateField changes the label and options of a field; omitted properties
+// keep their value. Values stored before an option was removed are kept.
+func (h *Handler) UpdateField(w http.ResponseWriter, r *http.Request) {
+	field, ok := h.loadField(w, r)
+	if !ok {
+		return
+	}
+
+	req := FieldRequest{Key: field.Key, Label: field.Label, Type: field.Type, Options: field.Options}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Key != field.Key || req.Type != field.Type {
+		h.RespondError(w, http.StatusBadRequest, "key and type cannot be changed")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		h.RespondError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	updated, err := db.New(h.pool).UpdateCustomField(r.Context(), db.UpdateCustomFieldParams{
+		ID:      field.ID,
+		Label:   req.Label,
+		Options: req.Options,
+	})
+	if err != nil {
+		h.logger.Error("failed to update custom field", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to update custom field")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusOK, updated)
+}
+
+// DeleteField removes a field and its values on every file and folder
+func (h *Handler) DeleteField(w http.ResponseWriter, r *http.Request) {
+	field, ok := h.loadField(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := db.New(h.pool).DeleteCustomField(r.Context(), field.ID); err != nil {
+		h.logger.Error("failed to delete custom field", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to delete custom field")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusOK, map[string]string{"message": "custom field deleted successfully"})
+}
+
+// loadField reads the field named by the id URL parameter. On failure it has
+// already written the error response.
+func (h *Handler) loadField(w http.ResponseWriter, r *http.Request) (db.CustomField, bool) {
+	fieldID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid custom field ID")
+		return db.CustomField{}, false
+	}
+
+	field, err := db.New(h.pool).GetCustomField(r.Context(), pgtype.UUID{Bytes: fieldID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.RespondError(w, http.StatusNotFound, "custom field not found")
+		return db.CustomField{}, false
+	}
+	if err != nil {
+		h.logger.Error("failed to get custom field", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to get custom field")
+		return db.CustomField{}, false
+	}
+	return field, true
+}
+
+// isUniqueViolation reports whether err is a conflict on a unique constraint
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,30 @@
+package metadata
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func New(pool *pgxpool.Pool, logger *zap.Logger) *Handler {
+	return &Handler{pool: pool, logger: logger}
+}
+
+func (h *Handler) RespondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if data != nil {
+		json.NewEncoder(w).Encode(data)
+	}
+}
+
+func (h *Handler) RespondError(w http.ResponseWriter, status int, message string) {
+	h.RespondJSON(w, status, map[string]string{"error": message})
+}
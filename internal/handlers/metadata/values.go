@@ -0,0 +1,213 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"stl-manager/internal/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Request is the body of the PATCH endpoints on files and folders. Omitted
+// properties are left alone; a rating of 0 clears the rating and a null
+// custom field value removes the value.
+type Request struct {
+	Favorite     *bool                      `json:"favorite"`
+	Rating       *int16                     `json:"rating"`
+	Note         *string                    `json:"note"`
+	CustomFields map[string]json.RawMessage `json:"custom_fields"`
+
+	fields map[string]db.CustomField
+}
+
+// Validate checks the request against the custom field definitions. The
+// returned message is empty when the request is valid; the error is only
+// set when the definitions could not be loaded.
+func (req *Request) Validate(ctx context.Context, queries *db.Queries) (string, error) {
+	if req.Favorite == nil && req.Rating == nil && req.Note == nil && len(req.CustomFields) == 0 {
+		return "nothing to update", nil
+	}
+	if req.Rating != nil && (*req.Rating < 0 || *req.Rating > 5) {
+		return "rating must be between 1 and 5, or 0 to clear it", nil
+	}
+	if len(req.CustomFields) == 0 {
+		return "", nil
+	}
+
+	keys := make([]string, 0, len(req.CustomFields))
+	for key := range req.CustomFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	defs, err := queries.GetCustomFieldsByKeys(ctx, keys)
+	if err != nil {
+		return "", err
+	}
+	req.fields = make(map[string]db.CustomField, len(defs))
+	for _, def := range defs {
+		req.fields[def.Key] = def
+	}
+
+	for _, key := range keys {
+		field, ok := req.fields[key]
+		if !ok {
+			return fmt.Sprintf("unknown custom field: %s", key), nil
+		}
+		value := req.CustomFields[key]
+		if isNull(value) {
+			continue
+		}
+		if msg := checkValue(field, value); msg != "" {
+			return msg, nil
+		}
+	}
+	return "", nil
+}
+
+// Merge applies the favourite, rating and note of the request to the
+// current values of a file or folder.
+func (req *Request) Merge(favorite bool, rating pgtype.Int2, note string) (bool, pgtype.Int2, string) {
+	if req.Favorite != nil {
+		favorite = *req.Favorite
+	}
+	if req.Rating != nil {
+		rating = pgtype.Int2{Int16: *req.Rating, Valid: *req.Rating > 0}
+	}
+	if req.Note != nil {
+		note = *req.Note
+	}
+	return favorite, rating, note
+}
+
+// SaveFile stores the custom field values of a validated request on a file
+func (req *Request) SaveFile(ctx context.Context, queries *db.Queries, fileID pgtype.UUID) error {
+	for key, value := range req.CustomFields {
+		fieldID := req.fields[key].ID
+		var err error
+		if isNull(value) {
+			err = queries.DeleteFileFieldValue(ctx, db.DeleteFileFieldValueParams{FieldID: fieldID, FileID: fileID})
+		} else {
+			err = queries.SetFileFieldValue(ctx, db.SetFileFieldValueParams{FieldID: fieldID, FileID: fileID, Value: compact(value)})
+		}
+		if err != nil {
+			return fmt.Errorf("custom field %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// SaveFolder stores the custom field values of a validated request on a folder
+func (req *Request) SaveFolder(ctx context.Context, queries *db.Queries, folderID pgtype.UUID) error {
+	for key, value := range req.CustomFields {
+		fieldID := req.fields[key].ID
+		var err error
+		if isNull(value) {
+			err = queries.DeleteFolderFieldValue(ctx, db.DeleteFolderFieldValueParams{FieldID: fieldID, FolderID: folderID})
+		} else {
+			err = queries.SetFolderFieldValue(ctx, db.SetFolderFieldValueParams{FieldID: fieldID, FolderID: folderID, Value: compact(value)})
+		}
+		if err != nil {
+			return fmt.Errorf("custom field %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// checkValue returns a message when value does not match the field type
+func checkValue(field db.CustomField, value json.RawMessage) string {
+	switch field.Type {
+	case TypeText:
+		var s string
+		if json.Unmarshal(value, &s) != nil {
+			return fmt.Sprintf("custom field %s must be a string", field.Key)
+		}
+	case TypeNumber:
+		var n float64
+		if json.Unmarshal(value, &n) != nil {
+			return fmt.Sprintf("custom field %s must be a number", field.Key)
+		}
+	case TypeBoolean:
+		var b bool
+		if json.Unmarshal(value, &b) != nil {
+			return fmt.Sprintf("custom field %s must be a boolean", field.Key)
+		}
+	case TypeSelect:
+		var s string
+		if json.Unmarshal(value, &s) != nil || !slices.Contains(field.Options, s) {
+			return fmt.Sprintf("custom field %s must be one of: %s", field.Key, strings.Join(field.Options, ", "))
+		}
+	}
+	return ""
+}
+
+func isNull(value json.RawMessage) bool {
+	return len(bytes.TrimSpace(value)) == 0 || bytes.Equal(bytes.TrimSpace(value), []byte("null"))
+}
+
+func compact(value json.RawMessage) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, value); err != nil {
+		return value
+	}
+	return buf.Bytes()
+}
+
+// Values holds the custom field values of one file or folder by field key
+type Values map[string]json.RawMessage
+
+// ValuesByID holds the custom field values of several files or folders
+type ValuesByID map[pgtype.UUID]Values
+
+// Get returns the values of id, never nil so it encodes as an object
+func (v ValuesByID) Get(id pgtype.UUID) Values {
+	if values, ok := v[id]; ok {
+		return values
+	}
+	return Values{}
+}
+
+func (v ValuesByID) add(id pgtype.UUID, key string, value []byte) {
+	if v[id] == nil {
+		v[id] = Values{}
+	}
+	v[id][key] = json.RawMessage(value)
+}
+
+// FileValues loads the custom field values of the given files
+func FileValues(ctx context.Context, queries *db.Queries, ids []pgtype.UUID) (ValuesByID, error) {
+	values := ValuesByID{}
+	if len(ids) == 0 {
+		return values, nil
+	}
+	rows, err := queries.ListFileFieldValues(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		values.add(row.FileID, row.Key, row.Value)
+	}
+	return values, nil
+}
+
+// FolderValues loads the custom field values of the given folders
+func FolderValues(ctx context.Context, queries *db.Queries, ids []pgtype.UUID) (ValuesByID, error) {
+	values := ValuesByID{}
+	if len(ids) == 0 {
+		return values, nil
+	}
+	rows, err := queries.ListFolderFieldValues(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		values.add(row.FolderID, row.Key, row.Value)
+	}
+	return values, nil
+}
@@ -0,0 +1,156 @@
+package prints
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"stl-manager/internal/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// Print results
+const (
+	ResultSuccess = "success"
+	ResultFailed  = "failed"
+	ResultPartial = "partial"
+)
+
+// PrintRequest is the body of create and update requests. Layer height and
+// nozzle are in millimetres; optional settings are null when unknown.
+type PrintRequest struct {
+	PrintedAt       *time.Time `json:"printed_at"`
+	Printer         string     `json:"printer"`
+	Material        string     `json:"material"`
+	LayerHeight     *float64   `json:"layer_height"`
+	Nozzle          *float64   `json:"nozzle"`
+	DurationSeconds *int32     `json:"duration_seconds"`
+	Result          string     `json:"result"`
+	Note            string     `json:"note"`
+}
+
+// PrintResponse hides where the photo is stored; photo_url is null without a
+// photo
+type PrintResponse struct {
+	ID              pgtype.UUID        `json:"id"`
+	FileID          pgtype.UUID        `json:"file_id"`
+	PrintedAt       pgtype.Timestamptz `json:"printed_at"`
+	Printer         string             `json:"printer"`
+	Material        string             `json:"material"`
+	LayerHeight     pgtype.Float8      `json:"layer_height"`
+	Nozzle          pgtype.Float8      `json:"nozzle"`
+	DurationSeconds pgtype.Int4        `json:"duration_seconds"`
+	Result          string             `json:"result"`
+	Note            string             `json:"note"`
+	PhotoURL        *string            `json:"photo_url"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
+func newPrintResponse(p db.Print) PrintResponse {
+	resp := PrintResponse{
+		ID:              p.ID,
+		FileID:          p.FileID,
+		PrintedAt:       p.PrintedAt,
+		Printer:         p.Printer,
+		Material:        p.Material,
+		LayerHeight:     p.LayerHeight,
+		Nozzle:          p.Nozzle,
+		DurationSeconds: p.DurationSeconds,
+		Result:          p.Result,
+		Note:            p.Note,
+		CreatedAt:       p.CreatedAt,
+		UpdatedAt:       p.UpdatedAt,
+	}
+	if p.PhotoPath.Valid {
+		url := "/v1/files/" + uuid.UUID(p.FileID.Bytes).String() + "/prints/" + uuid.UUID(p.ID.Bytes).String() + "/photo"
+		resp.PhotoURL = &url
+	}
+	return resp
+}
+
+// validate trims the request. The returned message is empty when the request
+// is valid.
+func (req *PrintRequest) validate() string {
+	req.Printer = strings.TrimSpace(req.Printer)
+	req.Material = strings.TrimSpace(req.Material)
+	req.Note = strings.TrimSpace(req.Note)
+	switch req.Result {
+	case ResultSuccess, ResultFailed, ResultPartial:
+	case "":
+		return "result is required"
+	default:
+		return "result must be success, failed or partial"
+	}
+	if req.LayerHeight != nil && *req.LayerHeight <= 0 {
+		return "layer_height must be greater than 0"
+	}
+	if req.Nozzle != nil && *req.Nozzle <= 0 {
+		return "nozzle must be greater than 0"
+	}
+	if req.DurationSeconds != nil && *req.DurationSeconds < 0 {
+		return "duration_seconds cannot be negative"
+	}
+	return ""
+}
+
+func float8(v *float64) pgtype.Float8 {
+	if v == nil {
+		return pgtype.Float8{}
+	}
+	return pgtype.Float8{Float64: *v, Valid: true}
+}
+
+func int4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
+
+// CreatePrint records a print of a file. printed_at defaults to now.
+func (h *Handler) CreatePrint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	file, ok := h.loadFile(w, r)
+	if !ok {
+		return
+	}
+
+	var req PrintRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		h.RespondError(w, http.StatusBadRequest, msg)
+		return
+	}
+	printedAt := time.Now()
+	if req.PrintedAt != nil {
+		printedAt = *req.PrintedAt
+	}
+
+	created, err := db.New(h.pool).CreatePrint(ctx, db.CreatePrintParams{
+		FileID:          file.ID,
+		PrintedAt:       pgtype.Timestamptz{Time: printedAt, Valid: true},
+		Printer:         req.Printer,
+		Material:        req.Material,
+		LayerHeight:     float8(req.LayerHeight),
+		Nozzle:          float8(req.Nozzle),
+		DurationSeconds: int4(req.DurationSeconds),
+		Result:          req.Result,
+		Note:            req.Note,
+	})
+	if err != nil {
+		h.logger.Error("failed to create print", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to create print")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusCreated, newPrintResponse(created))
+}
@@ -0,0 +1,31 @@
+package prints
+
+import (
+	"net/http"
+
+	"stl-manager/internal/db"
+
+	"go.uber.org/zap"
+)
+
+// DeletePrint removes a print and its photo
+func (h *Handler) DeletePrint(w http.ResponseWriter, r *http.Request) {
+	entry, ok := h.loadPrint(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := db.New(h.pool).DeletePrint(r.Context(), db.DeletePrintParams{FileID: entry.FileID, ID: entry.ID}); err != nil {
+		h.logger.Error("failed to delete print", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to delete print")
+		return
+	}
+
+	if entry.PhotoPath.Valid {
+		if err := h.media.Remove(entry.PhotoPath.String); err != nil {
+			h.logger.Warn("failed to remove print photo", zap.String("path", entry.PhotoPath.String), zap.Error(err))
+		}
+	}
+
+	h.RespondJSON(w, http.StatusOK, map[string]string{"message": "print deleted successfully"})
+}
@@ -0,0 +1,130 @@
+package prints
+
+import (
+	"errors"
+	"net/http"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/pagination"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// ListPrints lists the prints of a file, most recent first, with the file's
+// print statistics
+func (h *Handler) ListPrints(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	file, ok := h.loadFile(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := pagination.Parse(r.URL.Query(), pagination.DefaultPageSize)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if p.Cursor != nil {
+		h.RespondError(w, http.StatusBadRequest, "cursor is not supported; use page")
+		return
+	}
+
+	rows, err := queries.ListFilePrints(ctx, db.ListFilePrintsParams{
+		FileID: file.ID,
+		Limit:  int32(p.PageSize),
+		Offset: int32(p.Offset()),
+	})
+	if err != nil {
+		h.logger.Error("failed to list prints", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to list prints")
+		return
+	}
+
+	total, err := queries.CountFilePrints(ctx, file.ID)
+	if err != nil {
+		h.logger.Error("failed to count prints", zap.Error(err))
+		total = 0
+	}
+
+	stats, err := FileStats(ctx, queries, []pgtype.UUID{file.ID})
+	if err != nil {
+		h.logger.Warn("failed to get file print stats", zap.Error(err))
+	}
+
+	items := make([]PrintResponse, len(rows))
+	for i, row := range rows {
+		items[i] = newPrintResponse(row)
+	}
+
+	response := p.Response(items, total, "")
+	response["stats"] = stats[file.ID]
+	h.RespondJSON(w, http.StatusOK, response)
+}
+
+// GetPrint returns a single print of a file
+func (h *Handler) GetPrint(w http.ResponseWriter, r *http.Request) {
+	entry, ok := h.loadPrint(w, r)
+	if !ok {
+		return
+	}
+	h.RespondJSON(w, http.StatusOK, newPrintResponse(entry))
+}
+
+// loadFile reads the file named by the id URL parameter. On failure it has
+// already written the error response.
+func (h *Handler) loadFile(w http.ResponseWriter, r *http.Request) (db.File, bool) {
+	fileID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid file ID")
+		return db.File{}, false
+	}
+
+	file, err := db.New(h.pool).GetFile(r.Context(), pgtype.UUID{Bytes: fileID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.RespondError(w, http.StatusNotFound, "file not found")
+		return db.File{}, false
+	}
+	if err != nil {
+		h.logger.Error("failed to get file", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to get file")
+		return db.File{}, false
+	}
+	return file, true
+}
+
+// loadPrint reads the print named by the printId URL parameter, which must
+// belong to the file named by id. On failure it has already written the error
+// response.
+func (h *Handler) loadPrint(w http.ResponseWriter, r *http.Request) (db.Print, bool) {
+	fileID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid file ID")
+		return db.Print{}, false
+	}
+	printID, err := uuid.Parse(chi.URLParam(r, "printId"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid print ID")
+		return db.Print{}, false
+	}
+
+	entry, err := db.New(h.pool).GetPrint(r.Context(), db.GetPrintParams{
+		FileID: pgtype.UUID{Bytes: fileID, Valid: true},
+		ID:     pgtype.UUID{Bytes: printID, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.RespondError(w, http.StatusNotFound, "print not found")
+		return db.Print{}, false
+	}
+	if err != nil {
+		h.logger.Error("failed to get print", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to get print")
+		return db.Print{}, false
+	}
+	return entry, true
+}
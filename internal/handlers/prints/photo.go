@@ -0,0 +1,112 @@
+package prints
+
+import (
+	"errors"
+	"net/http"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/media"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// photoKind is the media directory print photos are stored in
+const photoKind = "prints"
+
+// UploadPhoto sets the photo of a print from a multipart "image" field,
+// replacing any previous photo
+func (h *Handler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
+	entry, ok := h.loadPrint(w, r)
+	if !ok {
+		return
+	}
+
+	image, err := media.FormImage(w, r, "image")
+	if errors.Is(err, media.ErrTooLarge) {
+		h.RespondError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer image.Close()
+
+	path, contentType, err := h.media.SaveImage(photoKind, uuid.UUID(entry.ID.Bytes).String(), image)
+	if errors.Is(err, media.ErrTooLarge) {
+		h.RespondError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	if errors.Is(err, media.ErrNotImage) {
+		h.RespondError(w, http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to save print photo", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to save photo")
+		return
+	}
+
+	updated, err := db.New(h.pool).SetPrintPhoto(r.Context(), db.SetPrintPhotoParams{
+		FileID:           entry.FileID,
+		ID:               entry.ID,
+		PhotoPath:        pgtype.Text{String: path, Valid: true},
+		PhotoContentType: pgtype.Text{String: contentType, Valid: true},
+	})
+	if err != nil {
+		h.logger.Error("failed to set print photo", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to save photo")
+		return
+	}
+
+	// A photo of another type was stored under a different extension
+	if entry.PhotoPath.Valid && entry.PhotoPath.String != path {
+		if err := h.media.Remove(entry.PhotoPath.String); err != nil {
+			h.logger.Warn("failed to remove previous print photo", zap.Error(err))
+		}
+	}
+
+	h.RespondJSON(w, http.StatusOK, newPrintResponse(updated))
+}
+
+// GetPhoto serves the photo of a print
+func (h *Handler) GetPhoto(w http.ResponseWriter, r *http.Request) {
+	entry, ok := h.loadPrint(w, r)
+	if !ok {
+		return
+	}
+	if !entry.PhotoPath.Valid {
+		h.RespondError(w, http.StatusNotFound, "print has no photo")
+		return
+	}
+
+	if err := h.media.Serve(w, r, entry.PhotoPath.String, entry.PhotoContentType.String); err != nil {
+		h.logger.Warn("print photo missing from media directory", zap.String("path", entry.PhotoPath.String), zap.Error(err))
+		h.RespondError(w, http.StatusNotFound, "photo not found")
+	}
+}
+
+// DeletePhoto removes the photo of a print
+func (h *Handler) DeletePhoto(w http.ResponseWriter, r *http.Request) {
+	entry, ok := h.loadPrint(w, r)
+	if !ok {
+		return
+	}
+	if !entry.PhotoPath.Valid {
+		h.RespondError(w, http.StatusNotFound, "print has no photo")
+		return
+	}
+
+	if _, err := db.New(h.pool).SetPrintPhoto(r.Context(), db.SetPrintPhotoParams{FileID: entry.FileID, ID: entry.ID}); err != nil {
+		h.logger.Error("failed to clear print photo", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to remove photo")
+		return
+	}
+	if err := h.media.Remove(entry.PhotoPath.String); err != nil {
+		h.logger.Warn("failed to remove print photo", zap.Error(err))
+	}
+
+	h.RespondJSON(w, http.StatusOK, map[string]string{"message": "photo removed"})
+}
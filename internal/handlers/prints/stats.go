@@ -0,0 +1,63 @@
+package prints
+
+import (
+	"context"
+
+	"stl-manager/internal/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Stats summarise the print history of a file, or of every file under a
+// folder. The zero value reads as never printed.
+type Stats struct {
+	PrintCount    int64              `json:"print_count"`
+	LastPrintedAt pgtype.Timestamptz `json:"last_printed_at"`
+	// SuccessRate is the share of prints that succeeded, from 0 to 1; partial
+	// prints do not count as successes. It is null before the first print.
+	SuccessRate *float64 `json:"success_rate"`
+}
+
+func newStats(printCount, successCount int64, lastPrintedAt pgtype.Timestamptz) Stats {
+	stats := Stats{PrintCount: printCount, LastPrintedAt: lastPrintedAt}
+	if printCount > 0 {
+		rate := float64(successCount) / float64(printCount)
+		stats.SuccessRate = &rate
+	}
+	return stats
+}
+
+// FileStats loads the print statistics of files in one query. Files that were
+// never printed are not in the map.
+func FileStats(ctx context.Context, queries *db.Queries, fileIDs []pgtype.UUID) (map[pgtype.UUID]Stats, error) {
+	stats := make(map[pgtype.UUID]Stats)
+	if len(fileIDs) == 0 {
+		return stats, nil
+	}
+	rows, err := queries.GetFilePrintStats(ctx, fileIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		stats[row.FileID] = newStats(row.PrintCount, row.SuccessCount, row.LastPrintedAt)
+	}
+	return stats, nil
+}
+
+// FolderStats loads the print statistics of folders in one query, counting
+// the prints of every file in their subtrees. Folders without prints are not
+// in the map.
+func FolderStats(ctx context.Context, queries *db.Queries, folderIDs []pgtype.UUID) (map[pgtype.UUID]Stats, error) {
+	stats := make(map[pgtype.UUID]Stats)
+	if len(folderIDs) == 0 {
+		return stats, nil
+	}
+	rows, err := queries.GetFolderPrintStats(ctx, folderIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		stats[row.FolderID] = newStats(row.PrintCount, row.SuccessCount, row.LastPrintedAt)
+	}
+	return stats, nil
+}
@@ -0,0 +1,54 @@
+package prints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"stl-manager/internal/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// UpdatePrint replaces the details of a print. printed_at keeps its value when
+// omitted; the photo is changed through its own endpoint.
+func (h *Handler) UpdatePrint(w http.ResponseWriter, r *http.Request) {
+	entry, ok := h.loadPrint(w, r)
+	if !ok {
+		return
+	}
+
+	var req PrintRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		h.RespondError(w, http.StatusBadRequest, msg)
+		return
+	}
+	printedAt := entry.PrintedAt
+	if req.PrintedAt != nil {
+		printedAt = pgtype.Timestamptz{Time: *req.PrintedAt, Valid: true}
+	}
+
+	updated, err := db.New(h.pool).UpdatePrint(r.Context(), db.UpdatePrintParams{
+		FileID:          entry.FileID,
+		ID:              entry.ID,
+		PrintedAt:       printedAt,
+		Printer:         req.Printer,
+		Material:        req.Material,
+		LayerHeight:     float8(req.LayerHeight),
+		Nozzle:          float8(req.Nozzle),
+		DurationSeconds: int4(req.DurationSeconds),
+		Result:          req.Result,
+		Note:            req.Note,
+	})
+	if err != nil {
+		h.logger.Error("failed to update print", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to update print")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusOK, newPrintResponse(updated))
+}
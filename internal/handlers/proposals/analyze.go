@@ -0,0 +1,176 @@
+package proposals
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"stl-manager/internal/ai"
+	"stl-manager/internal/db"
+	"stl-manager/internal/jobs"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultSampleSize = 100
+	maxSampleSize     = 300
+)
+
+// JobType is the job type used for category analysis
+const JobType = "category_analysis"
+
+type AnalyzeRequest struct {
+	SampleSize int `json:"sample_size"`
+}
+
+type AnalyzeResponse struct {
+	JobID      string `json:"job_id"`
+	SampleSize int    `json:"sample_size"`
+}
+
+// registerJobs registers category analysis with the job manager. Only one
+// analysis runs at a time and interrupted runs are not resumed.
+func (h *Handler) registerJobs() {
+	h.jobs.Register(JobType, jobs.Definition{
+		Run: func(ctx context.Context, job *jobs.Job) (any, error) {
+			var req AnalyzeRequest
+			if err := job.Decode(&req); err != nil {
+				return nil, err
+			}
+			suggester, ok := h.classifier.(ai.Suggester)
+			if !ok || !h.classifier.IsEnabled() {
+				return nil, errors.New("OpenAI classification is not enabled")
+			}
+			return h.runAnalysis(ctx, job, suggester, req.SampleSize)
+		},
+		Exclusive: true,
+	})
+}
+
+// AnalyzeUncategorized samples uncategorized files and asks the model for new
+// categories in a background job. Results are stored as pending proposals.
+func (h *Handler) AnalyzeUncategorized(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.classifier.(ai.Suggester); !ok || !h.classifier.IsEnabled() {
+		h.RespondError(w, http.StatusServiceUnavailable, "OpenAI classification is not enabled")
+		return
+	}
+
+	req := AnalyzeRequest{SampleSize: defaultSampleSize}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	if req.SampleSize <= 0 || req.SampleSize > maxSampleSize {
+		h.RespondError(w, http.StatusBadRequest, "sample_size must be between 1 and 300")
+		return
+	}
+
+	job, err := h.jobs.Enqueue(r.Context(), JobType, req)
+	if errors.Is(err, jobs.ErrAlreadyRunning) {
+		h.RespondError(w, http.StatusConflict, "an analysis is already running")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to start category analysis", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to start analysis")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusAccepted, AnalyzeResponse{
+		JobID:      uuid.UUID(job.ID.Bytes).String(),
+		SampleSize: req.SampleSize,
+	})
+}
+
+func (h *Handler) runAnalysis(ctx context.Context, job *jobs.Job, suggester ai.Suggester, sampleSize int) (any, error) {
+	queries := db.New(h.pool)
+
+	files, err := queries.SampleUncategorizedFiles(ctx, int32(sampleSize))
+	if err != nil {
+		h.logger.Error("failed to sample uncategorized files", zap.Error(err))
+		return nil, err
+	}
+	if len(files) == 0 {
+		job.Info("no uncategorized files to analyze")
+		return map[string]any{"sampled": 0, "proposals": 0}, nil
+	}
+	job.Info("sampled %d uncategorized files", len(files))
+	job.SetProgress(10)
+
+	categories, err := queries.ListCategories(ctx)
+	if err != nil {
+		h.logger.Error("failed to list categories", zap.Error(err))
+		return nil, err
+	}
+	categoryNames := make([]string, len(categories))
+	for i, cat := range categories {
+		categoryNames[i] = cat.Name
+	}
+
+	// Names merged into another category must not come back as proposals
+	aliases, err := queries.ListCategoryAliases(ctx)
+	if err != nil {
+		h.logger.Error("failed to list category aliases", zap.Error(err))
+		return nil, err
+	}
+	for _, alias := range aliases {
+		categoryNames = append(categoryNames, alias.Alias)
+	}
+
+	// Several files can share a name in different folders
+	idsByName := make(map[string][]pgtype.UUID)
+	fileNames := make([]string, 0, len(files))
+	for _, file := range files {
+		if _, seen := idsByName[file.FileName]; !seen {
+			fileNames = append(fileNames, file.FileName)
+		}
+		idsByName[file.FileName] = append(idsByName[file.FileName], file.ID)
+	}
+
+	suggestions, err := suggester.SuggestCategories(ctx, fileNames, categoryNames)
+	if err != nil {
+		h.logger.Error("failed to suggest categories", zap.Error(err))
+		return nil, err
+	}
+	job.SetProgress(80)
+
+	for _, suggestion := range suggestions {
+		proposal, err := queries.UpsertCategoryProposal(ctx, db.UpsertCategoryProposalParams{
+			Name:        suggestion.Name,
+			Description: suggestion.Description,
+		})
+		if err != nil {
+			h.logger.Error("failed to save category proposal", zap.String("name", suggestion.Name), zap.Error(err))
+			job.Error("failed to save proposal %q: %v", suggestion.Name, err)
+			continue
+		}
+
+		fileIDs := []pgtype.UUID{}
+		for _, example := range suggestion.Examples {
+			fileIDs = append(fileIDs, idsByName[example]...)
+		}
+		if err := queries.AddCategoryProposalFiles(ctx, db.AddCategoryProposalFilesParams{
+			ProposalID: proposal.ID,
+			FileIds:    fileIDs,
+		}); err != nil {
+			h.logger.Error("failed to save proposal files", zap.String("name", suggestion.Name), zap.Error(err))
+		}
+		job.Info("proposed %q with %d files", suggestion.Name, len(fileIDs))
+	}
+
+	h.logger.Info("category analysis completed",
+		zap.Int("sampled", len(files)),
+		zap.Int("proposals", len(suggestions)),
+	)
+
+	return map[string]any{
+		"sampled":   len(files),
+		"proposals": len(suggestions),
+	}, nil
+}
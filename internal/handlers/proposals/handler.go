@@ -0,0 +1,45 @@
+package proposals
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"stl-manager/internal/ai"
+	"stl-manager/internal/events"
+	"stl-manager/internal/jobs"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	pool       *pgxpool.Pool
+	classifier ai.Classifier
+	jobs       *jobs.Manager
+	events     *events.Broker
+	logger     *zap.Logger
+}
+
+func New(pool *pgxpool.Pool, classifier ai.Classifier, jobManager *jobs.Manager, broker *events.Broker, logger *zap.Logger) *Handler {
+	h := &Handler{
+		pool:       pool,
+		classifier: classifier,
+		jobs:       jobManager,
+		events:     broker,
+		logger:     logger,
+	}
+	h.registerJobs()
+	return h
+}
+
+func (h *Handler) RespondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if data != nil {
+		json.NewEncoder(w).Encode(data)
+	}
+}
+
+func (h *Handler) RespondError(w http.ResponseWriter, status int, message string) {
+	h.RespondJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,163 @@
+package proposals
+
+import (
+	"net/http"
+	"time"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/pagination"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// ProposalWithFiles is a proposal together with the sampled files the model put in it
+type ProposalWithFiles struct {
+	db.CategoryProposal
+	Files []db.File `json:"files"`
+}
+
+// sortByCreated tags cursors of the proposal list, newest first
+const sortByCreated = "created_at"
+
+var validStatuses = map[string]bool{
+	"pending":  true,
+	"accepted": true,
+	"rejected": true,
+}
+
+func (h *Handler) ListProposals(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	// Parse query parameters
+	query := r.URL.Query()
+	status := query.Get("status")
+	if status != "" && !validStatuses[status] {
+		h.RespondError(w, http.StatusBadRequest, "status must be pending, accepted or rejected")
+		return
+	}
+
+	// Parse pagination
+	p, err := pagination.Parse(query, pagination.DefaultPageSize)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	after, err := p.After(sortByCreated)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params := db.ListCategoryProposalsParams{
+		Status: status,
+		Limit:  int32(p.Limit()),
+		Offset: int32(p.Offset()),
+	}
+	if after != nil {
+		createdAt, err := time.Parse(time.RFC3339Nano, after.Key)
+		if err != nil {
+			h.RespondError(w, http.StatusBadRequest, pagination.ErrInvalidCursor.Error())
+			return
+		}
+		params.AfterID = after.ID
+		params.AfterCreatedAt = pgtype.Timestamptz{Time: createdAt, Valid: true}
+	}
+
+	proposals, err := queries.ListCategoryProposals(ctx, params)
+	if err != nil {
+		h.logger.Error("failed to list category proposals", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to list category proposals")
+		return
+	}
+
+	total, err := queries.CountCategoryProposals(ctx, status)
+	if err != nil {
+		h.logger.Error("failed to count category proposals", zap.Error(err))
+		total = 0
+	}
+
+	proposals, next := pagination.Trim(proposals, p, func(proposal db.CategoryProposal) pagination.Cursor {
+		return pagination.NewCursor(sortByCreated, proposal.CreatedAt.Time.Format(time.RFC3339Nano), proposal.ID)
+	})
+
+	// Attach files using batch query (1 query instead of N)
+	proposalIDs := make([]pgtype.UUID, len(proposals))
+	for i, proposal := range proposals {
+		proposalIDs[i] = proposal.ID
+	}
+
+	filesMap := make(map[pgtype.UUID][]db.File)
+	if len(proposalIDs) > 0 {
+		batchResults, err := queries.GetCategoryProposalFilesBatch(ctx, proposalIDs)
+		if err != nil {
+			h.logger.Error("failed to get proposal files batch", zap.Error(err))
+		} else {
+			for _, row := range batchResults {
+				filesMap[row.ProposalID] = append(filesMap[row.ProposalID], db.File{
+					ID:           row.ID,
+					Path:         row.Path,
+					FileName:     row.FileName,
+					Type:         row.Type,
+					Size:         row.Size,
+					ModifiedAt:   row.ModifiedAt,
+					Sha256:       row.Sha256,
+					FolderID:     row.FolderID,
+					CreatedAt:    row.CreatedAt,
+					UpdatedAt:    row.UpdatedAt,
+					ClassifiedAt: row.ClassifiedAt,
+					Favorite:     row.Favorite,
+					Rating:       row.Rating,
+					Note:         row.Note,
+				})
+			}
+		}
+	}
+
+	items := make([]ProposalWithFiles, len(proposals))
+	for i, proposal := range proposals {
+		files := filesMap[proposal.ID]
+		if files == nil {
+			files = []db.File{}
+		}
+		items[i] = ProposalWithFiles{
+			CategoryProposal: proposal,
+			Files:            files,
+		}
+	}
+
+	h.RespondJSON(w, http.StatusOK, p.Response(items, total, next))
+}
+
+func (h *Handler) GetProposal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	// Get ID from URL parameter
+	idStr := chi.URLParam(r, "id")
+	proposalID, err := uuid.Parse(idStr)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid proposal ID")
+		return
+	}
+
+	proposal, err := queries.GetCategoryProposal(ctx, pgtype.UUID{Bytes: proposalID, Valid: true})
+	if err != nil {
+		h.logger.Error("failed to get category proposal", zap.Error(err))
+		h.RespondError(w, http.StatusNotFound, "proposal not found")
+		return
+	}
+
+	files, err := queries.GetCategoryProposalFiles(ctx, proposal.ID)
+	if err != nil {
+		h.logger.Error("failed to get proposal files", zap.Error(err))
+		files = []db.File{}
+	}
+
+	h.RespondJSON(w, http.StatusOK, ProposalWithFiles{
+		CategoryProposal: proposal,
+		Files:            files,
+	})
+}
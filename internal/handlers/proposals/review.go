@@ -0,0 +1,174 @@
+package proposals
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/events"
+	"stl-manager/internal/handlers/categories"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+type AcceptRequest struct {
+	Reclassify bool `json:"reclassify"`
+}
+
+// AcceptProposal creates the proposed category and, when requested, moves the
+// proposal's files from "uncategorized" into it
+func (h *Handler) AcceptProposal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	// Get ID from URL parameter
+	idStr := chi.URLParam(r, "id")
+	proposalID, err := uuid.Parse(idStr)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid proposal ID")
+		return
+	}
+
+	var req AcceptRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	proposal, err := queries.GetCategoryProposal(ctx, pgtype.UUID{Bytes: proposalID, Valid: true})
+	if err != nil {
+		h.RespondError(w, http.StatusNotFound, "proposal not found")
+		return
+	}
+	if proposal.Status != "pending" {
+		h.RespondError(w, http.StatusConflict, "proposal already "+proposal.Status)
+		return
+	}
+
+	proposal, category, reclassified, err := h.accept(ctx, proposal, req.Reclassify)
+	if err != nil {
+		h.logger.Error("failed to accept proposal", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to accept proposal")
+		return
+	}
+
+	h.events.Publish(events.TopicLibrary, events.TypeCategoriesUpdated, map[string]any{
+		"category_id": category.ID,
+		"action":      "proposal_accepted",
+	})
+	if reclassified > 0 {
+		h.events.Publish(events.TopicLibrary, events.TypeFilesUpdated, map[string]any{"count": reclassified})
+	}
+
+	h.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"proposal":     proposal,
+		"category":     category,
+		"reclassified": reclassified,
+	})
+}
+
+// accept creates or reuses the proposed category, moves the proposal's files
+// into it when reclassify is set and marks the proposal accepted, all in one
+// transaction. It returns the number of files moved.
+func (h *Handler) accept(ctx context.Context, proposal db.CategoryProposal, reclassify bool) (db.CategoryProposal, db.Category, int, error) {
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		return db.CategoryProposal{}, db.Category{}, 0, err
+	}
+	defer tx.Rollback(ctx)
+	queries := db.New(h.pool).WithTx(tx)
+
+	// Reuse a category created (or merged under another name) since the
+	// analysis ran, otherwise create it
+	category, err := queries.ResolveCategoryName(ctx, proposal.Name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		category, err = categories.Create(ctx, queries, proposal.Name, pgtype.UUID{})
+	}
+	if err != nil {
+		return db.CategoryProposal{}, db.Category{}, 0, err
+	}
+
+	reclassified := 0
+	if reclassify {
+		files, err := queries.GetCategoryProposalFiles(ctx, proposal.ID)
+		if err != nil {
+			return db.CategoryProposal{}, db.Category{}, 0, err
+		}
+
+		uncategorized, err := queries.GetCategoryByName(ctx, "uncategorized")
+		hasUncategorized := err == nil
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return db.CategoryProposal{}, db.Category{}, 0, err
+		}
+		for _, file := range files {
+			if err := queries.AddFileCategory(ctx, db.AddFileCategoryParams{
+				FileID:     file.ID,
+				CategoryID: category.ID,
+			}); err != nil {
+				return db.CategoryProposal{}, db.Category{}, 0, err
+			}
+			if hasUncategorized {
+				if err := queries.RemoveFileCategory(ctx, db.RemoveFileCategoryParams{
+					FileID:     file.ID,
+					CategoryID: uncategorized.ID,
+				}); err != nil {
+					return db.CategoryProposal{}, db.Category{}, 0, err
+				}
+			}
+			reclassified++
+		}
+	}
+
+	proposal, err = queries.UpdateCategoryProposalStatus(ctx, db.UpdateCategoryProposalStatusParams{
+		ID:         proposal.ID,
+		Status:     "accepted",
+		CategoryID: category.ID,
+	})
+	if err != nil {
+		return db.CategoryProposal{}, db.Category{}, 0, err
+	}
+	return proposal, category, reclassified, tx.Commit(ctx)
+}
+
+func (h *Handler) RejectProposal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	// Get ID from URL parameter
+	idStr := chi.URLParam(r, "id")
+	proposalID, err := uuid.Parse(idStr)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid proposal ID")
+		return
+	}
+
+	proposal, err := queries.GetCategoryProposal(ctx, pgtype.UUID{Bytes: proposalID, Valid: true})
+	if err != nil {
+		h.RespondError(w, http.StatusNotFound, "proposal not found")
+		return
+	}
+	if proposal.Status != "pending" {
+		h.RespondError(w, http.StatusConflict, "proposal already "+proposal.Status)
+		return
+	}
+
+	proposal, err = queries.UpdateCategoryProposalStatus(ctx, db.UpdateCategoryProposalStatusParams{
+		ID:     proposal.ID,
+		Status: "rejected",
+	})
+	if err != nil {
+		h.logger.Error("failed to reject proposal", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to reject proposal")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusOK, proposal)
+}
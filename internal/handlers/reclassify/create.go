@@ -0,0 +1,133 @@
+package reclassify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/jobs"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// ReclassifyRequest selects the files to reclassify. Filters are combined with AND;
+// Queued selects the files waiting in the classification queue.
+type ReclassifyRequest struct {
+	Category         string     `json:"category,omitempty"`
+	FolderID         string     `json:"folder_id,omitempty"`
+	Type             string     `json:"type,omitempty"`
+	ClassifiedBefore *time.Time `json:"classified_before,omitempty"`
+	FileIDs          []string   `json:"file_ids,omitempty"`
+	Queued           bool       `json:"queued,omitempty"`
+	DryRun           bool       `json:"dry_run"`
+}
+
+type CreateReclassifyResponse struct {
+	RunID  string `json:"run_id"`
+	JobID  string `json:"job_id"`
+	DryRun bool   `json:"dry_run"`
+}
+
+// CreateReclassify starts a background reclassification job for every file matching the filter.
+// Files with manually assigned categories are skipped.
+func (h *Handler) CreateReclassify(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	var req ReclassifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Category == "" && req.FolderID == "" && req.Type == "" && req.ClassifiedBefore == nil && len(req.FileIDs) == 0 && !req.Queued {
+		h.RespondError(w, http.StatusBadRequest, "at least one filter is required (category, folder_id, type, classified_before, file_ids, queued)")
+		return
+	}
+	for _, id := range req.FileIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			h.RespondError(w, http.StatusBadRequest, "Invalid file ID: "+id)
+			return
+		}
+	}
+
+	if req.FolderID != "" {
+		folderID, err := uuid.Parse(req.FolderID)
+		if err != nil {
+			h.RespondError(w, http.StatusBadRequest, "Invalid folder ID")
+			return
+		}
+		if _, err := queries.GetFolder(ctx, pgtype.UUID{Bytes: folderID, Valid: true}); err != nil {
+			h.RespondError(w, http.StatusNotFound, "folder not found")
+			return
+		}
+	}
+
+	if !h.classifier.IsEnabled() {
+		h.RespondError(w, http.StatusServiceUnavailable, "OpenAI classification is not enabled")
+		return
+	}
+
+	run, job, err := h.Start(ctx, req)
+	if errors.Is(err, jobs.ErrAlreadyRunning) {
+		h.RespondError(w, http.StatusConflict, "a reclassification is already running")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to start reclassification", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to start reclassification")
+		return
+	}
+
+	runID := uuid.UUID(run.ID.Bytes)
+	jobUUID := uuid.UUID(job.ID.Bytes)
+	h.logger.Info("reclassification started",
+		zap.String("run_id", runID.String()),
+		zap.String("job_id", jobUUID.String()),
+		zap.Bool("dry_run", req.DryRun))
+
+	h.RespondJSON(w, http.StatusAccepted, CreateReclassifyResponse{
+		RunID:  runID.String(),
+		JobID:  jobUUID.String(),
+		DryRun: req.DryRun,
+	})
+}
+
+// Start records a reclassify run for the filter and starts its background job.
+// It returns jobs.ErrAlreadyRunning while another reclassification runs.
+func (h *Handler) Start(ctx context.Context, req ReclassifyRequest) (db.ReclassifyRun, db.Job, error) {
+	queries := db.New(h.pool)
+
+	filter, _ := json.Marshal(req)
+	run, err := queries.CreateReclassifyRun(ctx, db.CreateReclassifyRunParams{
+		DryRun: req.DryRun,
+		Filter: filter,
+	})
+	if err != nil {
+		return db.ReclassifyRun{}, db.Job{}, err
+	}
+
+	job, err := h.jobs.Enqueue(ctx, JobType, ReclassifyPayload{
+		RunID:   uuid.UUID(run.ID.Bytes),
+		Request: req,
+	})
+	if err != nil {
+		_ = queries.DeleteReclassifyRun(ctx, run.ID)
+		return db.ReclassifyRun{}, db.Job{}, err
+	}
+
+	if err := queries.SetReclassifyRunJob(ctx, db.SetReclassifyRunJobParams{ID: run.ID, JobID: job.ID}); err != nil {
+		h.logger.Error("failed to link reclassify run to job", zap.Error(err))
+	}
+	return run, job, nil
+}
+
+// StartFiles reclassifies the given files, as POST /v1/files/bulk does
+func (h *Handler) StartFiles(ctx context.Context, fileIDs []string) (db.ReclassifyRun, db.Job, error) {
+	return h.Start(ctx, ReclassifyRequest{FileIDs: fileIDs})
+}
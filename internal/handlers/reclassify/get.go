@@ -0,0 +1,110 @@
+package reclassify
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/pagination"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// RunResponse exposes the JSON columns of a run as JSON instead of base64
+type RunResponse struct {
+	db.ReclassifyRun
+	Filter json.RawMessage `json:"filter"`
+	Report json.RawMessage `json:"report,omitempty"`
+}
+
+func (h *Handler) GetReclassify(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	// Get ID from URL parameter
+	idStr := chi.URLParam(r, "id")
+	runID, err := uuid.Parse(idStr)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid run ID")
+		return
+	}
+
+	run, err := queries.GetReclassifyRun(ctx, pgtype.UUID{Bytes: runID, Valid: true})
+	if err != nil {
+		h.logger.Error("failed to get reclassify run", zap.Error(err))
+		h.RespondError(w, http.StatusNotFound, "reclassify run not found")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusOK, RunResponse{
+		ReclassifyRun: run,
+		Filter:        run.Filter,
+		Report:        run.Report,
+	})
+}
+
+// sortByCreated tags cursors of the run list, newest first
+const sortByCreated = "created_at"
+
+// ListReclassify lists runs without their reports
+func (h *Handler) ListReclassify(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	// Parse pagination
+	query := r.URL.Query()
+	p, err := pagination.Parse(query, pagination.DefaultPageSize)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	after, err := p.After(sortByCreated)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params := db.ListReclassifyRunsParams{
+		Limit:  int32(p.Limit()),
+		Offset: int32(p.Offset()),
+	}
+	if after != nil {
+		createdAt, err := time.Parse(time.RFC3339Nano, after.Key)
+		if err != nil {
+			h.RespondError(w, http.StatusBadRequest, pagination.ErrInvalidCursor.Error())
+			return
+		}
+		params.AfterID = after.ID
+		params.AfterCreatedAt = pgtype.Timestamptz{Time: createdAt, Valid: true}
+	}
+
+	runs, err := queries.ListReclassifyRuns(ctx, params)
+	if err != nil {
+		h.logger.Error("failed to list reclassify runs", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to list reclassify runs")
+		return
+	}
+
+	total, err := queries.CountReclassifyRuns(ctx)
+	if err != nil {
+		h.logger.Error("failed to count reclassify runs", zap.Error(err))
+		total = 0
+	}
+
+	runs, next := pagination.Trim(runs, p, func(run db.ReclassifyRun) pagination.Cursor {
+		return pagination.NewCursor(sortByCreated, run.CreatedAt.Time.Format(time.RFC3339Nano), run.ID)
+	})
+
+	items := make([]RunResponse, len(runs))
+	for i, run := range runs {
+		items[i] = RunResponse{
+			ReclassifyRun: run,
+			Filter:        run.Filter,
+		}
+	}
+
+	h.RespondJSON(w, http.StatusOK, p.Response(items, total, next))
+}
@@ -0,0 +1,53 @@
+package reclassify
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"stl-manager/internal/ai"
+	"stl-manager/internal/config"
+	"stl-manager/internal/events"
+	"stl-manager/internal/jobs"
+	"stl-manager/internal/worker"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Handler runs bulk reclassification of files in the background
+type Handler struct {
+	pool       *pgxpool.Pool
+	classifier ai.Classifier
+	workers    *worker.Pool
+	jobs       *jobs.Manager
+	events     *events.Broker
+	config     *config.Config
+	logger     *zap.Logger
+}
+
+// New creates a new reclassify Handler
+func New(pool *pgxpool.Pool, classifier ai.Classifier, workers *worker.Pool, jobManager *jobs.Manager, broker *events.Broker, cfg *config.Config, logger *zap.Logger) *Handler {
+	h := &Handler{
+		pool:       pool,
+		classifier: classifier,
+		workers:    workers,
+		jobs:       jobManager,
+		events:     broker,
+		config:     cfg,
+		logger:     logger,
+	}
+	h.registerJobs()
+	return h
+}
+
+func (h *Handler) RespondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if data != nil {
+		json.NewEncoder(w).Encode(data)
+	}
+}
+
+func (h *Handler) RespondError(w http.ResponseWriter, status int, message string) {
+	h.RespondJSON(w, status, map[string]string{"error": message})
+}
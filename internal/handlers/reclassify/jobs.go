@@ -0,0 +1,66 @@
+package reclassify
+
+import (
+	"context"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/jobs"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// JobType is the job type used for bulk reclassification
+const JobType = "reclassify"
+
+// ReclassifyPayload is stored on the job
+type ReclassifyPayload struct {
+	RunID   uuid.UUID         `json:"run_id"`
+	Request ReclassifyRequest `json:"request"`
+}
+
+// registerJobs registers bulk reclassification with the job manager. Runs are
+// exclusive and not resumed after a restart because every file would be sent
+// to the AI again.
+func (h *Handler) registerJobs() {
+	h.jobs.Register(JobType, jobs.Definition{
+		Run: func(ctx context.Context, job *jobs.Job) (any, error) {
+			var payload ReclassifyPayload
+			if err := job.Decode(&payload); err != nil {
+				return nil, err
+			}
+			return h.runReclassify(ctx, job, pgtype.UUID{Bytes: payload.RunID, Valid: true}, payload.Request)
+		},
+		Exclusive: true,
+		Cleanup: func(ctx context.Context) error {
+			failed, err := db.New(h.pool).FailInterruptedReclassifyRuns(ctx)
+			if failed > 0 {
+				h.logger.Warn("marked interrupted reclassify runs as failed", zap.Int64("count", failed))
+			}
+			return err
+		},
+	})
+}
+
+// candidateParams converts the request filter into query parameters
+func candidateParams(req ReclassifyRequest) db.ListReclassifyCandidatesParams {
+	params := db.ListReclassifyCandidatesParams{
+		Category: req.Category,
+		Type:     req.Type,
+		Queued:   req.Queued,
+	}
+	if folderID, err := uuid.Parse(req.FolderID); err == nil {
+		params.FolderID = pgtype.UUID{Bytes: folderID, Valid: true}
+	}
+	if req.ClassifiedBefore != nil {
+		params.ClassifiedBefore = pgtype.Timestamptz{Time: *req.ClassifiedBefore, Valid: true}
+	}
+	params.FileIds = []pgtype.UUID{}
+	for _, id := range req.FileIDs {
+		if fileID, err := uuid.Parse(id); err == nil {
+			params.FileIds = append(params.FileIds, pgtype.UUID{Bytes: fileID, Valid: true})
+		}
+	}
+	return params
+}
@@ -0,0 +1,89 @@
+package reclassify
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"stl-manager/internal/ai"
+	"stl-manager/internal/db"
+	"stl-manager/internal/handlers/categories"
+	"stl-manager/internal/jobs"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// queueCheckInterval is how often the classification queue is checked
+const queueCheckInterval = 15 * time.Minute
+
+// drainMinFiles is how many files today's budget must still cover before the
+// queue is drained, so runs do not start only to queue every file again
+const drainMinFiles = 10
+
+// DrainQueue classifies the files scans and reclassifications queued when
+// they ran out of AI budget, until ctx is cancelled. Whenever the queue has
+// files and limiter has budget left, a reclassification with queued: true
+// starts on them; files that do not fit are queued again for the next check.
+func (h *Handler) DrainQueue(ctx context.Context, limiter *ai.Limiter) {
+	ticker := time.NewTicker(queueCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		h.drainQueue(ctx, limiter)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drainQueue starts one reclassification of the queued files if there are any
+// and the budget allows it
+func (h *Handler) drainQueue(ctx context.Context, limiter *ai.Limiter) {
+	if !h.classifier.IsEnabled() {
+		return
+	}
+	queries := db.New(h.pool)
+
+	// Manually categorized files are never reclassified, so they would stay
+	// queued forever
+	if dropped, err := queries.DequeueManualClassifications(ctx); err != nil {
+		h.logger.Warn("failed to drop manually categorized files from classification queue", zap.Error(err))
+	} else if dropped > 0 {
+		h.logger.Info("dropped manually categorized files from classification queue", zap.Int64("count", dropped))
+	}
+
+	queued, err := queries.CountClassificationQueue(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			h.logger.Error("failed to count classification queue", zap.Error(err))
+		}
+		return
+	}
+	if queued == 0 {
+		return
+	}
+
+	catalog, err := categories.LoadCatalog(ctx, queries)
+	if err != nil {
+		h.logger.Error("failed to list categories for classification", zap.Error(err))
+		return
+	}
+	if !limiter.CanSpend(drainMinFiles * limiter.EstimateCost("", catalog.Paths)) {
+		return
+	}
+
+	run, _, err := h.Start(ctx, ReclassifyRequest{Queued: true})
+	if errors.Is(err, jobs.ErrAlreadyRunning) {
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to start classification of queued files", zap.Error(err))
+		return
+	}
+	h.logger.Info("classifying queued files",
+		zap.String("run_id", uuid.UUID(run.ID.Bytes).String()),
+		zap.Int64("queued", queued))
+}
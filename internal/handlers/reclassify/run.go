@@ -0,0 +1,244 @@
+package reclassify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+
+	"stl-manager/internal/ai"
+	"stl-manager/internal/db"
+	"stl-manager/internal/events"
+	"stl-manager/internal/handlers/categories"
+	"stl-manager/internal/jobs"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// maxReportEntries caps how many per-file results are stored on a run
+const maxReportEntries = 5000
+
+// ReportEntry is the before and after categories of one file
+type ReportEntry struct {
+	FileID  string   `json:"file_id"`
+	Path    string   `json:"path"`
+	Before  []string `json:"before"`
+	After   []string `json:"after"`
+	Changed bool     `json:"changed"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// runReclassify classifies every matching file on the shared worker pool.
+// In dry-run mode categories are computed but nothing is written.
+func (h *Handler) runReclassify(ctx context.Context, job *jobs.Job, runID pgtype.UUID, req ReclassifyRequest) (any, error) {
+	runIDStr := uuid.UUID(runID.Bytes).String()
+	queries := db.New(h.pool)
+	dryRun := req.DryRun
+
+	// Status writes must succeed even after the job was cancelled
+	writeCtx := context.WithoutCancel(ctx)
+
+	// Reclassification shares the per-scan AI spend cap
+	budget := ai.NewBudget(h.config.AIScanBudgetUSD)
+	ctx = ai.WithBudget(ctx, budget)
+
+	finish := func(status, errorMsg string, report []ReportEntry) {
+		reportJSON, _ := json.Marshal(report)
+		_, err := queries.FinishReclassifyRun(writeCtx, db.FinishReclassifyRunParams{
+			ID:     runID,
+			Status: status,
+			Error:  pgtype.Text{String: errorMsg, Valid: errorMsg != ""},
+			Report: reportJSON,
+		})
+		if err != nil {
+			h.logger.Error("failed to finish reclassify run", zap.Error(err))
+		}
+		h.events.Publish(events.TopicLibrary, events.TypeReclassifyFinished, map[string]any{
+			"run_id":  runIDStr,
+			"status":  status,
+			"dry_run": req.DryRun,
+		})
+	}
+
+	files, err := queries.ListReclassifyCandidates(ctx, candidateParams(req))
+	if err != nil {
+		h.logger.Error("failed to list files to reclassify", zap.Error(err))
+		finish("failed", err.Error(), []ReportEntry{})
+		return nil, err
+	}
+	job.Info("%d files match the filter", len(files))
+
+	catalog, err := categories.LoadCatalog(ctx, queries)
+	if err != nil {
+		h.logger.Error("failed to list categories for classification", zap.Error(err))
+		finish("failed", err.Error(), []ReportEntry{})
+		return nil, err
+	}
+
+	categoryMap := make(map[string]pgtype.UUID)
+	for _, cat := range catalog.Categories {
+		categoryMap[cat.Name] = cat.ID
+	}
+
+	var (
+		mu      sync.Mutex
+		report  = []ReportEntry{}
+		changed int
+		queued  int
+	)
+
+	updateProgress := func(processed int) {
+		progress := 100
+		if len(files) > 0 {
+			progress = int(float64(processed) / float64(len(files)) * 100)
+		}
+		mu.Lock()
+		params := db.UpdateReclassifyRunProgressParams{
+			ID:        runID,
+			Status:    "running",
+			Total:     int32(len(files)),
+			Processed: int32(processed),
+			Changed:   int32(changed),
+			Queued:    int32(queued),
+			Progress:  int32(progress),
+		}
+		mu.Unlock()
+		if err := queries.UpdateReclassifyRunProgress(writeCtx, params); err != nil {
+			h.logger.Error("failed to update reclassify progress", zap.Error(err))
+		}
+		job.SetProgress(progress)
+	}
+	updateProgress(0)
+
+	addEntry := func(entry ReportEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		if entry.Changed {
+			changed++
+		}
+		if len(report) < maxReportEntries {
+			report = append(report, entry)
+		}
+	}
+
+	runErr := h.workers.Run(ctx, len(files), func(i int) {
+		file := files[i]
+		entry := ReportEntry{
+			FileID: uuid.UUID(file.ID.Bytes).String(),
+			Path:   file.Path,
+			Before: []string{},
+		}
+
+		current, err := queries.GetFileCategories(ctx, file.ID)
+		if err != nil {
+			h.logger.Warn("failed to get file categories", zap.String("file", file.FileName), zap.Error(err))
+		}
+		for _, cat := range current {
+			entry.Before = append(entry.Before, cat.Name)
+		}
+		sort.Strings(entry.Before)
+
+		classified, err := h.classifier.Classify(ctx, file.FileName, catalog.Paths)
+		if errors.Is(err, ai.ErrBudgetExceeded) {
+			if !dryRun {
+				if err := queries.EnqueueClassification(ctx, db.EnqueueClassificationParams{
+					FileID: file.ID,
+					Reason: "budget_exceeded",
+				}); err != nil {
+					h.logger.Error("failed to queue file for classification",
+						zap.String("file", file.FileName),
+						zap.Error(err))
+				}
+			}
+			mu.Lock()
+			queued++
+			mu.Unlock()
+			return
+		}
+		if err != nil {
+			h.logger.Warn("classification failed", zap.String("file", file.FileName), zap.Error(err))
+			entry.After = entry.Before
+			entry.Error = err.Error()
+			addEntry(entry)
+			return
+		}
+
+		entry.After = []string{}
+		for _, path := range classified {
+			if cat, ok := catalog.Lookup(path); ok {
+				entry.After = append(entry.After, cat.Name)
+			}
+		}
+		if len(entry.After) == 0 {
+			entry.After = []string{"uncategorized"}
+		}
+		sort.Strings(entry.After)
+		entry.Changed = !sameCategories(entry.Before, entry.After)
+
+		if !dryRun {
+			_ = queries.DequeueClassification(ctx, file.ID)
+			_ = queries.MarkFileClassified(ctx, file.ID)
+			if entry.Changed {
+				_ = queries.RemoveAIFileCategories(ctx, file.ID)
+				for _, name := range entry.After {
+					if err := queries.AddFileCategory(ctx, db.AddFileCategoryParams{
+						FileID:     file.ID,
+						CategoryID: categoryMap[name],
+					}); err != nil {
+						h.logger.Error("failed to add category",
+							zap.String("file", file.FileName),
+							zap.String("category", name),
+							zap.Error(err))
+					}
+				}
+			}
+		}
+
+		addEntry(entry)
+	}, func(done int) {
+		if done%50 == 0 || done == len(files) {
+			updateProgress(done)
+		}
+	})
+
+	if runErr != nil {
+		finish("cancelled", "cancelled", report)
+		job.Warn("cancelled, %d files changed before stopping", changed)
+		return nil, runErr
+	}
+
+	finish("completed", "", report)
+	h.logger.Info("reclassification completed",
+		zap.String("run_id", runIDStr),
+		zap.Bool("dry_run", dryRun),
+		zap.Int("files", len(files)),
+		zap.Int("changed", changed),
+		zap.Int("queued", queued),
+		zap.Float64("ai_spend_usd", budget.Spent()))
+	job.Info("%d of %d files changed, %d queued for classification", changed, len(files), queued)
+
+	return map[string]any{
+		"run_id":       runIDStr,
+		"dry_run":      dryRun,
+		"files":        len(files),
+		"changed":      changed,
+		"queued":       queued,
+		"ai_spend_usd": budget.Spent(),
+	}, nil
+}
+
+// sameCategories reports whether two sorted category lists are equal
+func sameCategories(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
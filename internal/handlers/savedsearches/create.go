@@ -0,0 +1,107 @@
+package savedsearches
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/search"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// SavedSearchRequest is the body of create and update requests. Filters are
+// GET /v1/files query parameters, e.g. {"uncategorized": "true", "type": "stl"}.
+type SavedSearchRequest struct {
+	Name    string            `json:"name"`
+	Filters map[string]string `json:"filters"`
+	Pinned  bool              `json:"pinned"`
+}
+
+// SavedSearchResponse exposes the stored filters as JSON
+type SavedSearchResponse struct {
+	db.SavedSearch
+	Filters json.RawMessage `json:"filters"`
+}
+
+func newSavedSearchResponse(s db.SavedSearch) SavedSearchResponse {
+	return SavedSearchResponse{SavedSearch: s, Filters: json.RawMessage(s.Filters)}
+}
+
+// validated holds the normalized values of a SavedSearchRequest
+type validated struct {
+	name    string
+	filters []byte
+	pinned  bool
+}
+
+// validate checks the request and that its filters parse the way
+// GET /v1/files would. The returned message is empty when the request is valid.
+func (req SavedSearchRequest) validate() (validated, string) {
+	v := validated{
+		name:   strings.TrimSpace(req.Name),
+		pinned: req.Pinned,
+	}
+	if v.name == "" {
+		return v, "name is required"
+	}
+
+	values, err := search.Values(req.Filters)
+	if err != nil {
+		return v, "invalid filters: " + err.Error()
+	}
+	if _, _, err := search.Parse(values); err != nil {
+		return v, "invalid filters: " + err.Error()
+	}
+
+	// Store only the non-empty filters, trimmed
+	filters := make(map[string]string, len(values))
+	for key := range values {
+		filters[key] = values.Get(key)
+	}
+	v.filters, _ = json.Marshal(filters)
+	return v, ""
+}
+
+// isDuplicateName reports whether err is a conflict on the unique name
+func isDuplicateName(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func (h *Handler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	var req SavedSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	v, msg := req.validate()
+	if msg != "" {
+		h.RespondError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	created, err := queries.CreateSavedSearch(ctx, db.CreateSavedSearchParams{
+		Name:    v.name,
+		Filters: v.filters,
+		Pinned:  v.pinned,
+	})
+	if isDuplicateName(err) {
+		h.RespondError(w, http.StatusConflict, "a saved search with this name already exists")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to create saved search", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to create saved search")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusCreated, newSavedSearchResponse(created))
+}
@@ -0,0 +1,37 @@
+package savedsearches
+
+import (
+	"net/http"
+
+	"stl-manager/internal/db"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// DeleteSavedSearch removes a saved search. Matching files are not touched.
+func (h *Handler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	searchID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid saved search ID")
+		return
+	}
+
+	deleted, err := queries.DeleteSavedSearch(ctx, pgtype.UUID{Bytes: searchID, Valid: true})
+	if err != nil {
+		h.logger.Error("failed to delete saved search", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to delete saved search")
+		return
+	}
+	if deleted == 0 {
+		h.RespondError(w, http.StatusNotFound, "saved search not found")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusOK, map[string]string{"message": "saved search deleted successfully"})
+}
@@ -0,0 +1,47 @@
+package savedsearches
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"stl-manager/internal/search"
+
+	"go.uber.org/zap"
+)
+
+// requestKeys are the parameters of a run request that apply on top of the
+// saved filters: paging, facets and a different ordering
+var requestKeys = []string{"page", "page_size", "cursor", "facets", "sort", "order"}
+
+// ListSavedSearchFiles runs a saved search. The response is the one
+// GET /v1/files gives for the saved filters.
+func (h *Handler) ListSavedSearchFiles(w http.ResponseWriter, r *http.Request) {
+	saved, ok := h.loadSavedSearch(w, r)
+	if !ok {
+		return
+	}
+
+	var filters map[string]string
+	if err := json.Unmarshal(saved.Filters, &filters); err != nil {
+		h.logger.Error("failed to decode saved search filters", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to run saved search")
+		return
+	}
+	values, err := search.Values(filters)
+	if err != nil {
+		h.logger.Error("saved search has invalid filters", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to run saved search")
+		return
+	}
+
+	query := r.URL.Query()
+	for _, key := range requestKeys {
+		if v := query.Get(key); v != "" {
+			values.Set(key, v)
+		}
+	}
+
+	run := r.Clone(r.Context())
+	run.URL.RawQuery = values.Encode()
+	h.listFiles(w, run)
+}
@@ -0,0 +1,84 @@
+package savedsearches
+
+import (
+	"errors"
+	"net/http"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/pagination"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// ListSavedSearches lists pinned searches first, then by name
+func (h *Handler) ListSavedSearches(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	p, err := pagination.Parse(r.URL.Query(), pagination.DefaultPageSize)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if p.Cursor != nil {
+		h.RespondError(w, http.StatusBadRequest, "cursor is not supported; use page")
+		return
+	}
+
+	searches, err := queries.ListSavedSearches(ctx, db.ListSavedSearchesParams{
+		Limit:  int32(p.PageSize),
+		Offset: int32(p.Offset()),
+	})
+	if err != nil {
+		h.logger.Error("failed to list saved searches", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to list saved searches")
+		return
+	}
+
+	total, err := queries.CountSavedSearches(ctx)
+	if err != nil {
+		h.logger.Error("failed to count saved searches", zap.Error(err))
+		total = 0
+	}
+
+	items := make([]SavedSearchResponse, len(searches))
+	for i, s := range searches {
+		items[i] = newSavedSearchResponse(s)
+	}
+
+	h.RespondJSON(w, http.StatusOK, p.Response(items, total, ""))
+}
+
+func (h *Handler) GetSavedSearch(w http.ResponseWriter, r *http.Request) {
+	saved, ok := h.loadSavedSearch(w, r)
+	if !ok {
+		return
+	}
+	h.RespondJSON(w, http.StatusOK, newSavedSearchResponse(saved))
+}
+
+// loadSavedSearch reads the saved search named by the id URL parameter. On
+// failure it has already written the error response.
+func (h *Handler) loadSavedSearch(w http.ResponseWriter, r *http.Request) (db.SavedSearch, bool) {
+	searchID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid saved search ID")
+		return db.SavedSearch{}, false
+	}
+
+	saved, err := db.New(h.pool).GetSavedSearch(r.Context(), pgtype.UUID{Bytes: searchID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.RespondError(w, http.StatusNotFound, "saved search not found")
+		return db.SavedSearch{}, false
+	}
+	if err != nil {
+		h.logger.Error("failed to get saved search", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to get saved search")
+		return db.SavedSearch{}, false
+	}
+	return saved, true
+}
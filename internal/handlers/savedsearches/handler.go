@@ -0,0 +1,32 @@
+package savedsearches
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	pool *pgxpool.Pool
+	// listFiles serves GET /v1/files, which runs the saved filters
+	listFiles http.HandlerFunc
+	logger    *zap.Logger
+}
+
+func New(pool *pgxpool.Pool, listFiles http.HandlerFunc, logger *zap.Logger) *Handler {
+	return &Handler{pool: pool, listFiles: listFiles, logger: logger}
+}
+
+func (h *Handler) RespondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if data != nil {
+		json.NewEncoder(w).Encode(data)
+	}
+}
+
+func (h *Handler) RespondError(w http.ResponseWriter, status int, message string) {
+	h.RespondJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,61 @@
+package savedsearches
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"stl-manager/internal/db"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// UpdateSavedSearch replaces the name, filters and pinned flag of a saved search
+func (h *Handler) UpdateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	searchID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid saved search ID")
+		return
+	}
+
+	var req SavedSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	v, msg := req.validate()
+	if msg != "" {
+		h.RespondError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	updated, err := queries.UpdateSavedSearch(ctx, db.UpdateSavedSearchParams{
+		ID:      pgtype.UUID{Bytes: searchID, Valid: true},
+		Name:    v.name,
+		Filters: v.filters,
+		Pinned:  v.pinned,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.RespondError(w, http.StatusNotFound, "saved search not found")
+		return
+	}
+	if isDuplicateName(err) {
+		h.RespondError(w, http.StatusConflict, "a saved search with this name already exists")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to update saved search", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to update saved search")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusOK, newSavedSearchResponse(updated))
+}
@@ -0,0 +1,166 @@
+package scans
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/scanner"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// seeFolder records that the walk went through folderPath, so its sidecar
+// files and images are refreshed once the files are saved. Called from the diff stage,
+// which has a single worker, before incremental scans drop unchanged files.
+func (r *scanRun) seeFolder(folderPath string) {
+	if folderPath == "" || r.preview != nil {
+		return
+	}
+	r.seen[filepath.Clean(folderPath)] = struct{}{}
+}
+
+// refreshFolders reads the sidecar files and images of the folders the walk
+// went through and of their ancestors. It runs after the save stage, so save
+// workers never wait on it, and skips folders whose contents did not change
+// since the last scan read them.
+func (r *scanRun) refreshFolders(ctx context.Context, workers int) {
+	paths := make([]string, 0, len(r.seen))
+	added := make(map[string]bool, len(r.seen))
+	for dir := range r.seen {
+		for path, ok := dir, true; ok && !added[path]; path, ok = r.h.parentFolderPath(path) {
+			added[path] = true
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return
+	}
+
+	folders, err := r.queries.GetFoldersByPaths(ctx, paths)
+	if err != nil {
+		r.h.logger.Error("failed to load scanned folders", zap.Error(err))
+		r.job.Error("reading folder contents failed: %v", err)
+		return
+	}
+
+	in := make(chan db.Folder)
+	go func() {
+		defer close(in)
+		for _, folder := range folders {
+			select {
+			case in <- folder:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	refreshed := stage(ctx, workers, 0, in, func(folder db.Folder, send func(struct{}) bool) {
+		if r.refreshFolder(ctx, folder) {
+			send(struct{}{})
+		}
+	})
+
+	count := 0
+	for range refreshed {
+		count++
+	}
+	r.job.Info("read sidecars and images of %d of %d folders", count, len(folders))
+}
+
+// refreshFolder reads the sidecars and images of a folder unless its
+// contents are as they were when last read, and reports whether it did.
+// Failures leave the folder to be read again by the next scan.
+func (r *scanRun) refreshFolder(ctx context.Context, folder db.Folder) bool {
+	modTime, err := r.h.scanner.ContentsModTime(folder.Path)
+	if err != nil {
+		r.h.logger.Warn("failed to stat folder", zap.String("path", folder.Path), zap.Error(err))
+		return false
+	}
+	// The database keeps microseconds
+	modTime = modTime.Truncate(time.Microsecond)
+	if folder.ContentsModifiedAt.Valid && folder.ContentsModifiedAt.Time.Equal(modTime) {
+		return false
+	}
+
+	sourceRead := r.readSource(ctx, folder.ID, folder.Path)
+	imagesRead := r.readImages(ctx, folder.ID, folder.Path)
+	if !sourceRead || !imagesRead {
+		return false
+	}
+	if err := r.queries.SetFolderContentsModifiedAt(ctx, db.SetFolderContentsModifiedAtParams{
+		ID:                 folder.ID,
+		ContentsModifiedAt: pgtype.Timestamptz{Time: modTime, Valid: true},
+	}); err != nil {
+		r.h.logger.Warn("failed to save folder contents time", zap.String("path", folder.Path), zap.Error(err))
+	}
+	return true
+}
+
+// readSource stores what the sidecar files of a folder say about its models,
+// clearing values whose sidecars are gone. Failures are only logged.
+func (r *scanRun) readSource(ctx context.Context, folderID pgtype.UUID, folderPath string) bool {
+	src, err := r.h.scanner.ReadSource(folderPath)
+	if err != nil {
+		r.h.logger.Warn("failed to read folder sidecars", zap.String("path", folderPath), zap.Error(err))
+		return false
+	}
+	if src.Files == nil {
+		src.Files = []string{}
+	}
+
+	if err := r.queries.UpdateFolderSource(ctx, db.UpdateFolderSourceParams{
+		ID:                folderID,
+		SourceDesigner:    src.Designer,
+		SourceUrl:         src.URL,
+		SourceLicense:     src.License,
+		SourceDescription: src.Description,
+		SourceFiles:       src.Files,
+	}); err != nil {
+		r.h.logger.Warn("failed to save folder source", zap.String("path", folderPath), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// readImages stores the gallery of a folder and picks its cover, removing
+// images that are gone. Failures are only logged.
+func (r *scanRun) readImages(ctx context.Context, folderID pgtype.UUID, folderPath string) bool {
+	images, err := r.h.scanner.ListImages(folderPath)
+	if err != nil {
+		r.h.logger.Warn("failed to list folder images", zap.String("path", folderPath), zap.Error(err))
+		return false
+	}
+
+	params := db.UpsertFolderImagesParams{
+		FolderID:    folderID,
+		Names:       make([]string, len(images)),
+		Sizes:       make([]int64, len(images)),
+		ModifiedAts: make([]pgtype.Timestamptz, len(images)),
+	}
+	for i, image := range images {
+		params.Names[i] = image.Name
+		params.Sizes[i] = image.Size
+		params.ModifiedAts[i] = pgtype.Timestamptz{Time: image.ModifiedAt, Valid: true}
+	}
+
+	if err := r.queries.UpsertFolderImages(ctx, params); err != nil {
+		r.h.logger.Warn("failed to save folder images", zap.String("path", folderPath), zap.Error(err))
+		return false
+	}
+	if _, err := r.queries.DeleteMissingFolderImages(ctx, db.DeleteMissingFolderImagesParams{
+		FolderID: folderID,
+		Names:    params.Names,
+	}); err != nil {
+		r.h.logger.Warn("failed to remove folder images", zap.String("path", folderPath), zap.Error(err))
+	}
+	if err := r.queries.SetFolderPrimaryImage(ctx, db.SetFolderPrimaryImageParams{
+		ID:   folderID,
+		Name: scanner.PrimaryImage(filepath.Base(folderPath), images),
+	}); err != nil {
+		r.h.logger.Warn("failed to set folder cover", zap.String("path", folderPath), zap.Error(err))
+	}
+	return true
+}
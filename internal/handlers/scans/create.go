@@ -2,9 +2,14 @@ package scans
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 
 	"stl-manager/internal/db"
+	"stl-manager/internal/jobs"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -12,33 +17,109 @@ import (
 )
 
 type CreateScanResponse struct {
-	ScanID string `json:"scan_id"`
+	ScanID  string  `json:"scan_id"`
+	JobID   string  `json:"job_id"`
+	Options Options `json:"options"`
 }
 
+// CreateScan starts a scan. The body is optional and holds the scan Options;
+// without it the whole scan root is scanned.
 func (h *Handler) CreateScan(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+
+	var opts Options
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil && !errors.Is(err, io.EOF) {
+		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	scan, job, err := h.StartScan(ctx, opts, pgtype.UUID{})
+	switch {
+	case errors.Is(err, ErrFolderNotFound):
+		h.RespondError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, ErrInvalidPath), errors.Is(err, ErrPathNotFound),
+		errors.Is(err, ErrInvalidFolderID), errors.Is(err, ErrPathAndFolder):
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, jobs.ErrAlreadyRunning):
+		h.RespondError(w, http.StatusConflict, "a scan is already running")
+		return
+	case err != nil:
+		h.RespondError(w, http.StatusInternalServerError, "failed to create scan")
+		return
+	}
+
+	// The stored options are the resolved ones (e.g. folder_id turned into path)
+	var resolved Options
+	_ = json.Unmarshal(scan.Options, &resolved)
+	h.RespondJSON(w, http.StatusAccepted, CreateScanResponse{
+		ScanID:  uuid.UUID(scan.ID.Bytes).String(),
+		JobID:   uuid.UUID(job.ID.Bytes).String(),
+		Options: resolved,
+	})
+}
+
+// StartScan creates a scan record and enqueues its job. scheduleID links the scan to
+// the schedule that started it and may be invalid for manual scans. Invalid options
+// return the matching Err* from options.go, and jobs.ErrAlreadyRunning is returned
+// when another scan is queued or running.
+func (h *Handler) StartScan(ctx context.Context, opts Options, scheduleID pgtype.UUID) (db.Scan, db.Job, error) {
 	queries := db.New(h.Pool())
 
+	if err := opts.resolve(ctx, queries, h.config.ScanRootDir); err != nil {
+		return db.Scan{}, db.Job{}, err
+	}
+	optionsJSON, err := json.Marshal(opts)
+	if err != nil {
+		return db.Scan{}, db.Job{}, err
+	}
+
 	// Create scan record in database
 	scan, err := queries.CreateScan(ctx, db.CreateScanParams{
-		Status:    "running",
-		Found:     pgtype.Int4{Int32: 0, Valid: true},
-		Processed: pgtype.Int4{Int32: 0, Valid: true},
-		Progress:  pgtype.Int4{Int32: 0, Valid: true},
+		Status:     "running",
+		Found:      pgtype.Int4{Int32: 0, Valid: true},
+		Processed:  pgtype.Int4{Int32: 0, Valid: true},
+		Progress:   pgtype.Int4{Int32: 0, Valid: true},
+		Options:    optionsJSON,
+		ScheduleID: scheduleID,
 	})
 	if err != nil {
 		h.Logger().Error("failed to create scan record", zap.Error(err))
-		h.RespondError(w, http.StatusInternalServerError, "failed to create scan")
-		return
+		return db.Scan{}, db.Job{}, err
 	}
 
 	scanUUID := uuid.UUID(scan.ID.Bytes)
-	h.Logger().Info("scan started", zap.String("scan_id", scanUUID.String()))
 
-	// Start scan in goroutine
-	go h.runScan(context.Background(), scanUUID)
+	// Start scan as a background job
+	job, err := h.jobs.Enqueue(ctx, JobType, ScanPayload{ScanID: scanUUID, Options: opts})
+	if err != nil {
+		_ = queries.DeleteScan(ctx, scan.ID)
+		if !errors.Is(err, jobs.ErrAlreadyRunning) {
+			h.Logger().Error("failed to start scan job", zap.Error(err))
+		}
+		return db.Scan{}, db.Job{}, err
+	}
 
-	h.RespondJSON(w, http.StatusAccepted, CreateScanResponse{
-		ScanID: scanUUID.String(),
-	})
+	if err := queries.SetScanJob(ctx, db.SetScanJobParams{ID: scan.ID, JobID: job.ID}); err != nil {
+		h.Logger().Error("failed to link scan to job", zap.Error(err))
+	}
+	scan.JobID = job.ID
+
+	h.Logger().Info("scan started",
+		zap.String("scan_id", scanUUID.String()),
+		zap.String("job_id", uuid.UUID(job.ID.Bytes).String()),
+		zap.String("path", opts.Path))
+
+	return scan, job, nil
+}
+
+// StartScheduledScan starts the scan of a due schedule with the schedule's options
+func (h *Handler) StartScheduledScan(ctx context.Context, schedule db.ScanSchedule) (db.Scan, error) {
+	var opts Options
+	if err := json.Unmarshal(schedule.Options, &opts); err != nil {
+		return db.Scan{}, fmt.Errorf("decode schedule options: %w", err)
+	}
+	scan, _, err := h.StartScan(ctx, opts, schedule.ID)
+	return scan, err
 }
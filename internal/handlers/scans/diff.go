@@ -0,0 +1,224 @@
+package scans
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/scanner"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Kinds of file changes recorded in scan_events
+const (
+	changeNone    = ""
+	changeAdded   = "added"
+	changeUpdated = "updated"
+	changeMoved   = "moved"
+	changeRemoved = "removed"
+	changeFailed  = "failed"
+)
+
+// fileIndex compares scanned files with the files already stored, one directory
+// at a time, so a scan only holds the directories the walk is in and the files in
+// flight instead of the whole tree.
+//
+// match sees the walked paths in walk order and loads the stored files of a
+// directory when the walk enters it. The walk is depth-first, so once it leaves a
+// directory it does not come back: the stored files it did not find there are
+// missing. classify then compares a scanned file with the stored file at its path.
+// A scanned path that is not stored is a move when a stored file that is no
+// longer on disk has the same hash, or the same name, size and modification time;
+// otherwise it was added.
+type fileIndex struct {
+	queries *db.Queries
+	// prefix selects the stored files under the walked directory
+	prefix string
+	// stored is false when nothing was stored under the walked directory, so
+	// there is nothing a scanned file could have moved from
+	stored bool
+
+	// open are the directories the walk is in, outermost first, and visited
+	// every directory it went through. Only match uses them.
+	open    []*storedDir
+	visited []string
+
+	mu sync.Mutex
+	// pending is the stored file at each walked path until classify takes it
+	pending map[string]db.File
+	// missing are the stored files of the directories the walk left without finding them
+	missing []db.File
+	// claimed are the stored files taken over by moves
+	claimed map[pgtype.UUID]struct{}
+}
+
+// storedDir holds the stored files of a directory the walk is in that it has
+// not found yet
+type storedDir struct {
+	path  string
+	files map[string]db.File
+}
+
+// newFileIndex only treats stored files inside dir as candidates for moves and removals,
+// so scanning a subtree does not report the rest of the library as removed
+func newFileIndex(ctx context.Context, queries *db.Queries, dir string) (*fileIndex, error) {
+	prefix := dir + string(filepath.Separator)
+	stored, err := queries.HasFilesUnder(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return &fileIndex{
+		queries: queries,
+		prefix:  prefix,
+		stored:  stored,
+		visited: []string{},
+		pending: make(map[string]db.File),
+		claimed: make(map[pgtype.UUID]struct{}),
+	}, nil
+}
+
+// match records the stored file at a walked path, if any. Paths must come in
+// walk order from a single goroutine.
+func (x *fileIndex) match(ctx context.Context, path string) error {
+	dir := filepath.Dir(path)
+	x.leave(dir)
+
+	if n := len(x.open); n == 0 || x.open[n-1].path != dir {
+		d := &storedDir{path: dir, files: map[string]db.File{}}
+		if x.stored {
+			files, err := x.queries.ListFilesInDir(ctx, dir)
+			if err != nil {
+				return err
+			}
+			for _, file := range files {
+				d.files[file.Path] = file
+			}
+		}
+		x.open = append(x.open, d)
+		x.visited = append(x.visited, dir)
+	}
+
+	d := x.open[len(x.open)-1]
+	if file, ok := d.files[path]; ok {
+		delete(d.files, path)
+		x.mu.Lock()
+		x.pending[path] = file
+		x.mu.Unlock()
+	}
+	return nil
+}
+
+// leave closes the open directories that dir is not inside: the walk is done with them
+func (x *fileIndex) leave(dir string) {
+	for len(x.open) > 0 {
+		d := x.open[len(x.open)-1]
+		if dir != "" && inDir(d.path, dir) {
+			return
+		}
+		x.open = x.open[:len(x.open)-1]
+		x.mu.Lock()
+		for _, file := range d.files {
+			x.missing = append(x.missing, file)
+		}
+		x.mu.Unlock()
+	}
+}
+
+// forget drops the stored file of a walked path that did not reach classify
+func (x *fileIndex) forget(path string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	delete(x.pending, path)
+}
+
+// classify returns the change for a scanned file and the stored file it updates,
+// or for moves the one it replaces. Each missing file is matched by at most one
+// move. An error looking up moves leaves the file added.
+func (x *fileIndex) classify(ctx context.Context, f scanner.FileInfo) (string, *db.File, error) {
+	x.mu.Lock()
+	stored, ok := x.pending[f.Path]
+	delete(x.pending, f.Path)
+	x.mu.Unlock()
+
+	if ok {
+		if stored.Size != f.Size ||
+			stored.ModifiedAt.Time.UnixMicro() != f.ModifiedAt.UnixMicro() ||
+			(f.SHA256 != "" && stored.Sha256.Valid && stored.Sha256.String != f.SHA256) {
+			return changeUpdated, &stored, nil
+		}
+		return changeNone, &stored, nil
+	}
+	if !x.stored {
+		return changeAdded, nil, nil
+	}
+
+	candidates, err := x.queries.ListMoveCandidates(ctx, db.ListMoveCandidatesParams{
+		Prefix:     x.prefix,
+		Sha256:     f.SHA256,
+		FileName:   f.FileName,
+		Size:       f.Size,
+		ModifiedAt: pgtype.Timestamptz{Time: f.ModifiedAt, Valid: true},
+	})
+	if err != nil {
+		return changeAdded, nil, err
+	}
+	if from := x.claim(candidates); from != nil {
+		return changeMoved, from, nil
+	}
+	return changeAdded, nil, nil
+}
+
+// claim takes the first candidate that was not matched yet and is gone from disk.
+// Candidates come from the database, so they include files the walk found or has
+// not reached yet; checking the path directly skips both, and a candidate that
+// still exists makes the scanned file a copy, not a move.
+func (x *fileIndex) claim(candidates []db.File) *db.File {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for _, candidate := range candidates {
+		if _, ok := x.claimed[candidate.ID]; ok {
+			continue
+		}
+		if _, err := os.Stat(candidate.Path); !errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		x.claimed[candidate.ID] = struct{}{}
+		return &candidate
+	}
+	return nil
+}
+
+// removed returns the stored files that were not found on disk and not moved:
+// those the walk left behind in the directories it went through, and those in
+// directories it never entered. It must only be called once the walk has finished.
+func (x *fileIndex) removed(ctx context.Context) ([]db.File, error) {
+	x.leave("")
+
+	var unvisited []db.File
+	if x.stored {
+		var err error
+		unvisited, err = x.queries.ListFilesOutsideDirs(ctx, db.ListFilesOutsideDirsParams{
+			Prefix: x.prefix,
+			Dirs:   x.visited,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	files := make([]db.File, 0, len(x.missing)+len(unvisited))
+	for _, file := range append(x.missing, unvisited...) {
+		if _, ok := x.claimed[file.ID]; !ok {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
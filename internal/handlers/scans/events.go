@@ -0,0 +1,59 @@
+package scans
+
+import (
+	"net/http"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/events"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// finalEvents end a scan stream
+var finalEvents = map[string]bool{
+	"completed": true,
+	"failed":    true,
+	"cancelled": true,
+}
+
+// ScanEvents streams the progress of a scan as Server-Sent Events. The first event
+// is the current scan state; the stream closes after the scan finishes.
+func (h *Handler) ScanEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	scanID := chi.URLParam(r, "id")
+
+	uid, err := uuid.Parse(scanID)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "invalid scan_id format")
+		return
+	}
+
+	// Subscribe before reading the scan so no event is missed in between
+	sub := h.events.Subscribe(events.ScanTopic(uid))
+	defer sub.Close()
+
+	queries := db.New(h.pool)
+	scan, err := queries.GetScan(ctx, pgtype.UUID{Bytes: uid, Valid: true})
+	if err != nil {
+		h.RespondError(w, http.StatusNotFound, "scan not found")
+		return
+	}
+
+	snapshot := newScanResponse(scan)
+
+	initial := []events.Event{{Type: "status", Data: snapshot}}
+	if finalEvents[scan.Status] {
+		// Already finished: send the state and close
+		initial = append(initial, events.Event{Type: scan.Status, Data: snapshot})
+	}
+
+	err = events.Stream(w, r, sub, initial, func(e events.Event) bool {
+		return finalEvents[e.Type]
+	})
+	if err != nil {
+		h.logger.Debug("scan event stream closed", zap.String("scan_id", scanID), zap.Error(err))
+	}
+}
@@ -0,0 +1,136 @@
+package scans
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+
+	"stl-manager/internal/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// folderResolver returns the folder ID of scanned files. Folders and their
+// ancestors are registered the first time a file inside them is saved, so only
+// folders with files exist. Dry runs create nothing and add the folders that
+// would be created to the preview instead.
+type folderResolver struct {
+	h       *Handler
+	queries *db.Queries
+	preview *Preview
+
+	mu    sync.Mutex
+	cache map[string]pgtype.UUID
+}
+
+func newFolderResolver(h *Handler, queries *db.Queries, preview *Preview) *folderResolver {
+	return &folderResolver{
+		h:       h,
+		queries: queries,
+		preview: preview,
+		cache:   make(map[string]pgtype.UUID),
+	}
+}
+
+// resolve returns the ID of the folder at folderPath, registering it if needed.
+// Files at the scan root have no folder.
+func (r *folderResolver) resolve(ctx context.Context, folderPath string) (pgtype.UUID, error) {
+	if folderPath == "" {
+		return pgtype.UUID{}, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.resolveLocked(ctx, filepath.Clean(folderPath))
+}
+
+// count returns how many folders were resolved, which during a scan is the
+// number of folders holding scanned files
+func (r *folderResolver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.cache)
+}
+
+func (r *folderResolver) resolveLocked(ctx context.Context, folderPath string) (pgtype.UUID, error) {
+	if id, ok := r.cache[folderPath]; ok {
+		return id, nil
+	}
+
+	// Parents are resolved before their children
+	var parentFolderID pgtype.UUID
+	parentPath, hasParent := r.h.parentFolderPath(folderPath)
+	if hasParent {
+		id, err := r.resolveLocked(ctx, parentPath)
+		if err != nil {
+			return pgtype.UUID{}, err
+		}
+		parentFolderID = id
+	}
+
+	existing, err := r.queries.GetFolderByPath(ctx, folderPath)
+	switch {
+	case err == nil:
+		// Folder exists - update its parent_folder_id if needed
+		if existing.ParentFolderID != parentFolderID && r.preview == nil {
+			if _, err := r.queries.UpdateFolderParent(ctx, db.UpdateFolderParentParams{
+				ID:             existing.ID,
+				ParentFolderID: parentFolderID,
+			}); err != nil {
+				r.h.logger.Error("failed to update folder parent",
+					zap.String("path", folderPath),
+					zap.Error(err))
+			}
+		}
+		r.cache[folderPath] = existing.ID
+		return existing.ID, nil
+	case !errors.Is(err, pgx.ErrNoRows):
+		return pgtype.UUID{}, err
+	}
+
+	if r.preview != nil {
+		r.preview.addFolder(folderPath)
+		r.cache[folderPath] = pgtype.UUID{}
+		return pgtype.UUID{}, nil
+	}
+
+	// Create folder with parent_folder_id
+	folderName := filepath.Base(folderPath)
+	var created db.Folder
+	if parentFolderID.Valid {
+		created, err = r.queries.CreateFolderWithParent(ctx, db.CreateFolderWithParentParams{
+			Name:           folderName,
+			Path:           folderPath,
+			ParentFolderID: parentFolderID,
+		})
+	} else {
+		created, err = r.queries.CreateFolder(ctx, db.CreateFolderParams{
+			Name: folderName,
+			Path: folderPath,
+		})
+	}
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+
+	r.cache[folderPath] = created.ID
+	r.h.logger.Debug("created folder",
+		zap.String("name", folderName),
+		zap.String("path", folderPath),
+		zap.Bool("has_parent", hasParent))
+	return created.ID, nil
+}
+
+// parentFolderPath returns the parent folder of a path, or false when the parent is the scan root
+func (h *Handler) parentFolderPath(fullPath string) (parentPath string, hasParent bool) {
+	cleanRoot := filepath.Clean(h.config.ScanRootDir)
+	cleanDir := filepath.Clean(filepath.Dir(fullPath))
+
+	if cleanDir == cleanRoot || cleanDir == "." || cleanDir == filepath.Clean(fullPath) {
+		return "", false
+	}
+	return cleanDir, true
+}
@@ -1,6 +1,7 @@
 package scans
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"stl-manager/internal/db"
@@ -12,14 +13,17 @@ import (
 )
 
 type ScanResponse struct {
-	ID        string `json:"id"`
-	Status    string `json:"status"`
-	Found     int    `json:"found"`
-	Processed int    `json:"processed"`
-	Progress  int    `json:"progress"`
-	Error     string `json:"error,omitempty"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID         string          `json:"id"`
+	Status     string          `json:"status"`
+	Found      int             `json:"found"`
+	Processed  int             `json:"processed"`
+	Progress   int             `json:"progress"`
+	Error      string          `json:"error,omitempty"`
+	Options    json.RawMessage `json:"options"`
+	ScheduleID *string         `json:"schedule_id"`
+	Preview    json.RawMessage `json:"preview,omitempty"`
+	CreatedAt  string          `json:"created_at"`
+	UpdatedAt  string          `json:"updated_at"`
 }
 
 func (h *Handler) GetScan(w http.ResponseWriter, r *http.Request) {
@@ -47,19 +51,31 @@ func (h *Handler) GetScan(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Build response
+	h.RespondJSON(w, http.StatusOK, newScanResponse(scan))
+}
+
+// newScanResponse converts a scan row to its API representation
+func newScanResponse(scan db.Scan) ScanResponse {
 	response := ScanResponse{
-		ID:        scanID,
+		ID:        uuid.UUID(scan.ID.Bytes).String(),
 		Status:    scan.Status,
 		Found:     int(scan.Found.Int32),
 		Processed: int(scan.Processed.Int32),
 		Progress:  int(scan.Progress.Int32),
+		Options:   json.RawMessage(scan.Options),
+		Preview:   json.RawMessage(scan.Preview),
 		CreatedAt: scan.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt: scan.UpdatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
 	}
 	if scan.Error.Valid {
 		response.Error = scan.Error.String
 	}
-
-	h.RespondJSON(w, http.StatusOK, response)
+	if len(response.Options) == 0 {
+		response.Options = json.RawMessage("{}")
+	}
+	if scan.ScheduleID.Valid {
+		scheduleID := uuid.UUID(scan.ScheduleID.Bytes).String()
+		response.ScheduleID = &scheduleID
+	}
+	return response
 }
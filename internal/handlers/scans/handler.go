@@ -6,7 +6,10 @@ import (
 
 	"stl-manager/internal/ai"
 	"stl-manager/internal/config"
+	"stl-manager/internal/events"
+	"stl-manager/internal/jobs"
 	"stl-manager/internal/scanner"
+	"stl-manager/internal/worker"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
@@ -17,19 +20,27 @@ type Handler struct {
 	pool       *pgxpool.Pool
 	classifier ai.Classifier
 	scanner    *scanner.Scanner
+	workers    *worker.Pool
+	jobs       *jobs.Manager
+	events     *events.Broker
 	config     *config.Config
 	logger     *zap.Logger
 }
 
 // New creates a new scans Handler
-func New(pool *pgxpool.Pool, classifier ai.Classifier, scanner *scanner.Scanner, cfg *config.Config, logger *zap.Logger) *Handler {
-	return &Handler{
+func New(pool *pgxpool.Pool, classifier ai.Classifier, scanner *scanner.Scanner, workers *worker.Pool, jobManager *jobs.Manager, broker *events.Broker, cfg *config.Config, logger *zap.Logger) *Handler {
+	h := &Handler{
 		pool:       pool,
 		classifier: classifier,
 		scanner:    scanner,
+		workers:    workers,
+		jobs:       jobManager,
+		events:     broker,
 		config:     cfg,
 		logger:     logger,
 	}
+	h.registerJobs()
+	return h
 }
 
 // Getters for dependencies
@@ -0,0 +1,43 @@
+package scans
+
+import (
+	"context"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/jobs"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// JobType is the job type used for scans
+const JobType = "scan"
+
+// ScanPayload is stored on the job so an interrupted scan can be resumed
+type ScanPayload struct {
+	ScanID  uuid.UUID `json:"scan_id"`
+	Options Options   `json:"options"`
+}
+
+// registerJobs registers scans with the job manager. Scans are exclusive and
+// resumable: upserts are idempotent, so a scan interrupted by a restart is run again.
+func (h *Handler) registerJobs() {
+	h.jobs.Register(JobType, jobs.Definition{
+		Run: func(ctx context.Context, job *jobs.Job) (any, error) {
+			var payload ScanPayload
+			if err := job.Decode(&payload); err != nil {
+				return nil, err
+			}
+			return h.runScan(ctx, job, payload.ScanID, payload.Options)
+		},
+		Exclusive: true,
+		Resume:    true,
+		Cleanup: func(ctx context.Context) error {
+			failed, err := db.New(h.pool).FailInterruptedScans(ctx)
+			if failed > 0 {
+				h.logger.Warn("marked interrupted scans as failed", zap.Int64("count", failed))
+			}
+			return err
+		},
+	})
+}
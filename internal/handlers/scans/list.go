@@ -2,39 +2,62 @@ package scans
 
 import (
 	"net/http"
-	"strconv"
+	"time"
 
 	"stl-manager/internal/db"
+	"stl-manager/internal/pagination"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
 	"go.uber.org/zap"
 )
 
+// sortByCreated tags cursors of the scan list, newest first
+const sortByCreated = "created_at"
+
 func (h *Handler) ListScans(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	queries := db.New(h.pool)
 
 	// Parse pagination
 	query := r.URL.Query()
-	page := 1
-	pageSize := 20
-	if p := query.Get("page"); p != "" {
-		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
-			page = parsed
+	p, err := pagination.Parse(query, pagination.DefaultPageSize)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	after, err := p.After(sortByCreated)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params := db.ListScansParams{
+		Limit:  int32(p.Limit()),
+		Offset: int32(p.Offset()),
+	}
+	if after != nil {
+		createdAt, err := time.Parse(time.RFC3339Nano, after.Key)
+		if err != nil {
+			h.RespondError(w, http.StatusBadRequest, pagination.ErrInvalidCursor.Error())
+			return
 		}
+		params.AfterID = after.ID
+		params.AfterCreatedAt = pgtype.Timestamptz{Time: createdAt, Valid: true}
 	}
-	if ps := query.Get("page_size"); ps != "" {
-		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
-			pageSize = parsed
+
+	// Optional schedule filter
+	if id := query.Get("schedule_id"); id != "" {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			h.RespondError(w, http.StatusBadRequest, "invalid schedule_id format")
+			return
 		}
+		params.ScheduleID = pgtype.UUID{Bytes: parsed, Valid: true}
 	}
-	offset := (page - 1) * pageSize
 
 	// Get scans from database
-	scans, err := queries.ListScans(ctx, db.ListScansParams{
-		Limit:  int32(pageSize),
-		Offset: int32(offset),
-	})
+	scans, err := queries.ListScans(ctx, params)
 	if err != nil {
 		h.logger.Error("failed to list scans", zap.Error(err))
 		h.RespondError(w, http.StatusInternalServerError, "failed to list scans")
@@ -42,35 +65,22 @@ func (h *Handler) ListScans(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get total count (FIX: was using len(scans) before)
-	total, err := queries.CountScans(ctx)
+	total, err := queries.CountScans(ctx, params.ScheduleID)
 	if err != nil {
 		h.logger.Error("failed to count scans", zap.Error(err))
 		total = 0
 	}
 
-	// Convert to response format
+	scans, next := pagination.Trim(scans, p, func(scan db.Scan) pagination.Cursor {
+		return pagination.NewCursor(sortByCreated, scan.CreatedAt.Time.Format(time.RFC3339Nano), scan.ID)
+	})
+
+	// Convert to response format; dry-run previews are only returned by GetScan
 	items := make([]ScanResponse, len(scans))
 	for i, scan := range scans {
-		scanUUID := uuid.UUID(scan.ID.Bytes)
-		items[i] = ScanResponse{
-			ID:        scanUUID.String(),
-			Status:    scan.Status,
-			Found:     int(scan.Found.Int32),
-			Processed: int(scan.Processed.Int32),
-			Progress:  int(scan.Progress.Int32),
-			CreatedAt: scan.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt: scan.UpdatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
-		}
-		if scan.Error.Valid {
-			items[i].Error = scan.Error.String
-		}
+		items[i] = newScanResponse(scan)
+		items[i].Preview = nil
 	}
 
-	h.RespondJSON(w, http.StatusOK, map[string]any{
-		"items":       items,
-		"total":       total,
-		"page":        page,
-		"page_size":   pageSize,
-		"total_pages": int((total + int64(pageSize) - 1) / int64(pageSize)),
-	})
+	h.RespondJSON(w, http.StatusOK, p.Response(items, total, next))
 }
@@ -0,0 +1,128 @@
+package scans
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"stl-manager/internal/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrInvalidPath is returned for scan paths that are absolute or leave the scan root
+	ErrInvalidPath = errors.New("path must be relative to the scan root")
+	// ErrPathNotFound is returned when the scan path is not a directory
+	ErrPathNotFound = errors.New("path is not a directory under the scan root")
+	// ErrInvalidFolderID is returned when folder_id is not a UUID
+	ErrInvalidFolderID = errors.New("folder_id must be a valid UUID")
+	// ErrPathAndFolder is returned when both path and folder_id are set
+	ErrPathAndFolder = errors.New("path and folder_id cannot be combined")
+	// ErrFolderNotFound is returned when folder_id does not match a folder
+	ErrFolderNotFound = errors.New("folder not found")
+)
+
+// Options control what a scan covers and how much work it does per file
+type Options struct {
+	// Path limits the scan to a subtree, relative to SCAN_ROOT_DIR
+	Path string `json:"path,omitempty"`
+	// FolderID limits the scan to a registered folder; it is resolved to Path when the scan starts
+	FolderID string `json:"folder_id,omitempty"`
+	// Hash computes the SHA-256 of every file (slow, detects moves by content)
+	Hash bool `json:"hash"`
+	// Incremental skips files whose size and modification time did not change
+	Incremental bool `json:"incremental"`
+	// Classify runs AI classification on scanned files (default true)
+	Classify *bool `json:"classify,omitempty"`
+	// Prune deletes stored files missing from disk and folders left empty, within the scanned tree
+	Prune bool `json:"prune"`
+	// DryRun reports the changes without writing files, folders or categories
+	DryRun bool `json:"dry_run"`
+}
+
+// Normalize cleans Path and rejects paths outside the scan root
+func (o *Options) Normalize() error {
+	if o.FolderID != "" {
+		if o.Path != "" {
+			return ErrPathAndFolder
+		}
+		if _, err := uuid.Parse(o.FolderID); err != nil {
+			return ErrInvalidFolderID
+		}
+	}
+	if o.Path == "" {
+		return nil
+	}
+	path := filepath.Clean(filepath.FromSlash(o.Path))
+	if filepath.IsAbs(path) || filepath.VolumeName(path) != "" || path == ".." ||
+		strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		return ErrInvalidPath
+	}
+	if path == "." {
+		path = ""
+	}
+	o.Path = filepath.ToSlash(path)
+	return nil
+}
+
+// resolve normalizes the options, turns FolderID into a Path and checks that the
+// directory to walk exists
+func (o *Options) resolve(ctx context.Context, queries *db.Queries, root string) error {
+	if err := o.Normalize(); err != nil {
+		return err
+	}
+
+	if o.FolderID != "" {
+		folder, err := queries.GetFolder(ctx, pgtype.UUID{Bytes: uuid.MustParse(o.FolderID), Valid: true})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrFolderNotFound
+		}
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, folder.Path)
+		if err != nil {
+			return ErrInvalidPath
+		}
+		// Keep both on the scan record: the folder asked for and the path walked
+		resolved := Options{Path: filepath.ToSlash(rel)}
+		if err := resolved.Normalize(); err != nil {
+			return err
+		}
+		o.Path = resolved.Path
+	}
+
+	if o.Path != "" {
+		if info, err := os.Stat(o.dir(root)); err != nil || !info.IsDir() {
+			return ErrPathNotFound
+		}
+	}
+	return nil
+}
+
+// classify reports whether scanned files are sent to AI classification
+func (o Options) classify() bool {
+	return o.Classify == nil || *o.Classify
+}
+
+// dir returns the directory to walk for these options
+func (o Options) dir(root string) string {
+	if o.Path == "" {
+		return filepath.Clean(root)
+	}
+	return filepath.Join(root, filepath.FromSlash(o.Path))
+}
+
+// inDir reports whether path is dir or inside it
+func inDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
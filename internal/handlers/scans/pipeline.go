@@ -0,0 +1,135 @@
+package scans
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Scan pipeline defaults, used when the configuration leaves a value at 0
+const (
+	defaultStatWorkers   = 8
+	defaultHashWorkers   = 4
+	defaultUpsertWorkers = 2
+	defaultBatchSize     = 500
+	defaultQueueSize     = 1000
+
+	// batchInterval flushes a partial batch so a slow walk still makes progress
+	batchInterval = 500 * time.Millisecond
+)
+
+// pipelineSettings are the workers per stage and the buffer sizes of a scan.
+// Every stage reads from a bounded channel, so a slow stage holds back the ones
+// before it (down to the walk) and memory stays bounded by the buffers.
+type pipelineSettings struct {
+	statWorkers   int
+	hashWorkers   int
+	upsertWorkers int
+	batchSize     int
+	queueSize     int
+}
+
+func (h *Handler) pipelineSettings() pipelineSettings {
+	orDefault := func(value, fallback int) int {
+		if value > 0 {
+			return value
+		}
+		return fallback
+	}
+	return pipelineSettings{
+		statWorkers:   orDefault(h.config.ScanStatWorkers, defaultStatWorkers),
+		hashWorkers:   orDefault(h.config.ScanHashWorkers, defaultHashWorkers),
+		upsertWorkers: orDefault(h.config.ScanUpsertWorkers, defaultUpsertWorkers),
+		batchSize:     orDefault(h.config.ScanBatchSize, defaultBatchSize),
+		queueSize:     orDefault(h.config.ScanQueueSize, defaultQueueSize),
+	}
+}
+
+// stage runs workers goroutines that call fn for every value received from in.
+// fn passes its results to send, which blocks while the returned channel is full
+// and reports false once ctx is cancelled. The returned channel is closed when in
+// is closed and drained, or ctx is cancelled, and every worker has returned.
+func stage[In, Out any](ctx context.Context, workers, buffer int, in <-chan In, fn func(v In, send func(Out) bool)) <-chan Out {
+	out := make(chan Out, buffer)
+	send := func(v Out) bool {
+		select {
+		case out <- v:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case v, ok := <-in:
+					if !ok {
+						return
+					}
+					fn(v, send)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// batch groups values from in into slices of up to size values. Partial batches
+// are sent every batchInterval and when in is closed.
+func batch[T any](ctx context.Context, in <-chan T, size int) <-chan []T {
+	out := make(chan []T)
+
+	go func() {
+		defer close(out)
+
+		ticker := time.NewTicker(batchInterval)
+		defer ticker.Stop()
+
+		pending := make([]T, 0, size)
+		flush := func() bool {
+			if len(pending) == 0 {
+				return true
+			}
+			select {
+			case out <- pending:
+				pending = make([]T, 0, size)
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				pending = append(pending, v)
+				if len(pending) >= size && !flush() {
+					return
+				}
+			case <-ticker.C:
+				if !flush() {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
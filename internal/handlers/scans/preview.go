@@ -0,0 +1,98 @@
+package scans
+
+import "sync"
+
+// previewSamples caps how many example paths each preview group keeps
+const previewSamples = 20
+
+// PreviewGroup counts the paths of one kind and keeps the first few as samples
+type PreviewGroup struct {
+	Count   int      `json:"count"`
+	Samples []string `json:"samples"`
+}
+
+func (g *PreviewGroup) add(path string) {
+	g.Count++
+	if len(g.Samples) < previewSamples {
+		g.Samples = append(g.Samples, path)
+	}
+}
+
+// PreviewAI estimates the AI classification a real scan would run
+type PreviewAI struct {
+	Enabled          bool    `json:"enabled"`
+	Calls            int     `json:"calls"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
+	ScanBudgetUSD    float64 `json:"scan_budget_usd"`
+}
+
+// Preview is the report of a dry-run scan: what a real scan with the same
+// options would write to the library
+type Preview struct {
+	New        *PreviewGroup            `json:"new"`
+	Changed    *PreviewGroup            `json:"changed"`
+	Moved      *PreviewGroup            `json:"moved"`
+	Missing    *PreviewGroup            `json:"missing"`
+	Unchanged  int                      `json:"unchanged"`
+	NewFolders *PreviewGroup            `json:"new_folders"`
+	Ignored    map[string]*PreviewGroup `json:"ignored"`
+	AI         PreviewAI                `json:"ai"`
+
+	mu sync.Mutex
+}
+
+func newPreview() *Preview {
+	group := func() *PreviewGroup { return &PreviewGroup{Samples: []string{}} }
+	return &Preview{
+		New:        group(),
+		Changed:    group(),
+		Moved:      group(),
+		Missing:    group(),
+		NewFolders: group(),
+		Ignored:    map[string]*PreviewGroup{},
+	}
+}
+
+// addFile records the change a scan would make to a file and, when classify is
+// set, the estimated cost of classifying it
+func (p *Preview) addFile(change, path string, classify bool, cost float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch change {
+	case changeAdded:
+		p.New.add(path)
+	case changeUpdated:
+		p.Changed.add(path)
+	case changeMoved:
+		p.Moved.add(path)
+	case changeRemoved:
+		p.Missing.add(path)
+	default:
+		p.Unchanged++
+	}
+	if classify {
+		p.AI.Calls++
+		p.AI.EstimatedCostUSD += cost
+	}
+}
+
+// addFolder records a folder a real scan would create
+func (p *Preview) addFolder(path string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.NewFolders.add(path)
+}
+
+// addIgnored records a path the walk skipped, grouped by reason
+func (p *Preview) addIgnored(path, reason string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	group, ok := p.Ignored[reason]
+	if !ok {
+		group = &PreviewGroup{Samples: []string{}}
+		p.Ignored[reason] = group
+	}
+	group.add(path)
+}
@@ -0,0 +1,121 @@
+package scans
+
+import (
+	"net/http"
+	"strconv"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/pagination"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// sortEvents tags cursors of the scan report events, in the order they happened
+const sortEvents = "event_id"
+
+// validChanges are the event filters accepted by GetScanReport
+var validChanges = map[string]bool{
+	changeAdded:   true,
+	changeUpdated: true,
+	changeMoved:   true,
+	changeRemoved: true,
+	changeFailed:  true,
+}
+
+// GetScanReport summarises what a scan changed and paginates its per-file events
+func (h *Handler) GetScanReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	scanID := chi.URLParam(r, "id")
+
+	uid, err := uuid.Parse(scanID)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "invalid scan_id format")
+		return
+	}
+
+	query := r.URL.Query()
+	event := query.Get("event")
+	if event != "" && !validChanges[event] {
+		h.RespondError(w, http.StatusBadRequest, "invalid event, must be one of: added, updated, moved, removed, failed")
+		return
+	}
+
+	p, err := pagination.Parse(query, pagination.DefaultPageSize)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	after, err := p.After(sortEvents)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	queries := db.New(h.pool)
+	scanUUID := pgtype.UUID{Bytes: uid, Valid: true}
+
+	scan, err := queries.GetScan(ctx, scanUUID)
+	if err != nil {
+		h.RespondError(w, http.StatusNotFound, "scan not found")
+		return
+	}
+
+	rows, err := queries.SummarizeScanEvents(ctx, scanUUID)
+	if err != nil {
+		h.logger.Error("failed to summarize scan events", zap.String("scan_id", scanID), zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to get scan report")
+		return
+	}
+	summary := map[string]int64{}
+	for change := range validChanges {
+		summary[change] = 0
+	}
+	for _, row := range rows {
+		summary[row.Event] = row.Count
+	}
+
+	params := db.ListScanEventsParams{
+		Limit:  int32(p.Limit()),
+		Offset: int32(p.Offset()),
+		ScanID: scanUUID,
+		Event:  event,
+	}
+	if after != nil {
+		// Event IDs are not UUIDs, so the cursor carries the event ID as its
+		// key and the scan ID as its ID
+		eventID, err := strconv.ParseInt(after.Key, 10, 64)
+		if err != nil || after.ID != scanUUID {
+			h.RespondError(w, http.StatusBadRequest, pagination.ErrInvalidCursor.Error())
+			return
+		}
+		params.AfterID = eventID
+	}
+
+	items, err := queries.ListScanEvents(ctx, params)
+	if err != nil {
+		h.logger.Error("failed to list scan events", zap.String("scan_id", scanID), zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to get scan report")
+		return
+	}
+
+	total, err := queries.CountScanEvents(ctx, db.CountScanEventsParams{
+		ScanID: scanUUID,
+		Event:  event,
+	})
+	if err != nil {
+		h.logger.Error("failed to count scan events", zap.Error(err))
+		total = 0
+	}
+
+	items, next := pagination.Trim(items, p, func(event db.ScanEvent) pagination.Cursor {
+		return pagination.NewCursor(sortEvents, strconv.FormatInt(event.ID, 10), scanUUID)
+	})
+
+	resp := p.Response(items, total, next)
+	resp["scan"] = newScanResponse(scan)
+	resp["summary"] = summary
+	h.RespondJSON(w, http.StatusOK, resp)
+}
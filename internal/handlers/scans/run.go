@@ -2,13 +2,17 @@ package scans
 
 import (
 	"context"
-	"path/filepath"
-	"sort"
-	"strings"
-	"sync"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
 	"time"
 
+	"stl-manager/internal/ai"
 	"stl-manager/internal/db"
+	"stl-manager/internal/events"
+	"stl-manager/internal/handlers/categories"
+	"stl-manager/internal/jobs"
 	"stl-manager/internal/scanner"
 
 	"github.com/google/uuid"
@@ -16,329 +20,700 @@ import (
 	"go.uber.org/zap"
 )
 
-// runScan executes the scan process
-func (h *Handler) runScan(ctx context.Context, scanID uuid.UUID) {
-	h.logger.Info("running scan", zap.String("scan_id", scanID.String()))
-	queries := db.New(h.pool)
-	scanUUID := pgtype.UUID{Bytes: scanID, Valid: true}
-
-	// Update scan status to running
-	updateScanStatus := func(status string, found, processed, progress int, errorMsg string) {
-		_, err := queries.UpdateScan(ctx, db.UpdateScanParams{
-			ID:        scanUUID,
-			Status:    status,
-			Found:     pgtype.Int4{Int32: int32(found), Valid: true},
-			Processed: pgtype.Int4{Int32: int32(processed), Valid: true},
-			Progress:  pgtype.Int4{Int32: int32(progress), Valid: true},
-			Error:     pgtype.Text{String: errorMsg, Valid: errorMsg != ""},
-		})
-		if err != nil {
-			h.logger.Error("failed to update scan status", zap.Error(err))
-		}
-	}
+// progressInterval is how often a running scan writes and streams its counters
+const progressInterval = time.Second
+
+// scanItem is a file moving through the scan pipeline
+type scanItem struct {
+	file         scanner.FileInfo
+	change       string
+	storedID     pgtype.UUID // stored row at the same path, or the row a move takes over
+	previousPath string
+	folderID     pgtype.UUID
+	fileID       pgtype.UUID // row after the upsert
+}
 
-	// Scan files
-	files, err := h.scanner.Scan(ctx)
-	if err != nil {
-		h.logger.Error("scan failed", zap.Error(err))
-		updateScanStatus("failed", 0, 0, 0, err.Error())
-		return
-	}
+// scanEvent is a file change waiting to be written to scan_events
+type scanEvent struct {
+	fileID       pgtype.UUID
+	change       string
+	path         string
+	previousPath string
+	errorMsg     string
+}
 
-	h.logger.Info("scan completed",
+// scanRun is the state of one scan job while its pipeline runs
+type scanRun struct {
+	h        *Handler
+	job      *jobs.Job
+	queries  *db.Queries
+	scanID   uuid.UUID
+	scanUUID pgtype.UUID
+	opts     Options
+	topic    string
+
+	// Status writes must succeed even after the job was cancelled
+	writeCtx context.Context
+	// cancel stops the pipeline when listErr is set
+	cancel  context.CancelFunc
+	listErr error
+
+	index        *fileIndex
+	folders      *folderResolver
+	preview      *Preview
+	catalog      *categories.Catalog
+	aiEnabled    bool
+	estimateCost func(fileName string) float64
+
+	walking   atomic.Bool
+	found     atomic.Int64
+	processed atomic.Int64
+	skipped   atomic.Int64
+	queued    atomic.Int64
+	progress  atomic.Int64
+	changes   map[string]*atomic.Int64
+
+	// seen holds the folders the walk went through (see seeFolder)
+	seen map[string]struct{}
+}
+
+// runScan executes the scan process as a job and returns the scan statistics.
+// Files stream through a pipeline of stages connected by bounded channels:
+//
+//	walk -> stat -> hash -> diff -> batch -> save (folders, moves, upsert) -> classify
+//
+// so a large library is processed while it is still being walked, with memory
+// bounded by the channel buffers instead of the number of files. The sidecar
+// files of the folders walked are read afterwards (see refreshFolders).
+func (h *Handler) runScan(ctx context.Context, job *jobs.Job, scanID uuid.UUID, opts Options) (any, error) {
+	settings := h.pipelineSettings()
+	h.logger.Info("running scan",
 		zap.String("scan_id", scanID.String()),
-		zap.Int("files_found", len(files)),
-	)
+		zap.String("path", opts.Path),
+		zap.Bool("hash", opts.Hash),
+		zap.Bool("incremental", opts.Incremental),
+		zap.Bool("classify", opts.classify()),
+		zap.Bool("prune", opts.Prune),
+		zap.Bool("dry_run", opts.DryRun))
+
+	run := &scanRun{
+		h:        h,
+		job:      job,
+		queries:  db.New(h.pool),
+		scanID:   scanID,
+		scanUUID: pgtype.UUID{Bytes: scanID, Valid: true},
+		opts:     opts,
+		topic:    events.ScanTopic(scanID),
+		writeCtx: context.WithoutCancel(ctx),
+		seen:     map[string]struct{}{},
+		changes: map[string]*atomic.Int64{
+			changeAdded:   {},
+			changeUpdated: {},
+			changeMoved:   {},
+			changeRemoved: {},
+			changeFailed:  {},
+		},
+	}
+	h.events.Publish(events.TopicLibrary, events.TypeScanStarted, map[string]any{
+		"scan_id": scanID.String(),
+		"job_id":  uuid.UUID(job.ID.Bytes).String(),
+	})
 
-	// Update scan with found count
-	updateScanStatus("running", len(files), 0, 5, "")
+	// Per-scan AI spend cap
+	budget := ai.NewBudget(h.config.AIScanBudgetUSD)
+	ctx = ai.WithBudget(ctx, budget)
 
-	// PHASE 1: Discover and create complete folder hierarchy (only folders with files)
-	h.logger.Info("discovering folder hierarchy")
-	folderCache, err := h.discoverAndCreateFolderHierarchy(ctx, queries, files)
-	if err != nil {
-		h.logger.Error("failed to create folder hierarchy", zap.Error(err))
-		updateScanStatus("failed", len(files), 0, 0, err.Error())
-		return
+	// Dry runs collect a preview of what a real scan would change
+	var ignored scanner.IgnoreFunc
+	if opts.DryRun {
+		run.preview = newPreview()
+		ignored = run.preview.addIgnored
 	}
-	h.logger.Info("folder hierarchy created", zap.Int("total_folders", len(folderCache)))
-	updateScanStatus("running", len(files), 0, 10, "")
+	run.folders = newFolderResolver(h, run.queries, run.preview)
 
 	// Get all categories for classification
-	allCategories, err := queries.ListCategories(ctx)
+	catalog, err := categories.LoadCatalog(ctx, run.queries)
 	if err != nil {
 		h.logger.Error("failed to list categories for classification", zap.Error(err))
-		allCategories = []db.Category{}
+		catalog = categories.NewCatalog([]db.Category{}, []db.CategoryAlias{})
+	}
+	run.catalog = catalog
+
+	// Dry runs estimate the AI cost of the files a real scan would classify
+	run.aiEnabled = opts.classify() && h.classifier.IsEnabled()
+	run.estimateCost = func(fileName string) float64 { return 0 }
+	if reporter, ok := h.classifier.(ai.UsageReporter); ok {
+		limiter := reporter.Limiter()
+		run.estimateCost = func(fileName string) float64 {
+			return limiter.EstimateCost(fileName, run.catalog.Paths)
+		}
 	}
 
-	categoryNames := make([]string, len(allCategories))
-	categoryMap := make(map[string]pgtype.UUID)
-	for i, cat := range allCategories {
-		categoryNames[i] = cat.Name
-		categoryMap[cat.Name] = cat.ID
+	// Stored files under the scanned tree are compared one directory at a time
+	// as the walk reaches them, to record what this scan changes
+	walkDir := opts.dir(h.config.ScanRootDir)
+	run.index, err = newFileIndex(ctx, run.queries, walkDir)
+	if err != nil {
+		h.logger.Error("failed to list stored files", zap.Error(err))
+		job.Error("listing stored files failed: %v", err)
+		run.updateStatus("failed", err.Error())
+		run.finish("failed", map[string]any{"error": err.Error()})
+		return nil, err
 	}
+	ctx, run.cancel = context.WithCancel(ctx)
+	defer run.cancel()
 
-	// PHASE 2: Process files in parallel with controlled concurrency
-	var (
-		processed    = 0
-		mu           sync.Mutex
-		wg           sync.WaitGroup
-		sem          = make(chan struct{}, 20) // Max 20 concurrent workers
-		progressChan = make(chan int, len(files))
-	)
+	// Walk, stat, hash, save and classify concurrently
+	job.Info("walking %s", walkDir)
+	run.emit("phase", map[string]any{"phase": "walk"})
+
+	paths := make(chan string, settings.queueSize)
+	var walkErr error
+	run.walking.Store(true)
+	go func() {
+		defer close(paths)
+		walkErr = h.scanner.Walk(ctx, walkDir, ignored, paths)
+		run.walking.Store(false)
+		found := run.found.Load()
+		job.Info("walk finished with %d files found", found)
+		run.emit("phase", map[string]any{"phase": "files", "found": found})
+	}()
 
-	// Progress updater goroutine
+	matched := stage(ctx, 1, settings.queueSize, paths,
+		func(path string, send func(string) bool) { run.match(ctx, path, send) })
+	files := stage(ctx, settings.statWorkers, settings.queueSize, matched, run.stat)
+	if opts.Hash {
+		files = stage(ctx, settings.hashWorkers, settings.queueSize, files, run.hash)
+	}
+	items := stage(ctx, 1, settings.queueSize, files,
+		func(f scanner.FileInfo, send func(*scanItem) bool) { run.diff(ctx, f, send) })
+	saved := stage(ctx, settings.upsertWorkers, settings.queueSize, batch(ctx, items, settings.batchSize),
+		func(b []*scanItem, send func(*scanItem) bool) { run.save(ctx, b, send) })
+	done := stage(ctx, h.workers.Size(), 0, saved,
+		func(item *scanItem, _ func(struct{}) bool) { run.classify(ctx, item) })
+
+	stopProgress := make(chan struct{})
+	progressDone := make(chan struct{})
 	go func() {
-		lastUpdate := 0
-		for range progressChan {
-			mu.Lock()
-			processed++
-			current := processed
-			mu.Unlock()
-
-			// Update progress every 50 files or at completion
-			if current%50 == 0 || current == len(files) {
-				progress := 10 + int(float64(current)/float64(len(files))*80)
-				updateScanStatus("running", len(files), current, progress, "")
-				h.logger.Info("scan progress",
-					zap.String("scan_id", scanID.String()),
-					zap.Int("processed", current),
-					zap.Int("total", len(files)),
-					zap.Int("progress", progress))
-				lastUpdate = current
+		defer close(progressDone)
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				run.reportProgress()
+			case <-stopProgress:
+				return
 			}
 		}
-		// Final update if not aligned with batch
-		if lastUpdate != len(files) {
-			mu.Lock()
-			current := processed
-			mu.Unlock()
-			progress := 10 + int(float64(current)/float64(len(files))*80)
-			updateScanStatus("running", len(files), current, progress, "")
-		}
 	}()
 
-	// Process files in parallel
-	for _, file := range files {
-		wg.Add(1)
-		sem <- struct{}{} // Acquire semaphore
-
-		go func(f scanner.FileInfo) {
-			defer wg.Done()
-			defer func() { <-sem }() // Release semaphore
-
-			// Get folder ID from cache
-			var folderID pgtype.UUID
-			if f.FolderPath != "" {
-				if cachedID, ok := folderCache[f.FolderPath]; ok {
-					folderID = cachedID
-				} else {
-					h.logger.Warn("folder not found in cache",
-						zap.String("path", f.FolderPath))
-				}
-			}
+	for range done {
+	}
+	close(stopProgress)
+	<-progressDone
+
+	found, processed := run.found.Load(), run.processed.Load()
+	if run.listErr != nil {
+		h.logger.Error("failed to list stored files", zap.Error(run.listErr))
+		job.Error("listing stored files failed: %v", run.listErr)
+		run.updateStatus("failed", run.listErr.Error())
+		run.finish("failed", map[string]any{"error": run.listErr.Error()})
+		return nil, run.listErr
+	}
+	if ctx.Err() != nil {
+		run.updateStatus("cancelled", "cancelled")
+		job.Warn("cancelled after %d of %d files", processed, found)
+		run.finish("cancelled", map[string]any{"found": found, "processed": processed})
+		return nil, ctx.Err()
+	}
+	if walkErr != nil {
+		h.logger.Error("scan failed", zap.Error(walkErr))
+		job.Error("walk failed: %v", walkErr)
+		run.updateStatus("failed", walkErr.Error())
+		run.finish("failed", map[string]any{"error": walkErr.Error()})
+		return nil, walkErr
+	}
+	job.Info("folder hierarchy has %d folders", run.folders.count())
+	run.refreshFolders(run.writeCtx, settings.upsertWorkers)
 
-			// Upsert file
-			savedFile, err := queries.UpsertFile(ctx, db.UpsertFileParams{
-				Path:       f.Path,
-				FileName:   f.FileName,
-				Type:       f.Type,
-				Size:       f.Size,
-				ModifiedAt: pgtype.Timestamptz{Time: f.ModifiedAt, Valid: true},
-				Sha256:     pgtype.Text{String: f.SHA256, Valid: f.SHA256 != ""},
-				FolderID:   folderID,
-			})
+	// Stored files that were neither found nor moved; their rows are kept unless pruning
+	pruned := map[string]int64{"files": 0, "folders": 0}
+	removed, err := run.index.removed(run.writeCtx)
+	if err != nil {
+		h.logger.Error("failed to list removed files", zap.Error(err))
+		job.Error("listing removed files failed: %v", err)
+	}
+	if len(removed) > 0 {
+		params := db.AddRemovedScanEventsParams{
+			ScanID:  run.scanUUID,
+			FileIds: make([]pgtype.UUID, len(removed)),
+			Paths:   make([]string, len(removed)),
+		}
+		for i, file := range removed {
+			params.FileIds[i] = file.ID
+			params.Paths[i] = file.Path
+			if run.preview != nil {
+				run.preview.addFile(changeRemoved, file.Path, false, 0)
+			}
+		}
+		if err := run.queries.AddRemovedScanEvents(run.writeCtx, params); err != nil {
+			h.logger.Error("failed to record removed files", zap.Error(err))
+		}
+		run.changes[changeRemoved].Add(int64(len(removed)))
+		job.Info("%d stored files were not found on disk", len(removed))
 
+		if opts.Prune && !opts.DryRun {
+			deleted, err := run.queries.DeleteFilesByID(run.writeCtx, params.FileIds)
 			if err != nil {
-				h.logger.Error("failed to save file",
-					zap.String("path", f.Path),
-					zap.Error(err))
-				progressChan <- 1
-				return
+				h.logger.Error("failed to prune removed files", zap.Error(err))
+				job.Error("pruning files failed: %v", err)
 			}
+			pruned["files"] = deleted
+		}
+	}
 
-			// Classify file with OpenAI
-			var classifiedCategories []string
-			if h.classifier.IsEnabled() {
-				classifiedCategories, err = h.classifier.Classify(ctx, f.FileName, categoryNames)
-				if err != nil {
-					h.logger.Warn("classification failed",
-						zap.String("file", f.FileName),
-						zap.Error(err))
-					classifiedCategories = []string{"uncategorized"}
-				}
-				if len(classifiedCategories) == 0 {
-					classifiedCategories = []string{"uncategorized"}
-				}
-			} else {
-				classifiedCategories = []string{"uncategorized"}
-			}
+	// Folders under the scanned tree that no longer hold any file
+	if opts.Prune && !opts.DryRun {
+		deleted, err := run.queries.DeleteEmptyFolders(run.writeCtx, walkDir)
+		if err != nil {
+			h.logger.Error("failed to prune empty folders", zap.Error(err))
+			job.Error("pruning folders failed: %v", err)
+		}
+		pruned["folders"] = deleted
+		job.Info("pruned %d files and %d folders", pruned["files"], pruned["folders"])
+	}
 
-			// Remove existing categories and add new ones
-			_ = queries.RemoveAllFileCategories(ctx, savedFile.ID)
-			for _, catName := range classifiedCategories {
-				if catID, ok := categoryMap[catName]; ok {
-					err = queries.AddFileCategory(ctx, db.AddFileCategoryParams{
-						FileID:     savedFile.ID,
-						CategoryID: catID,
-					})
-					if err != nil {
-						h.logger.Error("failed to add category",
-							zap.String("file", f.FileName),
-							zap.String("category", catName),
-							zap.Error(err))
-					}
-				}
-			}
+	summary := make(map[string]int64, len(run.changes))
+	for change, count := range run.changes {
+		summary[change] = count.Load()
+	}
 
-			h.logger.Debug("saved and classified file",
-				zap.String("path", f.Path),
-				zap.Strings("categories", classifiedCategories))
+	// Mark scan as completed
+	run.progress.Store(100)
+	run.updateStatus("completed", "")
+	h.logger.Info("scan completed successfully",
+		zap.String("scan_id", scanID.String()),
+		zap.Int64("files_found", found),
+		zap.Int64("files_processed", processed),
+		zap.Int64("files_queued_for_classification", run.queued.Load()),
+		zap.Float64("ai_spend_usd", budget.Spent()))
+	job.Info("processed %d files (%d unchanged skipped), %d queued for classification",
+		processed, run.skipped.Load(), run.queued.Load())
+	job.Info("added %d, updated %d, moved %d, removed %d, failed %d",
+		summary[changeAdded], summary[changeUpdated], summary[changeMoved], summary[changeRemoved], summary[changeFailed])
+
+	stats := map[string]any{
+		"scan_id":      scanID.String(),
+		"found":        found,
+		"processed":    processed,
+		"queued":       run.queued.Load(),
+		"skipped":      run.skipped.Load(),
+		"ai_spend_usd": budget.Spent(),
+		"changes":      summary,
+		"dry_run":      opts.DryRun,
+	}
+	if opts.Prune && !opts.DryRun {
+		stats["pruned"] = pruned
+	}
+	if run.preview != nil {
+		run.preview.AI.Enabled = run.aiEnabled
+		run.preview.AI.ScanBudgetUSD = h.config.AIScanBudgetUSD
+		previewJSON, _ := json.Marshal(run.preview)
+		if err := run.queries.SetScanPreview(run.writeCtx, db.SetScanPreviewParams{ID: run.scanUUID, Preview: previewJSON}); err != nil {
+			h.logger.Error("failed to store scan preview", zap.Error(err))
+		}
+		job.Info("dry run: %d new, %d changed, %d moved, %d missing files, %d new folders, ~%d AI calls",
+			run.preview.New.Count, run.preview.Changed.Count, run.preview.Moved.Count, run.preview.Missing.Count,
+			run.preview.NewFolders.Count, run.preview.AI.Calls)
+		stats["preview"] = run.preview
+	}
+	run.finish("completed", stats)
+	return stats, nil
+}
 
-			progressChan <- 1
-		}(file)
+// emit publishes a live event for GET /v1/scans/{id}/events
+func (r *scanRun) emit(eventType string, data any) {
+	r.h.events.Publish(r.topic, eventType, data)
+}
+
+func (r *scanRun) warn(path, format string, args ...any) {
+	r.emit("warning", map[string]any{"path": path, "message": fmt.Sprintf(format, args...)})
+}
+
+// finish publishes the final scan event and tells library clients to refresh
+func (r *scanRun) finish(status string, data map[string]any) {
+	r.emit(status, data)
+	r.h.events.Publish(events.TopicLibrary, events.TypeScanFinished, map[string]any{
+		"scan_id": r.scanID.String(),
+		"status":  status,
+	})
+}
+
+// updateStatus writes the scan status and counters and mirrors progress on the job
+func (r *scanRun) updateStatus(status, errorMsg string) {
+	progress := int(r.progress.Load())
+	_, err := r.queries.UpdateScan(r.writeCtx, db.UpdateScanParams{
+		ID:        r.scanUUID,
+		Status:    status,
+		Found:     pgtype.Int4{Int32: int32(r.found.Load()), Valid: true},
+		Processed: pgtype.Int4{Int32: int32(r.processed.Load()), Valid: true},
+		Progress:  pgtype.Int4{Int32: int32(progress), Valid: true},
+		Error:     pgtype.Text{String: errorMsg, Valid: errorMsg != ""},
+	})
+	if err != nil {
+		r.h.logger.Error("failed to update scan status", zap.Error(err))
 	}
+	r.job.SetProgress(progress)
+}
 
-	// Wait for all workers to finish
-	wg.Wait()
-	close(progressChan)
+// reportProgress streams and stores the counters. The total is only known once
+// the walk ends, so progress stays below 50% while walking and never goes back.
+func (r *scanRun) reportProgress() {
+	found, processed := r.found.Load(), r.processed.Load()
+	walking := r.walking.Load()
 
-	// Wait for progress updater to finish
-	time.Sleep(100 * time.Millisecond)
+	progress := int64(0)
+	if found > 0 {
+		progress = processed * 99 / found
+	}
+	if walking {
+		progress = min(progress, 50)
+	}
+	if progress < r.progress.Load() {
+		progress = r.progress.Load()
+	}
+	r.progress.Store(progress)
 
-	// Mark scan as completed
-	updateScanStatus("completed", len(files), processed, 100, "")
-	h.logger.Info("scan completed successfully",
-		zap.String("scan_id", scanID.String()),
-		zap.Int("files_processed", processed))
+	r.emit("progress", map[string]any{
+		"processed": processed,
+		"total":     found,
+		"progress":  progress,
+		"walking":   walking,
+	})
+	r.updateStatus("running", "")
+	r.h.logger.Debug("scan progress",
+		zap.String("scan_id", r.scanID.String()),
+		zap.Int64("processed", processed),
+		zap.Int64("found", found),
+		zap.Int64("progress", progress))
 }
 
-// discoverAndCreateFolderHierarchy discovers folders that contain files (or are ancestors of such folders)
-// and creates them with proper parent_folder_id relationships. Empty folders are NOT registered.
-func (h *Handler) discoverAndCreateFolderHierarchy(ctx context.Context, queries *db.Queries, files []scanner.FileInfo) (map[string]pgtype.UUID, error) {
-	rootDir := h.config.ScanRootDir
-	folderCache := make(map[string]pgtype.UUID)
+// recordChanges counts file changes and writes them to scan_events in one statement
+func (r *scanRun) recordChanges(changes []scanEvent) {
+	if len(changes) == 0 {
+		return
+	}
+	params := db.AddScanEventsParams{ScanID: r.scanUUID}
+	for _, c := range changes {
+		r.changes[c.change].Add(1)
+		params.FileIds = append(params.FileIds, c.fileID)
+		params.Events = append(params.Events, c.change)
+		params.Paths = append(params.Paths, c.path)
+		params.PreviousPaths = append(params.PreviousPaths, c.previousPath)
+		params.Errors = append(params.Errors, c.errorMsg)
+	}
+	if err := r.queries.AddScanEvents(r.writeCtx, params); err != nil {
+		r.h.logger.Error("failed to record scan events", zap.Int("count", len(changes)), zap.Error(err))
+	}
+}
 
-	// Helper to get folder info from path
-	getParentPath := func(fullPath string) (parentPath string, hasParent bool) {
-		dir := filepath.Dir(fullPath)
-		cleanRoot := filepath.Clean(rootDir)
-		cleanDir := filepath.Clean(dir)
+// fail records a file that could not be saved; it leaves the pipeline here
+func (r *scanRun) fail(item *scanItem, fileID pgtype.UUID, what string, err error) scanEvent {
+	r.h.logger.Error("failed to "+what,
+		zap.String("path", item.file.Path),
+		zap.Error(err))
+	r.warn(item.file.Path, "failed to %s: %v", what, err)
+	r.emit("file", map[string]any{"path": item.file.Path, "status": "failed"})
+	r.processed.Add(1)
+	return scanEvent{
+		fileID:       fileID,
+		change:       changeFailed,
+		path:         item.file.Path,
+		previousPath: item.previousPath,
+		errorMsg:     err.Error(),
+	}
+}
 
-		if cleanDir == cleanRoot || cleanDir == "." {
-			return "", false
+// match is the stage after the walk: it sees the walked paths in walk order and
+// picks up the stored file at each one (see fileIndex). Failing to read the
+// stored files of a directory stops the scan, since every file in it would look
+// added.
+func (r *scanRun) match(ctx context.Context, path string, send func(string) bool) {
+	if err := r.index.match(ctx, path); err != nil {
+		if ctx.Err() == nil {
+			r.listErr = err
+			r.cancel()
 		}
-		return cleanDir, true
+		return
 	}
+	send(path)
+}
 
-	// Build a set of all folder paths that contain files (directly or indirectly)
-	foldersToCreate := make(map[string]bool)
-
-	for _, file := range files {
-		if file.FolderPath == "" {
-			continue // File at root level, no folder needed
+// stat is the stat stage: it reads the size and modification time of a walked path
+func (r *scanRun) stat(path string, send func(scanner.FileInfo) bool) {
+	f, err := r.h.scanner.Stat(path)
+	if err != nil {
+		r.index.forget(path)
+		r.h.logger.Warn("error accessing path", zap.String("path", path), zap.Error(err))
+		r.warn(path, "cannot read file: %v", err)
+		if r.preview != nil {
+			r.preview.addIgnored(path, scanner.IgnoredUnreadable)
 		}
+		return
+	}
+	r.found.Add(1)
+	send(f)
+}
 
-		// Add the immediate parent folder
-		folderPath := filepath.Clean(file.FolderPath)
-		foldersToCreate[folderPath] = true
+// hash is the optional hash stage
+func (r *scanRun) hash(f scanner.FileInfo, send func(scanner.FileInfo) bool) {
+	sha, err := r.h.scanner.ComputeSHA256(f.Path)
+	if err != nil {
+		r.h.logger.Warn("failed to hash file", zap.String("path", f.Path), zap.Error(err))
+		r.warn(f.Path, "failed to hash file: %v", err)
+	}
+	f.SHA256 = sha
+	send(f)
+}
 
-		// Add all ancestor folders up to the root
-		currentPath := folderPath
-		for {
-			parentPath, hasParent := getParentPath(currentPath)
-			if !hasParent {
-				break
-			}
-			foldersToCreate[parentPath] = true
-			currentPath = parentPath
+// diff is the diff stage: it compares a file with the stored snapshot and drops
+// unchanged files from incremental scans
+func (r *scanRun) diff(ctx context.Context, f scanner.FileInfo, send func(*scanItem) bool) {
+	r.seeFolder(f.FolderPath)
+	change, stored, err := r.index.classify(ctx, f)
+	if err != nil && ctx.Err() == nil {
+		r.h.logger.Warn("failed to look up moved files", zap.String("path", f.Path), zap.Error(err))
+	}
+	if change == changeNone && r.opts.Incremental {
+		r.skipped.Add(1)
+		r.processed.Add(1)
+		if r.preview != nil {
+			r.preview.addFile(changeNone, f.Path, false, 0)
 		}
+		return
 	}
 
-	// Convert map to slice
-	var allFolders []string
-	for folderPath := range foldersToCreate {
-		allFolders = append(allFolders, folderPath)
+	item := &scanItem{file: f, change: change}
+	if stored != nil {
+		item.storedID = stored.ID
+		if change == changeMoved {
+			item.previousPath = stored.Path
+		}
 	}
+	send(item)
+}
 
-	h.logger.Info("discovered folders with files", zap.Int("count", len(allFolders)))
+// save is the save stage: it registers the folders of a batch, applies moves and
+// upserts the batch in one statement. Dry runs only record the changes.
+func (r *scanRun) save(ctx context.Context, batch []*scanItem, send func(*scanItem) bool) {
+	var changes []scanEvent
+	defer func() { r.recordChanges(changes) }()
 
-	// Sort folders by depth (shallowest first) to ensure parents are created before children
-	sort.Slice(allFolders, func(i, j int) bool {
-		depthI := strings.Count(allFolders[i], string(filepath.Separator))
-		depthJ := strings.Count(allFolders[j], string(filepath.Separator))
-		return depthI < depthJ
-	})
-
-	// Create folders in order (parents first, then children)
-	for _, folderPath := range allFolders {
-		// Determine parent_folder_id first (before checking if folder exists)
-		var parentFolderID pgtype.UUID
-		parentPath, hasParent := getParentPath(folderPath)
-		if hasParent {
-			if parentID, ok := folderCache[parentPath]; ok {
-				parentFolderID = parentID
-			} else {
-				h.logger.Warn("parent folder not in cache (order issue?)",
-					zap.String("folder", folderPath),
-					zap.String("parent", parentPath))
+	pending := make([]*scanItem, 0, len(batch))
+	for _, item := range batch {
+		folderID, err := r.folders.resolve(ctx, item.file.FolderPath)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
 			}
+			r.h.logger.Warn("failed to register folder",
+				zap.String("path", item.file.FolderPath),
+				zap.Error(err))
+			r.warn(item.file.Path, "folder %s not registered: %v", item.file.FolderPath, err)
 		}
-
-		// Check if already exists in DB
-		existing, err := queries.GetFolderByPath(ctx, folderPath)
-		if err == nil {
-			// Folder exists - update its parent_folder_id if needed
-			if existing.ParentFolderID != parentFolderID {
-				h.logger.Debug("updating folder parent",
-					zap.String("folder", folderPath),
-					zap.Bool("has_parent", hasParent))
-
-				_, err := queries.UpdateFolderParent(ctx, db.UpdateFolderParentParams{
-					ID:             existing.ID,
-					ParentFolderID: parentFolderID,
+		item.folderID = folderID
+
+		if r.opts.DryRun {
+			if item.change != changeNone {
+				changes = append(changes, scanEvent{
+					fileID:       item.storedID,
+					change:       item.change,
+					path:         item.file.Path,
+					previousPath: item.previousPath,
 				})
-				if err != nil {
-					h.logger.Error("failed to update folder parent",
-						zap.String("path", folderPath),
-						zap.Error(err))
-				}
 			}
-			folderCache[folderPath] = existing.ID
+			if !send(item) {
+				return
+			}
 			continue
 		}
 
-		// Create folder with parent_folder_id
-		folderName := filepath.Base(folderPath)
+		// Moved files keep their row (and categories): point it at the new path first
+		if item.change == changeMoved {
+			if err := r.queries.MoveFile(ctx, db.MoveFileParams{
+				ID:       item.storedID,
+				Path:     item.file.Path,
+				FolderID: folderID,
+			}); err != nil {
+				changes = append(changes, r.fail(item, item.storedID, "move file", err))
+				continue
+			}
+		}
+		pending = append(pending, item)
+	}
+	if len(pending) == 0 {
+		return
+	}
 
-		// Use CreateFolderWithParent if parent exists, otherwise CreateFolder
-		var created db.Folder
-		if hasParent && parentFolderID.Valid {
-			created, err = queries.CreateFolderWithParent(ctx, db.CreateFolderWithParentParams{
-				Name:           folderName,
-				Path:           folderPath,
-				ParentFolderID: parentFolderID,
+	params := db.UpsertFilesParams{}
+	for _, item := range pending {
+		f := item.file
+		params.Paths = append(params.Paths, f.Path)
+		params.FileNames = append(params.FileNames, f.FileName)
+		params.Types = append(params.Types, f.Type)
+		params.Sizes = append(params.Sizes, f.Size)
+		params.ModifiedAts = append(params.ModifiedAts, pgtype.Timestamptz{Time: f.ModifiedAt, Valid: true})
+		params.Sha256s = append(params.Sha256s, f.SHA256)
+		params.FolderIds = append(params.FolderIds, item.folderID)
+	}
+	rows, err := r.queries.UpsertFiles(ctx, params)
+	if err == nil {
+		ids := make(map[string]pgtype.UUID, len(rows))
+		for _, row := range rows {
+			ids[row.Path] = row.ID
+		}
+		for _, item := range pending {
+			item.fileID = ids[item.file.Path]
+		}
+	} else {
+		if ctx.Err() != nil {
+			return
+		}
+		// One bad row fails the whole statement: save the batch file by file to find it
+		r.h.logger.Warn("batch upsert failed, saving files one by one",
+			zap.Int("files", len(pending)),
+			zap.Error(err))
+		for _, item := range pending {
+			f := item.file
+			savedFile, err := r.queries.UpsertFile(ctx, db.UpsertFileParams{
+				Path:       f.Path,
+				FileName:   f.FileName,
+				Type:       f.Type,
+				Size:       f.Size,
+				ModifiedAt: pgtype.Timestamptz{Time: f.ModifiedAt, Valid: true},
+				Sha256:     pgtype.Text{String: f.SHA256, Valid: f.SHA256 != ""},
+				FolderID:   item.folderID,
 			})
-		} else {
-			created, err = queries.CreateFolder(ctx, db.CreateFolderParams{
-				Name: folderName,
-				Path: folderPath,
+			if err != nil {
+				changes = append(changes, r.fail(item, pgtype.UUID{}, "save file", err))
+				continue
+			}
+			item.fileID = savedFile.ID
+		}
+	}
+
+	for _, item := range pending {
+		if !item.fileID.Valid {
+			continue
+		}
+		if item.change != changeNone {
+			changes = append(changes, scanEvent{
+				fileID:       item.fileID,
+				change:       item.change,
+				path:         item.file.Path,
+				previousPath: item.previousPath,
 			})
 		}
+		if !send(item) {
+			return
+		}
+	}
+}
+
+// classify is the last stage: it classifies a saved file with AI. Classification
+// shares the worker pool with bulk reclassification, so both together stay
+// within the configured concurrency.
+func (r *scanRun) classify(ctx context.Context, item *scanItem) {
+	defer r.processed.Add(1)
+	f := item.file
+
+	if r.opts.DryRun {
+		classify := r.aiEnabled
+		if classify && item.storedID.Valid {
+			manual, err := r.queries.CountManualFileCategories(ctx, item.storedID)
+			classify = err != nil || manual == 0
+		}
+		r.preview.addFile(item.change, f.Path, classify, r.estimateCost(f.FileName))
+		r.emit("file", map[string]any{"path": f.Path, "change": item.change, "status": "dry_run"})
+		return
+	}
 
+	if !r.opts.classify() {
+		r.emit("file", map[string]any{"path": f.Path, "change": item.change, "status": "saved"})
+		return
+	}
+
+	// Manually assigned categories are never overwritten by AI
+	if manual, err := r.queries.CountManualFileCategories(ctx, item.fileID); err == nil && manual > 0 {
+		r.emit("file", map[string]any{"path": f.Path, "change": item.change, "status": "manual"})
+		return
+	}
+
+	if err := r.h.workers.Acquire(ctx); err != nil {
+		return
+	}
+	defer r.h.workers.Release()
+
+	// Classify file with OpenAI
+	var (
+		classifiedCategories []string
+		err                  error
+	)
+	if r.h.classifier.IsEnabled() {
+		classifiedCategories, err = r.h.classifier.Classify(ctx, f.FileName, r.catalog.Paths)
+		if errors.Is(err, ai.ErrBudgetExceeded) {
+			// Keep current categories and classify the file once budget is available
+			if err := r.queries.EnqueueClassification(ctx, db.EnqueueClassificationParams{
+				FileID: item.fileID,
+				ScanID: r.scanUUID,
+				Reason: "budget_exceeded",
+			}); err != nil {
+				r.h.logger.Error("failed to queue file for classification",
+					zap.String("file", f.FileName),
+					zap.Error(err))
+			}
+			r.queued.Add(1)
+			r.emit("file", map[string]any{"path": f.Path, "change": item.change, "status": "queued"})
+			return
+		}
 		if err != nil {
-			h.logger.Error("failed to create folder",
-				zap.String("path", folderPath),
+			r.h.logger.Warn("classification failed",
+				zap.String("file", f.FileName),
 				zap.Error(err))
-			continue
+			r.warn(f.Path, "classification failed: %v", err)
+			classifiedCategories = []string{"uncategorized"}
+		}
+		if len(classifiedCategories) == 0 {
+			classifiedCategories = []string{"uncategorized"}
 		}
+		if err == nil {
+			_ = r.queries.DequeueClassification(ctx, item.fileID)
+			_ = r.queries.MarkFileClassified(ctx, item.fileID)
+		}
+	} else {
+		classifiedCategories = []string{"uncategorized"}
+	}
 
-		folderCache[folderPath] = created.ID
-		h.logger.Debug("created folder",
-			zap.String("name", folderName),
-			zap.String("path", folderPath),
-			zap.Bool("has_parent", hasParent))
+	// Remove existing categories and add new ones
+	_ = r.queries.RemoveAIFileCategories(ctx, item.fileID)
+	for _, catName := range classifiedCategories {
+		if cat, ok := r.catalog.Lookup(catName); ok {
+			err = r.queries.AddFileCategory(ctx, db.AddFileCategoryParams{
+				FileID:     item.fileID,
+				CategoryID: cat.ID,
+			})
+			if err != nil {
+				r.h.logger.Error("failed to add category",
+					zap.String("file", f.FileName),
+					zap.String("category", catName),
+					zap.Error(err))
+			}
+		}
 	}
 
-	return folderCache, nil
+	r.h.logger.Debug("saved and classified file",
+		zap.String("path", f.Path),
+		zap.Strings("categories", classifiedCategories))
+	r.emit("file", map[string]any{"path": f.Path, "change": item.change, "status": "classified", "categories": classifiedCategories})
 }
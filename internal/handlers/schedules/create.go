@@ -0,0 +1,112 @@
+package schedules
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/handlers/scans"
+	"stl-manager/internal/schedule"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// ScheduleRequest is the body of create and update requests
+type ScheduleRequest struct {
+	Name     string        `json:"name"`
+	Cron     string        `json:"cron"`
+	Timezone string        `json:"timezone"`
+	Enabled  *bool         `json:"enabled"`
+	Options  scans.Options `json:"options"`
+}
+
+// ScheduleResponse exposes the stored options as JSON
+type ScheduleResponse struct {
+	db.ScanSchedule
+	Options json.RawMessage `json:"options"`
+}
+
+func newScheduleResponse(s db.ScanSchedule) ScheduleResponse {
+	return ScheduleResponse{ScanSchedule: s, Options: json.RawMessage(s.Options)}
+}
+
+// validated holds the normalized values of a ScheduleRequest
+type validated struct {
+	name     string
+	cron     string
+	timezone string
+	enabled  bool
+	options  []byte
+	nextRun  pgtype.Timestamptz
+}
+
+// validate checks the request and computes the next run. The returned message is
+// empty when the request is valid.
+func (req ScheduleRequest) validate() (validated, string) {
+	v := validated{
+		name:     strings.TrimSpace(req.Name),
+		cron:     strings.TrimSpace(req.Cron),
+		timezone: strings.TrimSpace(req.Timezone),
+		enabled:  req.Enabled == nil || *req.Enabled,
+	}
+	if v.name == "" {
+		return v, "name is required"
+	}
+	if v.cron == "" {
+		return v, "cron is required"
+	}
+	if v.timezone == "" {
+		v.timezone = "UTC"
+	}
+
+	next, err := schedule.NextRun(v.cron, v.timezone, time.Now())
+	if err != nil {
+		return v, "invalid schedule: " + err.Error()
+	}
+	if v.enabled {
+		v.nextRun = pgtype.Timestamptz{Time: next, Valid: true}
+	}
+
+	opts := req.Options
+	if err := opts.Normalize(); err != nil {
+		return v, "invalid options: " + err.Error()
+	}
+	v.options, _ = json.Marshal(opts)
+	return v, ""
+}
+
+func (h *Handler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	var req ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	v, msg := req.validate()
+	if msg != "" {
+		h.RespondError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	created, err := queries.CreateScanSchedule(ctx, db.CreateScanScheduleParams{
+		Name:     v.name,
+		Cron:     v.cron,
+		Timezone: v.timezone,
+		Options:  v.options,
+		Enabled:  v.enabled,
+		NextRun:  v.nextRun,
+	})
+	if err != nil {
+		h.logger.Error("failed to create scan schedule", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to create schedule")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusCreated, newScheduleResponse(created))
+}
@@ -0,0 +1,40 @@
+package schedules
+
+import (
+	"errors"
+	"net/http"
+
+	"stl-manager/internal/db"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// DeleteSchedule removes a schedule. Scans it started are kept.
+func (h *Handler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	scheduleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid schedule ID")
+		return
+	}
+	id := pgtype.UUID{Bytes: scheduleID, Valid: true}
+
+	if _, err := queries.GetScanSchedule(ctx, id); errors.Is(err, pgx.ErrNoRows) {
+		h.RespondError(w, http.StatusNotFound, "schedule not found")
+		return
+	}
+
+	if err := queries.DeleteScanSchedule(ctx, id); err != nil {
+		h.logger.Error("failed to delete scan schedule", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to delete schedule")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusOK, map[string]string{"message": "schedule deleted successfully"})
+}
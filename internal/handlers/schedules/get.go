@@ -0,0 +1,91 @@
+package schedules
+
+import (
+	"errors"
+	"net/http"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/pagination"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// sortByName tags cursors of the schedule list, by name
+const sortByName = "name"
+
+func (h *Handler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	// Parse pagination
+	query := r.URL.Query()
+	p, err := pagination.Parse(query, pagination.DefaultPageSize)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	after, err := p.After(sortByName)
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params := db.ListScanSchedulesParams{
+		Limit:  int32(p.Limit()),
+		Offset: int32(p.Offset()),
+	}
+	if after != nil {
+		params.AfterID, params.AfterName = after.ID, after.Key
+	}
+
+	schedules, err := queries.ListScanSchedules(ctx, params)
+	if err != nil {
+		h.logger.Error("failed to list scan schedules", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to list schedules")
+		return
+	}
+
+	total, err := queries.CountScanSchedules(ctx)
+	if err != nil {
+		h.logger.Error("failed to count scan schedules", zap.Error(err))
+		total = 0
+	}
+
+	schedules, next := pagination.Trim(schedules, p, func(s db.ScanSchedule) pagination.Cursor {
+		return pagination.NewCursor(sortByName, s.Name, s.ID)
+	})
+
+	items := make([]ScheduleResponse, len(schedules))
+	for i, s := range schedules {
+		items[i] = newScheduleResponse(s)
+	}
+
+	h.RespondJSON(w, http.StatusOK, p.Response(items, total, next))
+}
+
+func (h *Handler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	scheduleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid schedule ID")
+		return
+	}
+
+	s, err := queries.GetScanSchedule(ctx, pgtype.UUID{Bytes: scheduleID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.RespondError(w, http.StatusNotFound, "schedule not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to get scan schedule", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to get schedule")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusOK, newScheduleResponse(s))
+}
@@ -0,0 +1,60 @@
+package schedules
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"stl-manager/internal/db"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// UpdateSchedule replaces a schedule and recomputes its next run
+func (h *Handler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queries := db.New(h.pool)
+
+	scheduleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid schedule ID")
+		return
+	}
+
+	var req ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	v, msg := req.validate()
+	if msg != "" {
+		h.RespondError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	updated, err := queries.UpdateScanSchedule(ctx, db.UpdateScanScheduleParams{
+		ID:       pgtype.UUID{Bytes: scheduleID, Valid: true},
+		Name:     v.name,
+		Cron:     v.cron,
+		Timezone: v.timezone,
+		Options:  v.options,
+		Enabled:  v.enabled,
+		NextRun:  v.nextRun,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.RespondError(w, http.StatusNotFound, "schedule not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to update scan schedule", zap.Error(err))
+		h.RespondError(w, http.StatusInternalServerError, "failed to update schedule")
+		return
+	}
+
+	h.RespondJSON(w, http.StatusOK, newScheduleResponse(updated))
+}
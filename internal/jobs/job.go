@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"stl-manager/internal/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// Job is the handle a running job uses to report progress and write logs
+type Job struct {
+	ID      pgtype.UUID
+	Type    string
+	Payload []byte
+
+	manager *Manager
+	ctx     context.Context
+}
+
+// Decode unmarshals the job payload into v
+func (j *Job) Decode(v any) error {
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return fmt.Errorf("decode %s payload: %w", j.Type, err)
+	}
+	return nil
+}
+
+// SetProgress records progress as a percentage (0-100)
+func (j *Job) SetProgress(progress int) {
+	if err := db.New(j.manager.pool).UpdateJobProgress(j.ctx, db.UpdateJobProgressParams{
+		ID:       j.ID,
+		Progress: int32(progress),
+	}); err != nil {
+		j.manager.logger.Error("failed to update job progress", zap.Error(err))
+	}
+}
+
+// Info writes an informational log line to the job
+func (j *Job) Info(format string, args ...any) {
+	j.manager.log(j.ctx, j.ID, "info", fmt.Sprintf(format, args...))
+}
+
+// Warn writes a warning log line to the job
+func (j *Job) Warn(format string, args ...any) {
+	j.manager.log(j.ctx, j.ID, "warn", fmt.Sprintf(format, args...))
+}
+
+// Error writes an error log line to the job
+func (j *Job) Error(format string, args ...any) {
+	j.manager.log(j.ctx, j.ID, "error", fmt.Sprintf(format, args...))
+}
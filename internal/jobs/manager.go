@@ -0,0 +1,321 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"stl-manager/internal/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Job states
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+var (
+	// ErrAlreadyRunning is returned when an exclusive job of the same type is active
+	ErrAlreadyRunning = errors.New("a job of this type is already running")
+	// ErrNotFound is returned for unknown job IDs
+	ErrNotFound = errors.New("job not found")
+	// ErrFinished is returned when cancelling a job that already finished
+	ErrFinished = errors.New("job already finished")
+	// ErrUnknownType is returned when enqueuing a type that was never registered
+	ErrUnknownType = errors.New("unknown job type")
+)
+
+// Func runs a job. The context is cancelled when the job is cancelled or the
+// server shuts down. The returned result is stored as JSON on the job.
+type Func func(ctx context.Context, job *Job) (any, error)
+
+// Definition describes how a job type runs and recovers
+type Definition struct {
+	Run Func
+	// Exclusive allows only one queued or running job of this type at a time
+	Exclusive bool
+	// Resume restarts interrupted jobs on startup instead of marking them failed
+	Resume bool
+	// Cleanup runs on startup after interrupted jobs were handled, so the job type
+	// can fix up its own records (e.g. scans left as running)
+	Cleanup func(ctx context.Context) error
+}
+
+// Manager starts, tracks and cancels background jobs
+type Manager struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	defs     map[string]Definition
+	running  map[uuid.UUID]context.CancelFunc
+	stopping bool
+	wg       sync.WaitGroup
+}
+
+// NewManager creates a job manager
+func NewManager(pool *pgxpool.Pool, logger *zap.Logger) *Manager {
+	return &Manager{
+		pool:    pool,
+		logger:  logger,
+		defs:    make(map[string]Definition),
+		running: make(map[uuid.UUID]context.CancelFunc),
+	}
+}
+
+// Register adds a job type. It must be called before Enqueue or Recover.
+func (m *Manager) Register(jobType string, def Definition) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.defs[jobType] = def
+}
+
+// Enqueue stores a new job and starts it in the background
+func (m *Manager) Enqueue(ctx context.Context, jobType string, payload any) (db.Job, error) {
+	m.mu.Lock()
+	def, ok := m.defs[jobType]
+	m.mu.Unlock()
+	if !ok {
+		return db.Job{}, fmt.Errorf("%w: %s", ErrUnknownType, jobType)
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return db.Job{}, fmt.Errorf("encode payload: %w", err)
+	}
+
+	var exclusiveKey pgtype.Text
+	if def.Exclusive {
+		exclusiveKey = pgtype.Text{String: jobType, Valid: true}
+	}
+
+	job, err := db.New(m.pool).CreateJob(ctx, db.CreateJobParams{
+		Type:         jobType,
+		ExclusiveKey: exclusiveKey,
+		Payload:      payloadJSON,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return db.Job{}, ErrAlreadyRunning
+		}
+		return db.Job{}, err
+	}
+
+	m.start(job, def)
+	return job, nil
+}
+
+// Cancel stops a queued or running job
+func (m *Manager) Cancel(ctx context.Context, id uuid.UUID) error {
+	queries := db.New(m.pool)
+	job, err := queries.GetJob(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if job.Status != StatusQueued && job.Status != StatusRunning {
+		return ErrFinished
+	}
+
+	m.mu.Lock()
+	cancel, ok := m.running[id]
+	m.mu.Unlock()
+	if ok {
+		// The job goroutine records the cancelled state when it returns
+		cancel()
+		return nil
+	}
+
+	// Not running in this process, nothing left to stop
+	return queries.FinishJob(ctx, db.FinishJobParams{
+		ID:     job.ID,
+		Status: StatusCancelled,
+		Error:  pgtype.Text{String: "cancelled", Valid: true},
+	})
+}
+
+// Recover handles jobs left queued or running by a previous process. Resumable
+// types are restarted, everything else is marked failed. It then runs each
+// type's Cleanup.
+func (m *Manager) Recover(ctx context.Context) error {
+	queries := db.New(m.pool)
+	unfinished, err := queries.ListUnfinishedJobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defs := make(map[string]Definition, len(m.defs))
+	for jobType, def := range m.defs {
+		defs[jobType] = def
+	}
+	m.mu.Unlock()
+
+	for _, job := range unfinished {
+		def, ok := defs[job.Type]
+		if ok && def.Resume {
+			m.logger.Info("resuming job",
+				zap.String("job_id", uuid.UUID(job.ID.Bytes).String()),
+				zap.String("type", job.Type))
+			m.log(ctx, job.ID, "warn", "resumed after server restart")
+			m.start(job, def)
+			continue
+		}
+
+		m.logger.Warn("marking interrupted job as failed",
+			zap.String("job_id", uuid.UUID(job.ID.Bytes).String()),
+			zap.String("type", job.Type))
+		m.log(ctx, job.ID, "error", "interrupted by server restart")
+		if err := queries.FinishJob(ctx, db.FinishJobParams{
+			ID:     job.ID,
+			Status: StatusFailed,
+			Error:  pgtype.Text{String: "interrupted by server restart", Valid: true},
+		}); err != nil {
+			m.logger.Error("failed to mark job as failed", zap.Error(err))
+		}
+	}
+
+	for jobType, def := range defs {
+		if def.Cleanup == nil {
+			continue
+		}
+		if err := def.Cleanup(ctx); err != nil {
+			m.logger.Error("job cleanup failed", zap.String("type", jobType), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// Shutdown stops all running jobs and waits for them to return. Their state is
+// left as running so that Recover can pick them up on the next start.
+func (m *Manager) Shutdown(ctx context.Context) {
+	m.mu.Lock()
+	m.stopping = true
+	for _, cancel := range m.running {
+		cancel()
+	}
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		m.logger.Warn("timed out waiting for jobs to stop")
+	}
+}
+
+func (m *Manager) start(job db.Job, def Definition) {
+	id := uuid.UUID(job.ID.Bytes)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.mu.Lock()
+	m.running[id] = cancel
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer func() {
+			m.mu.Lock()
+			delete(m.running, id)
+			m.mu.Unlock()
+			cancel()
+		}()
+
+		queries := db.New(m.pool)
+		// Final state must be written even though ctx is cancelled by then
+		writeCtx := context.WithoutCancel(ctx)
+
+		if err := queries.StartJob(writeCtx, job.ID); err != nil {
+			m.logger.Error("failed to mark job as running", zap.Error(err))
+		}
+
+		handle := &Job{
+			ID:      job.ID,
+			Type:    job.Type,
+			Payload: job.Payload,
+			manager: m,
+			ctx:     writeCtx,
+		}
+		result, err := m.run(ctx, def.Run, handle)
+
+		m.mu.Lock()
+		stopping := m.stopping
+		m.mu.Unlock()
+		if stopping && ctx.Err() != nil {
+			// Interrupted by shutdown: leave it running for Recover
+			return
+		}
+
+		status := StatusCompleted
+		var errorMsg string
+		switch {
+		case ctx.Err() != nil:
+			status = StatusCancelled
+			errorMsg = "cancelled"
+		case err != nil:
+			status = StatusFailed
+			errorMsg = err.Error()
+		}
+
+		var resultJSON []byte
+		if result != nil {
+			resultJSON, _ = json.Marshal(result)
+		}
+
+		if err := queries.FinishJob(writeCtx, db.FinishJobParams{
+			ID:     job.ID,
+			Status: status,
+			Error:  pgtype.Text{String: errorMsg, Valid: errorMsg != ""},
+			Result: resultJSON,
+		}); err != nil {
+			m.logger.Error("failed to finish job", zap.Error(err))
+		}
+
+		m.logger.Info("job finished",
+			zap.String("job_id", id.String()),
+			zap.String("type", job.Type),
+			zap.String("status", status))
+	}()
+}
+
+// run calls fn and turns a panic into an error so one bad job can't crash the API
+func (m *Manager) run(ctx context.Context, fn Func, job *Job) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			m.logger.Error("job panicked", zap.Any("panic", r))
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx, job)
+}
+
+func (m *Manager) log(ctx context.Context, jobID pgtype.UUID, level, message string) {
+	if err := db.New(m.pool).AddJobLog(ctx, db.AddJobLogParams{
+		JobID:   jobID,
+		Level:   level,
+		Message: message,
+	}); err != nil {
+		m.logger.Error("failed to write job log", zap.Error(err))
+	}
+}
@@ -0,0 +1,117 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// MaxImageSize is the largest image accepted as an upload
+const MaxImageSize = 10 << 20
+
+var (
+	ErrNotImage = errors.New("file must be a JPEG, PNG, WebP or GIF image")
+	ErrTooLarge = fmt.Errorf("image must be at most %d MB", MaxImageSize>>20)
+)
+
+// imageExts maps the accepted image types, as sniffed from their content, to
+// the extension they are stored with
+var imageExts = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+	"image/gif":  ".gif",
+}
+
+// Store keeps uploaded files under a directory. Paths handed out are relative
+// to it, so the directory can move without touching the database.
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// SaveImage stores an image as kind/name plus the extension of its type and
+// returns the relative path and the content type. The type is detected from
+// the content, not from the upload's headers.
+func (s *Store) SaveImage(kind, name string, r io.Reader) (string, string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxImageSize+1))
+	if err != nil {
+		return "", "", err
+	}
+	if len(data) > MaxImageSize {
+		return "", "", ErrTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExts[contentType]
+	if !ok {
+		return "", "", ErrNotImage
+	}
+
+	rel := filepath.ToSlash(filepath.Join(kind, name+ext))
+	path := s.Path(rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", "", err
+	}
+	// Write next to the target and rename, so a failed upload never leaves a
+	// truncated image in place of the previous one
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", "", err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", "", err
+	}
+	return rel, contentType, nil
+}
+
+// Path returns the location on disk of a relative path
+func (s *Store) Path(rel string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(rel))
+}
+
+// Remove deletes a stored file. A file that is already gone is not an error.
+func (s *Store) Remove(rel string) error {
+	if err := os.Remove(s.Path(rel)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// FormImage returns the image uploaded in a multipart form field. Request
+// bodies larger than an image plus some form overhead are cut off.
+func FormImage(w http.ResponseWriter, r *http.Request, field string) (io.ReadCloser, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxImageSize+1<<20)
+	file, _, err := r.FormFile(field)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, ErrTooLarge
+		}
+		return nil, fmt.Errorf("multipart field %q with an image is required", field)
+	}
+	return file, nil
+}
+
+// Serve writes a stored file with its content type, supporting conditional
+// and range requests
+func (s *Store) Serve(w http.ResponseWriter, r *http.Request, rel, contentType string) error {
+	file, err := os.Open(s.Path(rel))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", contentType)
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+	return nil
+}
@@ -0,0 +1,139 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var (
+	ErrInvalidCursor  = errors.New("invalid cursor")
+	ErrCursorMismatch = errors.New("cursor does not match the requested sort")
+)
+
+// Cursor is a keyset position: the sort key and ID of the last item of the
+// previous page. Clients only see it encoded, as an opaque string.
+type Cursor struct {
+	// Sort identifies the ordering the cursor was issued for
+	Sort string      `json:"s"`
+	Key  string      `json:"k"`
+	ID   pgtype.UUID `json:"id"`
+}
+
+// NewCursor returns the cursor positioned after an item
+func NewCursor(sort, key string, id pgtype.UUID) Cursor {
+	return Cursor{Sort: sort, Key: key, ID: id}
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decode(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || !c.ID.Valid {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Params is the requested page. With a cursor the page continues after it
+// (keyset pagination); otherwise page and page_size select an offset.
+type Params struct {
+	Page     int
+	PageSize int
+	Cursor   *Cursor
+}
+
+// Parse reads page, page_size and cursor. Invalid page and page_size values
+// fall back to the defaults; an invalid cursor is an error.
+func Parse(values url.Values, defaultPageSize int) (Params, error) {
+	p := Params{Page: 1, PageSize: defaultPageSize}
+	if v := values.Get("page"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
+			p.Page = parsed
+		}
+	}
+	if v := values.Get("page_size"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 && parsed <= MaxPageSize {
+			p.PageSize = parsed
+		}
+	}
+	if v := values.Get("cursor"); v != "" {
+		cursor, err := decode(v)
+		if err != nil {
+			return Params{}, err
+		}
+		p.Cursor = cursor
+	}
+	return p, nil
+}
+
+// After returns the cursor to continue from, or nil for offset pagination.
+// A cursor issued for a different sort is rejected.
+func (p Params) After(sort string) (*Cursor, error) {
+	if p.Cursor == nil {
+		return nil, nil
+	}
+	if p.Cursor.Sort != sort {
+		return nil, ErrCursorMismatch
+	}
+	return p.Cursor, nil
+}
+
+// Offset is ignored when continuing from a cursor
+func (p Params) Offset() int {
+	if p.Cursor != nil {
+		return 0
+	}
+	return (p.Page - 1) * p.PageSize
+}
+
+// Limit fetches one row past the page to tell whether there is a next page
+func (p Params) Limit() int {
+	return p.PageSize + 1
+}
+
+// Trim cuts rows fetched with Limit down to the page and returns the encoded
+// cursor of the next page, or "" when this is the last one
+func Trim[T any](rows []T, p Params, cursor func(T) Cursor) ([]T, string) {
+	if len(rows) <= p.PageSize {
+		return rows, ""
+	}
+	rows = rows[:p.PageSize]
+	return rows, cursor(rows[len(rows)-1]).Encode()
+}
+
+// Response builds the list response body. page is null for cursor requests;
+// next_cursor is null on the last page.
+func (p Params) Response(items any, total int64, next string) map[string]any {
+	resp := map[string]any{
+		"items":       items,
+		"total":       total,
+		"page":        p.Page,
+		"page_size":   p.PageSize,
+		"total_pages": int((total + int64(p.PageSize) - 1) / int64(p.PageSize)),
+		"next_cursor": nil,
+	}
+	if p.Cursor != nil {
+		resp["page"] = nil
+	}
+	if next != "" {
+		resp["next_cursor"] = next
+	}
+	return resp
+}
@@ -0,0 +1,163 @@
+package scanner
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// imageTypes maps the extensions of preview images to their content type
+var imageTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".webp": "image/webp",
+	".gif":  "image/gif",
+}
+
+// imageDirs are subfolders whose images belong to the folder above them, as
+// in packs that keep their renders apart from the STLs
+var imageDirs = map[string]bool{
+	"images":   true,
+	"image":    true,
+	"img":      true,
+	"renders":  true,
+	"render":   true,
+	"pictures": true,
+	"photos":   true,
+	"preview":  true,
+	"previews": true,
+	"gallery":  true,
+}
+
+// Image is a preview image of a folder
+type Image struct {
+	// Name is the path relative to the folder, with forward slashes
+	Name       string
+	Size       int64
+	ModifiedAt time.Time
+}
+
+// IsImage reports whether a file name is a preview image ListImages keeps
+func IsImage(name string) bool {
+	_, ok := ImageContentType(name)
+	return ok
+}
+
+// ImageContentType returns the content type of an image from its extension
+func ImageContentType(name string) (string, bool) {
+	contentType, ok := imageTypes[strings.ToLower(filepath.Ext(name))]
+	return contentType, ok
+}
+
+// ListImages returns the images directly inside dir and inside its image
+// subfolders (Images, Renders, ...), sorted by name. Unreadable images and
+// subfolders are skipped.
+func (s *Scanner) ListImages(dir string) ([]Image, error) {
+	images, err := s.readImages(dir, "")
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !imageDirs[strings.ToLower(entry.Name())] {
+			continue
+		}
+		nested, err := s.readImages(filepath.Join(dir, entry.Name()), entry.Name())
+		if err != nil {
+			s.logger.Warn("failed to read image folder", zap.String("path", filepath.Join(dir, entry.Name())), zap.Error(err))
+			continue
+		}
+		images = append(images, nested...)
+	}
+
+	sort.Slice(images, func(i, j int) bool { return images[i].Name < images[j].Name })
+	return images, nil
+}
+
+// readImages returns the images directly inside dir, named with prefix
+func (s *Scanner) readImages(dir, prefix string) ([]Image, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []Image
+	for _, entry := range entries {
+		if entry.IsDir() || !IsImage(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			s.logger.Warn("failed to read image", zap.String("path", filepath.Join(dir, entry.Name())), zap.Error(err))
+			continue
+		}
+		images = append(images, Image{
+			Name:       path.Join(prefix, entry.Name()),
+			Size:       info.Size(),
+			ModifiedAt: info.ModTime(),
+		})
+	}
+	return images, nil
+}
+
+// primaryWords are the words that mark an image as the cover of its folder,
+// with how strongly
+var primaryWords = map[string]int{
+	"cover":     4,
+	"main":      3,
+	"hero":      3,
+	"preview":   3,
+	"thumbnail": 3,
+	"thumb":     3,
+	"render":    2,
+	"front":     1,
+}
+
+// PrimaryImage picks the cover of a folder among its images and returns its
+// name, or "" when there are none. Images named cover, main, preview and the
+// like win, then images named after the folder, then images directly inside
+// it; ties go to the largest image, which is usually the main render.
+func PrimaryImage(folderName string, images []Image) string {
+	folder := strings.Join(nameWords(folderName), " ")
+
+	best, bestScore := -1, -1
+	for i, image := range images {
+		words := nameWords(strings.TrimSuffix(path.Base(image.Name), path.Ext(image.Name)))
+
+		score := 0
+		for _, word := range words {
+			score = max(score, primaryWords[word]*10)
+		}
+		if folder != "" && strings.Join(words, " ") == folder {
+			score = max(score, 25)
+		}
+		if !strings.Contains(image.Name, "/") {
+			score++
+		}
+
+		if score > bestScore || (score == bestScore && image.Size > images[best].Size) {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return ""
+	}
+	return images[best].Name
+}
+
+// nameWords splits a file or folder name into lower case words
+func nameWords(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == '_' || r == '-' || r == ' ' || r == '.' || r == '(' || r == ')'
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -25,6 +26,22 @@ type FileInfo struct {
 	FolderName string // Name of parent folder (empty for root-level files)
 }
 
+// Reasons passed to an IgnoreFunc
+const (
+	IgnoredDirectory  = "excluded_directory"
+	IgnoredExtension  = "unsupported_extension"
+	IgnoredUnreadable = "unreadable"
+	// IgnoredSidecar marks README, LICENSE and similar files, which are not
+	// stored as files but read into their folder (see ReadSource)
+	IgnoredSidecar = "sidecar"
+	// IgnoredImage marks preview images, which are not stored as files but
+	// listed in their folder's gallery (see ListImages)
+	IgnoredImage = "image"
+)
+
+// IgnoreFunc receives every path a walk skips and the reason
+type IgnoreFunc func(path, reason string)
+
 type Scanner struct {
 	rootDir       string
 	supportedExts []string
@@ -39,12 +56,57 @@ func New(rootDir string, supportedExts []string, logger *zap.Logger) *Scanner {
 	}
 }
 
-func (s *Scanner) Scan(ctx context.Context) ([]FileInfo, error) {
-	var files []FileInfo
+// Walk sends the path of every supported file under dir to out, blocking while
+// out is full, and returns when the walk ends. It does not close out. Skipped
+// directories, unsupported files and unreadable paths are reported to ignored,
+// which may be nil.
+func (s *Scanner) Walk(ctx context.Context, dir string, ignored IgnoreFunc, out chan<- string) error {
+	return s.walk(ctx, dir, ignored, func(path string) error {
+		select {
+		case out <- path:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// Stat builds the FileInfo of a file found by Walk
+func (s *Scanner) Stat(path string) (FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	// Extract folder information
+	folderPath, folderName := s.extractFolderInfo(path)
+
+	s.logger.Debug("found file",
+		zap.String("path", path),
+		zap.Int64("size", info.Size()),
+	)
+
+	return FileInfo{
+		Path:       path,
+		FileName:   info.Name(),
+		Type:       s.getFileType(strings.ToLower(filepath.Ext(path))),
+		Size:       info.Size(),
+		ModifiedAt: info.ModTime(),
+		FolderPath: folderPath,
+		FolderName: folderName,
+	}, nil
+}
+
+// walk calls found with the path of every supported file under dir
+func (s *Scanner) walk(ctx context.Context, dir string, ignored IgnoreFunc, found func(path string) error) error {
+	if ignored == nil {
+		ignored = func(string, string) {}
+	}
 
-	err := filepath.Walk(s.rootDir, func(path string, info os.FileInfo, err error) error {
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			s.logger.Warn("error accessing path", zap.String("path", path), zap.Error(err))
+			ignored(path, IgnoredUnreadable)
 			return nil // Continue walking
 		}
 
@@ -56,59 +118,41 @@ func (s *Scanner) Scan(ctx context.Context) ([]FileInfo, error) {
 		}
 
 		// Skip directories
-		if info.IsDir() {
+		if d.IsDir() {
 			// Skip hidden directories and specific folders
-			dirName := filepath.Base(path)
+			dirName := d.Name()
 			if strings.HasPrefix(dirName, ".") ||
 				strings.HasPrefix(dirName, "$") ||
 				dirName == "stl-manager-backend" {
+				ignored(path, IgnoredDirectory)
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
-		// Check if file extension is supported
-		ext := strings.ToLower(filepath.Ext(path))
-		if !s.isSupported(ext) {
+		if IsSidecar(d.Name()) {
+			ignored(path, IgnoredSidecar)
 			return nil
 		}
-
-		// Determine file type
-		fileType := s.getFileType(ext)
-		if fileType == "" {
+		if IsImage(d.Name()) {
+			ignored(path, IgnoredImage)
 			return nil
 		}
 
-		// Extract folder information
-		folderPath, folderName := s.extractFolderInfo(path)
-
-		// Create FileInfo
-		fileInfo := FileInfo{
-			Path:       path,
-			FileName:   info.Name(),
-			Type:       fileType,
-			Size:       info.Size(),
-			ModifiedAt: info.ModTime(),
-			FolderPath: folderPath,
-			FolderName: folderName,
+		// Check if file extension is supported
+		ext := strings.ToLower(filepath.Ext(path))
+		if !s.isSupported(ext) || s.getFileType(ext) == "" {
+			ignored(path, IgnoredExtension)
+			return nil
 		}
 
-		files = append(files, fileInfo)
-
-		s.logger.Debug("found file",
-			zap.String("path", path),
-			zap.String("type", fileType),
-			zap.Int64("size", info.Size()),
-		)
-
-		return nil
+		return found(path)
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to walk directory: %w", err)
+		return fmt.Errorf("failed to walk directory: %w", err)
 	}
-
-	return files, nil
+	return nil
 }
 
 func (s *Scanner) isSupported(ext string) bool {
@@ -133,6 +177,36 @@ func (s *Scanner) getFileType(ext string) string {
 	}
 }
 
+// ContentsModTime returns when what a scan reads from dir itself last
+// changed: the latest modification time of dir and of its image subfolders,
+// which moves when files are added, removed or renamed, and of their sidecar
+// files and images, which moves when they are edited in place
+func (s *Scanner) ContentsModTime(dir string) (time.Time, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+	latest := info.ModTime()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, entry := range entries {
+		switch {
+		case entry.IsDir() && imageDirs[strings.ToLower(entry.Name())]:
+			if sub, err := s.ContentsModTime(filepath.Join(dir, entry.Name())); err == nil && sub.After(latest) {
+				latest = sub
+			}
+		case !entry.IsDir() && (IsSidecar(entry.Name()) || IsImage(entry.Name())):
+			if info, err := entry.Info(); err == nil && info.ModTime().After(latest) {
+				latest = info.ModTime()
+			}
+		}
+	}
+	return latest, nil
+}
+
 // ComputeSHA256 computes SHA256 hash of a file (optional, can be slow)
 func (s *Scanner) ComputeSHA256(path string) (string, error) {
 	file, err := os.Open(path)
@@ -0,0 +1,360 @@
+package scanner
+
+import (
+	"encoding/json"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// Licenses recognised in sidecar files. CC-BY covers its ShareAlike and
+// NoDerivatives variants, CC-BY-NC every NonCommercial one.
+const (
+	LicenseCC0        = "CC0"
+	LicenseCCBY       = "CC-BY"
+	LicenseCCBYNC     = "CC-BY-NC"
+	LicenseCommercial = "commercial"
+)
+
+// Licenses lists the recognised licenses
+var Licenses = []string{LicenseCC0, LicenseCCBY, LicenseCCBYNC, LicenseCommercial}
+
+// CommercialLicenses are the licenses that allow selling prints
+var CommercialLicenses = []string{LicenseCC0, LicenseCCBY, LicenseCommercial}
+
+const (
+	// maxSidecarSize caps how much of each sidecar file is read
+	maxSidecarSize = 256 << 10
+	// maxDescription caps the description kept from a sidecar, in runes
+	maxDescription = 2000
+)
+
+// Source is what the sidecar files of a folder say about its models. Empty
+// fields were not found.
+type Source struct {
+	Designer    string
+	URL         string
+	License     string
+	Description string
+	// Files are the names of the sidecar files read, sorted
+	Files []string
+}
+
+// sidecarKind orders sidecars by how much their values are trusted
+type sidecarKind int
+
+const (
+	sidecarMetadata sidecarKind = iota
+	sidecarLicense
+	sidecarAttribution
+	sidecarReadme
+)
+
+// sidecarKindOf recognises the README, LICENSE, Thingiverse attribution card
+// and Printables/Thingiverse metadata files that come with downloaded models
+func sidecarKindOf(name string) (sidecarKind, bool) {
+	lower := strings.ToLower(name)
+	ext := filepath.Ext(lower)
+	base := strings.TrimSuffix(lower, ext)
+	text := ext == "" || ext == ".txt" || ext == ".md"
+
+	switch {
+	case lower == "metadata.json" || lower == "printables.json" || lower == "thingiverse.json":
+		return sidecarMetadata, true
+	case (base == "license" || base == "licence") && text:
+		return sidecarLicense, true
+	case base == "attribution_card" && (ext == ".html" || ext == ".htm"):
+		return sidecarAttribution, true
+	case base == "readme" && text:
+		return sidecarReadme, true
+	}
+	return 0, false
+}
+
+// IsSidecar reports whether a file name is a sidecar ReadSource reads
+func IsSidecar(name string) bool {
+	_, ok := sidecarKindOf(name)
+	return ok
+}
+
+// ReadSource reads the sidecar files directly inside dir. Metadata files are
+// trusted over license files, those over attribution cards and those over
+// READMEs: each only fills what the previous ones left empty. Unreadable
+// sidecars are skipped.
+func (s *Scanner) ReadSource(dir string) (Source, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Source{}, err
+	}
+
+	byKind := map[sidecarKind][]string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if kind, ok := sidecarKindOf(entry.Name()); ok {
+			byKind[kind] = append(byKind[kind], entry.Name())
+		}
+	}
+
+	var src Source
+	for _, kind := range []sidecarKind{sidecarMetadata, sidecarLicense, sidecarAttribution, sidecarReadme} {
+		for _, name := range byKind[kind] {
+			data, err := readSidecar(filepath.Join(dir, name))
+			if err != nil {
+				s.logger.Warn("failed to read sidecar", zap.String("path", filepath.Join(dir, name)), zap.Error(err))
+				continue
+			}
+			src.Files = append(src.Files, name)
+			src.fill(parseSidecar(kind, data))
+		}
+	}
+	sort.Strings(src.Files)
+	return src, nil
+}
+
+// fill copies the fields of other that src does not have yet
+func (src *Source) fill(other Source) {
+	if src.Designer == "" {
+		src.Designer = other.Designer
+	}
+	if src.URL == "" {
+		src.URL = other.URL
+	}
+	if src.License == "" {
+		src.License = other.License
+	}
+	if src.Description == "" {
+		src.Description = other.Description
+	}
+}
+
+func readSidecar(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxSidecarSize))
+	if err != nil {
+		return "", err
+	}
+	return strings.ToValidUTF8(string(data), ""), nil
+}
+
+func parseSidecar(kind sidecarKind, data string) Source {
+	switch kind {
+	case sidecarMetadata:
+		return parseMetadata(data)
+	case sidecarLicense:
+		// License texts describe the license, not the model
+		return Source{Designer: findDesigner(data), URL: findSourceURL(data), License: detectLicense(data)}
+	case sidecarAttribution:
+		// The card links the model and the license; the text names the designer
+		text := html.UnescapeString(htmlTag.ReplaceAllString(data, " "))
+		text = strings.Join(strings.Fields(text), " ")
+		return Source{Designer: findDesigner(text), URL: findSourceURL(data), License: detectLicense(data)}
+	default:
+		return Source{
+			Designer:    findDesigner(data),
+			URL:         findSourceURL(data),
+			License:     detectLicense(data),
+			Description: findDescription(data),
+		}
+	}
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// parseMetadata reads the JSON exported with Printables and Thingiverse
+// models. Both name the same things differently, so the first key present
+// wins; nested objects such as {"user": {"name": ...}} are read by name.
+func parseMetadata(data string) Source {
+	var meta map[string]any
+	if err := json.Unmarshal([]byte(data), &meta); err != nil {
+		return Source{}
+	}
+
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if value := jsonString(meta[key]); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	src := Source{
+		Designer:    first("designer", "author", "creator", "user", "owner"),
+		URL:         first("source_url", "url", "public_url", "link"),
+		Description: truncate(strings.TrimSpace(htmlTag.ReplaceAllString(first("description", "summary"), ""))),
+	}
+	if license := first("license", "licence", "license_name"); license != "" {
+		src.License = detectLicense(license)
+	}
+	return src
+}
+
+// jsonString returns a JSON string, or the name of a JSON object
+func jsonString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case map[string]any:
+		for _, key := range []string{"name", "publicUsername", "username", "display_name", "url"} {
+			if s, ok := v[key].(string); ok && strings.TrimSpace(s) != "" {
+				return strings.TrimSpace(s)
+			}
+		}
+	}
+	return ""
+}
+
+var (
+	// Thingiverse license files: "created by Thingiverse user Name, and is licensed"
+	designerUser = regexp.MustCompile(`(?i)created by (?:thingiverse |printables )?user ([^,\n]+)`)
+	// "Designer: Name", "Designed by Name", ...
+	designerLine = regexp.MustCompile(`(?im)^[ \t]*(?:designer|author|creator|designed by|created by|made by)[ \t]*:?[ \t]+(\S[^\n]*)$`)
+	// Thingiverse READMEs and attribution cards: "Thing by Name on Thingiverse",
+	// "Thing by Name is licensed under ..."
+	designerBy = regexp.MustCompile(`(?i)\bby[ \t]+([^\n]+?)[ \t]+(?:on[ \t]+(?:thingiverse|printables|myminifactory|cults)|is[ \t]+licensed)`)
+	// A trailing "on Thingiverse: https://..." after a name
+	designerSuffix = regexp.MustCompile(`(?i)[ \t]+on[ \t]+\S+.*$`)
+)
+
+func findDesigner(text string) string {
+	for _, re := range []*regexp.Regexp{designerUser, designerLine, designerBy} {
+		if m := re.FindStringSubmatch(text); m != nil {
+			name := strings.TrimSpace(designerSuffix.ReplaceAllString(m[1], ""))
+			if name != "" && utf8.RuneCountInString(name) <= 100 {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+var (
+	urlPattern = regexp.MustCompile(`https?://[^\s"'<>()\[\]]+`)
+	// Model hosting sites, preferred over any other link
+	modelHosts = []string{"thingiverse.com", "printables.com", "myminifactory.com", "cults3d.com", "thangs.com", "makerworld.com"}
+)
+
+// findSourceURL returns the first link to a model hosting site, or else the
+// first link that is not a license
+func findSourceURL(text string) string {
+	var fallback string
+	for _, url := range urlPattern.FindAllString(text, -1) {
+		url = strings.TrimRight(url, ".,;:!?")
+		lower := strings.ToLower(url)
+		for _, host := range modelHosts {
+			if strings.Contains(lower, host) {
+				return url
+			}
+		}
+		if fallback == "" && !strings.Contains(lower, "creativecommons.org") {
+			fallback = url
+		}
+	}
+	return fallback
+}
+
+var (
+	licenseSeparators = strings.NewReplacer("-", " ", "_", " ", "–", " ", "—", " ")
+	licenseNC         = regexp.MustCompile(`\bnon ?commercial\b|\bcc by nc\b|licenses/by nc\b`)
+	licenseCC0        = regexp.MustCompile(`\bcc0\b|publicdomain/zero|\bpublic domain\b`)
+	licenseBY         = regexp.MustCompile(`\bcc by\b|\bcreative commons attribution\b|licenses/by\b`)
+	// Wording that keeps commercial use from the buyer: "personal use only",
+	// "commercial use requires a commercial license", "rights reserved", ...
+	notCommercial = regexp.MustCompile(strings.Join([]string{
+		`\bpersonal use\b`,
+		`\b(?:no|not|never|without|prohibited|forbidden) (?:for |a |any )?commercial\b`,
+		`\bcommercial (?:use|usage|rights|sales?)(?: is| are)? (?:not|prohibited|forbidden|restricted|reserved)\b`,
+		`\b(?:requires?|required|need|needs|buy|purchase|contact (?:us|me)(?: for)?|ask)(?: for)? (?:a |an |the )?(?:separate )?commercial (?:license|licence|tier|rights)\b`,
+		`\brights reserved\b`,
+	}, "|"))
+	// Explicit grants: "commercial use allowed", "commercial license included",
+	// "you may sell prints", ...
+	commercial = regexp.MustCompile(strings.Join([]string{
+		`\bcommercial (?:use|usage|sales?)(?: is| of prints is)? (?:allowed|permitted|granted|ok|okay|welcome)\b`,
+		`\bcommercial (?:license|licence|rights)(?: is| are)? (?:included|granted)\b`,
+		`\b(?:includes?|including|with) (?:a )?commercial (?:license|licence|rights)\b`,
+		`\b(?:licensed|allowed|permitted|approved|ok) for commercial use\b`,
+		`\b(?:may|can|are allowed to|are free to) sell (?:the )?(?:physical )?prints\b`,
+	}, "|"))
+)
+
+// detectLicense recognises a license from its name, its Creative Commons link
+// or the wording of a license text. Restrictions win over grants because
+// such texts often mention commercial use only to forbid it or to sell it
+// separately, and commercial is only returned for an explicit grant.
+func detectLicense(text string) string {
+	t := strings.Join(strings.Fields(licenseSeparators.Replace(strings.ToLower(text))), " ")
+
+	switch {
+	case licenseNC.MatchString(t):
+		return LicenseCCBYNC
+	case licenseCC0.MatchString(t):
+		return LicenseCC0
+	case licenseBY.MatchString(t):
+		return LicenseCCBY
+	case notCommercial.MatchString(t):
+		return ""
+	case commercial.MatchString(t):
+		return LicenseCommercial
+	}
+	return ""
+}
+
+// sectionHeading matches README headings such as "Print Settings" or "Post-Printing:"
+var sectionHeading = regexp.MustCompile(`^[A-Z][A-Za-z /&-]{0,40}:?$`)
+
+// findDescription returns the "Summary" or "Description" section of a README,
+// or else its first paragraph that does not name the designer, a link or the
+// license
+func findDescription(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	for i, line := range lines {
+		heading := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(line)), ":")
+		if heading != "summary" && heading != "description" {
+			continue
+		}
+		var section []string
+		for _, next := range lines[i+1:] {
+			trimmed := strings.TrimSpace(next)
+			previousBlank := len(section) > 0 && section[len(section)-1] == ""
+			if previousBlank && sectionHeading.MatchString(trimmed) {
+				break
+			}
+			section = append(section, trimmed)
+		}
+		if description := strings.TrimSpace(strings.Join(section, "\n")); description != "" {
+			return truncate(description)
+		}
+	}
+
+	for _, paragraph := range strings.Split(strings.Join(lines, "\n"), "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" || findDesigner(paragraph) != "" || urlPattern.MatchString(paragraph) || detectLicense(paragraph) != "" {
+			continue
+		}
+		return truncate(paragraph)
+	}
+	return ""
+}
+
+func truncate(s string) string {
+	if utf8.RuneCountInString(s) <= maxDescription {
+		return s
+	}
+	return strings.TrimSpace(string([]rune(s)[:maxDescription])) + "…"
+}
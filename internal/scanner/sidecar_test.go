@@ -0,0 +1,41 @@
+package scanner
+
+import "testing"
+
+func TestDetectLicense(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Creative Commons - Attribution - Non-Commercial", LicenseCCBYNC},
+		{"https://creativecommons.org/licenses/by-nc-sa/4.0/", LicenseCCBYNC},
+		{"Licensed under CC BY-SA 4.0", LicenseCCBY},
+		{"Creative Commons - Attribution - Share Alike", LicenseCCBY},
+		{"https://creativecommons.org/licenses/by/4.0/", LicenseCCBY},
+		{"CC0 1.0 Universal", LicenseCC0},
+		{"Released into the public domain", LicenseCC0},
+		{"Commercial use allowed.", LicenseCommercial},
+		{"Commercial use is permitted, credit appreciated", LicenseCommercial},
+		{"This pack includes a commercial license.", LicenseCommercial},
+		{"Merchant tier: commercial license included", LicenseCommercial},
+		{"You may sell prints of these models.", LicenseCommercial},
+		{"For personal use only. Commercial use requires a commercial license.", ""},
+		{"Contact us for commercial licence", ""},
+		{"Commercial rights are reserved", ""},
+		{"Commercial use is not allowed", ""},
+		{"Not for commercial use", ""},
+		{"No commercial use without permission", ""},
+		{"All rights reserved. Commercial use allowed for patrons", ""},
+		{"Buy the commercial tier to sell prints", ""},
+		{"A dragon miniature, 32mm scale", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := detectLicense(tt.text); got != tt.want {
+				t.Errorf("detectLicense(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package scanner
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Supports of a model variant, as told by its name
+const (
+	SupportsSupported    = "supported"
+	SupportsPresupported = "presupported"
+	SupportsUnsupported  = "unsupported"
+)
+
+// Variant is what the name of a model file says about it
+type Variant struct {
+	// Model is the name shared by every variant of the model
+	Model string
+	// Supports is SupportsSupported, SupportsPresupported, SupportsUnsupported
+	// or "" when the name does not say
+	Supports string
+	// Scale is the scale in the name ("75mm", "150%", "x2", "scaled") or ""
+	Scale string
+}
+
+// supportsWords maps the words, and pairs of words, that name supports
+var supportsWords = map[string]string{
+	"supported":     SupportsSupported,
+	"supports":      SupportsSupported,
+	"sup":           SupportsSupported,
+	"supp":          SupportsSupported,
+	"presupported":  SupportsPresupported,
+	"presupports":   SupportsPresupported,
+	"presup":        SupportsPresupported,
+	"pre supported": SupportsPresupported,
+	"pre supports":  SupportsPresupported,
+	"pre sup":       SupportsPresupported,
+	"unsupported":   SupportsUnsupported,
+	"nosupports":    SupportsUnsupported,
+	"nosupport":     SupportsUnsupported,
+	"no supports":   SupportsUnsupported,
+	"no support":    SupportsUnsupported,
+	"not supported": SupportsUnsupported,
+}
+
+// folderNoise are words that say nothing about the model, as in "STL" or
+// "Supported STLs" folders
+var folderNoise = map[string]bool{
+	"stl": true, "stls": true, "file": true, "files": true, "model": true, "models": true,
+	"version": true, "versions": true, "variant": true, "variants": true,
+}
+
+var (
+	scaleMM      = regexp.MustCompile(`^\d+(?:\.\d+)?mm$`)
+	scalePercent = regexp.MustCompile(`^(\d+(?:\.\d+)?)(?:%|pct|percent)$`)
+	scaleTimes   = regexp.MustCompile(`^(?:x(\d+(?:\.\d+)?)|(\d+(?:\.\d+)?)x)$`)
+	number       = regexp.MustCompile(`^\d+(?:\.\d+)?$`)
+)
+
+// ParseVariant reads a model file path, relative to the folder the model is
+// in and with forward slashes, into the model it belongs to and how it
+// differs from the other variants. Supports and scale are read from the file
+// name ("dragon_presupported.stl", "dragon_75mm.stl") and from variant
+// subfolders ("Pre-Supported/dragon.stl"); other subfolders are part of the
+// model name, so "Knight/base.stl" and "Archer/base.stl" stay apart.
+func ParseVariant(relPath string) Variant {
+	parts := strings.Split(relPath, "/")
+	base := parts[len(parts)-1]
+	if dot := strings.LastIndex(base, "."); dot > 0 {
+		base = base[:dot]
+	}
+
+	var v Variant
+	var model []string
+	for _, dir := range parts[:len(parts)-1] {
+		words, supports, scale := splitVariant(dir)
+		if v.Supports == "" {
+			v.Supports = supports
+		}
+		if v.Scale == "" {
+			v.Scale = scale
+		}
+		if !allNoise(words) {
+			model = append(model, strings.Join(words, " "))
+		}
+	}
+
+	// The file name wins over the folders
+	words, supports, scale := splitVariant(base)
+	if supports != "" {
+		v.Supports = supports
+	}
+	if scale != "" {
+		v.Scale = scale
+	}
+	if len(words) == 0 {
+		model = append(model, base)
+	} else {
+		model = append(model, strings.Join(words, " "))
+	}
+	v.Model = strings.Join(model, "/")
+	return v
+}
+
+// splitVariant splits a name into words and returns those that do not name
+// supports or a scale, with the supports and scale found
+func splitVariant(name string) (words []string, supports, scale string) {
+	original := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_' || r == '-' || r == ' ' || r == '.' || r == '(' || r == ')' || r == '[' || r == ']'
+	})
+	lower := make([]string, len(original))
+	for i, word := range original {
+		lower[i] = strings.ToLower(word)
+	}
+
+	for i := 0; i < len(lower); i++ {
+		if i+1 < len(lower) {
+			pair := lower[i] + " " + lower[i+1]
+			if s, ok := supportsWords[pair]; ok {
+				supports = s
+				i++
+				continue
+			}
+			// "28 mm", "scale 150", "scaled 2"
+			if lower[i+1] == "mm" && number.MatchString(lower[i]) {
+				scale = lower[i] + "mm"
+				i++
+				continue
+			}
+			if (lower[i] == "scale" || lower[i] == "scaled") && number.MatchString(lower[i+1]) {
+				scale = scaleOf(lower[i+1])
+				i++
+				continue
+			}
+		}
+		if s, ok := supportsWords[lower[i]]; ok {
+			supports = s
+			continue
+		}
+		if s := wordScale(lower[i]); s != "" {
+			scale = s
+			continue
+		}
+		words = append(words, original[i])
+	}
+	return words, supports, scale
+}
+
+// wordScale returns the scale a single word names, or ""
+func wordScale(word string) string {
+	switch {
+	case word == "scaled":
+		return "scaled"
+	case scaleMM.MatchString(word):
+		return word
+	}
+	if m := scalePercent.FindStringSubmatch(word); m != nil {
+		return m[1] + "%"
+	}
+	if m := scaleTimes.FindStringSubmatch(word); m != nil {
+		return "x" + m[1] + m[2]
+	}
+	return ""
+}
+
+// scaleOf reads the number after "scale": percentages from 10 up, factors below
+func scaleOf(n string) string {
+	if f, err := strconv.ParseFloat(n, 64); err == nil && f < 10 {
+		return "x" + n
+	}
+	return n + "%"
+}
+
+func allNoise(words []string) bool {
+	for _, word := range words {
+		if !folderNoise[strings.ToLower(word)] {
+			return false
+		}
+	}
+	return true
+}
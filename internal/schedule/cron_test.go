@@ -0,0 +1,169 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		// loc is the timezone Next runs in; nil is UTC
+		loc  *time.Location
+		from time.Time
+		want []time.Time
+	}{
+		{
+			name: "every 15 minutes",
+			expr: "*/15 * * * *",
+			from: time.Date(2026, 1, 1, 10, 7, 30, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 1, 1, 10, 15, 0, 0, time.UTC),
+				time.Date(2026, 1, 1, 10, 30, 0, 0, time.UTC),
+				time.Date(2026, 1, 1, 10, 45, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "strictly after a matching time",
+			expr: "0 3 * * *",
+			from: time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC),
+			want: []time.Time{time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "step from a start value",
+			expr: "5/20 9 * * *",
+			from: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 1, 1, 9, 5, 0, 0, time.UTC),
+				time.Date(2026, 1, 1, 9, 25, 0, 0, time.UTC),
+				time.Date(2026, 1, 1, 9, 45, 0, 0, time.UTC),
+				time.Date(2026, 1, 2, 9, 5, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "month and day names",
+			expr: "0 12 * feb-mar mon,fri",
+			from: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 2, 2, 12, 0, 0, 0, time.UTC),
+				time.Date(2026, 2, 6, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "7 is sunday",
+			expr: "0 0 * * 7",
+			from: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "day of month or day of week",
+			expr: "0 0 13 * fri",
+			from: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 2, 6, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 2, 13, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "day of month only",
+			expr: "0 0 31 * *",
+			from: time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "macro",
+			expr: "@monthly",
+			from: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "time skipped by spring forward",
+			loc:  newYork,
+			expr: "30 2 * * *",
+			from: time.Date(2026, 3, 8, 5, 0, 0, 0, time.UTC),
+			want: []time.Time{time.Date(2026, 3, 9, 2, 30, 0, 0, newYork)},
+		},
+		{
+			name: "hourly across spring forward",
+			loc:  newYork,
+			expr: "0 * * * *",
+			from: time.Date(2026, 3, 8, 0, 30, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, 3, 8, 1, 0, 0, 0, newYork),
+				time.Date(2026, 3, 8, 3, 0, 0, 0, newYork),
+				time.Date(2026, 3, 8, 4, 0, 0, 0, newYork),
+			},
+		},
+		{
+			name: "time repeated by fall back runs once",
+			loc:  newYork,
+			expr: "30 1 * * *",
+			from: time.Date(2026, 11, 1, 0, 0, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC),
+				time.Date(2026, 11, 2, 1, 30, 0, 0, newYork),
+			},
+		},
+		{
+			name: "hourly across fall back runs every hour",
+			loc:  newYork,
+			expr: "0 * * * *",
+			from: time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC),
+				time.Date(2026, 11, 1, 7, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			loc := tt.loc
+			if loc == nil {
+				loc = time.UTC
+			}
+
+			prev := tt.from.In(loc)
+			for _, want := range tt.want {
+				next := cron.Next(prev)
+				if !next.After(prev) {
+					t.Fatalf("Next(%v) = %v, not after it", prev, next)
+				}
+				if !next.Equal(want) {
+					t.Fatalf("Next(%v) = %v, want %v", prev, next, want)
+				}
+				prev = next
+			}
+		})
+	}
+}
+
+func TestCronNextNeverMatches(t *testing.T) {
+	cron, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := cron.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !next.IsZero() {
+		t.Fatalf("Next = %v, want zero time", next)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "* * * * funday"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want error", expr)
+		}
+	}
+}
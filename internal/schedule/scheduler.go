@@ -0,0 +1,131 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/jobs"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Last run states stored on a schedule
+const (
+	StatusStarted = "started"
+	StatusSkipped = "skipped"
+	StatusFailed  = "failed"
+)
+
+// checkInterval is how often due schedules are looked up
+const checkInterval = 30 * time.Second
+
+// StartFunc starts the scan of a due schedule. It returns jobs.ErrAlreadyRunning
+// when a scan is already queued or running.
+type StartFunc func(ctx context.Context, schedule db.ScanSchedule) (db.Scan, error)
+
+// Scheduler runs due scan schedules inside the API process
+type Scheduler struct {
+	pool   *pgxpool.Pool
+	start  StartFunc
+	logger *zap.Logger
+}
+
+// NewScheduler creates a scheduler that starts scans with start
+func NewScheduler(pool *pgxpool.Pool, start StartFunc, logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		pool:   pool,
+		start:  start,
+		logger: logger,
+	}
+}
+
+// Run checks for due schedules until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		s.RunDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue starts every schedule whose next_run has passed. Missed runs (e.g. while
+// the server was down) are not backfilled: a schedule runs once and moves on.
+func (s *Scheduler) RunDue(ctx context.Context) {
+	queries := db.New(s.pool)
+
+	due, err := queries.ListDueScanSchedules(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("failed to list due scan schedules", zap.Error(err))
+		}
+		return
+	}
+
+	for _, schedule := range due {
+		scheduleID := uuid.UUID(schedule.ID.Bytes).String()
+
+		next, err := NextRun(schedule.Cron, schedule.Timezone, time.Now())
+		nextRun := pgtype.Timestamptz{Time: next, Valid: err == nil}
+		if err != nil {
+			s.logger.Error("invalid scan schedule, disabling next run",
+				zap.String("schedule_id", scheduleID),
+				zap.Error(err))
+		}
+
+		// Claiming moves next_run forward first, so only one process runs it
+		claimed, err := queries.ClaimScanSchedule(ctx, db.ClaimScanScheduleParams{
+			NextRun: nextRun,
+			ID:      schedule.ID,
+			DueAt:   schedule.NextRun,
+		})
+		if err != nil {
+			s.logger.Error("failed to claim scan schedule", zap.String("schedule_id", scheduleID), zap.Error(err))
+			continue
+		}
+		if claimed == 0 {
+			continue
+		}
+
+		status, errorMsg := StatusStarted, ""
+		scan, err := s.start(ctx, schedule)
+		switch {
+		case errors.Is(err, jobs.ErrAlreadyRunning):
+			status, errorMsg = StatusSkipped, "a scan is already running"
+		case err != nil:
+			status, errorMsg = StatusFailed, err.Error()
+		}
+
+		if err := queries.SetScanScheduleResult(ctx, db.SetScanScheduleResultParams{
+			ID:         schedule.ID,
+			LastStatus: pgtype.Text{String: status, Valid: true},
+			LastError:  pgtype.Text{String: errorMsg, Valid: errorMsg != ""},
+		}); err != nil {
+			s.logger.Error("failed to record scan schedule result", zap.Error(err))
+		}
+
+		fields := []zap.Field{
+			zap.String("schedule_id", scheduleID),
+			zap.String("name", schedule.Name),
+			zap.String("status", status),
+			zap.Time("next_run", next),
+		}
+		if scan.ID.Valid {
+			fields = append(fields, zap.String("scan_id", uuid.UUID(scan.ID.Bytes).String()))
+		}
+		if errorMsg != "" {
+			fields = append(fields, zap.String("error", errorMsg))
+		}
+		s.logger.Info("scheduled scan", fields...)
+	}
+}
@@ -0,0 +1,215 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"stl-manager/internal/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Facets are aggregate counts over every file matching a filter, not only
+// the current page
+type Facets struct {
+	Categories []CategoryFacet `json:"categories"`
+	Types      []TypeFacet     `json:"types"`
+	Folders    []FolderFacet   `json:"folders"`
+	Sizes      []SizeFacet     `json:"sizes"`
+}
+
+// CategoryFacet counts the files in a category or any of its descendants,
+// matching what ?category=<name> would return
+type CategoryFacet struct {
+	ID       pgtype.UUID `json:"id"`
+	Name     string      `json:"name"`
+	ParentID pgtype.UUID `json:"parent_id"`
+	Count    int64       `json:"count"`
+}
+
+type TypeFacet struct {
+	Type  string `json:"type"`
+	Count int64  `json:"count"`
+}
+
+// FolderFacet counts the files anywhere under a root folder. Files outside
+// any folder are not counted.
+type FolderFacet struct {
+	ID    pgtype.UUID `json:"id"`
+	Name  string      `json:"name"`
+	Count int64       `json:"count"`
+}
+
+// SizeFacet counts the files in a size range. MinSize and MaxSize are
+// inclusive and map onto the min_size and max_size filters; MaxSize is nil
+// for the last bucket.
+type SizeFacet struct {
+	Key     string `json:"key"`
+	MinSize int64  `json:"min_size"`
+	MaxSize *int64 `json:"max_size"`
+	Count   int64  `json:"count"`
+}
+
+const mb = 1 << 20
+
+// sizeBuckets are the size facet ranges. Each bucket ends where the next starts.
+var sizeBuckets = []struct {
+	key string
+	min int64
+}{
+	{"lt_1mb", 0},
+	{"1mb_10mb", 1 * mb},
+	{"10mb_100mb", 10 * mb},
+	{"gte_100mb", 100 * mb},
+}
+
+// WantFacets reads the facets query parameter
+func WantFacets(values url.Values) (bool, error) {
+	v := values.Get("facets")
+	if v == "" {
+		return false, nil
+	}
+	want, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errors.New("facets must be true or false")
+	}
+	return want, nil
+}
+
+// FileFacets counts the files matching the filter by category, type, root
+// folder and size bucket. Counts are aggregated in SQL.
+func FileFacets(ctx context.Context, conn db.DBTX, f Filter) (*Facets, error) {
+	b := newBuilder(f)
+	where := b.whereClause()
+
+	facets := &Facets{}
+	var err error
+	if facets.Categories, err = categoryFacets(ctx, conn, where, b.args); err != nil {
+		return nil, fmt.Errorf("category facets: %w", err)
+	}
+	if facets.Types, err = typeFacets(ctx, conn, where, b.args); err != nil {
+		return nil, fmt.Errorf("type facets: %w", err)
+	}
+	if facets.Folders, err = folderFacets(ctx, conn, where, b.args); err != nil {
+		return nil, fmt.Errorf("folder facets: %w", err)
+	}
+	if facets.Sizes, err = sizeFacets(ctx, conn, where, b.args); err != nil {
+		return nil, fmt.Errorf("size facets: %w", err)
+	}
+	return facets, nil
+}
+
+// categoryFacets credits each file to its categories and all their
+// ancestors, counting a file once per category
+func categoryFacets(ctx context.Context, conn db.DBTX, where string, args []any) ([]CategoryFacet, error) {
+	query := `WITH RECURSIVE matched AS (
+  SELECT f.id FROM files f
+  ` + where + `
+),
+ancestry AS (
+  SELECT id AS category_id, id AS ancestor_id FROM categories
+  UNION ALL
+  SELECT a.category_id, c.parent_id FROM ancestry a
+  INNER JOIN categories c ON c.id = a.ancestor_id
+  WHERE c.parent_id IS NOT NULL
+)
+SELECT c.id, c.name, c.parent_id, COUNT(DISTINCT fc.file_id) AS count
+FROM matched m
+INNER JOIN files_categories fc ON fc.file_id = m.id
+INNER JOIN ancestry a ON a.category_id = fc.category_id
+INNER JOIN categories c ON c.id = a.ancestor_id AND c.deleted_at IS NULL
+GROUP BY c.id, c.name, c.parent_id
+ORDER BY count DESC, c.name ASC`
+
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (CategoryFacet, error) {
+		var i CategoryFacet
+		err := row.Scan(&i.ID, &i.Name, &i.ParentID, &i.Count)
+		return i, err
+	})
+}
+
+func typeFacets(ctx context.Context, conn db.DBTX, where string, args []any) ([]TypeFacet, error) {
+	query := `SELECT f.type, COUNT(*) AS count
+FROM files f
+` + where + `
+GROUP BY f.type
+ORDER BY count DESC, f.type ASC`
+
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (TypeFacet, error) {
+		var i TypeFacet
+		err := row.Scan(&i.Type, &i.Count)
+		return i, err
+	})
+}
+
+func folderFacets(ctx context.Context, conn db.DBTX, where string, args []any) ([]FolderFacet, error) {
+	query := `WITH RECURSIVE tree AS (
+  SELECT id, id AS root_id FROM folders WHERE parent_folder_id IS NULL
+  UNION ALL
+  SELECT sub.id, tree.root_id FROM folders sub
+  INNER JOIN tree ON sub.parent_folder_id = tree.id
+)
+SELECT root.id, root.name, COUNT(*) AS count
+FROM files f
+INNER JOIN tree ON tree.id = f.folder_id
+INNER JOIN folders root ON root.id = tree.root_id
+` + where + `
+GROUP BY root.id, root.name
+ORDER BY count DESC, root.name ASC`
+
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (FolderFacet, error) {
+		var i FolderFacet
+		err := row.Scan(&i.ID, &i.Name, &i.Count)
+		return i, err
+	})
+}
+
+// sizeFacets returns every bucket, including empty ones, from a single row
+// of filtered counts
+func sizeFacets(ctx context.Context, conn db.DBTX, where string, args []any) ([]SizeFacet, error) {
+	counts := make([]string, len(sizeBuckets))
+	for i, bucket := range sizeBuckets {
+		cond := fmt.Sprintf("f.size >= %d", bucket.min)
+		if i+1 < len(sizeBuckets) {
+			cond += fmt.Sprintf(" AND f.size < %d", sizeBuckets[i+1].min)
+		}
+		counts[i] = "COUNT(*) FILTER (WHERE " + cond + ")"
+	}
+	query := "SELECT " + strings.Join(counts, ", ") + "\nFROM files f\n" + where
+
+	values := make([]int64, len(sizeBuckets))
+	dest := make([]any, len(values))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := conn.QueryRow(ctx, query, args...).Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	facets := make([]SizeFacet, len(sizeBuckets))
+	for i, bucket := range sizeBuckets {
+		facets[i] = SizeFacet{Key: bucket.key, MinSize: bucket.min, Count: values[i]}
+		if i+1 < len(sizeBuckets) {
+			max := sizeBuckets[i+1].min - 1
+			facets[i].MaxSize = &max
+		}
+	}
+	return facets, nil
+}
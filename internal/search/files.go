@@ -0,0 +1,342 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/pagination"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fileColumns matches the field order of db.File
+const fileColumns = `f.id, f.path, f.file_name, f.type, f.size, f.modified_at, f.sha256,
+  f.folder_id, f.created_at, f.updated_at, f.classified_at, f.favorite, f.rating, f.note`
+
+// categorySubtree selects the categories named by a text[] parameter, the
+// categories merged into them and all their active descendants
+const categorySubtree = `WITH RECURSIVE subtree AS (
+      SELECT id FROM categories
+      WHERE deleted_at IS NULL AND (name = ANY(%[1]s::text[]::citext[]) OR id IN (
+        SELECT category_id FROM category_aliases WHERE alias = ANY(%[1]s::text[]::citext[])
+      ))
+      UNION ALL
+      SELECT c.id FROM categories c
+      INNER JOIN subtree ON c.parent_id = subtree.id
+      WHERE c.deleted_at IS NULL
+    )
+    SELECT id FROM subtree`
+
+// builder collects WHERE conditions and their positional arguments
+type builder struct {
+	conds []string
+	args  []any
+	// query is the placeholder of the text query, reused to rank by relevance
+	query string
+}
+
+// arg adds a query argument and returns its placeholder
+func (b *builder) arg(v any) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *builder) where(format string, args ...any) {
+	b.conds = append(b.conds, fmt.Sprintf(format, args...))
+}
+
+func (b *builder) whereClause() string {
+	if len(b.conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conds, "\n  AND ")
+}
+
+// likeEscaper escapes ILIKE wildcards in user input
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// newBuilder translates a filter into conditions on files aliased as f
+func newBuilder(f Filter) *builder {
+	b := &builder{}
+
+	// A text query matches by substring, by trigram similarity (typos) or by
+	// words of the file's search document: its tokenised name, folders and
+	// categories
+	if f.Query != "" {
+		b.query = b.arg(f.Query)
+		like := b.arg("%" + likeEscaper.Replace(f.Query) + "%")
+		b.where(`(f.file_name ILIKE %[1]s OR f.file_name %% %[2]s OR f.path %% %[2]s OR EXISTS (
+    SELECT 1 FROM file_search fs
+    WHERE fs.file_id = f.id AND fs.document @@ search_query(%[2]s)
+  ))`, like, b.query)
+	}
+	if f.Type != "" {
+		b.where("f.type = %s", b.arg(f.Type))
+	}
+
+	if len(f.Categories) > 0 {
+		hasCategory := `EXISTS (
+    SELECT 1 FROM files_categories fc
+    WHERE fc.file_id = f.id AND fc.category_id IN (
+    ` + categorySubtree + `
+    )
+  )`
+		if f.MatchAll {
+			for _, name := range f.Categories {
+				b.where(hasCategory, b.arg([]string{name}))
+			}
+		} else {
+			b.where(hasCategory, b.arg(f.Categories))
+		}
+	}
+	if f.Uncategorized {
+		b.where(`NOT EXISTS (
+    SELECT 1 FROM files_categories fc
+    INNER JOIN categories c ON c.id = fc.category_id
+    WHERE fc.file_id = f.id AND c.name <> 'uncategorized'
+  )`)
+	}
+
+	if f.FolderID != uuid.Nil {
+		b.where(`f.folder_id IN (
+    WITH RECURSIVE subtree AS (
+      SELECT id FROM folders WHERE id = %[1]s
+      UNION ALL
+      SELECT sub.id FROM folders sub
+      INNER JOIN subtree ON sub.parent_folder_id = subtree.id
+    )
+    SELECT id FROM subtree
+  )`, b.arg(pgtype.UUID{Bytes: f.FolderID, Valid: true}))
+	}
+
+	if f.CollectionID != uuid.Nil {
+		b.where(`(f.id IN (
+    SELECT file_id FROM collection_items WHERE collection_id = %[1]s AND file_id IS NOT NULL
+  ) OR f.folder_id IN (
+    WITH RECURSIVE subtree AS (
+      SELECT folder_id AS id FROM collection_items WHERE collection_id = %[1]s AND folder_id IS NOT NULL
+      UNION ALL
+      SELECT sub.id FROM folders sub
+      INNER JOIN subtree ON sub.parent_folder_id = subtree.id
+    )
+    SELECT id FROM subtree
+  ))`, b.arg(pgtype.UUID{Bytes: f.CollectionID, Valid: true}))
+	}
+
+	switch f.Printed {
+	case PrintedNever:
+		b.where("NOT EXISTS (SELECT 1 FROM prints p WHERE p.file_id = f.id)")
+	case PrintedAny:
+		b.where("EXISTS (SELECT 1 FROM prints p WHERE p.file_id = f.id)")
+	case PrintedSuccess:
+		b.where("EXISTS (SELECT 1 FROM prints p WHERE p.file_id = f.id AND p.result = 'success')")
+	}
+
+	if f.Favorite != nil {
+		b.where("f.favorite = %s", b.arg(*f.Favorite))
+	}
+	if f.MinRating > 0 {
+		b.where("f.rating >= %s", b.arg(f.MinRating))
+	}
+	// Values are compared as text so "true" and "1.5" match booleans and numbers
+	for _, key := range slices.Sorted(maps.Keys(f.Fields)) {
+		b.where(`EXISTS (
+    SELECT 1 FROM custom_field_values v
+    INNER JOIN custom_fields cf ON cf.id = v.field_id
+    WHERE v.file_id = f.id AND cf.key = %s AND lower(v.value #>> '{}') = lower(%s)
+  )`, b.arg(key), b.arg(f.Fields[key]))
+	}
+
+	// A folder's license covers the folders below it until one has its own
+	if len(f.Licenses) > 0 {
+		b.where(`f.folder_id IN (
+    WITH RECURSIVE licensed AS (
+      SELECT id FROM folders WHERE source_license = ANY(%s)
+      UNION ALL
+      SELECT sub.id FROM folders sub
+      INNER JOIN licensed ON sub.parent_folder_id = licensed.id
+      WHERE sub.source_license = ''
+    )
+    SELECT id FROM licensed
+  )`, b.arg(f.Licenses))
+	}
+
+	if f.RootFolderName != "" {
+		b.where(`f.folder_id IN (
+    WITH RECURSIVE subtree AS (
+      SELECT id FROM folders
+      WHERE parent_folder_id IS NULL AND (name ILIKE %[1]s
+        OR to_tsvector('simple', search_tokens(name)) @@ search_query(%[2]s))
+      UNION ALL
+      SELECT sub.id FROM folders sub
+      INNER JOIN subtree ON sub.parent_folder_id = subtree.id
+    )
+    SELECT id FROM subtree
+  )`, b.arg("%"+likeEscaper.Replace(f.RootFolderName)+"%"), b.arg(f.RootFolderName))
+	}
+
+	if f.MinSize != nil {
+		b.where("f.size >= %s", b.arg(*f.MinSize))
+	}
+	if f.MaxSize != nil {
+		b.where("f.size <= %s", b.arg(*f.MaxSize))
+	}
+	if f.ModifiedAfter != nil {
+		b.where("f.modified_at >= %s", b.arg(*f.ModifiedAfter))
+	}
+	if f.ModifiedBefore != nil {
+		b.where("f.modified_at < %s", b.arg(*f.ModifiedBefore))
+	}
+
+	return b
+}
+
+// sortKey returns the expression files are ordered by and its SQL type, used
+// to compare it against a cursor
+func (b *builder) sortKey(s Sort) (expr, typ string) {
+	switch {
+	case s.Field == SortSize:
+		return "f.size", "bigint"
+	case s.Field == SortModified:
+		return "f.modified_at", "timestamptz"
+	case s.Field == SortRelevance && b.query != "":
+		// Text rank (name words weigh most) plus trigram similarity, so
+		// both word matches and near-misses rank
+		return fmt.Sprintf(`(COALESCE((
+    SELECT ts_rank(fs.document, search_query(%[1]s)) FROM file_search fs WHERE fs.file_id = f.id
+  ), 0) + similarity(f.file_name, %[1]s))`, b.query), "real"
+	default:
+		return "f.file_name", "text"
+	}
+}
+
+// cursorSort tags cursors with the sort and direction they were issued for
+func cursorSort(s Sort) string {
+	if s.Desc {
+		return s.Field + ":desc"
+	}
+	return s.Field + ":asc"
+}
+
+// after restricts the results to files past the cursor. The file ID breaks
+// ties, in the same direction as the sort key, so pages are stable.
+func (b *builder) after(s Sort, c *pagination.Cursor) {
+	expr, typ := b.sortKey(s)
+	cmp := ">"
+	if s.Desc {
+		cmp = "<"
+	}
+	b.where("(%s, f.id) %s (%s::text::%s, %s::uuid)", expr, cmp, b.arg(c.Key), typ, b.arg(c.ID))
+}
+
+func (b *builder) orderBy(s Sort) string {
+	expr, _ := b.sortKey(s)
+	dir := "ASC"
+	if s.Desc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf("ORDER BY %[1]s %[2]s, f.id %[2]s", expr, dir)
+}
+
+// Files returns one page of files matching the filter, the exact number of
+// matches and the cursor of the next page ("" on the last page)
+func Files(ctx context.Context, conn db.DBTX, f Filter, s Sort, p pagination.Params) ([]db.File, int64, string, error) {
+	tag := cursorSort(s)
+	after, err := p.After(tag)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	total, err := Count(ctx, conn, f)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	b := newBuilder(f)
+
+	if after != nil {
+		b.after(s, after)
+	}
+	key, _ := b.sortKey(s)
+	query := fmt.Sprintf("SELECT %s, %s::text AS sort_key FROM files f\n%s\n%s\nLIMIT %s OFFSET %s",
+		fileColumns, key, b.whereClause(), b.orderBy(s), b.arg(p.Limit()), b.arg(p.Offset()))
+	rows, err := conn.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("search files: %w", err)
+	}
+	results, err := pgx.CollectRows(rows, scanResult)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("search files: %w", err)
+	}
+
+	results, next := pagination.Trim(results, p, func(r result) pagination.Cursor {
+		return pagination.NewCursor(tag, r.sortKey, r.ID)
+	})
+	files := make([]db.File, len(results))
+	for i, r := range results {
+		files[i] = r.File
+	}
+	return files, total, next, nil
+}
+
+// Select returns the files matching the filter, by path, up to limit of
+// them. It is how bulk actions pick the same files GET /v1/files lists.
+func Select(ctx context.Context, conn db.DBTX, f Filter, limit int) ([]db.File, error) {
+	b := newBuilder(f)
+	query := fmt.Sprintf("SELECT %s FROM files f\n%s\nORDER BY f.path, f.id\nLIMIT %s",
+		fileColumns, b.whereClause(), b.arg(limit))
+	rows, err := conn.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("select files: %w", err)
+	}
+	files, err := pgx.CollectRows(rows, pgx.RowToStructByPos[db.File])
+	if err != nil {
+		return nil, fmt.Errorf("select files: %w", err)
+	}
+	return files, nil
+}
+
+// Count returns the number of files matching the filter
+func Count(ctx context.Context, conn db.DBTX, f Filter) (int64, error) {
+	b := newBuilder(f)
+	var total int64
+	if err := conn.QueryRow(ctx, "SELECT COUNT(*) FROM files f "+b.whereClause(), b.args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("count files: %w", err)
+	}
+	return total, nil
+}
+
+// result is a file with its sort key rendered as text, which the database
+// casts back when the key is used in a cursor
+type result struct {
+	db.File
+	sortKey string
+}
+
+func scanResult(row pgx.CollectableRow) (result, error) {
+	var i result
+	err := row.Scan(
+		&i.ID,
+		&i.Path,
+		&i.FileName,
+		&i.Type,
+		&i.Size,
+		&i.ModifiedAt,
+		&i.Sha256,
+		&i.FolderID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClassifiedAt,
+		&i.Favorite,
+		&i.Rating,
+		&i.Note,
+		&i.sortKey,
+	)
+	return i, err
+}
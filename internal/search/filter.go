@@ -0,0 +1,351 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"stl-manager/internal/scanner"
+
+	"github.com/google/uuid"
+)
+
+// Sort fields for file results
+const (
+	SortName      = "name"
+	SortSize      = "size"
+	SortModified  = "modified"
+	SortRelevance = "relevance"
+)
+
+// Category match modes
+const (
+	MatchAny = "any"
+	MatchAll = "all"
+)
+
+// Print history filters
+const (
+	PrintedNever   = "never"
+	PrintedAny     = "any"
+	PrintedSuccess = "success"
+)
+
+// Filter selects files. Zero values do not filter.
+type Filter struct {
+	// Query matches file names by substring or trigram similarity
+	Query string
+	Type  string
+	// Categories are category names; each also matches its descendant
+	// categories and the names merged into it
+	Categories []string
+	// MatchAll requires every category instead of any of them
+	MatchAll bool
+	// Uncategorized keeps files without categories other than "uncategorized"
+	Uncategorized bool
+	FolderID      uuid.UUID
+	// CollectionID keeps the files of a collection: its file items and the
+	// files anywhere under its folder items
+	CollectionID uuid.UUID
+	// RootFolderName keeps files under root folders whose name contains it,
+	// the way GET /v1/browse searches
+	RootFolderName string
+	MinSize        *int64
+	MaxSize        *int64
+	ModifiedAfter  *time.Time
+	ModifiedBefore *time.Time
+	// Printed keeps files never printed, printed at least once, or printed
+	// successfully at least once
+	Printed string
+	// Favorite keeps files marked or not marked as favourite
+	Favorite  *bool
+	MinRating int16
+	// Fields keeps files whose custom field values match, case-insensitively,
+	// every given value by field key
+	Fields map[string]string
+	// Licenses keeps files whose license, read by scans from the sidecar files
+	// of their nearest folder that has one, is one of these
+	Licenses []string
+}
+
+// Keys are the query parameters Parse reads, in the order they are documented
+var Keys = []string{
+	"q", "type", "category", "category_match", "uncategorized", "folder_id",
+	"collection_id", "min_size", "max_size", "modified_after", "modified_before", "printed",
+	"favorite", "min_rating", "field", "license", "commercial", "sort", "order",
+}
+
+// Values turns stored filters (parameter name to value, as a saved search
+// keeps them) back into query parameters. Keys Parse does not read are
+// rejected so a typo does not silently widen the search.
+func Values(filters map[string]string) (url.Values, error) {
+	values := url.Values{}
+	for key, value := range filters {
+		if !slices.Contains(Keys, key) {
+			return nil, fmt.Errorf("unknown filter %q", key)
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			values.Set(key, value)
+		}
+	}
+	return values, nil
+}
+
+// Sort orders file results. Relevance only applies with a text query.
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Parse reads a filter and sort from query parameters:
+// q, type, category (comma separated or repeated), category_match,
+// uncategorized, folder_id, collection_id, min_size, max_size, modified_after,
+// modified_before, printed, favorite, min_rating, field (key:value, repeated),
+// license, commercial, sort and order
+func Parse(values url.Values) (Filter, Sort, error) {
+	f := Filter{
+		Query: strings.TrimSpace(values.Get("q")),
+		Type:  strings.ToLower(strings.TrimSpace(values.Get("type"))),
+	}
+
+	for _, value := range values["category"] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				f.Categories = append(f.Categories, name)
+			}
+		}
+	}
+
+	switch values.Get("category_match") {
+	case "", MatchAny:
+	case MatchAll:
+		f.MatchAll = true
+	default:
+		return Filter{}, Sort{}, errors.New("category_match must be any or all")
+	}
+
+	if v := values.Get("uncategorized"); v != "" {
+		uncategorized, err := strconv.ParseBool(v)
+		if err != nil {
+			return Filter{}, Sort{}, errors.New("uncategorized must be true or false")
+		}
+		f.Uncategorized = uncategorized
+	}
+	if f.Uncategorized && len(f.Categories) > 0 {
+		return Filter{}, Sort{}, errors.New("uncategorized cannot be combined with category")
+	}
+
+	if v := values.Get("folder_id"); v != "" {
+		folderID, err := uuid.Parse(v)
+		if err != nil {
+			return Filter{}, Sort{}, errors.New("invalid folder_id")
+		}
+		f.FolderID = folderID
+	}
+	if v := values.Get("collection_id"); v != "" {
+		collectionID, err := uuid.Parse(v)
+		if err != nil {
+			return Filter{}, Sort{}, errors.New("invalid collection_id")
+		}
+		f.CollectionID = collectionID
+	}
+
+	var err error
+	if f.MinSize, err = parseSize(values, "min_size"); err != nil {
+		return Filter{}, Sort{}, err
+	}
+	if f.MaxSize, err = parseSize(values, "max_size"); err != nil {
+		return Filter{}, Sort{}, err
+	}
+	if f.MinSize != nil && f.MaxSize != nil && *f.MinSize > *f.MaxSize {
+		return Filter{}, Sort{}, errors.New("min_size cannot be greater than max_size")
+	}
+
+	if f.ModifiedAfter, err = parseTime(values, "modified_after"); err != nil {
+		return Filter{}, Sort{}, err
+	}
+	if f.ModifiedBefore, err = parseTime(values, "modified_before"); err != nil {
+		return Filter{}, Sort{}, err
+	}
+	if f.ModifiedAfter != nil && f.ModifiedBefore != nil && f.ModifiedAfter.After(*f.ModifiedBefore) {
+		return Filter{}, Sort{}, errors.New("modified_after cannot be later than modified_before")
+	}
+
+	if f.Printed, err = ParsePrinted(values.Get("printed")); err != nil {
+		return Filter{}, Sort{}, err
+	}
+
+	if v := values.Get("favorite"); v != "" {
+		favorite, err := strconv.ParseBool(v)
+		if err != nil {
+			return Filter{}, Sort{}, errors.New("favorite must be true or false")
+		}
+		f.Favorite = &favorite
+	}
+	if v := values.Get("min_rating"); v != "" {
+		rating, err := strconv.ParseInt(v, 10, 16)
+		if err != nil || rating < 1 || rating > 5 {
+			return Filter{}, Sort{}, errors.New("min_rating must be between 1 and 5")
+		}
+		f.MinRating = int16(rating)
+	}
+	for _, v := range values["field"] {
+		key, value, ok := strings.Cut(v, ":")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return Filter{}, Sort{}, errors.New("field must be key:value")
+		}
+		if f.Fields == nil {
+			f.Fields = map[string]string{}
+		}
+		f.Fields[key] = value
+	}
+
+	if f.Licenses, err = ParseLicenses(values); err != nil {
+		return Filter{}, Sort{}, err
+	}
+
+	s, err := parseSort(values, f)
+	if err != nil {
+		return Filter{}, Sort{}, err
+	}
+	return f, s, nil
+}
+
+// parseSort defaults to relevance when there is a text query and to name otherwise
+func parseSort(values url.Values, f Filter) (Sort, error) {
+	s := Sort{Field: values.Get("sort")}
+	switch s.Field {
+	case "":
+		s.Field = SortName
+		if f.Query != "" {
+			s.Field = SortRelevance
+		}
+	case SortName, SortSize, SortModified:
+	case SortRelevance:
+		if f.Query == "" {
+			return Sort{}, errors.New("sort=relevance requires q")
+		}
+	default:
+		return Sort{}, errors.New("sort must be name, size, modified or relevance")
+	}
+
+	switch values.Get("order") {
+	case "":
+		// Relevance reads best highest first, the rest ascending
+		s.Desc = s.Field == SortRelevance
+	case "asc":
+	case "desc":
+		s.Desc = true
+	default:
+		return Sort{}, errors.New("order must be asc or desc")
+	}
+	return s, nil
+}
+
+// ParsePrinted validates the printed parameter; empty does not filter
+func ParsePrinted(v string) (string, error) {
+	switch v {
+	case "", PrintedNever, PrintedAny, PrintedSuccess:
+		return v, nil
+	}
+	return "", errors.New("printed must be never, any or success")
+}
+
+// ParseLicenses reads the license (comma separated) and commercial
+// parameters into the licenses to keep; nil does not filter. commercial=true
+// keeps the licenses that allow selling prints.
+func ParseLicenses(values url.Values) ([]string, error) {
+	var licenses []string
+	if v := values.Get("license"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			i := slices.IndexFunc(scanner.Licenses, func(license string) bool {
+				return strings.EqualFold(license, name)
+			})
+			if i < 0 {
+				return nil, fmt.Errorf("license must be one of: %s", strings.Join(scanner.Licenses, ", "))
+			}
+			licenses = append(licenses, scanner.Licenses[i])
+		}
+	}
+
+	if v := values.Get("commercial"); v != "" {
+		commercial, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.New("commercial must be true or false")
+		}
+		if commercial {
+			if licenses == nil {
+				return slices.Clone(scanner.CommercialLicenses), nil
+			}
+			licenses = slices.DeleteFunc(licenses, func(license string) bool {
+				return !slices.Contains(scanner.CommercialLicenses, license)
+			})
+			if len(licenses) == 0 {
+				return nil, errors.New("license does not allow commercial use")
+			}
+		}
+	}
+	return licenses, nil
+}
+
+func parseSize(values url.Values, key string) (*int64, error) {
+	v := values.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	size, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || size < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative number of bytes", key)
+	}
+	return &size, nil
+}
+
+// parseTime accepts RFC3339 timestamps, plain dates (YYYY-MM-DD, UTC
+// midnight) and dates relative to now, so saved searches stay current:
+// today, this_week (from Monday), this_month, this_year, or -Nd for N days ago
+func parseTime(values url.Values, key string) (*time.Time, error) {
+	v := values.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	if t, ok := relativeTime(v, time.Now().UTC()); ok {
+		return &t, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%s must be an RFC3339 timestamp, a YYYY-MM-DD date, today, this_week, this_month, this_year or -Nd", key)
+}
+
+// relativeTime resolves a relative date against now. Periods start at UTC
+// midnight.
+func relativeTime(v string, now time.Time) (time.Time, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch v {
+	case "today":
+		return today, true
+	case "this_week":
+		// time.Weekday counts from Sunday; weeks start on Monday
+		return today.AddDate(0, 0, -(int(today.Weekday())+6)%7), true
+	case "this_month":
+		return today.AddDate(0, 0, 1-today.Day()), true
+	case "this_year":
+		return today.AddDate(0, 0, 1-today.YearDay()), true
+	}
+	if days, ok := strings.CutPrefix(v, "-"); ok {
+		if n, ok := strings.CutSuffix(days, "d"); ok {
+			if parsed, err := strconv.Atoi(n); err == nil && parsed >= 0 {
+				return now.AddDate(0, 0, -parsed), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,96 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Token is a word of a name. Start and End are character (rune) offsets
+// into the original name.
+type Token struct {
+	Text  string
+	Start int
+	End   int
+}
+
+// Range is a highlighted part of a name, in character offsets
+type Range struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Tokenize splits a name into lowercase words the way the database indexes
+// it (search_tokens in migrations/017_create_file_search.sql): on any
+// non-alphanumeric character, at camelCase boundaries ("OrcWarrior",
+// "STLFile") and between letters and digits ("v2", "32mm"). The two must
+// stay in sync.
+func Tokenize(name string) []Token {
+	runes := []rune(name)
+	tokens := []Token{}
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			tokens = append(tokens, Token{
+				Text:  strings.ToLower(string(runes[start:end])),
+				Start: start,
+				End:   end,
+			})
+			start = -1
+		}
+	}
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush(i)
+			continue
+		}
+		if start >= 0 && isBoundary(runes, i) {
+			flush(i)
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	flush(len(runes))
+	return tokens
+}
+
+// isBoundary reports whether a new word starts at runes[i], given that
+// runes[i-1] is part of the current word
+func isBoundary(runes []rune, i int) bool {
+	prev, cur := runes[i-1], runes[i]
+	switch {
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return true
+	case unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+		return true
+	case unicode.IsLetter(prev) && unicode.IsDigit(cur), unicode.IsDigit(prev) && unicode.IsLetter(cur):
+		return true
+	}
+	return false
+}
+
+// Highlight returns the parts of name that match a word of query. Like the
+// database query, each query word matches as a prefix, so "warr" highlights
+// the "Warr" of "Orc_Warrior".
+func Highlight(name, query string) []Range {
+	words := Tokenize(query)
+	if len(words) == 0 {
+		return nil
+	}
+
+	ranges := []Range{}
+	for _, token := range Tokenize(name) {
+		longest := 0
+		for _, word := range words {
+			if strings.HasPrefix(token.Text, word.Text) && len(word.Text) > longest {
+				longest = len(word.Text)
+			}
+		}
+		if longest > 0 {
+			end := token.Start + len([]rune(token.Text[:longest]))
+			ranges = append(ranges, Range{Start: token.Start, End: end})
+		}
+	}
+	return ranges
+}
@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"sync"
+)
+
+// Pool bounds how many tasks run at once across every caller that shares it.
+// Scans and bulk reclassification use the same pool so that together they
+// never exceed the configured concurrency.
+type Pool struct {
+	sem chan struct{}
+}
+
+// NewPool creates a pool that runs at most size tasks concurrently
+func NewPool(size int) *Pool {
+	if size <= 0 {
+		size = 1
+	}
+	return &Pool{sem: make(chan struct{}, size)}
+}
+
+// Size returns the maximum number of concurrent tasks
+func (p *Pool) Size() int {
+	return cap(p.sem)
+}
+
+// Run calls task for every index in [0, n) and blocks until all started tasks
+// have returned. onDone, if not nil, is called after each task with the number
+// of tasks finished so far; calls are serialized so it can update shared state.
+// No new tasks are started once ctx is cancelled, and ctx.Err() is returned.
+func (p *Pool) Run(ctx context.Context, n int, task func(i int), onDone func(done int)) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+	)
+
+	for i := 0; i < n; i++ {
+		select {
+		case p.sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-p.sem }()
+
+			task(i)
+
+			if onDone != nil {
+				mu.Lock()
+				done++
+				onDone(done)
+				mu.Unlock()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	return ctx.Err()
+}
+
+// Acquire blocks until a task slot is free or ctx is cancelled. Streaming callers
+// that do not know the number of tasks up front use it instead of Run, and must
+// call Release after every successful Acquire.
+func (p *Pool) Acquire(ctx context.Context) error {
+	select {
+	case p.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot taken with Acquire
+func (p *Pool) Release() {
+	<-p.sem
+}
@@ -0,0 +1,26 @@
+-- Migration: Track AI usage and queue files skipped by the spend cap
+-- Description: Daily token/spend totals for OpenAI calls and a queue of files
+-- whose classification was postponed because a budget was exhausted
+
+-- Up Migration
+CREATE TABLE IF NOT EXISTS ai_usage_daily (
+    day DATE PRIMARY KEY,
+    requests INT NOT NULL DEFAULT 0,
+    prompt_tokens BIGINT NOT NULL DEFAULT 0,
+    completion_tokens BIGINT NOT NULL DEFAULT 0,
+    cost_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS classification_queue (
+    file_id UUID PRIMARY KEY REFERENCES files(id) ON DELETE CASCADE,
+    scan_id UUID REFERENCES scans(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL,
+    queued_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_classification_queue_queued_at ON classification_queue(queued_at);
+
+-- Down Migration
+-- DROP TABLE IF EXISTS classification_queue;
+-- DROP TABLE IF EXISTS ai_usage_daily;
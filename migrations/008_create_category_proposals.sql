@@ -0,0 +1,27 @@
+-- Migration: Create category proposals
+-- Description: New categories suggested by AI from uncategorized files, stored for review
+
+-- Up Migration
+CREATE TABLE IF NOT EXISTS category_proposals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name CITEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','accepted','rejected')),
+    category_id UUID REFERENCES categories(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Only one pending proposal per name
+CREATE UNIQUE INDEX IF NOT EXISTS idx_category_proposals_pending_name ON category_proposals(name) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_category_proposals_status ON category_proposals(status);
+
+CREATE TABLE IF NOT EXISTS category_proposal_files (
+    proposal_id UUID NOT NULL REFERENCES category_proposals(id) ON DELETE CASCADE,
+    file_id UUID NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+    PRIMARY KEY (proposal_id, file_id)
+);
+
+-- Down Migration
+-- DROP TABLE IF EXISTS category_proposal_files;
+-- DROP TABLE IF EXISTS category_proposals;
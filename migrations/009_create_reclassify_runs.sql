@@ -0,0 +1,36 @@
+-- Migration: Bulk reclassification runs and manual category assignments
+-- Description: Tracks background reclassification runs, records whether a file
+-- category was assigned by AI or by hand, and when a file was last classified
+
+-- Up Migration
+ALTER TABLE files_categories ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'ai'
+    CHECK (source IN ('ai','manual'));
+ALTER TABLE files ADD COLUMN IF NOT EXISTS classified_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_files_categories_manual ON files_categories(file_id) WHERE source = 'manual';
+CREATE INDEX IF NOT EXISTS idx_files_classified_at ON files(classified_at);
+
+CREATE TABLE IF NOT EXISTS reclassify_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    status TEXT NOT NULL DEFAULT 'running',
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    filter JSONB NOT NULL DEFAULT '{}',
+    total INT NOT NULL DEFAULT 0,
+    processed INT NOT NULL DEFAULT 0,
+    changed INT NOT NULL DEFAULT 0,
+    queued INT NOT NULL DEFAULT 0,
+    progress INT NOT NULL DEFAULT 0,
+    error TEXT,
+    report JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reclassify_runs_created_at ON reclassify_runs(created_at DESC);
+
+-- Down Migration
+-- DROP TABLE IF EXISTS reclassify_runs;
+-- DROP INDEX IF EXISTS idx_files_classified_at;
+-- DROP INDEX IF EXISTS idx_files_categories_manual;
+-- ALTER TABLE files DROP COLUMN IF EXISTS classified_at;
+-- ALTER TABLE files_categories DROP COLUMN IF EXISTS source;
@@ -0,0 +1,44 @@
+-- Migration: Persistent background jobs
+-- Description: Generic job records with progress, logs and cancellation. Scans,
+-- bulk reclassification and category analysis run as jobs so they survive restarts.
+
+-- Up Migration
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued','running','completed','failed','cancelled')),
+    exclusive_key TEXT,
+    payload JSONB NOT NULL DEFAULT '{}',
+    result JSONB,
+    progress INT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Single-runner guard: only one active job per exclusive key
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_exclusive_active ON jobs(exclusive_key)
+    WHERE exclusive_key IS NOT NULL AND status IN ('queued','running');
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status);
+CREATE INDEX IF NOT EXISTS idx_jobs_created_at ON jobs(created_at DESC);
+
+CREATE TABLE IF NOT EXISTS job_logs (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    level TEXT NOT NULL DEFAULT 'info',
+    message TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_logs_job_id ON job_logs(job_id, id);
+
+ALTER TABLE scans ADD COLUMN IF NOT EXISTS job_id UUID REFERENCES jobs(id) ON DELETE SET NULL;
+ALTER TABLE reclassify_runs ADD COLUMN IF NOT EXISTS job_id UUID REFERENCES jobs(id) ON DELETE SET NULL;
+
+-- Down Migration
+-- ALTER TABLE reclassify_runs DROP COLUMN IF EXISTS job_id;
+-- ALTER TABLE scans DROP COLUMN IF EXISTS job_id;
+-- DROP TABLE IF EXISTS job_logs;
+-- DROP TABLE IF EXISTS jobs;
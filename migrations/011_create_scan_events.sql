@@ -0,0 +1,20 @@
+-- Migration: Per-file scan event log
+-- Description: Records every file a scan added, updated, moved, found removed or
+-- failed to save, so GET /v1/scans/{id}/report can show exactly what a scan did.
+
+-- Up Migration
+CREATE TABLE IF NOT EXISTS scan_events (
+    id BIGSERIAL PRIMARY KEY,
+    scan_id UUID NOT NULL REFERENCES scans(id) ON DELETE CASCADE,
+    file_id UUID REFERENCES files(id) ON DELETE SET NULL,
+    event TEXT NOT NULL CHECK (event IN ('added','updated','moved','removed','failed')),
+    path TEXT NOT NULL,
+    previous_path TEXT,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scan_events_scan_id ON scan_events(scan_id, event, id);
+
+-- Down Migration
+-- DROP TABLE IF EXISTS scan_events;
@@ -0,0 +1,32 @@
+-- Migration: Scheduled scans
+-- Description: Cron-style scan schedules run by the API process. Scans store the
+-- options they ran with and link back to the schedule that started them.
+
+-- Up Migration
+CREATE TABLE IF NOT EXISTS scan_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    cron TEXT NOT NULL,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    options JSONB NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run TIMESTAMPTZ,
+    last_run_at TIMESTAMPTZ,
+    last_status TEXT,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scan_schedules_next_run ON scan_schedules(next_run) WHERE enabled;
+
+ALTER TABLE scans ADD COLUMN IF NOT EXISTS options JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE scans ADD COLUMN IF NOT EXISTS schedule_id UUID REFERENCES scan_schedules(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_scans_schedule_id ON scans(schedule_id, created_at DESC);
+
+-- Down Migration
+-- DROP INDEX IF EXISTS idx_scans_schedule_id;
+-- ALTER TABLE scans DROP COLUMN IF EXISTS schedule_id;
+-- ALTER TABLE scans DROP COLUMN IF EXISTS options;
+-- DROP TABLE IF EXISTS scan_schedules;
@@ -0,0 +1,10 @@
+-- Migration: Dry-run scan preview
+-- Description: Dry-run scans store a preview of what a real scan would change:
+-- new, changed, moved and missing files, folders to create, ignored paths and
+-- the estimated AI classification cost.
+
+-- Up Migration
+ALTER TABLE scans ADD COLUMN IF NOT EXISTS preview JSONB;
+
+-- Down Migration
+-- ALTER TABLE scans DROP COLUMN IF EXISTS preview;
@@ -0,0 +1,12 @@
+-- Migration: Favourite files
+-- Description: Files can be marked as favourite, individually or through
+-- POST /v1/files/bulk.
+
+-- Up Migration
+ALTER TABLE files ADD COLUMN IF NOT EXISTS favorite BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_files_favorite ON files(favorite) WHERE favorite;
+
+-- Down Migration
+-- DROP INDEX IF EXISTS idx_files_favorite;
+-- ALTER TABLE files DROP COLUMN IF EXISTS favorite;
@@ -0,0 +1,12 @@
+-- Migration: Hierarchical categories
+-- Description: Categories can have an optional parent, e.g.
+-- miniature > fantasy > dragons. Filtering by a category includes its descendants.
+
+-- Up Migration
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES categories(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
+
+-- Down Migration
+-- DROP INDEX IF EXISTS idx_categories_parent_id;
+-- ALTER TABLE categories DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,15 @@
+-- Migration: Category aliases
+-- Description: Names of categories merged into another one. AI output that
+-- still uses a merged name is mapped to the category it was merged into.
+
+-- Up Migration
+CREATE TABLE IF NOT EXISTS category_aliases (
+    alias CITEXT PRIMARY KEY,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_aliases_category_id ON category_aliases(category_id);
+
+-- Down Migration
+-- DROP TABLE IF EXISTS category_aliases;
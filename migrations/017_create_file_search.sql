@@ -0,0 +1,166 @@
+-- Migration: Tokenised full-text search
+-- Description: Splits names like "Orc_Warrior_v2_supported_32mm" into words
+-- ("orc warrior v 2 supported 32 mm") and keeps one tsvector per file with
+-- its name, folder names and category names (including parent categories).
+-- Triggers keep the documents in sync; the tokeniser rules match
+-- search.Tokenize in internal/search.
+
+-- Up Migration
+
+-- search_tokens splits camelCase, letter/digit boundaries and any
+-- non-alphanumeric separator, and lowercases the words
+CREATE OR REPLACE FUNCTION search_tokens(input TEXT) RETURNS TEXT
+LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT btrim(regexp_replace(lower(
+        regexp_replace(regexp_replace(regexp_replace(regexp_replace(input,
+            '([[:lower:]])([[:upper:]])', '\1 \2', 'g'),
+            '([[:upper:]])([[:upper:]][[:lower:]])', '\1 \2', 'g'),
+            '([[:alpha:]])([[:digit:]])', '\1 \2', 'g'),
+            '([[:digit:]])([[:alpha:]])', '\1 \2', 'g')),
+        '[^[:alnum:]]+', ' ', 'g'))
+$$;
+
+-- search_query matches documents containing every word of the input, each
+-- as a prefix ("orc warr" matches "Orc_Warrior")
+CREATE OR REPLACE FUNCTION search_query(input TEXT) RETURNS tsquery
+LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT to_tsquery('simple', COALESCE(string_agg(word || ':*', ' & '), ''))
+    FROM unnest(string_to_array(search_tokens(input), ' ')) AS word
+    WHERE word <> ''
+$$;
+
+CREATE TABLE IF NOT EXISTS file_search (
+    file_id UUID PRIMARY KEY REFERENCES files(id) ON DELETE CASCADE,
+    document TSVECTOR NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_search_document ON file_search USING GIN (document);
+CREATE INDEX IF NOT EXISTS idx_folders_name_search ON folders USING GIN (to_tsvector('simple', search_tokens(name)));
+
+-- refresh_file_search rebuilds the documents of the given files. The file
+-- name weighs most, then the names of its folder and their ancestors, then
+-- its active categories and their ancestors.
+CREATE OR REPLACE FUNCTION refresh_file_search(file_ids UUID[]) RETURNS VOID
+LANGUAGE sql AS $$
+    INSERT INTO file_search (file_id, document)
+    SELECT f.id,
+        setweight(to_tsvector('simple', search_tokens(f.file_name)), 'A') ||
+        setweight(to_tsvector('simple', COALESCE((
+            WITH RECURSIVE ancestors AS (
+                SELECT id, name, parent_folder_id FROM folders WHERE id = f.folder_id
+                UNION ALL
+                SELECT p.id, p.name, p.parent_folder_id FROM folders p
+                INNER JOIN ancestors a ON p.id = a.parent_folder_id
+            )
+            SELECT string_agg(search_tokens(name), ' ') FROM ancestors
+        ), '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE((
+            WITH RECURSIVE cats AS (
+                SELECT c.id, c.name, c.parent_id FROM files_categories fc
+                INNER JOIN categories c ON c.id = fc.category_id
+                WHERE fc.file_id = f.id AND c.deleted_at IS NULL
+                UNION
+                SELECT p.id, p.name, p.parent_id FROM categories p
+                INNER JOIN cats ON p.id = cats.parent_id
+                WHERE p.deleted_at IS NULL
+            )
+            SELECT string_agg(search_tokens(name::text), ' ') FROM cats
+        ), '')), 'C')
+    FROM files f
+    WHERE f.id = ANY(file_ids)
+    ON CONFLICT (file_id) DO UPDATE SET document = EXCLUDED.document
+$$;
+
+CREATE OR REPLACE FUNCTION file_search_on_file() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    PERFORM refresh_file_search(ARRAY[NEW.id]);
+    RETURN NULL;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION file_search_on_file_category() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM refresh_file_search(ARRAY[OLD.file_id]);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM refresh_file_search(ARRAY[NEW.file_id]);
+    END IF;
+    RETURN NULL;
+END;
+$$;
+
+-- A category change reaches the files of the category and of its descendants
+CREATE OR REPLACE FUNCTION file_search_on_category() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    PERFORM refresh_file_search(ARRAY(
+        WITH RECURSIVE subtree AS (
+            SELECT NEW.id AS id
+            UNION ALL
+            SELECT c.id FROM categories c
+            INNER JOIN subtree ON c.parent_id = subtree.id
+        )
+        SELECT DISTINCT file_id FROM files_categories
+        WHERE category_id IN (SELECT id FROM subtree)
+    ));
+    RETURN NULL;
+END;
+$$;
+
+-- Renaming or moving a folder changes the documents of every file below it
+CREATE OR REPLACE FUNCTION file_search_on_folder() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    PERFORM refresh_file_search(ARRAY(
+        WITH RECURSIVE subtree AS (
+            SELECT NEW.id AS id
+            UNION ALL
+            SELECT sub.id FROM folders sub
+            INNER JOIN subtree ON sub.parent_folder_id = subtree.id
+        )
+        SELECT f.id FROM files f WHERE f.folder_id IN (SELECT id FROM subtree)
+    ));
+    RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_file_search_file ON files;
+CREATE TRIGGER trg_file_search_file
+    AFTER INSERT OR UPDATE OF file_name, folder_id ON files
+    FOR EACH ROW EXECUTE FUNCTION file_search_on_file();
+
+DROP TRIGGER IF EXISTS trg_file_search_file_category ON files_categories;
+CREATE TRIGGER trg_file_search_file_category
+    AFTER INSERT OR UPDATE OR DELETE ON files_categories
+    FOR EACH ROW EXECUTE FUNCTION file_search_on_file_category();
+
+DROP TRIGGER IF EXISTS trg_file_search_category ON categories;
+CREATE TRIGGER trg_file_search_category
+    AFTER UPDATE OF name, deleted_at, parent_id ON categories
+    FOR EACH ROW EXECUTE FUNCTION file_search_on_category();
+
+DROP TRIGGER IF EXISTS trg_file_search_folder ON folders;
+CREATE TRIGGER trg_file_search_folder
+    AFTER UPDATE OF name, parent_folder_id ON folders
+    FOR EACH ROW EXECUTE FUNCTION file_search_on_folder();
+
+-- Backfill existing files
+SELECT refresh_file_search(ARRAY(SELECT id FROM files));
+
+-- Down Migration
+-- DROP TRIGGER IF EXISTS trg_file_search_folder ON folders;
+-- DROP TRIGGER IF EXISTS trg_file_search_category ON categories;
+-- DROP TRIGGER IF EXISTS trg_file_search_file_category ON files_categories;
+-- DROP TRIGGER IF EXISTS trg_file_search_file ON files;
+-- DROP FUNCTION IF EXISTS file_search_on_folder();
+-- DROP FUNCTION IF EXISTS file_search_on_category();
+-- DROP FUNCTION IF EXISTS file_search_on_file_category();
+-- DROP FUNCTION IF EXISTS file_search_on_file();
+-- DROP FUNCTION IF EXISTS refresh_file_search(UUID[]);
+-- DROP INDEX IF EXISTS idx_folders_name_search;
+-- DROP TABLE IF EXISTS file_search;
+-- DROP FUNCTION IF EXISTS search_query(TEXT);
+-- DROP FUNCTION IF EXISTS search_tokens(TEXT);
@@ -0,0 +1,20 @@
+-- Migration: Saved searches
+-- Description: Named filter sets for GET /v1/files. Filters are stored as the
+-- query parameters they were saved with, so relative dates ("this_month")
+-- stay live. Pinned searches show up as smart collections in browse.
+
+-- Up Migration
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name CITEXT NOT NULL UNIQUE,
+    filters JSONB NOT NULL DEFAULT '{}',
+    pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_searches_pinned ON saved_searches(name) WHERE pinned;
+
+-- Down Migration
+-- DROP INDEX IF EXISTS idx_saved_searches_pinned;
+-- DROP TABLE IF EXISTS saved_searches;
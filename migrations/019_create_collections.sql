@@ -0,0 +1,38 @@
+-- Migration: Collections
+-- Description: Hand-picked groupings of files and folders ("Voron 2.4 build")
+-- that cut across categories. Members are ordered and can carry a note; the
+-- cover image is stored under MEDIA_DIR.
+
+-- Up Migration
+CREATE TABLE IF NOT EXISTS collections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name CITEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    cover_path TEXT,
+    cover_content_type TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Each item is either a file or a folder. Positions are kept dense from 0 by
+-- the API; deleted files and folders leave gaps that do not affect ordering.
+CREATE TABLE IF NOT EXISTS collection_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    collection_id UUID NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    file_id UUID REFERENCES files(id) ON DELETE CASCADE,
+    folder_id UUID REFERENCES folders(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((file_id IS NULL) <> (folder_id IS NULL)),
+    UNIQUE (collection_id, file_id),
+    UNIQUE (collection_id, folder_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_collection_items_position ON collection_items(collection_id, position);
+CREATE INDEX IF NOT EXISTS idx_collection_items_file_id ON collection_items(file_id) WHERE file_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_collection_items_folder_id ON collection_items(folder_id) WHERE folder_id IS NOT NULL;
+
+-- Down Migration
+-- DROP TABLE IF EXISTS collection_items;
+-- DROP TABLE IF EXISTS collections;
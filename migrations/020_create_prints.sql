@@ -0,0 +1,29 @@
+-- Migration: Print history
+-- Description: A log of the times a file was printed, with the printer and
+-- settings used and how it turned out. The optional photo is stored under
+-- MEDIA_DIR.
+
+-- Up Migration
+CREATE TABLE IF NOT EXISTS prints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    file_id UUID NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+    printed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    printer TEXT NOT NULL DEFAULT '',
+    material TEXT NOT NULL DEFAULT '',
+    -- Layer height and nozzle diameter in millimetres
+    layer_height DOUBLE PRECISION,
+    nozzle DOUBLE PRECISION,
+    duration_seconds INT,
+    result TEXT NOT NULL CHECK (result IN ('success', 'failed', 'partial')),
+    note TEXT NOT NULL DEFAULT '',
+    photo_path TEXT,
+    photo_content_type TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_prints_file_id ON prints(file_id, printed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_prints_success ON prints(file_id) WHERE result = 'success';
+
+-- Down Migration
+-- DROP TABLE IF EXISTS prints;
@@ -0,0 +1,125 @@
+-- Migration: Ratings, notes and custom fields
+-- Description: User-editable metadata on files and folders: a favourite flag,
+-- a 1-5 rating, a markdown note and values for custom fields defined once for
+-- the library (designer, license, scale, ...). Notes and text field values are
+-- added to the file search documents.
+
+-- Up Migration
+ALTER TABLE files ADD COLUMN IF NOT EXISTS rating SMALLINT CHECK (rating BETWEEN 1 AND 5);
+ALTER TABLE files ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS favorite BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS rating SMALLINT CHECK (rating BETWEEN 1 AND 5);
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_files_rating ON files(rating) WHERE rating IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_folders_favorite ON folders(favorite) WHERE favorite;
+
+-- Field definitions. The key names the field in requests and filters; select
+-- fields only accept one of their options.
+CREATE TABLE IF NOT EXISTS custom_fields (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    key TEXT NOT NULL UNIQUE CHECK (key ~ '^[a-z][a-z0-9_]*$'),
+    label TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('text', 'number', 'boolean', 'select')),
+    options TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Each value belongs to either a file or a folder and holds the JSON value
+-- of the field's type
+CREATE TABLE IF NOT EXISTS custom_field_values (
+    field_id UUID NOT NULL REFERENCES custom_fields(id) ON DELETE CASCADE,
+    file_id UUID REFERENCES files(id) ON DELETE CASCADE,
+    folder_id UUID REFERENCES folders(id) ON DELETE CASCADE,
+    value JSONB NOT NULL,
+    CHECK ((file_id IS NULL) <> (folder_id IS NULL)),
+    UNIQUE (field_id, file_id),
+    UNIQUE (field_id, folder_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_custom_field_values_file_id ON custom_field_values(file_id) WHERE file_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_custom_field_values_folder_id ON custom_field_values(folder_id) WHERE folder_id IS NOT NULL;
+
+INSERT INTO custom_fields (key, label, type) VALUES
+    ('designer', 'Designer', 'text'),
+    ('license', 'License', 'text'),
+    ('scale', 'Scale', 'text'),
+    ('supports_needed', 'Supports needed', 'boolean')
+ON CONFLICT (key) DO NOTHING;
+
+-- refresh_file_search as in 017, plus the file's note and its text custom
+-- field values at the lowest weight
+CREATE OR REPLACE FUNCTION refresh_file_search(file_ids UUID[]) RETURNS VOID
+LANGUAGE sql AS $$
+    INSERT INTO file_search (file_id, document)
+    SELECT f.id,
+        setweight(to_tsvector('simple', search_tokens(f.file_name)), 'A') ||
+        setweight(to_tsvector('simple', COALESCE((
+            WITH RECURSIVE ancestors AS (
+                SELECT id, name, parent_folder_id FROM folders WHERE id = f.folder_id
+                UNION ALL
+                SELECT p.id, p.name, p.parent_folder_id FROM folders p
+                INNER JOIN ancestors a ON p.id = a.parent_folder_id
+            )
+            SELECT string_agg(search_tokens(name), ' ') FROM ancestors
+        ), '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE((
+            WITH RECURSIVE cats AS (
+                SELECT c.id, c.name, c.parent_id FROM files_categories fc
+                INNER JOIN categories c ON c.id = fc.category_id
+                WHERE fc.file_id = f.id AND c.deleted_at IS NULL
+                UNION
+                SELECT p.id, p.name, p.parent_id FROM categories p
+                INNER JOIN cats ON p.id = cats.parent_id
+                WHERE p.deleted_at IS NULL
+            )
+            SELECT string_agg(search_tokens(name::text), ' ') FROM cats
+        ), '')), 'C') ||
+        setweight(to_tsvector('simple', search_tokens(f.note || ' ' || COALESCE((
+            SELECT string_agg(v.value #>> '{}', ' ') FROM custom_field_values v
+            INNER JOIN custom_fields cf ON cf.id = v.field_id
+            WHERE v.file_id = f.id AND cf.type IN ('text', 'select')
+        ), ''))), 'D')
+    FROM files f
+    WHERE f.id = ANY(file_ids)
+    ON CONFLICT (file_id) DO UPDATE SET document = EXCLUDED.document
+$$;
+
+CREATE OR REPLACE FUNCTION file_search_on_field_value() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.file_id IS NOT NULL THEN
+        PERFORM refresh_file_search(ARRAY[OLD.file_id]);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.file_id IS NOT NULL THEN
+        PERFORM refresh_file_search(ARRAY[NEW.file_id]);
+    END IF;
+    RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_file_search_file ON files;
+CREATE TRIGGER trg_file_search_file
+    AFTER INSERT OR UPDATE OF file_name, folder_id, note ON files
+    FOR EACH ROW EXECUTE FUNCTION file_search_on_file();
+
+DROP TRIGGER IF EXISTS trg_file_search_field_value ON custom_field_values;
+CREATE TRIGGER trg_file_search_field_value
+    AFTER INSERT OR UPDATE OR DELETE ON custom_field_values
+    FOR EACH ROW EXECUTE FUNCTION file_search_on_field_value();
+
+-- Down Migration
+-- DROP TRIGGER IF EXISTS trg_file_search_field_value ON custom_field_values;
+-- DROP FUNCTION IF EXISTS file_search_on_field_value();
+-- (re-run 017 to restore refresh_file_search and trg_file_search_file)
+-- DROP TABLE IF EXISTS custom_field_values;
+-- DROP TABLE IF EXISTS custom_fields;
+-- DROP INDEX IF EXISTS idx_folders_favorite;
+-- DROP INDEX IF EXISTS idx_files_rating;
+-- ALTER TABLE folders DROP COLUMN IF EXISTS note;
+-- ALTER TABLE folders DROP COLUMN IF EXISTS rating;
+-- ALTER TABLE folders DROP COLUMN IF EXISTS favorite;
+-- ALTER TABLE files DROP COLUMN IF EXISTS note;
+-- ALTER TABLE files DROP COLUMN IF EXISTS rating;
@@ -0,0 +1,24 @@
+-- Migration: Folder source and license
+-- Description: What the sidecar files of a folder (README.txt, LICENSE,
+-- attribution_card.html, Printables/Thingiverse metadata) say about its
+-- models: designer, source URL, license and description. Set by scans.
+
+-- Up Migration
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS source_designer TEXT NOT NULL DEFAULT '';
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS source_url TEXT NOT NULL DEFAULT '';
+-- Empty when no sidecar names a license the scanner recognises
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS source_license TEXT NOT NULL DEFAULT ''
+    CHECK (source_license IN ('', 'CC0', 'CC-BY', 'CC-BY-NC', 'commercial'));
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS source_description TEXT NOT NULL DEFAULT '';
+-- Names of the sidecar files the values were read from
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS source_files TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_folders_source_license ON folders(source_license) WHERE source_license <> '';
+
+-- Down Migration
+-- DROP INDEX IF EXISTS idx_folders_source_license;
+-- ALTER TABLE folders DROP COLUMN IF EXISTS source_files;
+-- ALTER TABLE folders DROP COLUMN IF EXISTS source_description;
+-- ALTER TABLE folders DROP COLUMN IF EXISTS source_license;
+-- ALTER TABLE folders DROP COLUMN IF EXISTS source_url;
+-- ALTER TABLE folders DROP COLUMN IF EXISTS source_designer;
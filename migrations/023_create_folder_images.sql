@@ -0,0 +1,23 @@
+-- Migration: Folder images
+-- Description: Preview images found next to the models of a folder (or in
+-- an Images/Renders subfolder), served as a gallery. Each folder points to
+-- the image picked as its cover. Set by scans.
+
+-- Up Migration
+CREATE TABLE IF NOT EXISTS folder_images (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    folder_id UUID NOT NULL REFERENCES folders(id) ON DELETE CASCADE,
+    -- Path relative to the folder, with forward slashes ("Images/front.jpg")
+    name TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    modified_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (folder_id, name)
+);
+
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS primary_image_id UUID REFERENCES folder_images(id) ON DELETE SET NULL;
+
+-- Down Migration
+-- ALTER TABLE folders DROP COLUMN IF EXISTS primary_image_id;
+-- DROP TABLE IF EXISTS folder_images;
@@ -0,0 +1,10 @@
+-- Migration: Folder contents modification time
+-- Description: When scans last read the sidecar files and images of a
+-- folder, the modification time of its contents at that point. Scans skip
+-- folders whose contents have not changed since.
+
+-- Up Migration
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS contents_modified_at TIMESTAMPTZ;
+
+-- Down Migration
+-- ALTER TABLE folders DROP COLUMN IF EXISTS contents_modified_at;
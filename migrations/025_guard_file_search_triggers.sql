@@ -0,0 +1,73 @@
+-- Migration: Guard file search triggers
+-- Description: UPDATE OF triggers fire whenever a column is in the SET list,
+-- even with its old value, so every rescan (UpsertFiles always sets file_name
+-- and folder_id) rebuilt the search document of every file. The update
+-- triggers now only fire when a column the documents use really changed.
+-- Postgres does not allow OLD in the WHEN of insert triggers, so insert and
+-- delete get triggers of their own.
+
+-- Up Migration
+DROP TRIGGER IF EXISTS trg_file_search_file ON files;
+CREATE TRIGGER trg_file_search_file
+    AFTER INSERT ON files
+    FOR EACH ROW EXECUTE FUNCTION file_search_on_file();
+
+DROP TRIGGER IF EXISTS trg_file_search_file_update ON files;
+CREATE TRIGGER trg_file_search_file_update
+    AFTER UPDATE OF file_name, folder_id, note ON files
+    FOR EACH ROW
+    WHEN (OLD.file_name IS DISTINCT FROM NEW.file_name
+        OR OLD.folder_id IS DISTINCT FROM NEW.folder_id
+        OR OLD.note IS DISTINCT FROM NEW.note)
+    EXECUTE FUNCTION file_search_on_file();
+
+DROP TRIGGER IF EXISTS trg_file_search_file_category ON files_categories;
+CREATE TRIGGER trg_file_search_file_category
+    AFTER INSERT OR DELETE ON files_categories
+    FOR EACH ROW EXECUTE FUNCTION file_search_on_file_category();
+
+-- Changing only the source (manual or ai) of a link does not change documents
+DROP TRIGGER IF EXISTS trg_file_search_file_category_update ON files_categories;
+CREATE TRIGGER trg_file_search_file_category_update
+    AFTER UPDATE ON files_categories
+    FOR EACH ROW
+    WHEN (OLD.file_id IS DISTINCT FROM NEW.file_id
+        OR OLD.category_id IS DISTINCT FROM NEW.category_id)
+    EXECUTE FUNCTION file_search_on_file_category();
+
+DROP TRIGGER IF EXISTS trg_file_search_category ON categories;
+CREATE TRIGGER trg_file_search_category
+    AFTER UPDATE OF name, deleted_at, parent_id ON categories
+    FOR EACH ROW
+    WHEN (OLD.name IS DISTINCT FROM NEW.name
+        OR OLD.deleted_at IS DISTINCT FROM NEW.deleted_at
+        OR OLD.parent_id IS DISTINCT FROM NEW.parent_id)
+    EXECUTE FUNCTION file_search_on_category();
+
+DROP TRIGGER IF EXISTS trg_file_search_folder ON folders;
+CREATE TRIGGER trg_file_search_folder
+    AFTER UPDATE OF name, parent_folder_id ON folders
+    FOR EACH ROW
+    WHEN (OLD.name IS DISTINCT FROM NEW.name
+        OR OLD.parent_folder_id IS DISTINCT FROM NEW.parent_folder_id)
+    EXECUTE FUNCTION file_search_on_folder();
+
+DROP TRIGGER IF EXISTS trg_file_search_field_value ON custom_field_values;
+CREATE TRIGGER trg_file_search_field_value
+    AFTER INSERT OR DELETE ON custom_field_values
+    FOR EACH ROW EXECUTE FUNCTION file_search_on_field_value();
+
+DROP TRIGGER IF EXISTS trg_file_search_field_value_update ON custom_field_values;
+CREATE TRIGGER trg_file_search_field_value_update
+    AFTER UPDATE ON custom_field_values
+    FOR EACH ROW
+    WHEN (OLD.value IS DISTINCT FROM NEW.value
+        OR OLD.file_id IS DISTINCT FROM NEW.file_id
+        OR OLD.field_id IS DISTINCT FROM NEW.field_id)
+    EXECUTE FUNCTION file_search_on_field_value();
+
+-- Down Migration
+-- DROP TRIGGER IF EXISTS trg_file_search_field_value_update ON custom_field_values;
+-- DROP TRIGGER IF EXISTS trg_file_search_file_category_update ON files_categories;
+-- DROP TRIGGER IF EXISTS trg_file_search_file_update ON files;
+-- (re-run the triggers of 017 and 021 to restore the unguarded ones)
@@ -0,0 +1,197 @@
+-- Migration: Statement-level file search triggers
+-- Description: The search triggers ran once per row, so a statement that
+-- touched many rows (a scan upserting a folder, a bulk categorization, a
+-- folder propagation) rebuilt the search document of a file once per row
+-- that mentioned it. The triggers now run once per statement, read the
+-- changed rows from transition tables and call refresh_file_search once
+-- with the distinct file IDs. Transition tables cannot be combined with
+-- several events, column lists or WHEN conditions, so every event gets its
+-- own trigger and the guards of 025 move into the functions.
+
+-- Up Migration
+CREATE OR REPLACE FUNCTION file_search_on_file() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        PERFORM refresh_file_search(ARRAY(SELECT id FROM new_rows));
+    ELSE
+        PERFORM refresh_file_search(ARRAY(
+            SELECT n.id FROM new_rows n
+            INNER JOIN old_rows o ON o.id = n.id
+            WHERE o.file_name IS DISTINCT FROM n.file_name
+                OR o.folder_id IS DISTINCT FROM n.folder_id
+                OR o.note IS DISTINCT FROM n.note
+        ));
+    END IF;
+    RETURN NULL;
+END;
+$$;
+
+-- Links whose file and category did not change (only their source did) are
+-- in both tables and do not change documents
+CREATE OR REPLACE FUNCTION file_search_on_file_category() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        PERFORM refresh_file_search(ARRAY(SELECT DISTINCT file_id FROM new_rows));
+    ELSIF TG_OP = 'DELETE' THEN
+        PERFORM refresh_file_search(ARRAY(SELECT DISTINCT file_id FROM old_rows));
+    ELSE
+        PERFORM refresh_file_search(ARRAY(
+            SELECT file_id FROM (
+                SELECT file_id, category_id FROM old_rows
+                EXCEPT
+                SELECT file_id, category_id FROM new_rows
+            ) removed
+            UNION
+            SELECT file_id FROM (
+                SELECT file_id, category_id FROM new_rows
+                EXCEPT
+                SELECT file_id, category_id FROM old_rows
+            ) added
+        ));
+    END IF;
+    RETURN NULL;
+END;
+$$;
+
+-- A category change reaches the files of the category and of its descendants
+CREATE OR REPLACE FUNCTION file_search_on_category() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    PERFORM refresh_file_search(ARRAY(
+        WITH RECURSIVE subtree AS (
+            SELECT n.id FROM new_rows n
+            INNER JOIN old_rows o ON o.id = n.id
+            WHERE o.name IS DISTINCT FROM n.name
+                OR o.deleted_at IS DISTINCT FROM n.deleted_at
+                OR o.parent_id IS DISTINCT FROM n.parent_id
+            UNION
+            SELECT c.id FROM categories c
+            INNER JOIN subtree ON c.parent_id = subtree.id
+        )
+        SELECT DISTINCT file_id FROM files_categories
+        WHERE category_id IN (SELECT id FROM subtree)
+    ));
+    RETURN NULL;
+END;
+$$;
+
+-- Renaming or moving a folder changes the documents of every file below it
+CREATE OR REPLACE FUNCTION file_search_on_folder() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    PERFORM refresh_file_search(ARRAY(
+        WITH RECURSIVE subtree AS (
+            SELECT n.id FROM new_rows n
+            INNER JOIN old_rows o ON o.id = n.id
+            WHERE o.name IS DISTINCT FROM n.name
+                OR o.parent_folder_id IS DISTINCT FROM n.parent_folder_id
+            UNION
+            SELECT sub.id FROM folders sub
+            INNER JOIN subtree ON sub.parent_folder_id = subtree.id
+        )
+        SELECT f.id FROM files f WHERE f.folder_id IN (SELECT id FROM subtree)
+    ));
+    RETURN NULL;
+END;
+$$;
+
+-- Folder values have no file_id and do not change file documents
+CREATE OR REPLACE FUNCTION file_search_on_field_value() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        PERFORM refresh_file_search(ARRAY(
+            SELECT DISTINCT file_id FROM new_rows WHERE file_id IS NOT NULL
+        ));
+    ELSIF TG_OP = 'DELETE' THEN
+        PERFORM refresh_file_search(ARRAY(
+            SELECT DISTINCT file_id FROM old_rows WHERE file_id IS NOT NULL
+        ));
+    ELSE
+        PERFORM refresh_file_search(ARRAY(
+            SELECT file_id FROM (
+                SELECT field_id, file_id, value FROM old_rows
+                EXCEPT
+                SELECT field_id, file_id, value FROM new_rows
+            ) removed
+            WHERE file_id IS NOT NULL
+            UNION
+            SELECT file_id FROM (
+                SELECT field_id, file_id, value FROM new_rows
+                EXCEPT
+                SELECT field_id, file_id, value FROM old_rows
+            ) added
+            WHERE file_id IS NOT NULL
+        ));
+    END IF;
+    RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_file_search_file ON files;
+CREATE TRIGGER trg_file_search_file
+    AFTER INSERT ON files
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION file_search_on_file();
+
+DROP TRIGGER IF EXISTS trg_file_search_file_update ON files;
+CREATE TRIGGER trg_file_search_file_update
+    AFTER UPDATE ON files
+    REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION file_search_on_file();
+
+DROP TRIGGER IF EXISTS trg_file_search_file_category ON files_categories;
+CREATE TRIGGER trg_file_search_file_category
+    AFTER INSERT ON files_categories
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION file_search_on_file_category();
+
+DROP TRIGGER IF EXISTS trg_file_search_file_category_delete ON files_categories;
+CREATE TRIGGER trg_file_search_file_category_delete
+    AFTER DELETE ON files_categories
+    REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION file_search_on_file_category();
+
+DROP TRIGGER IF EXISTS trg_file_search_file_category_update ON files_categories;
+CREATE TRIGGER trg_file_search_file_category_update
+    AFTER UPDATE ON files_categories
+    REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION file_search_on_file_category();
+
+DROP TRIGGER IF EXISTS trg_file_search_category ON categories;
+CREATE TRIGGER trg_file_search_category
+    AFTER UPDATE ON categories
+    REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION file_search_on_category();
+
+DROP TRIGGER IF EXISTS trg_file_search_folder ON folders;
+CREATE TRIGGER trg_file_search_folder
+    AFTER UPDATE ON folders
+    REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION file_search_on_folder();
+
+DROP TRIGGER IF EXISTS trg_file_search_field_value ON custom_field_values;
+CREATE TRIGGER trg_file_search_field_value
+    AFTER INSERT ON custom_field_values
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION file_search_on_field_value();
+
+DROP TRIGGER IF EXISTS trg_file_search_field_value_delete ON custom_field_values;
+CREATE TRIGGER trg_file_search_field_value_delete
+    AFTER DELETE ON custom_field_values
+    REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION file_search_on_field_value();
+
+DROP TRIGGER IF EXISTS trg_file_search_field_value_update ON custom_field_values;
+CREATE TRIGGER trg_file_search_field_value_update
+    AFTER UPDATE ON custom_field_values
+    REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION file_search_on_field_value();
+
+-- Down Migration
+-- DROP TRIGGER IF EXISTS trg_file_search_field_value_delete ON custom_field_values;
+-- DROP TRIGGER IF EXISTS trg_file_search_file_category_delete ON files_categories;
+-- (re-run the functions of 017 and 021 and the triggers of 025 to restore
+-- the row-level ones)
@@ -0,0 +1,16 @@
+-- Migration: Index files by directory
+-- Description: Scans compare stored files with the disk one directory at a
+-- time instead of loading every stored file under the scanned tree, so they
+-- look files up by parent directory, and by hash or name and size to find
+-- the files that moved. The parent directory is the path without the file
+-- name, which works with either path separator.
+
+-- Up Migration
+CREATE INDEX IF NOT EXISTS idx_files_dir ON files ((left(path, length(path) - length(file_name) - 1)));
+CREATE INDEX IF NOT EXISTS idx_files_sha256 ON files(sha256);
+CREATE INDEX IF NOT EXISTS idx_files_name_size ON files(file_name, size);
+
+-- Down Migration
+-- DROP INDEX IF EXISTS idx_files_name_size;
+-- DROP INDEX IF EXISTS idx_files_sha256;
+-- DROP INDEX IF EXISTS idx_files_dir;
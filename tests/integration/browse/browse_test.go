@@ -1,12 +1,18 @@
 package browse
 
 import (
+	"context"
 	"net/http"
+	"strconv"
 	"testing"
 
+	"stl-manager/internal/db"
 	"stl-manager/tests/integration/helpers"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListBrowse(t *testing.T) {
@@ -36,7 +42,7 @@ func TestListBrowse(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			resp := helpers.MakeRequest(t, tt.req, handler.ListBrowse)
 			assert.Equal(t, tt.want, resp.Code)
-			helpers.AssertPaginatedResponse(t, resp)
+			helpers.AssertCursorPaginatedResponse(t, resp)
 		})
 	}
 }
@@ -63,7 +69,150 @@ func TestListMixed(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			resp := helpers.MakeRequest(t, tt.req, handler.ListMixed)
 			assert.Equal(t, tt.want, resp.Code)
-			helpers.AssertPaginatedResponse(t, resp)
+			helpers.AssertCursorPaginatedResponse(t, resp)
 		})
 	}
 }
+
+func TestListBrowseFacets(t *testing.T) {
+	t.Run("facets for matching root folders", func(t *testing.T) {
+		req := helpers.GET("/browse").WithQueryParam("q", "test").WithQueryParam("facets", "true")
+		resp := helpers.MakeRequest(t, req, handler.ListBrowse)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		helpers.AssertCursorPaginatedResponse(t, resp)
+		helpers.AssertHasFields(t, resp.GetMap("facets"), "categories", "types", "folders", "sizes")
+	})
+
+	t.Run("invalid facets", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, helpers.GET("/browse").WithQueryParam("facets", "maybe"), handler.ListBrowse)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestListBrowseSmartCollections(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "smart-collection")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+	for _, name := range []string{"one", "two"} {
+		file := helpers.CreateTestFile(t, name, "stl", folder.ID)
+		defer helpers.DeleteTestFile(t, file.ID)
+	}
+
+	filters := map[string]string{"folder_id": uuid.UUID(folder.ID.Bytes).String()}
+	pinned := helpers.CreateTestSavedSearch(t, "test-pinned-"+uuid.New().String()[:8], filters, true)
+	defer helpers.DeleteTestSavedSearch(t, pinned.ID)
+	unpinned := helpers.CreateTestSavedSearch(t, "test-unpinned-"+uuid.New().String()[:8], filters, false)
+	defer helpers.DeleteTestSavedSearch(t, unpinned.ID)
+
+	resp := helpers.MakeRequest(t, helpers.GET("/browse"), handler.ListBrowse)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	counts := map[string]float64{}
+	for _, entry := range resp.GetArray("smart_collections") {
+		e := entry.(map[string]interface{})
+		counts[e["name"].(string)] = e["count"].(float64)
+	}
+	assert.Equal(t, float64(2), counts[pinned.Name])
+	assert.NotContains(t, counts, unpinned.Name)
+}
+
+func TestListBrowseCollections(t *testing.T) {
+	collection := helpers.CreateTestCollection(t, "test-sidebar-"+uuid.New().String()[:8])
+	defer helpers.DeleteTestCollection(t, collection.ID)
+	folder := helpers.CreateTestFolder(t, "sidebar-folder")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+	_, err := db.New(helpers.TestPool).AddCollectionItem(context.Background(), db.AddCollectionItemParams{
+		CollectionID: collection.ID,
+		FolderID:     folder.ID,
+	})
+	require.NoError(t, err)
+
+	resp := helpers.MakeRequest(t, helpers.GET("/browse"), handler.ListBrowse)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	counts := map[string]float64{}
+	for _, entry := range resp.GetArray("collections") {
+		e := entry.(map[string]interface{})
+		counts[e["name"].(string)] = e["item_count"].(float64)
+	}
+	assert.Equal(t, float64(1), counts[collection.Name])
+}
+
+func TestListMixedPages(t *testing.T) {
+	parent := helpers.CreateTestFolder(t, "mixed-pages")
+	defer helpers.DeleteTestFolder(t, parent.ID)
+
+	id := func(id pgtype.UUID) string { return uuid.UUID(id.Bytes).String() }
+
+	// Subfolders come first, then files, each ordered by name
+	want := []string{}
+	for _, name := range []string{"a-sub", "b-sub", "c-sub"} {
+		sub := helpers.CreateTestSubfolder(t, name, parent)
+		defer helpers.DeleteTestFolder(t, sub.ID)
+		want = append(want, id(sub.ID))
+	}
+	for _, name := range []string{"a-file", "b-file"} {
+		file := helpers.CreateTestFile(t, name, "stl", parent.ID)
+		defer helpers.DeleteTestFile(t, file.ID)
+		want = append(want, id(file.ID))
+	}
+
+	for _, pageSize := range []string{"1", "2", "3", "5"} {
+		t.Run("page size "+pageSize, func(t *testing.T) {
+			list := func() helpers.HTTPTestRequest {
+				return helpers.GET("/mixed").
+					WithQueryParam("folder_id", id(parent.ID)).
+					WithQueryParam("page_size", pageSize)
+			}
+			assert.Equal(t, want, helpers.FollowCursor(t, list(), handler.ListMixed), "cursor pages")
+
+			offsetIDs := []string{}
+			for page := 1; page <= len(want); page++ {
+				resp := helpers.MakeRequest(t, list().WithQueryParam("page", strconv.Itoa(page)), handler.ListMixed)
+				require.Equal(t, http.StatusOK, resp.Code)
+				assert.Equal(t, float64(len(want)), resp.GetFloat("total"))
+				for _, item := range resp.GetArray("items") {
+					offsetIDs = append(offsetIDs, item.(map[string]interface{})["id"].(string))
+				}
+			}
+			assert.Equal(t, want, offsetIDs, "offset pages")
+		})
+	}
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		req := helpers.GET("/mixed").WithQueryParam("cursor", "invalid")
+		resp := helpers.MakeRequest(t, req, handler.ListMixed)
+		helpers.AssertErrorResponse(t, resp, http.StatusBadRequest)
+	})
+}
+
+func TestListBrowseMetadata(t *testing.T) {
+	ctx := context.Background()
+	queries := db.New(helpers.TestPool)
+
+	folder := helpers.CreateTestFolder(t, "browse-metadata-"+uuid.New().String()[:8])
+	defer helpers.DeleteTestFolder(t, folder.ID)
+	designer := helpers.CreateTestCustomField(t, "text")
+	defer helpers.DeleteTestCustomField(t, designer.ID)
+
+	_, err := queries.UpdateFolderMetadata(ctx, db.UpdateFolderMetadataParams{
+		ID:       folder.ID,
+		Favorite: true,
+		Rating:   pgtype.Int2{Int16: 4, Valid: true},
+	})
+	require.NoError(t, err)
+	require.NoError(t, queries.SetFolderFieldValue(ctx, db.SetFolderFieldValueParams{
+		FieldID:  designer.ID,
+		FolderID: folder.ID,
+		Value:    []byte(`"Artisan Guild"`),
+	}))
+
+	resp := helpers.MakeRequest(t, helpers.GET("/browse").WithQueryParam("q", folder.Name), handler.ListBrowse)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	items := resp.GetArray("items")
+	require.Len(t, items, 1)
+	item := items[0].(map[string]interface{})
+	assert.Equal(t, true, item["favorite"])
+	assert.Equal(t, float64(4), item["rating"])
+	assert.Equal(t, "Artisan Guild", item["custom_fields"].(map[string]interface{})[designer.Key])
+}
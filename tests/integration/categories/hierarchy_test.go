@@ -0,0 +1,129 @@
+package categories
+
+import (
+	"net/http"
+	"testing"
+
+	"stl-manager/internal/handlers/categories"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSubcategory(t *testing.T) {
+	parent := helpers.CreateTestCategory(t, "test-parent")
+	defer helpers.DeleteTestCategory(t, parent.ID)
+
+	tests := []struct {
+		name     string
+		parentID string
+		wantCode int
+	}{
+		{
+			name:     "create under parent",
+			parentID: uuid.UUID(parent.ID.Bytes).String(),
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "invalid parent id",
+			parentID: "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "parent not found",
+			parentID: uuid.New().String(),
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.POST("/categories", categories.CreateCategoryRequest{
+				Name:     "test-child-" + uuid.New().String()[:8],
+				ParentID: tt.parentID,
+			})
+			resp := helpers.MakeRequest(t, req, handler.CreateCategory)
+			assert.Equal(t, tt.wantCode, resp.Code)
+
+			if resp.Code == http.StatusCreated {
+				assert.Equal(t, tt.parentID, resp.GetString("parent_id"))
+				categoryID, _ := uuid.Parse(resp.GetString("id"))
+				helpers.DeleteTestCategory(t, pgtype.UUID{Bytes: categoryID, Valid: true})
+			}
+		})
+	}
+}
+
+func TestMoveCategory(t *testing.T) {
+	root := helpers.CreateTestCategory(t, "test-root")
+	defer helpers.DeleteTestCategory(t, root.ID)
+	child := helpers.CreateTestSubcategory(t, "test-child", root)
+	defer helpers.DeleteTestCategory(t, child.ID)
+	leaf := helpers.CreateTestSubcategory(t, "test-leaf", child)
+	defer helpers.DeleteTestCategory(t, leaf.ID)
+
+	idOf := func(id pgtype.UUID) string { return uuid.UUID(id.Bytes).String() }
+	update := func(cat string, parentID *string) *helpers.HTTPTestResponse {
+		req := helpers.PUT("/categories/"+cat, categories.UpdateCategoryRequest{
+			Name:     "test-moved-" + uuid.New().String()[:8],
+			ParentID: parentID,
+		}).WithURLParam("id", cat)
+		return helpers.MakeRequest(t, req, handler.UpdateCategory)
+	}
+	ptr := func(s string) *string { return &s }
+
+	t.Run("renaming keeps the parent", func(t *testing.T) {
+		resp := update(idOf(leaf.ID), nil)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, idOf(child.ID), resp.GetString("parent_id"))
+	})
+
+	t.Run("cannot move under itself", func(t *testing.T) {
+		resp := update(idOf(root.ID), ptr(idOf(root.ID)))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("cannot move under a descendant", func(t *testing.T) {
+		resp := update(idOf(root.ID), ptr(idOf(leaf.ID)))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("move to root", func(t *testing.T) {
+		resp := update(idOf(leaf.ID), ptr(""))
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Nil(t, resp.Body["parent_id"])
+	})
+
+	t.Run("category not found", func(t *testing.T) {
+		resp := update(uuid.New().String(), nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
+
+func TestListCategoriesTree(t *testing.T) {
+	parent := helpers.CreateTestCategory(t, "test-tree")
+	defer helpers.DeleteTestCategory(t, parent.ID)
+	child := helpers.CreateTestSubcategory(t, "test-tree-child", parent)
+	defer helpers.DeleteTestCategory(t, child.ID)
+
+	resp := helpers.MakeRequest(t, helpers.GET("/categories").WithQueryParam("tree", "true"), handler.ListCategories)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	parentID := uuid.UUID(parent.ID.Bytes).String()
+	var found map[string]interface{}
+	for _, item := range resp.GetArray("items") {
+		node := item.(map[string]interface{})
+		assert.NotEqual(t, uuid.UUID(child.ID.Bytes).String(), node["id"], "children are not listed as roots")
+		if node["id"] == parentID {
+			found = node
+		}
+	}
+	require.NotNil(t, found, "parent listed as a root")
+
+	children := found["children"].([]interface{})
+	require.Len(t, children, 1)
+	assert.Equal(t, child.Name, children[0].(map[string]interface{})["name"])
+}
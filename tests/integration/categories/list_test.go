@@ -6,6 +6,7 @@ import (
 
 	"stl-manager/tests/integration/helpers"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -43,7 +44,24 @@ func TestListCategories(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			resp := helpers.MakeRequest(t, tt.req, handler.ListCategories)
 			assert.Equal(t, tt.want, resp.Code)
-			helpers.AssertPaginatedResponse(t, resp)
+			helpers.AssertCursorPaginatedResponse(t, resp)
 		})
 	}
 }
+
+func TestListCategoriesCursor(t *testing.T) {
+	want := []string{}
+	for _, name := range []string{"cursor-list-a", "Cursor-List-B", "cursor-list-c"} {
+		cat := helpers.CreateTestCategory(t, name)
+		defer helpers.DeleteTestCategory(t, cat.ID)
+		want = append(want, uuid.UUID(cat.ID.Bytes).String())
+	}
+
+	req := helpers.GET("/categories").WithQueryParam("q", "cursor-list").WithQueryParam("page_size", "1")
+	assert.Equal(t, want, helpers.FollowCursor(t, req, handler.ListCategories), "names sort case-insensitively")
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, helpers.GET("/categories").WithQueryParam("cursor", "invalid"), handler.ListCategories)
+		helpers.AssertErrorResponse(t, resp, http.StatusBadRequest)
+	})
+}
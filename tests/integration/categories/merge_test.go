@@ -0,0 +1,166 @@
+package categories
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/handlers/categories"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func merge(t *testing.T, id string, body interface{}) *helpers.HTTPTestResponse {
+	req := helpers.POST("/categories/"+id+"/merge", body).WithURLParam("id", id)
+	return helpers.MakeRequest(t, req, handler.MergeCategories)
+}
+
+func TestMergeCategoriesValidation(t *testing.T) {
+	target := helpers.CreateTestCategory(t, "merge-target")
+	defer helpers.DeleteTestCategory(t, target.ID)
+	parent := helpers.CreateTestCategory(t, "merge-parent")
+	defer helpers.DeleteTestCategory(t, parent.ID)
+	child := helpers.CreateTestSubcategory(t, "merge-child", parent)
+	defer helpers.DeleteTestCategory(t, child.ID)
+
+	targetID := uuid.UUID(target.ID.Bytes).String()
+	tests := []struct {
+		name     string
+		id       string
+		body     interface{}
+		wantCode int
+	}{
+		{
+			name:     "invalid id",
+			id:       "invalid",
+			body:     categories.MergeRequest{SourceIDs: []string{uuid.New().String()}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "no sources",
+			id:       targetID,
+			body:     categories.MergeRequest{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid source id",
+			id:       targetID,
+			body:     categories.MergeRequest{SourceIDs: []string{"invalid"}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "merge into itself",
+			id:       targetID,
+			body:     categories.MergeRequest{SourceIDs: []string{targetID}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "merge into a descendant",
+			id:       uuid.UUID(child.ID.Bytes).String(),
+			body:     categories.MergeRequest{SourceIDs: []string{uuid.UUID(parent.ID.Bytes).String()}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "target not found",
+			id:       uuid.New().String(),
+			body:     categories.MergeRequest{SourceIDs: []string{targetID}},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "source not found",
+			id:       targetID,
+			body:     categories.MergeRequest{SourceIDs: []string{uuid.New().String()}},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "invalid request body",
+			id:       targetID,
+			body:     "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := merge(t, tt.id, tt.body)
+			assert.Equal(t, tt.wantCode, resp.Code)
+		})
+	}
+}
+
+func TestMergeCategories(t *testing.T) {
+	ctx := context.Background()
+	queries := db.New(helpers.TestPool)
+
+	target := helpers.CreateTestCategory(t, "merge-mount")
+	defer helpers.DeleteTestCategory(t, target.ID)
+	holder := helpers.CreateTestCategory(t, "merge-holder")
+	defer helpers.DeleteTestCategory(t, holder.ID)
+	stand := helpers.CreateTestCategory(t, "merge-stand")
+	defer helpers.DeleteTestCategory(t, stand.ID)
+	standChild := helpers.CreateTestSubcategory(t, "merge-stand-child", stand)
+	defer helpers.DeleteTestCategory(t, standChild.ID)
+
+	// One file already has the target and a source, the other only a source
+	both := helpers.CreateTestFile(t, "merge-both", "stl", pgtype.UUID{})
+	defer helpers.DeleteTestFile(t, both.ID)
+	only := helpers.CreateTestFile(t, "merge-only", "stl", pgtype.UUID{})
+	defer helpers.DeleteTestFile(t, only.ID)
+	folder := helpers.CreateTestFolder(t, "merge-folder")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+
+	require.NoError(t, queries.AddFileCategory(ctx, db.AddFileCategoryParams{FileID: both.ID, CategoryID: target.ID}))
+	require.NoError(t, queries.AddFileCategory(ctx, db.AddFileCategoryParams{FileID: both.ID, CategoryID: holder.ID}))
+	require.NoError(t, queries.AddManualFileCategory(ctx, db.AddManualFileCategoryParams{FileID: only.ID, CategoryID: stand.ID}))
+	require.NoError(t, queries.AddFolderCategory(ctx, db.AddFolderCategoryParams{FolderID: folder.ID, CategoryID: holder.ID}))
+
+	resp := merge(t, uuid.UUID(target.ID.Bytes).String(), categories.MergeRequest{
+		SourceIDs: []string{uuid.UUID(holder.ID.Bytes).String(), uuid.UUID(stand.ID.Bytes).String()},
+	})
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, float64(2), resp.GetFloat("files"))
+	assert.Equal(t, float64(1), resp.GetFloat("folders"))
+	assert.ElementsMatch(t, []interface{}{holder.Name, stand.Name}, resp.GetArray("aliases"))
+
+	fileCategories := func(id pgtype.UUID) []string {
+		cats, err := queries.GetFileCategories(ctx, id)
+		require.NoError(t, err)
+		names := []string{}
+		for _, cat := range cats {
+			names = append(names, cat.Name)
+		}
+		return names
+	}
+	assert.Equal(t, []string{target.Name}, fileCategories(both.ID), "duplicate pair collapses")
+	assert.Equal(t, []string{target.Name}, fileCategories(only.ID))
+
+	manual, err := queries.CountManualFileCategories(ctx, only.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), manual, "manual links stay manual")
+
+	folderCategories, err := queries.GetFolderCategories(ctx, folder.ID)
+	require.NoError(t, err)
+	require.Len(t, folderCategories, 1)
+	assert.Equal(t, target.ID, folderCategories[0].ID)
+
+	// Sources are soft deleted, their children move to the target and their
+	// names resolve to the target
+	_, err = queries.GetCategory(ctx, holder.ID)
+	assert.Error(t, err)
+	assert.Equal(t, target.ID, helpers.GetTestCategory(t, standChild.ID).ParentID)
+
+	resolved, err := queries.ResolveCategoryName(ctx, stand.Name)
+	require.NoError(t, err)
+	assert.Equal(t, target.ID, resolved.ID)
+
+	catalog, err := categories.LoadCatalog(ctx, queries)
+	require.NoError(t, err)
+	cat, ok := catalog.Lookup(holder.Name)
+	require.True(t, ok, "AI output using a merged name maps to the target")
+	assert.Equal(t, target.ID, cat.ID)
+}
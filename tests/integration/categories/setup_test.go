@@ -4,6 +4,7 @@ import (
 	"os"
 	"testing"
 
+	"stl-manager/internal/events"
 	"stl-manager/internal/handlers/categories"
 	"stl-manager/tests/integration/helpers"
 )
@@ -17,7 +18,7 @@ func TestMain(m *testing.M) {
 	}
 
 	// Create handler
-	handler = categories.New(helpers.TestPool, helpers.TestLogger)
+	handler = categories.New(helpers.TestPool, events.NewBroker(), helpers.TestLogger)
 
 	// Run tests
 	code := m.Run()
@@ -0,0 +1,169 @@
+package collections
+
+import (
+	"net/http"
+	"testing"
+
+	"stl-manager/internal/handlers/collections"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateCollection(t *testing.T) {
+	existing := helpers.CreateTestCollection(t, "test-taken-"+uuid.New().String()[:8])
+	defer helpers.DeleteTestCollection(t, existing.ID)
+
+	tests := []struct {
+		name     string
+		body     interface{}
+		wantCode int
+	}{
+		{
+			name:     "create successfully",
+			body:     collections.CollectionRequest{Name: "Voron 2.4 build " + uuid.New().String()[:8], Description: "Printed parts"},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "missing name fails",
+			body:     collections.CollectionRequest{Description: "no name"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "duplicate name conflicts",
+			body:     collections.CollectionRequest{Name: existing.Name},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "invalid json fails",
+			body:     "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := helpers.MakeRequest(t, helpers.POST("/collections", tt.body), handler.CreateCollection)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+
+			if resp.Code == http.StatusCreated {
+				id, _ := uuid.Parse(resp.GetString("id"))
+				defer helpers.DeleteTestCollection(t, pgtype.UUID{Bytes: id, Valid: true})
+
+				assert.Equal(t, "Printed parts", resp.GetString("description"))
+				assert.Equal(t, float64(0), resp.GetFloat("item_count"))
+				assert.Nil(t, resp.Body["cover_url"])
+			}
+		})
+	}
+}
+
+func TestGetCollection(t *testing.T) {
+	c := helpers.CreateTestCollection(t, "test-get-"+uuid.New().String()[:8])
+	defer helpers.DeleteTestCollection(t, c.ID)
+
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{
+			name:     "get existing collection",
+			id:       uuid.UUID(c.ID.Bytes).String(),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid id",
+			id:       "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not found",
+			id:       uuid.New().String(),
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.GET("/collections/"+tt.id).WithURLParam("id", tt.id)
+			resp := helpers.MakeRequest(t, req, handler.GetCollection)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+			if resp.Code == http.StatusOK {
+				assert.Equal(t, c.Name, resp.GetString("name"))
+			}
+		})
+	}
+}
+
+func TestListCollections(t *testing.T) {
+	c := helpers.CreateTestCollection(t, "test-list-"+uuid.New().String()[:8])
+	defer helpers.DeleteTestCollection(t, c.ID)
+
+	resp := helpers.MakeRequest(t, helpers.GET("/collections").WithQueryParam("page_size", "100"), handler.ListCollections)
+	require.Equal(t, http.StatusOK, resp.Code)
+	helpers.AssertPaginatedResponse(t, resp)
+
+	found := false
+	for _, item := range resp.GetArray("items") {
+		if item.(map[string]interface{})["name"] == c.Name {
+			found = true
+		}
+	}
+	assert.True(t, found, "created collection should be listed")
+}
+
+func TestUpdateCollection(t *testing.T) {
+	c := helpers.CreateTestCollection(t, "test-update-"+uuid.New().String()[:8])
+	defer helpers.DeleteTestCollection(t, c.ID)
+	other := helpers.CreateTestCollection(t, "test-update-other-"+uuid.New().String()[:8])
+	defer helpers.DeleteTestCollection(t, other.ID)
+	id := uuid.UUID(c.ID.Bytes).String()
+
+	t.Run("update successfully", func(t *testing.T) {
+		body := collections.CollectionRequest{Name: c.Name + "-renamed", Description: "Christmas market stock"}
+		resp := helpers.MakeRequest(t, helpers.PUT("/collections/"+id, body).WithURLParam("id", id), handler.UpdateCollection)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, c.Name+"-renamed", resp.GetString("name"))
+		assert.Equal(t, "Christmas market stock", resp.GetString("description"))
+	})
+
+	t.Run("duplicate name conflicts", func(t *testing.T) {
+		body := collections.CollectionRequest{Name: other.Name}
+		resp := helpers.MakeRequest(t, helpers.PUT("/collections/"+id, body).WithURLParam("id", id), handler.UpdateCollection)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		missing := uuid.New().String()
+		body := collections.CollectionRequest{Name: "missing"}
+		resp := helpers.MakeRequest(t, helpers.PUT("/collections/"+missing, body).WithURLParam("id", missing), handler.UpdateCollection)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
+
+func TestDeleteCollection(t *testing.T) {
+	c := helpers.CreateTestCollection(t, "test-delete-"+uuid.New().String()[:8])
+	defer helpers.DeleteTestCollection(t, c.ID)
+	file := helpers.CreateTestFile(t, "collection-delete", "stl", pgtype.UUID{})
+	defer helpers.DeleteTestFile(t, file.ID)
+	id := uuid.UUID(c.ID.Bytes).String()
+
+	body := collections.AddItemRequest{FileID: uuid.UUID(file.ID.Bytes).String()}
+	resp := helpers.MakeRequest(t, helpers.POST("/collections/"+id+"/items", body).WithURLParam("id", id), handler.AddCollectionItem)
+	require.Equal(t, http.StatusCreated, resp.Code)
+
+	resp = helpers.MakeRequest(t, helpers.DELETE("/collections/"+id).WithURLParam("id", id), handler.DeleteCollection)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// Members are not touched
+	assert.NotNil(t, helpers.GetTestFile(t, file.ID))
+
+	resp = helpers.MakeRequest(t, helpers.DELETE("/collections/"+id).WithURLParam("id", id), handler.DeleteCollection)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,174 @@
+package collections
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/handlers/collections"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// uploadCover sends data as the multipart "image" field
+func uploadCover(t *testing.T, id string, data []byte) *helpers.HTTPTestResponse {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("image", "cover.png")
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	req := helpers.PUT("/collections/"+id+"/cover", body.String()).
+		WithURLParam("id", id).
+		WithHeader("Content-Type", mw.FormDataContentType())
+	return helpers.MakeRequest(t, req, handler.UploadCover)
+}
+
+func pngImage(t *testing.T) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestCollectionCover(t *testing.T) {
+	c := helpers.CreateTestCollection(t, "test-cover-"+uuid.New().String()[:8])
+	defer helpers.DeleteTestCollection(t, c.ID)
+	id := uuid.UUID(c.ID.Bytes).String()
+	data := pngImage(t)
+
+	t.Run("no cover yet", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, helpers.GET("/collections/"+id+"/cover").WithURLParam("id", id), handler.GetCover)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("upload and serve", func(t *testing.T) {
+		resp := uploadCover(t, id, data)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "/v1/collections/"+id+"/cover", resp.GetString("cover_url"))
+
+		raw := helpers.RawRequest(t, helpers.GET("/collections/"+id+"/cover").WithURLParam("id", id), handler.GetCover)
+		require.Equal(t, http.StatusOK, raw.Code)
+		assert.Equal(t, "image/png", raw.Header().Get("Content-Type"))
+		assert.Equal(t, data, raw.Body.Bytes())
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		resp := uploadCover(t, id, []byte("solid cube\nendsolid cube\n"))
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		req := helpers.PUT("/collections/"+id+"/cover", "not multipart").WithURLParam("id", id)
+		resp := helpers.MakeRequest(t, req, handler.UploadCover)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, helpers.DELETE("/collections/"+id+"/cover").WithURLParam("id", id), handler.DeleteCover)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		_, err := os.Stat(filepath.Join(mediaDir, "covers", id+".png"))
+		assert.True(t, os.IsNotExist(err), "cover file should be removed")
+	})
+}
+
+func TestExportCollection(t *testing.T) {
+	ctx := context.Background()
+	queries := db.New(helpers.TestPool)
+	dir := t.TempDir()
+
+	c := helpers.CreateTestCollection(t, "Voron build "+uuid.New().String()[:8])
+	defer helpers.DeleteTestCollection(t, c.ID)
+	id := uuid.UUID(c.ID.Bytes).String()
+
+	root := helpers.CreateTestFolder(t, "voron-frame")
+	defer helpers.DeleteTestFolder(t, root.ID)
+	parts := helpers.CreateTestSubfolder(t, "parts", root)
+	defer helpers.DeleteTestFolder(t, parts.ID)
+
+	// create stores a library file whose content is on disk
+	create := func(name, content string, folderID pgtype.UUID) *db.File {
+		path := filepath.Join(dir, uuid.New().String()[:8]+"-"+name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		file, err := queries.CreateFile(ctx, db.CreateFileParams{
+			Path:       path,
+			FileName:   name,
+			Type:       "stl",
+			Size:       int64(len(content)),
+			ModifiedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { helpers.DeleteTestFile(t, file.ID) })
+		if folderID.Valid {
+			require.NoError(t, queries.UpdateFileFolderID(ctx, db.UpdateFileFolderIDParams{ID: file.ID, FolderID: folderID}))
+		}
+		return &file
+	}
+	bracket := create("bracket.stl", "solid bracket", pgtype.UUID{})
+	create("gantry.stl", "solid gantry", root.ID)
+	create("gear.stl", "solid gear", parts.ID)
+	missing := helpers.CreateTestFile(t, "not-on-disk", "stl", pgtype.UUID{})
+	defer helpers.DeleteTestFile(t, missing.ID)
+
+	add := func(body collections.AddItemRequest) {
+		req := helpers.POST("/collections/"+id+"/items", body).WithURLParam("id", id)
+		require.Equal(t, http.StatusCreated, helpers.MakeRequest(t, req, handler.AddCollectionItem).Code)
+	}
+	add(collections.AddItemRequest{FileID: uuid.UUID(bracket.ID.Bytes).String(), Note: "x4"})
+	add(collections.AddItemRequest{FolderID: uuid.UUID(root.ID.Bytes).String()})
+	add(collections.AddItemRequest{FileID: uuid.UUID(missing.ID.Bytes).String()})
+	require.Equal(t, http.StatusOK, uploadCover(t, id, pngImage(t)).Code)
+
+	raw := helpers.RawRequest(t, helpers.GET("/collections/"+id+"/export").WithURLParam("id", id), handler.ExportCollection)
+	require.Equal(t, http.StatusOK, raw.Code)
+	assert.Equal(t, "application/zip", raw.Header().Get("Content-Type"))
+	assert.Contains(t, raw.Header().Get("Content-Disposition"), "Voron_build_")
+
+	body := raw.Body.Bytes()
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	entries := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		entries[f.Name] = string(data)
+	}
+
+	assert.Equal(t, "solid bracket", entries["bracket.stl"])
+	assert.Equal(t, "solid gantry", entries["voron-frame/gantry.stl"])
+	assert.Equal(t, "solid gear", entries["voron-frame/parts/gear.stl"])
+	assert.Contains(t, entries, "cover.png")
+
+	var manifest collections.Manifest
+	require.NoError(t, json.Unmarshal([]byte(entries["collection.json"]), &manifest))
+	assert.Equal(t, c.Name, manifest.Name)
+	assert.Equal(t, "cover.png", manifest.Cover)
+	require.Len(t, manifest.Items, 3)
+	assert.Equal(t, "bracket.stl", manifest.Items[0].Path)
+	assert.Equal(t, "x4", manifest.Items[0].Note)
+	assert.Equal(t, "voron-frame/", manifest.Items[1].Path)
+	assert.Equal(t, []string{missing.Path}, manifest.Missing)
+}
@@ -0,0 +1,134 @@
+package collections
+
+import (
+	"net/http"
+	"testing"
+
+	"stl-manager/internal/handlers/collections"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectionItems(t *testing.T) {
+	c := helpers.CreateTestCollection(t, "test-items-"+uuid.New().String()[:8])
+	defer helpers.DeleteTestCollection(t, c.ID)
+	id := uuid.UUID(c.ID.Bytes).String()
+
+	folder := helpers.CreateTestFolder(t, "collection-items")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+	first := helpers.CreateTestFile(t, "collection-first", "stl", pgtype.UUID{})
+	defer helpers.DeleteTestFile(t, first.ID)
+	second := helpers.CreateTestFile(t, "collection-second", "stl", pgtype.UUID{})
+	defer helpers.DeleteTestFile(t, second.ID)
+
+	add := func(body interface{}) *helpers.HTTPTestResponse {
+		req := helpers.POST("/collections/"+id+"/items", body).WithURLParam("id", id)
+		return helpers.MakeRequest(t, req, handler.AddCollectionItem)
+	}
+	get := func() []interface{} {
+		resp := helpers.MakeRequest(t, helpers.GET("/collections/"+id).WithURLParam("id", id), handler.GetCollection)
+		require.Equal(t, http.StatusOK, resp.Code)
+		return resp.GetArray("items")
+	}
+	fileID := func(f pgtype.UUID) string { return uuid.UUID(f.Bytes).String() }
+
+	resp := add(collections.AddItemRequest{FileID: fileID(first.ID), Note: "print in PETG"})
+	require.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, float64(0), resp.GetFloat("position"))
+	firstItem := resp.GetString("id")
+
+	resp = add(collections.AddItemRequest{FolderID: fileID(folder.ID)})
+	require.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, float64(1), resp.GetFloat("position"))
+	assert.Equal(t, "folder", resp.GetString("type"))
+	folderItem := resp.GetString("id")
+
+	position := 0
+	resp = add(collections.AddItemRequest{FileID: fileID(second.ID), Position: &position})
+	require.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, float64(0), resp.GetFloat("position"))
+	secondItem := resp.GetString("id")
+
+	itemIDs := func() []string {
+		var ids []string
+		for _, item := range get() {
+			ids = append(ids, item.(map[string]interface{})["id"].(string))
+		}
+		return ids
+	}
+	assert.Equal(t, []string{secondItem, firstItem, folderItem}, itemIDs())
+
+	t.Run("items carry their file or folder", func(t *testing.T) {
+		items := get()
+		file := items[1].(map[string]interface{})
+		assert.Equal(t, "print in PETG", file["note"])
+		assert.Equal(t, first.FileName, file["file"].(map[string]interface{})["file_name"])
+		dir := items[2].(map[string]interface{})
+		assert.Equal(t, folder.Name, dir["folder"].(map[string]interface{})["name"])
+		assert.Nil(t, dir["file"])
+	})
+
+	t.Run("invalid items fail", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			body     interface{}
+			wantCode int
+		}{
+			{"already in the collection", collections.AddItemRequest{FileID: fileID(first.ID)}, http.StatusConflict},
+			{"file and folder", collections.AddItemRequest{FileID: fileID(first.ID), FolderID: fileID(folder.ID)}, http.StatusBadRequest},
+			{"neither file nor folder", collections.AddItemRequest{Note: "empty"}, http.StatusBadRequest},
+			{"invalid file_id", collections.AddItemRequest{FileID: "invalid"}, http.StatusBadRequest},
+			{"file not found", collections.AddItemRequest{FileID: uuid.New().String()}, http.StatusNotFound},
+			{"folder not found", collections.AddItemRequest{FolderID: uuid.New().String()}, http.StatusNotFound},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.wantCode, add(tt.body).Code)
+			})
+		}
+	})
+
+	t.Run("move and edit the note", func(t *testing.T) {
+		note := "second pass"
+		last := 10
+		body := collections.UpdateItemRequest{Note: &note, Position: &last}
+		req := helpers.PATCH("/collections/"+id+"/items/"+secondItem, body).
+			WithURLParam("id", id).
+			WithURLParam("itemId", secondItem)
+		resp := helpers.MakeRequest(t, req, handler.UpdateCollectionItem)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, float64(2), resp.GetFloat("position"), "a position past the end moves the item last")
+		assert.Equal(t, "second pass", resp.GetString("note"))
+
+		assert.Equal(t, []string{firstItem, folderItem, secondItem}, itemIDs())
+	})
+
+	t.Run("update unknown item", func(t *testing.T) {
+		missing := uuid.New().String()
+		note := "x"
+		req := helpers.PATCH("/collections/"+id+"/items/"+missing, collections.UpdateItemRequest{Note: &note}).
+			WithURLParam("id", id).
+			WithURLParam("itemId", missing)
+		assert.Equal(t, http.StatusNotFound, helpers.MakeRequest(t, req, handler.UpdateCollectionItem).Code)
+	})
+
+	t.Run("remove closes the gap", func(t *testing.T) {
+		req := helpers.DELETE("/collections/"+id+"/items/"+firstItem).
+			WithURLParam("id", id).
+			WithURLParam("itemId", firstItem)
+		require.Equal(t, http.StatusOK, helpers.MakeRequest(t, req, handler.DeleteCollectionItem).Code)
+
+		items := get()
+		require.Len(t, items, 2)
+		for i, item := range items {
+			assert.Equal(t, float64(i), item.(map[string]interface{})["position"])
+		}
+
+		resp := helpers.MakeRequest(t, req, handler.DeleteCollectionItem)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
@@ -0,0 +1,33 @@
+package collections
+
+import (
+	"os"
+	"testing"
+
+	"stl-manager/internal/handlers/collections"
+	"stl-manager/internal/media"
+	"stl-manager/tests/integration/helpers"
+)
+
+var handler *collections.Handler
+
+// mediaDir holds the covers uploaded by the tests
+var mediaDir string
+
+func TestMain(m *testing.M) {
+	if err := helpers.SetupTestDatabase(); err != nil {
+		panic(err)
+	}
+
+	var err error
+	mediaDir, err = os.MkdirTemp("", "collections-media-")
+	if err != nil {
+		panic(err)
+	}
+	handler = collections.New(helpers.TestPool, media.NewStore(mediaDir), helpers.TestLogger)
+
+	code := m.Run()
+	os.RemoveAll(mediaDir)
+	helpers.CleanupTestDatabase()
+	os.Exit(code)
+}
@@ -0,0 +1,95 @@
+package events
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"stl-manager/internal/events"
+	eventshandler "stl-manager/internal/handlers/events"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamLibrary(t *testing.T) {
+	tests := []struct {
+		name      string
+		topic     string
+		eventType string
+		wantEvent bool
+	}{
+		{
+			name:      "library event is delivered",
+			topic:     events.TopicLibrary,
+			eventType: events.TypeCategoriesUpdated,
+			wantEvent: true,
+		},
+		{
+			name:      "scan event is not delivered",
+			topic:     "scan:00000000-0000-0000-0000-000000000000",
+			eventType: "progress",
+			wantEvent: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.GET("/events")
+			resp := helpers.StreamRequest(t, req, handler.StreamLibrary, func() {
+				broker.Publish(tt.topic, tt.eventType, map[string]string{"action": "created"})
+			}, 300*time.Millisecond)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, "text/event-stream", resp.Header().Get("Content-Type"))
+
+			body := resp.Body.String()
+			assert.Contains(t, body, "event: connected")
+			if tt.wantEvent {
+				assert.Contains(t, body, "event: "+tt.eventType)
+			} else {
+				assert.NotContains(t, body, "event: "+tt.eventType)
+			}
+		})
+	}
+}
+
+func TestStreamLibraryClosedBroker(t *testing.T) {
+	closed := events.NewBroker()
+	closed.Close()
+	h := eventshandler.New(closed, "test-api-key", helpers.TestLogger)
+
+	resp := helpers.StreamRequest(t, helpers.GET("/events"), h.StreamLibrary, nil, 2*time.Second)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "event: connected")
+}
+
+func TestCreateStreamToken(t *testing.T) {
+	resp := helpers.MakeRequest(t, helpers.POST("/events/token", nil), handler.CreateStreamToken)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	helpers.AssertHasFields(t, resp.Body, "token", "expires_at")
+
+	token, _ := resp.Body["token"].(string)
+	other := eventshandler.New(broker, "other-api-key", helpers.TestLogger)
+
+	tests := []struct {
+		name    string
+		handler *eventshandler.Handler
+		token   string
+		want    bool
+	}{
+		{name: "issued token", handler: handler, token: token, want: true},
+		{name: "signed with another key", handler: other, token: token, want: false},
+		{name: "tampered expiry", handler: handler, token: "9" + token, want: false},
+		{name: "expired", handler: handler, token: "1." + strings.SplitN(token, ".", 2)[1], want: false},
+		{name: "empty", handler: handler, token: "", want: false},
+		{name: "api key", handler: handler, token: "test-api-key", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.handler.ValidStreamToken(tt.token))
+		})
+	}
+}
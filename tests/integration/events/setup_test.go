@@ -0,0 +1,28 @@
+package events
+
+import (
+	"os"
+	"testing"
+
+	"stl-manager/internal/events"
+	eventshandler "stl-manager/internal/handlers/events"
+	"stl-manager/tests/integration/helpers"
+)
+
+var (
+	handler *eventshandler.Handler
+	broker  *events.Broker
+)
+
+func TestMain(m *testing.M) {
+	if err := helpers.SetupTestDatabase(); err != nil {
+		panic(err)
+	}
+
+	broker = events.NewBroker()
+	handler = eventshandler.New(broker, "test-api-key", helpers.TestLogger)
+
+	code := m.Run()
+	helpers.CleanupTestDatabase()
+	os.Exit(code)
+}
@@ -0,0 +1,280 @@
+package files
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/handlers/files"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bulk(t *testing.T, body interface{}) *helpers.HTTPTestResponse {
+	req := helpers.POST("/files/bulk", body)
+	return helpers.MakeRequest(t, req, handler.BulkUpdateFiles)
+}
+
+func idOf(id pgtype.UUID) string {
+	return uuid.UUID(id.Bytes).String()
+}
+
+func TestBulkUpdateFilesValidation(t *testing.T) {
+	file := helpers.CreateTestFile(t, "bulk-validation", "stl", pgtype.UUID{})
+	defer helpers.DeleteTestFile(t, file.ID)
+
+	tests := []struct {
+		name     string
+		body     interface{}
+		wantCode int
+	}{
+		{
+			name:     "missing action",
+			body:     files.BulkRequest{FileIDs: []string{idOf(file.ID)}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown action",
+			body:     files.BulkRequest{FileIDs: []string{idOf(file.ID)}, Action: "archive"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "no selection",
+			body:     files.BulkRequest{Action: files.BulkFavorite},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "file ids and filter",
+			body: files.BulkRequest{
+				FileIDs: []string{idOf(file.ID)},
+				Filter:  map[string]string{"type": "stl"},
+				Action:  files.BulkFavorite,
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown filter",
+			body:     files.BulkRequest{Filter: map[string]string{"name": "dragon"}, Action: files.BulkFavorite},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid filter",
+			body:     files.BulkRequest{Filter: map[string]string{"min_size": "-1"}, Action: files.BulkFavorite},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid file id",
+			body:     files.BulkRequest{FileIDs: []string{"invalid"}, Action: files.BulkFavorite},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "categories required",
+			body:     files.BulkRequest{FileIDs: []string{idOf(file.ID)}, Action: files.BulkAddCategories},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "category not found",
+			body: files.BulkRequest{
+				FileIDs:     []string{idOf(file.ID)},
+				Action:      files.BulkAddCategories,
+				CategoryIDs: []string{uuid.New().String()},
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "move folder not found",
+			body: files.BulkRequest{
+				FileIDs:  []string{idOf(file.ID)},
+				Action:   files.BulkMove,
+				FolderID: uuid.New().String(),
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "reclassify without OpenAI",
+			body:     files.BulkRequest{FileIDs: []string{idOf(file.ID)}, Action: files.BulkReclassify},
+			wantCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:     "invalid request body",
+			body:     "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := bulk(t, tt.body)
+			assert.Equal(t, tt.wantCode, resp.Code)
+		})
+	}
+}
+
+func TestBulkUpdateFilesNotFoundRollsBack(t *testing.T) {
+	file := helpers.CreateTestFile(t, "bulk-missing", "stl", pgtype.UUID{})
+	defer helpers.DeleteTestFile(t, file.ID)
+
+	missing := uuid.New().String()
+	resp := bulk(t, files.BulkRequest{
+		FileIDs: []string{idOf(file.ID), missing},
+		Action:  files.BulkFavorite,
+	})
+	require.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Equal(t, false, resp.Body["committed"])
+	assert.Equal(t, float64(1), resp.Body["failed"])
+
+	statuses := map[string]string{}
+	for _, item := range resp.Body["results"].([]interface{}) {
+		result := item.(map[string]interface{})
+		statuses[result["file_id"].(string)] = result["status"].(string)
+	}
+	assert.Equal(t, files.BulkStatusNotFound, statuses[missing])
+	assert.Equal(t, files.BulkStatusSkipped, statuses[idOf(file.ID)])
+	assert.False(t, helpers.GetTestFile(t, file.ID).Favorite)
+}
+
+func TestBulkUpdateFilesActions(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "bulk-folder")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+
+	file1 := helpers.CreateTestFile(t, "bulk-one", "stl", folder.ID)
+	defer helpers.DeleteTestFile(t, file1.ID)
+	file2 := helpers.CreateTestFile(t, "bulk-two", "zip", folder.ID)
+	defer helpers.DeleteTestFile(t, file2.ID)
+
+	cat := helpers.CreateTestCategory(t, "bulk-cat")
+	defer helpers.DeleteTestCategory(t, cat.ID)
+
+	queries := db.New(helpers.TestPool)
+	ids := []string{idOf(file1.ID), idOf(file2.ID)}
+	categoryCount := func(file *db.File) int {
+		categories, err := queries.GetFileCategories(context.Background(), file.ID)
+		require.NoError(t, err)
+		return len(categories)
+	}
+
+	t.Run("add categories", func(t *testing.T) {
+		resp := bulk(t, files.BulkRequest{FileIDs: ids, Action: files.BulkAddCategories, CategoryIDs: []string{idOf(cat.ID)}})
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, true, resp.Body["committed"])
+		assert.Equal(t, float64(2), resp.Body["succeeded"])
+		assert.Len(t, resp.Body["results"], 2)
+		assert.Equal(t, 1, categoryCount(file1))
+		assert.Equal(t, 1, categoryCount(file2))
+	})
+
+	t.Run("filter matches merged category names", func(t *testing.T) {
+		ctx := context.Background()
+		_, err := helpers.TestPool.Exec(ctx, "INSERT INTO category_aliases (alias, category_id) VALUES ('bulk-cat-old', $1)", cat.ID)
+		require.NoError(t, err)
+		defer helpers.TestPool.Exec(ctx, "DELETE FROM category_aliases WHERE alias = 'bulk-cat-old'")
+
+		resp := bulk(t, files.BulkRequest{
+			Filter: map[string]string{"category": "bulk-cat-old", "type": "stl"},
+			Action: files.BulkFavorite,
+		})
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, float64(1), resp.Body["total"])
+		assert.True(t, helpers.GetTestFile(t, file1.ID).Favorite)
+		assert.False(t, helpers.GetTestFile(t, file2.ID).Favorite)
+	})
+
+	t.Run("remove categories by filter", func(t *testing.T) {
+		resp := bulk(t, files.BulkRequest{
+			Filter:      map[string]string{"folder_id": idOf(folder.ID), "type": "zip"},
+			Action:      files.BulkRemoveCategories,
+			CategoryIDs: []string{idOf(cat.ID)},
+		})
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, float64(1), resp.Body["total"])
+		assert.Equal(t, 1, categoryCount(file1))
+		assert.Equal(t, 0, categoryCount(file2))
+	})
+
+	t.Run("favorite and unfavorite", func(t *testing.T) {
+		resp := bulk(t, files.BulkRequest{FileIDs: ids, Action: files.BulkFavorite})
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.True(t, helpers.GetTestFile(t, file1.ID).Favorite)
+		assert.True(t, helpers.GetTestFile(t, file2.ID).Favorite)
+
+		resp = bulk(t, files.BulkRequest{FileIDs: ids[:1], Action: files.BulkUnfavorite})
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.False(t, helpers.GetTestFile(t, file1.ID).Favorite)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		resp := bulk(t, files.BulkRequest{FileIDs: ids[1:], Action: files.BulkDelete})
+		require.Equal(t, http.StatusOK, resp.Code)
+		_, err := queries.GetFile(context.Background(), file2.ID)
+		assert.Error(t, err)
+	})
+}
+
+func TestBulkMoveFiles(t *testing.T) {
+	ctx := context.Background()
+	queries := db.New(helpers.TestPool)
+
+	root := t.TempDir()
+	srcDir := filepath.Join(root, "src")
+	dstDir := filepath.Join(root, "dst")
+	require.NoError(t, os.MkdirAll(srcDir, 0o755))
+	require.NoError(t, os.MkdirAll(dstDir, 0o755))
+
+	dst, err := queries.CreateFolder(ctx, db.CreateFolderParams{Name: "dst", Path: dstDir})
+	require.NoError(t, err)
+	defer helpers.DeleteTestFolder(t, dst.ID)
+
+	createOnDisk := func(name string) db.File {
+		path := filepath.Join(srcDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("solid"), 0o644))
+		file, err := queries.CreateFile(ctx, db.CreateFileParams{
+			Path:       path,
+			FileName:   name,
+			Type:       "stl",
+			Size:       5,
+			ModifiedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		})
+		require.NoError(t, err)
+		return file
+	}
+	moved := createOnDisk("moved.stl")
+	defer helpers.DeleteTestFile(t, moved.ID)
+
+	t.Run("moves file on disk and in the catalogue", func(t *testing.T) {
+		resp := bulk(t, files.BulkRequest{FileIDs: []string{idOf(moved.ID)}, Action: files.BulkMove, FolderID: idOf(dst.ID)})
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		updated := helpers.GetTestFile(t, moved.ID)
+		assert.Equal(t, filepath.Join(dstDir, "moved.stl"), updated.Path)
+		assert.Equal(t, dst.ID, updated.FolderID)
+		assert.FileExists(t, filepath.Join(dstDir, "moved.stl"))
+		assert.NoFileExists(t, filepath.Join(srcDir, "moved.stl"))
+	})
+
+	t.Run("failed rename rolls back every file", func(t *testing.T) {
+		onDisk := createOnDisk("kept.stl")
+		defer helpers.DeleteTestFile(t, onDisk.ID)
+		stale := helpers.CreateTestFile(t, "stale", "stl", pgtype.UUID{})
+		defer helpers.DeleteTestFile(t, stale.ID)
+
+		resp := bulk(t, files.BulkRequest{
+			FileIDs:  []string{idOf(onDisk.ID), idOf(stale.ID)},
+			Action:   files.BulkMove,
+			FolderID: idOf(dst.ID),
+		})
+		require.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+		assert.Equal(t, false, resp.Body["committed"])
+
+		assert.Equal(t, onDisk.Path, helpers.GetTestFile(t, onDisk.ID).Path)
+		assert.FileExists(t, onDisk.Path)
+		assert.NoFileExists(t, filepath.Join(dstDir, "kept.stl"))
+	})
+}
@@ -0,0 +1,118 @@
+package files
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"stl-manager/internal/db"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// facetCounts maps each facet entry's key field to its count
+func facetCounts(t *testing.T, facets map[string]interface{}, facet, key string) map[string]float64 {
+	entries, ok := facets[facet].([]interface{})
+	require.True(t, ok, "facet %s should be an array", facet)
+	counts := map[string]float64{}
+	for _, entry := range entries {
+		e := entry.(map[string]interface{})
+		counts[e[key].(string)] = e["count"].(float64)
+	}
+	return counts
+}
+
+func TestListFilesFacets(t *testing.T) {
+	ctx := context.Background()
+	queries := db.New(helpers.TestPool)
+
+	root := helpers.CreateTestFolder(t, "facets-root")
+	defer helpers.DeleteTestFolder(t, root.ID)
+	sub := helpers.CreateTestSubfolder(t, "facets-sub", root)
+	defer helpers.DeleteTestFolder(t, sub.ID)
+
+	minis := helpers.CreateTestCategory(t, "facets-minis")
+	defer helpers.DeleteTestCategory(t, minis.ID)
+	dragons := helpers.CreateTestSubcategory(t, "facets-dragons", minis)
+	defer helpers.DeleteTestCategory(t, dragons.ID)
+	terrain := helpers.CreateTestCategory(t, "facets-terrain")
+	defer helpers.DeleteTestCategory(t, terrain.ID)
+
+	create := func(name, fileType string, size int64, folder *db.Folder, categories ...*db.Category) {
+		file, err := queries.CreateFile(ctx, db.CreateFileParams{
+			Path:       folder.Path + "/" + name + "-" + uuid.New().String()[:8] + "." + fileType,
+			FileName:   name + "." + fileType,
+			Type:       fileType,
+			Size:       size,
+			ModifiedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { helpers.DeleteTestFile(t, file.ID) })
+		require.NoError(t, queries.UpdateFileFolderID(ctx, db.UpdateFileFolderIDParams{ID: file.ID, FolderID: folder.ID}))
+		for _, cat := range categories {
+			require.NoError(t, queries.AddFileCategory(ctx, db.AddFileCategoryParams{FileID: file.ID, CategoryID: cat.ID}))
+		}
+	}
+
+	create("red-dragon", "stl", 3000, root, dragons, terrain)
+	create("blue-dragon", "zip", 2<<20, sub, dragons)
+	create("castle-wall", "stl", 2000, sub, terrain)
+	create("loose-part", "rar", 500, sub)
+
+	folderID := uuid.UUID(root.ID.Bytes).String()
+
+	t.Run("facets cover every matching file", func(t *testing.T) {
+		req := helpers.GET("/files").
+			WithQueryParam("folder_id", folderID).
+			WithQueryParam("page_size", "1").
+			WithQueryParam("facets", "true")
+		resp := helpers.MakeRequest(t, req, handler.ListFiles)
+		require.Equal(t, http.StatusOK, resp.Code)
+		facets := resp.GetMap("facets")
+
+		categories := facetCounts(t, facets, "categories", "name")
+		assert.Equal(t, float64(2), categories[minis.Name], "parent counts files of its subcategories")
+		assert.Equal(t, float64(2), categories[dragons.Name])
+		assert.Equal(t, float64(2), categories[terrain.Name])
+
+		assert.Equal(t, map[string]float64{"stl": 2, "zip": 1, "rar": 1}, facetCounts(t, facets, "types", "type"))
+		assert.Equal(t, map[string]float64{root.Name: 4}, facetCounts(t, facets, "folders", "name"))
+		assert.Equal(t, map[string]float64{
+			"lt_1mb":     3,
+			"1mb_10mb":   1,
+			"10mb_100mb": 0,
+			"gte_100mb":  0,
+		}, facetCounts(t, facets, "sizes", "key"))
+	})
+
+	t.Run("facets follow the filter", func(t *testing.T) {
+		req := helpers.GET("/files").
+			WithQueryParam("folder_id", folderID).
+			WithQueryParam("type", "stl").
+			WithQueryParam("facets", "true")
+		resp := helpers.MakeRequest(t, req, handler.ListFiles)
+		require.Equal(t, http.StatusOK, resp.Code)
+		facets := resp.GetMap("facets")
+
+		assert.Equal(t, map[string]float64{"stl": 2}, facetCounts(t, facets, "types", "type"))
+		categories := facetCounts(t, facets, "categories", "name")
+		assert.Equal(t, float64(1), categories[dragons.Name])
+		assert.Equal(t, float64(2), categories[terrain.Name])
+	})
+
+	t.Run("no facets by default", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, helpers.GET("/files").WithQueryParam("folder_id", folderID), handler.ListFiles)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.NotContains(t, resp.Body, "facets")
+	})
+
+	t.Run("invalid facets", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, helpers.GET("/files").WithQueryParam("facets", "maybe"), handler.ListFiles)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
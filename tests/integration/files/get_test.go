@@ -49,6 +49,11 @@ func TestGetFile(t *testing.T) {
 				assert.NotNil(t, resp.Body["id"])
 				assert.NotNil(t, resp.Body["file_name"])
 				assert.NotNil(t, resp.Body["categories"])
+				assert.Equal(t, float64(0), resp.Body["print_count"])
+				assert.Nil(t, resp.Body["success_rate"])
+				assert.Equal(t, false, resp.Body["favorite"])
+				assert.Nil(t, resp.Body["rating"])
+				assert.Empty(t, resp.GetMap("custom_fields"))
 			}
 		})
 	}
@@ -1,12 +1,17 @@
 package files
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
+	"stl-manager/internal/db"
 	"stl-manager/tests/integration/helpers"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListFiles(t *testing.T) {
@@ -41,7 +46,31 @@ func TestListFiles(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			resp := helpers.MakeRequest(t, tt.req, handler.ListFiles)
 			assert.Equal(t, tt.want, resp.Code)
-			helpers.AssertPaginatedResponse(t, resp)
+			helpers.AssertCursorPaginatedResponse(t, resp)
 		})
 	}
 }
+
+func TestListFilesByParentCategory(t *testing.T) {
+	parent := helpers.CreateTestCategory(t, "list-parent")
+	defer helpers.DeleteTestCategory(t, parent.ID)
+	child := helpers.CreateTestSubcategory(t, "list-child", parent)
+	defer helpers.DeleteTestCategory(t, child.ID)
+
+	file := helpers.CreateTestFile(t, "list-child-file", "stl", pgtype.UUID{})
+	defer helpers.DeleteTestFile(t, file.ID)
+	err := db.New(helpers.TestPool).AddFileCategory(context.Background(), db.AddFileCategoryParams{
+		FileID:     file.ID,
+		CategoryID: child.ID,
+	})
+	require.NoError(t, err)
+
+	resp := helpers.MakeRequest(t, helpers.GET("/files").WithQueryParam("category", parent.Name), handler.ListFiles)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	ids := []string{}
+	for _, item := range resp.GetArray("items") {
+		ids = append(ids, item.(map[string]interface{})["id"].(string))
+	}
+	assert.Contains(t, ids, uuid.UUID(file.ID.Bytes).String(), "parent category includes files of its descendants")
+}
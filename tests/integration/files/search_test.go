@@ -0,0 +1,486 @@
+package files
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/handlers/metadata"
+	"stl-manager/internal/handlers/prints"
+	"stl-manager/internal/scanner"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFilesFilters(t *testing.T) {
+	ctx := context.Background()
+	queries := db.New(helpers.TestPool)
+
+	root := helpers.CreateTestFolder(t, "search-root")
+	defer helpers.DeleteTestFolder(t, root.ID)
+	sub := helpers.CreateTestSubfolder(t, "search-sub", root)
+	defer helpers.DeleteTestFolder(t, sub.ID)
+
+	minis := helpers.CreateTestCategory(t, "search-minis")
+	defer helpers.DeleteTestCategory(t, minis.ID)
+	dragons := helpers.CreateTestSubcategory(t, "search-dragons", minis)
+	defer helpers.DeleteTestCategory(t, dragons.ID)
+	terrain := helpers.CreateTestCategory(t, "search-terrain")
+	defer helpers.DeleteTestCategory(t, terrain.ID)
+
+	day := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	create := func(name, fileType string, size int64, modified time.Time, folder *db.Folder, categories ...*db.Category) string {
+		file, err := queries.CreateFile(ctx, db.CreateFileParams{
+			Path:       folder.Path + "/" + name + "-" + uuid.New().String()[:8] + "." + fileType,
+			FileName:   name + "." + fileType,
+			Type:       fileType,
+			Size:       size,
+			ModifiedAt: pgtype.Timestamptz{Time: modified, Valid: true},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { helpers.DeleteTestFile(t, file.ID) })
+		require.NoError(t, queries.UpdateFileFolderID(ctx, db.UpdateFileFolderIDParams{ID: file.ID, FolderID: folder.ID}))
+		for _, cat := range categories {
+			require.NoError(t, queries.AddFileCategory(ctx, db.AddFileCategoryParams{FileID: file.ID, CategoryID: cat.ID}))
+		}
+		return uuid.UUID(file.ID.Bytes).String()
+	}
+
+	red := create("red-dragon", "stl", 3000, day, root, dragons, terrain)
+	blue := create("blue-dragon", "zip", 1000, day.AddDate(0, 1, 0), sub, dragons)
+	castle := create("castle-wall", "stl", 2000, day.AddDate(0, 2, 0), sub, terrain)
+	loose := create("loose-part", "rar", 500, day.AddDate(0, 3, 0), sub)
+
+	folderID := uuid.UUID(root.ID.Bytes).String()
+	tests := []struct {
+		name   string
+		params map[string]string
+		want   []string
+	}{
+		{
+			name:   "folder subtree sorted by name",
+			params: map[string]string{},
+			want:   []string{blue, castle, loose, red},
+		},
+		{
+			name:   "text with category",
+			params: map[string]string{"q": "dragon", "category": terrain.Name},
+			want:   []string{red},
+		},
+		{
+			name:   "parent category includes descendants",
+			params: map[string]string{"category": minis.Name, "sort": "size"},
+			want:   []string{blue, red},
+		},
+		{
+			name:   "any category",
+			params: map[string]string{"category": dragons.Name + "," + terrain.Name, "sort": "modified", "order": "desc"},
+			want:   []string{castle, blue, red},
+		},
+		{
+			name:   "all categories",
+			params: map[string]string{"category": dragons.Name + "," + terrain.Name, "category_match": "all"},
+			want:   []string{red},
+		},
+		{
+			name:   "type and size range",
+			params: map[string]string{"type": "stl", "min_size": "2000", "max_size": "2500"},
+			want:   []string{castle},
+		},
+		{
+			name:   "modified range",
+			params: map[string]string{"modified_after": "2024-06-15", "modified_before": "2024-08-15"},
+			want:   []string{blue, castle},
+		},
+		{
+			name:   "uncategorized only",
+			params: map[string]string{"uncategorized": "true"},
+			want:   []string{loose},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.GET("/files").WithQueryParam("folder_id", folderID)
+			for key, value := range tt.params {
+				req = req.WithQueryParam(key, value)
+			}
+			resp := helpers.MakeRequest(t, req, handler.ListFiles)
+			require.Equal(t, http.StatusOK, resp.Code)
+			helpers.AssertCursorPaginatedResponse(t, resp)
+
+			ids := []string{}
+			for _, item := range resp.GetArray("items") {
+				ids = append(ids, item.(map[string]interface{})["id"].(string))
+			}
+			assert.Equal(t, tt.want, ids)
+			assert.Equal(t, float64(len(tt.want)), resp.GetFloat("total"), "total counts only matching files")
+		})
+	}
+
+	t.Run("total is exact across pages", func(t *testing.T) {
+		req := helpers.GET("/files").
+			WithQueryParam("folder_id", folderID).
+			WithQueryParam("page_size", "1").
+			WithQueryParam("page", "2")
+		resp := helpers.MakeRequest(t, req, handler.ListFiles)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Len(t, resp.GetArray("items"), 1)
+		assert.Equal(t, float64(4), resp.GetFloat("total"))
+		assert.Equal(t, float64(4), resp.GetFloat("total_pages"))
+	})
+
+	t.Run("cursor pages follow the sort", func(t *testing.T) {
+		req := helpers.GET("/files").
+			WithQueryParam("folder_id", folderID).
+			WithQueryParam("page_size", "1").
+			WithQueryParam("sort", "size").
+			WithQueryParam("order", "desc")
+		assert.Equal(t, []string{red, castle, blue, loose}, helpers.FollowCursor(t, req, handler.ListFiles))
+
+		req = helpers.GET("/files").
+			WithQueryParam("folder_id", folderID).
+			WithQueryParam("page_size", "1").
+			WithQueryParam("q", "dragon")
+		assert.ElementsMatch(t, []string{red, blue}, helpers.FollowCursor(t, req, handler.ListFiles))
+	})
+
+	t.Run("cursor from another sort", func(t *testing.T) {
+		req := helpers.GET("/files").
+			WithQueryParam("folder_id", folderID).
+			WithQueryParam("page_size", "1")
+		resp := helpers.MakeRequest(t, req, handler.ListFiles)
+		require.Equal(t, http.StatusOK, resp.Code)
+		cursor := resp.GetString("next_cursor")
+		require.NotEmpty(t, cursor)
+
+		req = req.WithQueryParam("cursor", cursor).WithQueryParam("sort", "size")
+		resp = helpers.MakeRequest(t, req, handler.ListFiles)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestListFilesFilterValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{name: "invalid sort", key: "sort", value: "color"},
+		{name: "invalid order", key: "order", value: "up"},
+		{name: "relevance without query", key: "sort", value: "relevance"},
+		{name: "invalid category match", key: "category_match", value: "some"},
+		{name: "invalid folder id", key: "folder_id", value: "invalid"},
+		{name: "invalid collection id", key: "collection_id", value: "invalid"},
+		{name: "negative size", key: "min_size", value: "-1"},
+		{name: "invalid date", key: "modified_after", value: "yesterday"},
+		{name: "invalid uncategorized", key: "uncategorized", value: "maybe"},
+		{name: "invalid printed", key: "printed", value: "sometimes"},
+		{name: "invalid favorite", key: "favorite", value: "maybe"},
+		{name: "min rating out of range", key: "min_rating", value: "6"},
+		{name: "field without value", key: "field", value: "designer"},
+		{name: "invalid license", key: "license", value: "GPL"},
+		{name: "invalid commercial", key: "commercial", value: "maybe"},
+		{name: "invalid cursor", key: "cursor", value: "not-a-cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := helpers.MakeRequest(t, helpers.GET("/files").WithQueryParam(tt.key, tt.value), handler.ListFiles)
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+		})
+	}
+}
+
+func TestListFilesWordSearch(t *testing.T) {
+	ctx := context.Background()
+	queries := db.New(helpers.TestPool)
+
+	folder := helpers.CreateTestFolder(t, "GrimbleDungeon_Crawlers")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+	heroes := helpers.CreateTestCategory(t, "grimble-heroes")
+	defer helpers.DeleteTestCategory(t, heroes.ID)
+
+	orc := helpers.CreateTestFile(t, "Grimble_Orc_Warrior_v2_supported_32mm", "stl", folder.ID)
+	defer helpers.DeleteTestFile(t, orc.ID)
+	priest := helpers.CreateTestFile(t, "GrimbleWarriorPriest", "stl", pgtype.UUID{})
+	defer helpers.DeleteTestFile(t, priest.ID)
+	gate := helpers.CreateTestFile(t, "grimble_crawlers_gate", "stl", pgtype.UUID{})
+	defer helpers.DeleteTestFile(t, gate.ID)
+	knight := helpers.CreateTestFile(t, "grimble-knight", "stl", pgtype.UUID{})
+	defer helpers.DeleteTestFile(t, knight.ID)
+	require.NoError(t, queries.AddFileCategory(ctx, db.AddFileCategoryParams{FileID: knight.ID, CategoryID: heroes.ID}))
+
+	orcID := uuid.UUID(orc.ID.Bytes).String()
+	priestID := uuid.UUID(priest.ID.Bytes).String()
+	gateID := uuid.UUID(gate.ID.Bytes).String()
+	knightID := uuid.UUID(knight.ID.Bytes).String()
+
+	// search returns the matching items by ID, in relevance order
+	search := func(t *testing.T, q string) ([]string, map[string]map[string]interface{}) {
+		req := helpers.GET("/files").WithQueryParam("q", q).WithQueryParam("page_size", "100")
+		resp := helpers.MakeRequest(t, req, handler.ListFiles)
+		require.Equal(t, http.StatusOK, resp.Code)
+		ids := []string{}
+		items := map[string]map[string]interface{}{}
+		for _, item := range resp.GetArray("items") {
+			m := item.(map[string]interface{})
+			ids = append(ids, m["id"].(string))
+			items[m["id"].(string)] = m
+		}
+		return ids, items
+	}
+
+	t.Run("words split on separators and highlighted", func(t *testing.T) {
+		ids, items := search(t, "grimble orc warrior")
+		require.Contains(t, ids, orcID)
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"start": float64(0), "end": float64(7)},
+			map[string]interface{}{"start": float64(8), "end": float64(11)},
+			map[string]interface{}{"start": float64(12), "end": float64(19)},
+		}, items[orcID]["highlight"])
+	})
+
+	t.Run("camelCase and prefixes", func(t *testing.T) {
+		ids, items := search(t, "grimble warr priest")
+		require.Contains(t, ids, priestID)
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"start": float64(0), "end": float64(7)},
+			map[string]interface{}{"start": float64(7), "end": float64(11)},
+			map[string]interface{}{"start": float64(14), "end": float64(20)},
+		}, items[priestID]["highlight"])
+	})
+
+	t.Run("folder names", func(t *testing.T) {
+		ids, _ := search(t, "grimble dungeon")
+		assert.Contains(t, ids, orcID)
+	})
+
+	t.Run("name matches rank above folder matches", func(t *testing.T) {
+		ids, _ := search(t, "grimble crawlers")
+		require.Contains(t, ids, gateID)
+		require.Contains(t, ids, orcID)
+		assert.Equal(t, gateID, ids[0])
+	})
+
+	t.Run("categories follow renames", func(t *testing.T) {
+		ids, _ := search(t, "grimble heroes")
+		assert.Contains(t, ids, knightID)
+
+		_, err := queries.UpdateCategory(ctx, db.UpdateCategoryParams{ID: heroes.ID, Name: "grimble-paladins"})
+		require.NoError(t, err)
+		ids, _ = search(t, "grimble paladins")
+		assert.Contains(t, ids, knightID)
+	})
+}
+
+func TestListFilesByCollection(t *testing.T) {
+	ctx := context.Background()
+	queries := db.New(helpers.TestPool)
+
+	root := helpers.CreateTestFolder(t, "collection-root")
+	defer helpers.DeleteTestFolder(t, root.ID)
+	sub := helpers.CreateTestSubfolder(t, "collection-sub", root)
+	defer helpers.DeleteTestFolder(t, sub.ID)
+
+	picked := helpers.CreateTestFile(t, "collection-picked", "stl", pgtype.UUID{})
+	defer helpers.DeleteTestFile(t, picked.ID)
+	nested := helpers.CreateTestFile(t, "collection-nested", "stl", sub.ID)
+	defer helpers.DeleteTestFile(t, nested.ID)
+	outside := helpers.CreateTestFile(t, "collection-outside", "stl", pgtype.UUID{})
+	defer helpers.DeleteTestFile(t, outside.ID)
+
+	collection := helpers.CreateTestCollection(t, "test-filter-"+uuid.New().String()[:8])
+	defer helpers.DeleteTestCollection(t, collection.ID)
+	_, err := queries.AddCollectionItem(ctx, db.AddCollectionItemParams{CollectionID: collection.ID, FileID: picked.ID})
+	require.NoError(t, err)
+	_, err = queries.AddCollectionItem(ctx, db.AddCollectionItemParams{CollectionID: collection.ID, FolderID: root.ID})
+	require.NoError(t, err)
+
+	req := helpers.GET("/files").WithQueryParam("collection_id", uuid.UUID(collection.ID.Bytes).String())
+	resp := helpers.MakeRequest(t, req, handler.ListFiles)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var names []string
+	for _, item := range resp.GetArray("items") {
+		names = append(names, item.(map[string]interface{})["file_name"].(string))
+	}
+	assert.ElementsMatch(t, []string{picked.FileName, nested.FileName}, names)
+}
+
+func TestListFilesByPrinted(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "printed-filter")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+
+	never := helpers.CreateTestFile(t, "printed-never", "stl", folder.ID)
+	defer helpers.DeleteTestFile(t, never.ID)
+	failed := helpers.CreateTestFile(t, "printed-failed", "stl", folder.ID)
+	defer helpers.DeleteTestFile(t, failed.ID)
+	succeeded := helpers.CreateTestFile(t, "printed-success", "stl", folder.ID)
+	defer helpers.DeleteTestFile(t, succeeded.ID)
+
+	helpers.CreateTestPrint(t, failed.ID, prints.ResultFailed, time.Now())
+	helpers.CreateTestPrint(t, succeeded.ID, prints.ResultPartial, time.Now().Add(-time.Hour))
+	helpers.CreateTestPrint(t, succeeded.ID, prints.ResultSuccess, time.Now())
+
+	tests := []struct {
+		printed string
+		want    []string
+	}{
+		{printed: "never", want: []string{never.FileName}},
+		{printed: "any", want: []string{failed.FileName, succeeded.FileName}},
+		{printed: "success", want: []string{succeeded.FileName}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.printed, func(t *testing.T) {
+			req := helpers.GET("/files").
+				WithQueryParam("folder_id", uuid.UUID(folder.ID.Bytes).String()).
+				WithQueryParam("printed", tt.printed)
+			resp := helpers.MakeRequest(t, req, handler.ListFiles)
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			var names []string
+			for _, item := range resp.GetArray("items") {
+				names = append(names, item.(map[string]interface{})["file_name"].(string))
+			}
+			assert.ElementsMatch(t, tt.want, names)
+		})
+	}
+
+	t.Run("items carry print stats", func(t *testing.T) {
+		req := helpers.GET("/files").
+			WithQueryParam("folder_id", uuid.UUID(folder.ID.Bytes).String()).
+			WithQueryParam("printed", "success")
+		resp := helpers.MakeRequest(t, req, handler.ListFiles)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		items := resp.GetArray("items")
+		require.Len(t, items, 1)
+		item := items[0].(map[string]interface{})
+		assert.Equal(t, float64(2), item["print_count"])
+		assert.Equal(t, 0.5, item["success_rate"])
+		assert.NotNil(t, item["last_printed_at"])
+	})
+}
+
+func TestListFilesByMetadata(t *testing.T) {
+	ctx := context.Background()
+	queries := db.New(helpers.TestPool)
+
+	folder := helpers.CreateTestFolder(t, "metadata-filter")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+	designer := helpers.CreateTestCustomField(t, metadata.TypeText)
+	defer helpers.DeleteTestCustomField(t, designer.ID)
+
+	create := func(name string, favorite bool, rating int16, designed string) *db.File {
+		file := helpers.CreateTestFile(t, name, "stl", folder.ID)
+		t.Cleanup(func() { helpers.DeleteTestFile(t, file.ID) })
+		_, err := queries.UpdateFileMetadata(ctx, db.UpdateFileMetadataParams{
+			ID:       file.ID,
+			Favorite: favorite,
+			Rating:   pgtype.Int2{Int16: rating, Valid: rating > 0},
+		})
+		require.NoError(t, err)
+		if designed != "" {
+			require.NoError(t, queries.SetFileFieldValue(ctx, db.SetFileFieldValueParams{
+				FieldID: designer.ID,
+				FileID:  file.ID,
+				Value:   []byte(`"` + designed + `"`),
+			}))
+		}
+		return file
+	}
+
+	dragon := create("metadata-dragon", true, 5, "Loot Studios")
+	knight := create("metadata-knight", false, 3, "Loot Studios")
+	tower := create("metadata-tower", true, 0, "")
+
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  []string
+	}{
+		{name: "favorites", key: "favorite", value: "true", want: []string{dragon.FileName, tower.FileName}},
+		{name: "not favorites", key: "favorite", value: "false", want: []string{knight.FileName}},
+		{name: "min rating", key: "min_rating", value: "4", want: []string{dragon.FileName}},
+		{name: "custom field ignores case", key: "field", value: designer.Key + ":loot studios", want: []string{dragon.FileName, knight.FileName}},
+		{name: "custom field without match", key: "field", value: designer.Key + ":Hero Forge", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.GET("/files").
+				WithQueryParam("folder_id", uuid.UUID(folder.ID.Bytes).String()).
+				WithQueryParam(tt.key, tt.value)
+			resp := helpers.MakeRequest(t, req, handler.ListFiles)
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			var names []string
+			for _, item := range resp.GetArray("items") {
+				names = append(names, item.(map[string]interface{})["file_name"].(string))
+			}
+			assert.ElementsMatch(t, tt.want, names)
+		})
+	}
+}
+
+func TestListFilesByLicense(t *testing.T) {
+	root := helpers.CreateTestFolder(t, "license-filter")
+	defer helpers.DeleteTestFolder(t, root.ID)
+	inherited := helpers.CreateTestSubfolder(t, "inherited", root)
+	defer helpers.DeleteTestFolder(t, inherited.ID)
+	own := helpers.CreateTestSubfolder(t, "own", inherited)
+	defer helpers.DeleteTestFolder(t, own.ID)
+
+	helpers.SetTestFolderSource(t, root.ID, scanner.LicenseCommercial, "Loot Studios")
+	helpers.SetTestFolderSource(t, own.ID, scanner.LicenseCCBYNC, "Someone Else")
+
+	top := helpers.CreateTestFile(t, "license-top", "stl", root.ID)
+	defer helpers.DeleteTestFile(t, top.ID)
+	nested := helpers.CreateTestFile(t, "license-nested", "stl", inherited.ID)
+	defer helpers.DeleteTestFile(t, nested.ID)
+	restricted := helpers.CreateTestFile(t, "license-restricted", "stl", own.ID)
+	defer helpers.DeleteTestFile(t, restricted.ID)
+
+	tests := []struct {
+		name   string
+		params map[string]string
+		want   []string
+	}{
+		{name: "commercial inherits down the tree", params: map[string]string{"commercial": "true"}, want: []string{top.FileName, nested.FileName}},
+		{name: "subfolder license wins", params: map[string]string{"license": "cc-by-nc"}, want: []string{restricted.FileName}},
+		{name: "several licenses", params: map[string]string{"license": "commercial,CC-BY-NC"}, want: []string{top.FileName, nested.FileName, restricted.FileName}},
+		{name: "commercial false does not filter", params: map[string]string{"commercial": "false"}, want: []string{top.FileName, nested.FileName, restricted.FileName}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.GET("/files").WithQueryParam("folder_id", uuid.UUID(root.ID.Bytes).String())
+			for key, value := range tt.params {
+				req = req.WithQueryParam(key, value)
+			}
+			resp := helpers.MakeRequest(t, req, handler.ListFiles)
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			var names []string
+			for _, item := range resp.GetArray("items") {
+				names = append(names, item.(map[string]interface{})["file_name"].(string))
+			}
+			assert.ElementsMatch(t, tt.want, names)
+		})
+	}
+
+	t.Run("non-commercial license with commercial", func(t *testing.T) {
+		req := helpers.GET("/files").WithQueryParam("license", "CC-BY-NC").WithQueryParam("commercial", "true")
+		resp := helpers.MakeRequest(t, req, handler.ListFiles)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
@@ -6,6 +6,7 @@ import (
 
 	"stl-manager/internal/ai"
 	"stl-manager/internal/config"
+	"stl-manager/internal/events"
 	"stl-manager/internal/handlers/files"
 	"stl-manager/tests/integration/helpers"
 )
@@ -19,7 +20,7 @@ func TestMain(m *testing.M) {
 
 	cfg := &config.Config{OpenAIAPIKey: ""}
 	classifier := ai.NewOpenAIClassifier("")
-	handler = files.New(helpers.TestPool, classifier, cfg, helpers.TestLogger)
+	handler = files.New(helpers.TestPool, classifier, nil, events.NewBroker(), cfg, helpers.TestLogger)
 
 	code := m.Run()
 	helpers.CleanupTestDatabase()
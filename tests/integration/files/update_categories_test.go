@@ -4,11 +4,14 @@ import (
 	"net/http"
 	"testing"
 
+	"stl-manager/internal/db"
 	"stl-manager/internal/handlers/files"
 	"stl-manager/tests/integration/helpers"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUpdateFileCategories(t *testing.T) {
@@ -82,3 +85,46 @@ func TestUpdateFileCategories(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateFileCategoriesModes(t *testing.T) {
+	file := helpers.CreateTestFile(t, "mode-file", "stl", pgtype.UUID{})
+	defer helpers.DeleteTestFile(t, file.ID)
+
+	cat1 := helpers.CreateTestCategory(t, "mode-cat-1")
+	defer helpers.DeleteTestCategory(t, cat1.ID)
+
+	cat2 := helpers.CreateTestCategory(t, "mode-cat-2")
+	defer helpers.DeleteTestCategory(t, cat2.ID)
+
+	fileID := uuid.UUID(file.ID.Bytes).String()
+	update := func(mode string, categories ...*db.Category) map[string]interface{} {
+		ids := []string{}
+		for _, cat := range categories {
+			ids = append(ids, uuid.UUID(cat.ID.Bytes).String())
+		}
+		req := helpers.PATCH("/files/"+fileID+"/categories", files.UpdateCategoriesRequest{
+			CategoryIDs: ids,
+			Mode:        mode,
+		}).WithURLParam("id", fileID)
+		resp := helpers.MakeRequest(t, req, handler.UpdateFileCategories)
+		require.Equal(t, http.StatusOK, resp.Code)
+		return resp.Body
+	}
+	count := func(body map[string]interface{}) int {
+		return len(body["categories"].([]interface{}))
+	}
+
+	assert.Equal(t, 1, count(update(files.ModeReplace, cat1)))
+	assert.Equal(t, 2, count(update(files.ModeAdd, cat2)), "add keeps existing categories")
+	assert.Equal(t, 1, count(update(files.ModeRemove, cat1)), "remove only drops the listed category")
+	assert.Equal(t, 1, count(update("", cat1)), "replace is the default")
+
+	t.Run("invalid mode", func(t *testing.T) {
+		req := helpers.PATCH("/files/"+fileID+"/categories", files.UpdateCategoriesRequest{
+			CategoryIDs: []string{},
+			Mode:        "merge",
+		}).WithURLParam("id", fileID)
+		resp := helpers.MakeRequest(t, req, handler.UpdateFileCategories)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
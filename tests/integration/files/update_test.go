@@ -0,0 +1,145 @@
+package files
+
+import (
+	"net/http"
+	"testing"
+
+	"stl-manager/internal/handlers/metadata"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateFile(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "file-metadata")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+	file := helpers.CreateTestFile(t, "file-metadata", "stl", folder.ID)
+	defer helpers.DeleteTestFile(t, file.ID)
+	id := uuid.UUID(file.ID.Bytes).String()
+
+	designer := helpers.CreateTestCustomField(t, metadata.TypeText)
+	defer helpers.DeleteTestCustomField(t, designer.ID)
+	scale := helpers.CreateTestCustomField(t, metadata.TypeNumber)
+	defer helpers.DeleteTestCustomField(t, scale.ID)
+	supports := helpers.CreateTestCustomField(t, metadata.TypeBoolean)
+	defer helpers.DeleteTestCustomField(t, supports.ID)
+	license := helpers.CreateTestCustomField(t, metadata.TypeSelect, "CC-BY", "commercial")
+	defer helpers.DeleteTestCustomField(t, license.ID)
+
+	tests := []struct {
+		name     string
+		id       string
+		body     interface{}
+		wantCode int
+	}{
+		{
+			name: "update successfully",
+			id:   id,
+			body: map[string]interface{}{
+				"favorite": true,
+				"rating":   4,
+				"note":     "Print **hollowed**",
+				"custom_fields": map[string]interface{}{
+					designer.Key: "Loot Studios",
+					scale.Key:    1.5,
+					supports.Key: true,
+					license.Key:  "CC-BY",
+				},
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "empty request fails",
+			id:       id,
+			body:     map[string]interface{}{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "rating above 5 fails",
+			id:       id,
+			body:     map[string]interface{}{"rating": 6},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown custom field fails",
+			id:       id,
+			body:     map[string]interface{}{"custom_fields": map[string]interface{}{"no_such_field": "x"}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "wrong value type fails",
+			id:       id,
+			body:     map[string]interface{}{"custom_fields": map[string]interface{}{scale.Key: "big"}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown select option fails",
+			id:       id,
+			body:     map[string]interface{}{"custom_fields": map[string]interface{}{license.Key: "GPL"}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid json fails",
+			id:       id,
+			body:     "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid file id",
+			id:       "invalid",
+			body:     map[string]interface{}{"favorite": true},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "file not found",
+			id:       uuid.New().String(),
+			body:     map[string]interface{}{"favorite": true},
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.PATCH("/files/"+tt.id, tt.body).WithURLParam("id", tt.id)
+			resp := helpers.MakeRequest(t, req, handler.UpdateFile)
+			assert.Equal(t, tt.wantCode, resp.Code)
+
+			if resp.Code == http.StatusOK {
+				assert.Equal(t, true, resp.Body["favorite"])
+				assert.Equal(t, float64(4), resp.GetFloat("rating"))
+				assert.Equal(t, "Print **hollowed**", resp.GetString("note"))
+				assert.Equal(t, map[string]interface{}{
+					designer.Key: "Loot Studios",
+					scale.Key:    1.5,
+					supports.Key: true,
+					license.Key:  "CC-BY",
+				}, resp.GetMap("custom_fields"))
+			}
+		})
+	}
+
+	t.Run("omitted properties are kept", func(t *testing.T) {
+		body := map[string]interface{}{
+			"rating":        0,
+			"custom_fields": map[string]interface{}{designer.Key: nil},
+		}
+		resp := helpers.MakeRequest(t, helpers.PATCH("/files/"+id, body).WithURLParam("id", id), handler.UpdateFile)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		assert.Equal(t, true, resp.Body["favorite"])
+		assert.Nil(t, resp.Body["rating"])
+		assert.Equal(t, "Print **hollowed**", resp.GetString("note"))
+		fields := resp.GetMap("custom_fields")
+		assert.NotContains(t, fields, designer.Key)
+		assert.Equal(t, true, fields[supports.Key])
+	})
+
+	t.Run("returned by get", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, helpers.GET("/files/"+id).WithURLParam("id", id), handler.GetFile)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, true, resp.Body["favorite"])
+		assert.Equal(t, "CC-BY", resp.GetMap("custom_fields")[license.Key])
+	})
+}
@@ -3,11 +3,15 @@ package folders
 import (
 	"net/http"
 	"testing"
+	"time"
 
+	"stl-manager/internal/handlers/prints"
+	"stl-manager/internal/scanner"
 	"stl-manager/tests/integration/helpers"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetFolder(t *testing.T) {
@@ -118,3 +122,81 @@ func TestGetFolderWithPagination(t *testing.T) {
 		})
 	}
 }
+
+func TestGetFolderPrintStats(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "print-stats")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+	sub := helpers.CreateTestSubfolder(t, "print-stats-sub", folder)
+	defer helpers.DeleteTestFolder(t, sub.ID)
+
+	top := helpers.CreateTestFile(t, "print-stats-top", "stl", folder.ID)
+	defer helpers.DeleteTestFile(t, top.ID)
+	nested := helpers.CreateTestFile(t, "print-stats-nested", "stl", sub.ID)
+	defer helpers.DeleteTestFile(t, nested.ID)
+	unprinted := helpers.CreateTestFile(t, "print-stats-unprinted", "stl", folder.ID)
+	defer helpers.DeleteTestFile(t, unprinted.ID)
+
+	helpers.CreateTestPrint(t, top.ID, prints.ResultSuccess, time.Now().Add(-time.Hour))
+	helpers.CreateTestPrint(t, nested.ID, prints.ResultFailed, time.Now())
+
+	id := uuid.UUID(folder.ID.Bytes).String()
+
+	t.Run("folder and subfolders count their subtrees", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, helpers.GET("/folders/"+id).WithURLParam("id", id), handler.GetFolder)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		stats := resp.GetMap("folder")
+		assert.Equal(t, float64(2), stats["print_count"])
+		assert.Equal(t, 0.5, stats["success_rate"])
+
+		subfolders := resp.GetArray("subfolders")
+		require.Len(t, subfolders, 1)
+		assert.Equal(t, float64(1), subfolders[0].(map[string]interface{})["print_count"])
+		assert.Equal(t, float64(0), subfolders[0].(map[string]interface{})["success_rate"])
+	})
+
+	tests := []struct {
+		printed string
+		want    []string
+	}{
+		{printed: "never", want: []string{unprinted.FileName}},
+		{printed: "success", want: []string{top.FileName}},
+	}
+
+	for _, tt := range tests {
+		t.Run("printed="+tt.printed, func(t *testing.T) {
+			req := helpers.GET("/folders/"+id).WithURLParam("id", id).WithQueryParam("printed", tt.printed)
+			resp := helpers.MakeRequest(t, req, handler.GetFolder)
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			var names []string
+			for _, item := range resp.GetArray("files") {
+				names = append(names, item.(map[string]interface{})["file_name"].(string))
+			}
+			assert.ElementsMatch(t, tt.want, names)
+		})
+	}
+
+	t.Run("invalid printed", func(t *testing.T) {
+		req := helpers.GET("/folders/"+id).WithURLParam("id", id).WithQueryParam("printed", "sometimes")
+		resp := helpers.MakeRequest(t, req, handler.GetFolder)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestGetFolderSource(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "folder-source")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+
+	helpers.SetTestFolderSource(t, folder.ID, scanner.LicenseCCBY, "Loot Studios")
+
+	id := uuid.UUID(folder.ID.Bytes).String()
+	resp := helpers.MakeRequest(t, helpers.GET("/folders/"+id).WithURLParam("id", id), handler.GetFolder)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	got := resp.GetMap("folder")
+	assert.Equal(t, "Loot Studios", got["source_designer"])
+	assert.Equal(t, scanner.LicenseCCBY, got["source_license"])
+	assert.Equal(t, "", got["source_url"])
+	assert.Equal(t, []interface{}{"LICENSE.txt"}, got["source_files"])
+}
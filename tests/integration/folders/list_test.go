@@ -4,8 +4,10 @@ import (
 	"net/http"
 	"testing"
 
+	"stl-manager/internal/scanner"
 	"stl-manager/tests/integration/helpers"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -36,7 +38,63 @@ func TestListFolders(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			resp := helpers.MakeRequest(t, tt.req, handler.ListFolders)
 			assert.Equal(t, tt.want, resp.Code)
-			helpers.AssertPaginatedResponse(t, resp)
+			helpers.AssertCursorPaginatedResponse(t, resp)
 		})
 	}
 }
+
+func TestListFoldersCursor(t *testing.T) {
+	parent := helpers.CreateTestFolder(t, "cursor-folders")
+	defer helpers.DeleteTestFolder(t, parent.ID)
+
+	want := []string{}
+	for _, name := range []string{"cursor-folders-a", "cursor-folders-b", "cursor-folders-c"} {
+		sub := helpers.CreateTestSubfolder(t, name, parent)
+		defer helpers.DeleteTestFolder(t, sub.ID)
+		want = append(want, uuid.UUID(sub.ID.Bytes).String())
+	}
+
+	req := helpers.GET("/folders").WithQueryParam("q", "cursor-folders-").WithQueryParam("page_size", "2")
+	assert.Equal(t, want, helpers.FollowCursor(t, req, handler.ListFolders))
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, helpers.GET("/folders").WithQueryParam("cursor", "invalid"), handler.ListFolders)
+		helpers.AssertErrorResponse(t, resp, http.StatusBadRequest)
+	})
+}
+
+func TestListFoldersByLicense(t *testing.T) {
+	parent := helpers.CreateTestFolder(t, "license-folders")
+	defer helpers.DeleteTestFolder(t, parent.ID)
+	free := helpers.CreateTestSubfolder(t, "license-folders-free", parent)
+	defer helpers.DeleteTestFolder(t, free.ID)
+	personal := helpers.CreateTestSubfolder(t, "license-folders-personal", parent)
+	defer helpers.DeleteTestFolder(t, personal.ID)
+
+	helpers.SetTestFolderSource(t, free.ID, scanner.LicenseCC0, "")
+	helpers.SetTestFolderSource(t, personal.ID, scanner.LicenseCCBYNC, "")
+
+	tests := []struct {
+		name   string
+		params map[string]string
+		want   []string
+	}{
+		{name: "commercial", params: map[string]string{"commercial": "true"}, want: []string{uuid.UUID(free.ID.Bytes).String()}},
+		{name: "license", params: map[string]string{"license": "cc-by-nc"}, want: []string{uuid.UUID(personal.ID.Bytes).String()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.GET("/folders").WithQueryParam("q", "license-folders-")
+			for key, value := range tt.params {
+				req = req.WithQueryParam(key, value)
+			}
+			assert.Equal(t, tt.want, helpers.FollowCursor(t, req, handler.ListFolders))
+		})
+	}
+
+	t.Run("invalid license", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, helpers.GET("/folders").WithQueryParam("license", "GPL"), handler.ListFolders)
+		helpers.AssertErrorResponse(t, resp, http.StatusBadRequest)
+	})
+}
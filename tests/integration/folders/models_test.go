@@ -0,0 +1,131 @@
+package folders
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"stl-manager/internal/db"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListModels(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "models-pack")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+	supported := helpers.CreateTestSubfolder(t, "Supported", folder)
+	defer helpers.DeleteTestFolder(t, supported.ID)
+
+	for _, rel := range []string{
+		"dragon.stl",
+		"dragon_presupported.stl",
+		"knight.stl",
+	} {
+		file := helpers.CreateTestFileAt(t, folder, rel)
+		defer helpers.DeleteTestFile(t, file.ID)
+	}
+	nested := helpers.CreateTestFileAt(t, supported, "Dragon_75mm.stl")
+	defer helpers.DeleteTestFile(t, nested.ID)
+
+	id := uuid.UUID(folder.ID.Bytes).String()
+	resp := helpers.MakeRequest(t, helpers.GET("/folders/"+id+"/models").WithURLParam("id", id), handler.ListModels)
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, float64(2), resp.Body["total"])
+
+	items := resp.GetArray("items")
+	require.Len(t, items, 2)
+	dragon := items[0].(map[string]interface{})
+	assert.Equal(t, "dragon", dragon["name"])
+
+	type variant struct{ name, supports, scale string }
+	var got []variant
+	for _, item := range dragon["files"].([]interface{}) {
+		file := item.(map[string]interface{})
+		got = append(got, variant{file["file_name"].(string), file["supports"].(string), file["scale"].(string)})
+	}
+	assert.ElementsMatch(t, []variant{
+		{"dragon.stl", "", ""},
+		{"dragon_presupported.stl", "presupported", ""},
+		{"Dragon_75mm.stl", "supported", "75mm"},
+	}, got)
+	assert.Equal(t, "knight", items[1].(map[string]interface{})["name"])
+
+	t.Run("invalid id", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, helpers.GET("/folders/invalid/models").WithURLParam("id", "invalid"), handler.ListModels)
+		helpers.AssertErrorResponse(t, resp, http.StatusBadRequest)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		missing := uuid.New().String()
+		resp := helpers.MakeRequest(t, helpers.GET("/folders/"+missing+"/models").WithURLParam("id", missing), handler.ListModels)
+		helpers.AssertErrorResponse(t, resp, http.StatusNotFound)
+	})
+}
+
+func TestFolderImages(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "Images"), 0o755))
+	png := []byte("\x89PNG\r\n\x1a\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Images", "cover.png"), png, 0o644))
+
+	folder, err := db.New(helpers.TestPool).CreateFolder(context.Background(), db.CreateFolderParams{
+		Name: "gallery",
+		Path: dir,
+	})
+	require.NoError(t, err)
+	defer helpers.DeleteTestFolder(t, folder.ID)
+
+	cover := helpers.CreateTestFolderImage(t, folder.ID, "Images/cover.png", true)
+	missing := helpers.CreateTestFolderImage(t, folder.ID, "side.jpg", false)
+
+	id := uuid.UUID(folder.ID.Bytes).String()
+	coverID := uuid.UUID(cover.ID.Bytes).String()
+
+	t.Run("gallery", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, helpers.GET("/folders/"+id+"/images").WithURLParam("id", id), handler.ListImages)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, float64(2), resp.Body["total"])
+		assert.Equal(t, coverID, resp.Body["primary_image_id"])
+
+		items := resp.GetArray("items")
+		require.Len(t, items, 2)
+		assert.Equal(t, "Images/cover.png", items[0].(map[string]interface{})["name"])
+	})
+
+	t.Run("folder shows its cover", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, helpers.GET("/folders/"+id).WithURLParam("id", id), handler.GetFolder)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, coverID, resp.GetMap("folder")["primary_image_id"])
+	})
+
+	image := func(imageID string) helpers.HTTPTestRequest {
+		return helpers.GET("/folders/"+id+"/images/"+imageID).WithURLParam("id", id).WithURLParam("imageId", imageID)
+	}
+
+	t.Run("serve image", func(t *testing.T) {
+		raw := helpers.RawRequest(t, image(coverID), handler.GetImage)
+		require.Equal(t, http.StatusOK, raw.Code)
+		assert.Equal(t, "image/png", raw.Header().Get("Content-Type"))
+		assert.Equal(t, png, raw.Body.Bytes())
+	})
+
+	t.Run("image missing from disk", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, image(uuid.UUID(missing.ID.Bytes).String()), handler.GetImage)
+		helpers.AssertErrorResponse(t, resp, http.StatusNotFound)
+	})
+
+	t.Run("unknown image", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, image(uuid.New().String()), handler.GetImage)
+		helpers.AssertErrorResponse(t, resp, http.StatusNotFound)
+	})
+
+	t.Run("invalid image id", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, image("invalid"), handler.GetImage)
+		helpers.AssertErrorResponse(t, resp, http.StatusBadRequest)
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"os"
 	"testing"
 
+	"stl-manager/internal/events"
 	"stl-manager/internal/handlers/folders"
+	"stl-manager/internal/jobs"
 	"stl-manager/tests/integration/helpers"
 )
 
@@ -15,7 +17,7 @@ func TestMain(m *testing.M) {
 		panic(err)
 	}
 
-	handler = folders.New(helpers.TestPool, helpers.TestLogger)
+	handler = folders.New(helpers.TestPool, jobs.NewManager(helpers.TestPool, helpers.TestLogger), events.NewBroker(), helpers.TestLogger)
 
 	code := m.Run()
 	helpers.CleanupTestDatabase()
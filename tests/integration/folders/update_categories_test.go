@@ -1,13 +1,17 @@
 package folders
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
+	"stl-manager/internal/db"
+	"stl-manager/internal/handlers/folders"
 	"stl-manager/tests/integration/helpers"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUpdateFolderCategories(t *testing.T) {
@@ -95,3 +99,187 @@ func TestUpdateFolderCategories(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateFolderCategoriesValidation(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "validation-folder")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+
+	tests := []struct {
+		name     string
+		folderID string
+		body     interface{}
+		wantCode int
+	}{
+		{
+			name:     "folder not found",
+			folderID: uuid.New().String(),
+			body:     map[string]interface{}{"category_ids": []string{}},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "category not found",
+			folderID: uuid.UUID(folder.ID.Bytes).String(),
+			body:     map[string]interface{}{"category_ids": []string{uuid.New().String()}},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "negative max depth",
+			folderID: uuid.UUID(folder.ID.Bytes).String(),
+			body: map[string]interface{}{
+				"category_ids":        []string{},
+				"apply_to_subfolders": true,
+				"max_depth":           -1,
+			},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.PATCH("/folders/"+tt.folderID+"/categories", tt.body).WithURLParam("id", tt.folderID)
+			resp := helpers.MakeRequest(t, req, handler.UpdateFolderCategories)
+			assert.Equal(t, tt.wantCode, resp.Code)
+		})
+	}
+}
+
+func TestUpdateFolderCategoriesRecursive(t *testing.T) {
+	root := helpers.CreateTestFolder(t, "tree-root")
+	defer helpers.DeleteTestFolder(t, root.ID)
+	child := helpers.CreateTestSubfolder(t, "child", root)
+	grandchild := helpers.CreateTestSubfolder(t, "grandchild", child)
+
+	deepSTL := helpers.CreateTestFile(t, "deep", "stl", grandchild.ID)
+	defer helpers.DeleteTestFile(t, deepSTL.ID)
+	deepZIP := helpers.CreateTestFile(t, "deep", "zip", grandchild.ID)
+	defer helpers.DeleteTestFile(t, deepZIP.ID)
+
+	cat := helpers.CreateTestCategory(t, "tree-cat")
+	defer helpers.DeleteTestCategory(t, cat.ID)
+
+	rootID := uuid.UUID(root.ID.Bytes).String()
+	body := func(maxDepth int, dryRun bool) map[string]interface{} {
+		return map[string]interface{}{
+			"category_ids":        []string{uuid.UUID(cat.ID.Bytes).String()},
+			"apply_to_stl":        true,
+			"apply_to_subfolders": true,
+			"max_depth":           maxDepth,
+			"dry_run":             dryRun,
+		}
+	}
+	queries := db.New(helpers.TestPool)
+	folderHasCategory := func(folder *db.Folder) bool {
+		categories, err := queries.GetFolderCategories(context.Background(), folder.ID)
+		require.NoError(t, err)
+		return len(categories) == 1 && categories[0].ID == cat.ID
+	}
+	fileCategories := func(file *db.File) int {
+		categories, err := queries.GetFileCategories(context.Background(), file.ID)
+		require.NoError(t, err)
+		return len(categories)
+	}
+
+	t.Run("dry run previews the whole tree", func(t *testing.T) {
+		req := helpers.PATCH("/folders/"+rootID+"/categories", body(0, true)).WithURLParam("id", rootID)
+		resp := helpers.MakeRequest(t, req, handler.UpdateFolderCategories)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		preview := resp.Body["preview"].(map[string]interface{})
+		assert.Equal(t, float64(2), preview["folders"])
+		assert.Equal(t, float64(1), preview["files"])
+		assert.Equal(t, float64(2), preview["depth"])
+		assert.False(t, folderHasCategory(child))
+	})
+
+	t.Run("max depth stops below the limit", func(t *testing.T) {
+		req := helpers.PATCH("/folders/"+rootID+"/categories", body(1, false)).WithURLParam("id", rootID)
+		resp := helpers.MakeRequest(t, req, handler.UpdateFolderCategories)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		assert.True(t, folderHasCategory(child))
+		assert.False(t, folderHasCategory(grandchild))
+		assert.Equal(t, 0, fileCategories(deepSTL))
+	})
+
+	t.Run("whole tree", func(t *testing.T) {
+		req := helpers.PATCH("/folders/"+rootID+"/categories", body(0, false)).WithURLParam("id", rootID)
+		resp := helpers.MakeRequest(t, req, handler.UpdateFolderCategories)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		assert.True(t, folderHasCategory(grandchild))
+		assert.Equal(t, 1, fileCategories(deepSTL))
+		assert.Equal(t, 0, fileCategories(deepZIP))
+	})
+}
+
+func TestUpdateFolderCategoriesAddMode(t *testing.T) {
+	root := helpers.CreateTestFolder(t, "add-root")
+	defer helpers.DeleteTestFolder(t, root.ID)
+	child := helpers.CreateTestSubfolder(t, "child", root)
+
+	file := helpers.CreateTestFile(t, "curated", "stl", child.ID)
+	defer helpers.DeleteTestFile(t, file.ID)
+
+	curated := helpers.CreateTestCategory(t, "add-curated")
+	defer helpers.DeleteTestCategory(t, curated.ID)
+	anime := helpers.CreateTestCategory(t, "add-anime")
+	defer helpers.DeleteTestCategory(t, anime.ID)
+
+	queries := db.New(helpers.TestPool)
+	ctx := context.Background()
+	require.NoError(t, queries.AddManualFileCategory(ctx, db.AddManualFileCategoryParams{FileID: file.ID, CategoryID: curated.ID}))
+	require.NoError(t, queries.AddFolderCategory(ctx, db.AddFolderCategoryParams{FolderID: child.ID, CategoryID: curated.ID}))
+
+	rootID := uuid.UUID(root.ID.Bytes).String()
+	request := func(mode string, dryRun bool) *helpers.HTTPTestResponse {
+		req := helpers.PATCH("/folders/"+rootID+"/categories", map[string]interface{}{
+			"category_ids":        []string{uuid.UUID(anime.ID.Bytes).String()},
+			"apply_to_stl":        true,
+			"apply_to_subfolders": true,
+			"mode":                mode,
+			"dry_run":             dryRun,
+		}).WithURLParam("id", rootID)
+		resp := helpers.MakeRequest(t, req, handler.UpdateFolderCategories)
+		require.Equal(t, http.StatusOK, resp.Code)
+		return resp
+	}
+	update := func(mode string) {
+		request(mode, false)
+	}
+	preview := func(mode string) (changedFolders, changedFiles float64) {
+		preview := request(mode, true).Body["preview"].(map[string]interface{})
+		return preview["folders"].(float64), preview["files"].(float64)
+	}
+
+	folderCount, fileCount := preview(folders.ModeRemove)
+	assert.Equal(t, []float64{0, 0}, []float64{folderCount, fileCount}, "remove previews nothing without the category")
+	folderCount, fileCount = preview(folders.ModeAdd)
+	assert.Equal(t, []float64{1, 1}, []float64{folderCount, fileCount}, "add previews rows without the category")
+
+	update(folders.ModeAdd)
+	folderCount, fileCount = preview(folders.ModeAdd)
+	assert.Equal(t, []float64{0, 0}, []float64{folderCount, fileCount}, "add previews nothing once applied")
+	folderCount, fileCount = preview(folders.ModeReplace)
+	assert.Equal(t, []float64{1, 1}, []float64{folderCount, fileCount}, "replace previews rows with other categories")
+	fileCategories, err := queries.GetFileCategories(ctx, file.ID)
+	require.NoError(t, err)
+	assert.Len(t, fileCategories, 2, "add keeps curated file categories")
+	folderCategories, err := queries.GetFolderCategories(ctx, child.ID)
+	require.NoError(t, err)
+	assert.Len(t, folderCategories, 2, "add keeps subfolder categories")
+
+	update(folders.ModeRemove)
+	fileCategories, err = queries.GetFileCategories(ctx, file.ID)
+	require.NoError(t, err)
+	require.Len(t, fileCategories, 1)
+	assert.Equal(t, curated.ID, fileCategories[0].ID)
+
+	t.Run("invalid mode", func(t *testing.T) {
+		req := helpers.PATCH("/folders/"+rootID+"/categories", map[string]interface{}{
+			"category_ids": []string{},
+			"mode":         "merge",
+		}).WithURLParam("id", rootID)
+		resp := helpers.MakeRequest(t, req, handler.UpdateFolderCategories)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
@@ -0,0 +1,97 @@
+package folders
+
+import (
+	"net/http"
+	"testing"
+
+	"stl-manager/internal/handlers/metadata"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateFolder(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "folder-metadata")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+	id := uuid.UUID(folder.ID.Bytes).String()
+
+	designer := helpers.CreateTestCustomField(t, metadata.TypeText)
+	defer helpers.DeleteTestCustomField(t, designer.ID)
+
+	tests := []struct {
+		name     string
+		id       string
+		body     interface{}
+		wantCode int
+	}{
+		{
+			name: "update successfully",
+			id:   id,
+			body: map[string]interface{}{
+				"favorite":      true,
+				"rating":        5,
+				"note":          "Whole army",
+				"custom_fields": map[string]interface{}{designer.Key: "Artisan Guild"},
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "empty request fails",
+			id:       id,
+			body:     map[string]interface{}{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "negative rating fails",
+			id:       id,
+			body:     map[string]interface{}{"rating": -1},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "wrong value type fails",
+			id:       id,
+			body:     map[string]interface{}{"custom_fields": map[string]interface{}{designer.Key: 42}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid folder id",
+			id:       "invalid",
+			body:     map[string]interface{}{"favorite": true},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "folder not found",
+			id:       uuid.New().String(),
+			body:     map[string]interface{}{"favorite": true},
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.PATCH("/folders/"+tt.id, tt.body).WithURLParam("id", tt.id)
+			resp := helpers.MakeRequest(t, req, handler.UpdateFolder)
+			assert.Equal(t, tt.wantCode, resp.Code)
+
+			if resp.Code == http.StatusOK {
+				assert.Equal(t, true, resp.Body["favorite"])
+				assert.Equal(t, float64(5), resp.GetFloat("rating"))
+				assert.Equal(t, "Whole army", resp.GetString("note"))
+				assert.Equal(t, "Artisan Guild", resp.GetMap("custom_fields")[designer.Key])
+				assert.Equal(t, float64(0), resp.Body["print_count"])
+			}
+		})
+	}
+
+	t.Run("returned by get", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, helpers.GET("/folders/"+id).WithURLParam("id", id), handler.GetFolder)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		got := resp.GetMap("folder")
+		assert.Equal(t, true, got["favorite"])
+		assert.Equal(t, float64(5), got["rating"])
+		assert.Equal(t, "Artisan Guild", got["custom_fields"].(map[string]interface{})[designer.Key])
+	})
+}
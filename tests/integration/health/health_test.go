@@ -24,3 +24,11 @@ func TestGetAIStatus(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.Code)
 	helpers.AssertHasFields(t, resp.Body, "enabled")
 }
+
+func TestGetAIUsage(t *testing.T) {
+	req := helpers.GET("/ai/usage").WithQueryParam("days", "7")
+	resp := helpers.MakeRequest(t, req, handler.GetAIUsage)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	helpers.AssertHasFields(t, resp.Body, "enabled", "queued", "history")
+}
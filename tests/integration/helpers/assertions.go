@@ -28,3 +28,9 @@ func AssertHasFields(t *testing.T, body map[string]interface{}, fields ...string
 func AssertPaginatedResponse(t *testing.T, resp *HTTPTestResponse) {
 	AssertHasFields(t, resp.Body, "items", "total", "page", "page_size", "total_pages")
 }
+
+// AssertCursorPaginatedResponse asserts a paginated response that also supports cursors
+func AssertCursorPaginatedResponse(t *testing.T, resp *HTTPTestResponse) {
+	AssertPaginatedResponse(t, resp)
+	AssertHasFields(t, resp.Body, "next_cursor")
+}
@@ -2,7 +2,10 @@ package helpers
 
 import (
 	"context"
+	"encoding/json"
 	"os"
+	"path"
+	"strings"
 	"testing"
 	"time"
 
@@ -68,12 +71,26 @@ func CreateTestCategory(t *testing.T, name string) *db.Category {
 	// Add UUID to ensure uniqueness
 	uniqueName := name + "-" + uuid.New().String()[:8]
 
-	category, err := queries.CreateCategory(ctx, uniqueName)
+	category, err := queries.CreateCategory(ctx, db.CreateCategoryParams{Name: uniqueName})
 	require.NoError(t, err, "Failed to create test category")
 
 	return &category
 }
 
+// CreateTestSubcategory creates a test category under parent with a unique name
+func CreateTestSubcategory(t *testing.T, name string, parent *db.Category) *db.Category {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	category, err := queries.CreateCategory(ctx, db.CreateCategoryParams{
+		Name:     name + "-" + uuid.New().String()[:8],
+		ParentID: parent.ID,
+	})
+	require.NoError(t, err, "Failed to create test subcategory")
+
+	return &category
+}
+
 // DeleteTestCategory hard deletes a test category (cleanup)
 func DeleteTestCategory(t *testing.T, id pgtype.UUID) {
 	ctx := context.Background()
@@ -150,6 +167,30 @@ func CreateTestFile(t *testing.T, fileName, fileType string, folderID pgtype.UUI
 	return &file
 }
 
+// CreateTestFileAt creates a test file at relPath (forward slashes) inside
+// folder, for handlers that read the layout of a folder
+func CreateTestFileAt(t *testing.T, folder *db.Folder, relPath string) *db.File {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	file, err := queries.CreateFile(ctx, db.CreateFileParams{
+		Path:       folder.Path + "/" + relPath,
+		FileName:   path.Base(relPath),
+		Type:       strings.TrimPrefix(path.Ext(relPath), "."),
+		Size:       1024,
+		ModifiedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	})
+	require.NoError(t, err, "Failed to create test file")
+
+	err = queries.UpdateFileFolderID(ctx, db.UpdateFileFolderIDParams{
+		ID:       file.ID,
+		FolderID: folder.ID,
+	})
+	require.NoError(t, err, "Failed to set file folder")
+
+	return &file
+}
+
 // DeleteTestFile hard deletes a test file (cleanup)
 func DeleteTestFile(t *testing.T, id pgtype.UUID) {
 	ctx := context.Background()
@@ -191,6 +232,21 @@ func CreateTestFolder(t *testing.T, name string) *db.Folder {
 	return &folder
 }
 
+// CreateTestSubfolder creates a test folder inside parent
+func CreateTestSubfolder(t *testing.T, name string, parent *db.Folder) *db.Folder {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	folder, err := queries.CreateFolderWithParent(ctx, db.CreateFolderWithParentParams{
+		Name:           name,
+		Path:           parent.Path + "/" + name,
+		ParentFolderID: parent.ID,
+	})
+	require.NoError(t, err, "Failed to create test subfolder")
+
+	return &folder
+}
+
 // DeleteTestFolder hard deletes a test folder (cleanup)
 func DeleteTestFolder(t *testing.T, id pgtype.UUID) {
 	ctx := context.Background()
@@ -213,6 +269,50 @@ func GetTestFolder(t *testing.T, id pgtype.UUID) *db.Folder {
 	return &folder
 }
 
+// CreateTestFolderImage adds an image to the gallery of a folder the way a
+// scan does, optionally as its cover. Images are removed with their folder.
+func CreateTestFolderImage(t *testing.T, folderID pgtype.UUID, name string, primary bool) *db.FolderImage {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	err := queries.UpsertFolderImages(ctx, db.UpsertFolderImagesParams{
+		FolderID:    folderID,
+		Names:       []string{name},
+		Sizes:       []int64{2048},
+		ModifiedAts: []pgtype.Timestamptz{{Time: time.Now(), Valid: true}},
+	})
+	require.NoError(t, err, "Failed to create test folder image")
+	if primary {
+		err = queries.SetFolderPrimaryImage(ctx, db.SetFolderPrimaryImageParams{ID: folderID, Name: name})
+		require.NoError(t, err, "Failed to set test folder cover")
+	}
+
+	images, err := queries.ListFolderImages(ctx, folderID)
+	require.NoError(t, err, "Failed to list test folder images")
+	for _, image := range images {
+		if image.Name == name {
+			return &image
+		}
+	}
+	t.Fatalf("test folder image %s not found", name)
+	return nil
+}
+
+// SetTestFolderSource stores a source on a folder the way a scan reading its
+// sidecar files does
+func SetTestFolderSource(t *testing.T, id pgtype.UUID, license, designer string) {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	err := queries.UpdateFolderSource(ctx, db.UpdateFolderSourceParams{
+		ID:             id,
+		SourceDesigner: designer,
+		SourceLicense:  license,
+		SourceFiles:    []string{"LICENSE.txt"},
+	})
+	require.NoError(t, err, "Failed to set test folder source")
+}
+
 // Scan Helpers
 
 // CreateTestScan creates a test scan
@@ -225,6 +325,7 @@ func CreateTestScan(t *testing.T, status string) *db.Scan {
 		Found:     pgtype.Int4{Int32: 0, Valid: true},
 		Processed: pgtype.Int4{Int32: 0, Valid: true},
 		Progress:  pgtype.Int4{Int32: 0, Valid: true},
+		Options:   []byte("{}"),
 	})
 	require.NoError(t, err, "Failed to create test scan")
 
@@ -241,3 +342,230 @@ func DeleteTestScan(t *testing.T, id pgtype.UUID) {
 		t.Logf("Warning: failed to delete test scan: %v", err)
 	}
 }
+
+// CreateTestScanSchedule creates an enabled schedule that is not due
+func CreateTestScanSchedule(t *testing.T, name, cron string) *db.ScanSchedule {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	schedule, err := queries.CreateScanSchedule(ctx, db.CreateScanScheduleParams{
+		Name:     name,
+		Cron:     cron,
+		Timezone: "UTC",
+		Options:  []byte("{}"),
+		Enabled:  true,
+		NextRun:  pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	})
+	require.NoError(t, err, "Failed to create test scan schedule")
+
+	return &schedule
+}
+
+// DeleteTestScanSchedule hard deletes a test scan schedule (cleanup)
+func DeleteTestScanSchedule(t *testing.T, id pgtype.UUID) {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	err := queries.DeleteScanSchedule(ctx, id)
+	if err != nil {
+		t.Logf("Warning: failed to delete test scan schedule: %v", err)
+	}
+}
+
+// CreateTestSavedSearch creates a saved search with the given GET /v1/files filters
+func CreateTestSavedSearch(t *testing.T, name string, filters map[string]string, pinned bool) *db.SavedSearch {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	data, err := json.Marshal(filters)
+	require.NoError(t, err, "Failed to encode saved search filters")
+
+	saved, err := queries.CreateSavedSearch(ctx, db.CreateSavedSearchParams{
+		Name:    name,
+		Filters: data,
+		Pinned:  pinned,
+	})
+	require.NoError(t, err, "Failed to create test saved search")
+
+	return &saved
+}
+
+// DeleteTestSavedSearch hard deletes a test saved search (cleanup)
+func DeleteTestSavedSearch(t *testing.T, id pgtype.UUID) {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	_, err := queries.DeleteSavedSearch(ctx, id)
+	if err != nil {
+		t.Logf("Warning: failed to delete test saved search: %v", err)
+	}
+}
+
+// CreateTestCollection creates an empty collection
+func CreateTestCollection(t *testing.T, name string) *db.Collection {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	collection, err := queries.CreateCollection(ctx, db.CreateCollectionParams{Name: name})
+	require.NoError(t, err, "Failed to create test collection")
+
+	return &collection
+}
+
+// DeleteTestCollection hard deletes a test collection and its items (cleanup)
+func DeleteTestCollection(t *testing.T, id pgtype.UUID) {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	_, err := queries.DeleteCollection(ctx, id)
+	if err != nil {
+		t.Logf("Warning: failed to delete test collection: %v", err)
+	}
+}
+
+// CreateTestPrint records a print of a file (removed with the file)
+func CreateTestPrint(t *testing.T, fileID pgtype.UUID, result string, printedAt time.Time) *db.Print {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	entry, err := queries.CreatePrint(ctx, db.CreatePrintParams{
+		FileID:    fileID,
+		PrintedAt: pgtype.Timestamptz{Time: printedAt, Valid: true},
+		Printer:   "Test Printer",
+		Material:  "PLA",
+		Result:    result,
+	})
+	require.NoError(t, err, "Failed to create test print")
+
+	return &entry
+}
+
+// CreateTestCustomField creates a custom field with a unique key
+func CreateTestCustomField(t *testing.T, fieldType string, options ...string) *db.CustomField {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	key := "test_" + strings.ReplaceAll(uuid.New().String(), "-", "")[:12]
+	field, err := queries.CreateCustomField(ctx, db.CreateCustomFieldParams{
+		Key:     key,
+		Label:   key,
+		Type:    fieldType,
+		Options: append([]string{}, options...),
+	})
+	require.NoError(t, err, "Failed to create test custom field")
+
+	return &field
+}
+
+// DeleteTestCustomField hard deletes a test custom field and its values (cleanup)
+func DeleteTestCustomField(t *testing.T, id pgtype.UUID) {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	_, err := queries.DeleteCustomField(ctx, id)
+	if err != nil {
+		t.Logf("Warning: failed to delete test custom field: %v", err)
+	}
+}
+
+// CreateTestScanEvent records a per-file event on a scan (removed with the scan)
+func CreateTestScanEvent(t *testing.T, scanID pgtype.UUID, event, path, errorMsg string) {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	err := queries.AddScanEvent(ctx, db.AddScanEventParams{
+		ScanID: scanID,
+		Event:  event,
+		Path:   path,
+		Error:  pgtype.Text{String: errorMsg, Valid: errorMsg != ""},
+	})
+	require.NoError(t, err, "Failed to create test scan event")
+}
+
+// Category Proposal Helpers
+
+// CreateTestCategoryProposal creates a pending proposal with a unique name and the given files
+func CreateTestCategoryProposal(t *testing.T, name string, fileIDs ...pgtype.UUID) *db.CategoryProposal {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	uniqueName := name + "_" + uuid.New().String()[:8]
+
+	proposal, err := queries.UpsertCategoryProposal(ctx, db.UpsertCategoryProposalParams{
+		Name:        uniqueName,
+		Description: "test proposal",
+	})
+	require.NoError(t, err, "Failed to create test category proposal")
+
+	if len(fileIDs) > 0 {
+		err = queries.AddCategoryProposalFiles(ctx, db.AddCategoryProposalFilesParams{
+			ProposalID: proposal.ID,
+			FileIds:    fileIDs,
+		})
+		require.NoError(t, err, "Failed to add test proposal files")
+	}
+
+	return &proposal
+}
+
+// DeleteTestCategoryProposal hard deletes a test proposal (cleanup)
+func DeleteTestCategoryProposal(t *testing.T, id pgtype.UUID) {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	err := queries.DeleteCategoryProposal(ctx, id)
+	if err != nil {
+		t.Logf("Warning: failed to delete test category proposal: %v", err)
+	}
+}
+
+// Reclassify Run Helpers
+
+// CreateTestReclassifyRun creates a reclassify run record
+func CreateTestReclassifyRun(t *testing.T, dryRun bool) *db.ReclassifyRun {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	run, err := queries.CreateReclassifyRun(ctx, db.CreateReclassifyRunParams{
+		DryRun: dryRun,
+		Filter: []byte(`{"category":"uncategorized"}`),
+	})
+	require.NoError(t, err, "Failed to create test reclassify run")
+
+	return &run
+}
+
+// DeleteTestReclassifyRun hard deletes a reclassify run (cleanup)
+func DeleteTestReclassifyRun(t *testing.T, id pgtype.UUID) {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	err := queries.DeleteReclassifyRun(ctx, id)
+	if err != nil {
+		t.Logf("Warning: failed to delete test reclassify run: %v", err)
+	}
+}
+
+// Job Helpers
+
+// DeleteTestJob hard deletes a job and its logs (cleanup)
+func DeleteTestJob(t *testing.T, id pgtype.UUID) {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	err := queries.DeleteJob(ctx, id)
+	if err != nil {
+		t.Logf("Warning: failed to delete test job: %v", err)
+	}
+}
+
+// GetTestJob retrieves a job by ID
+func GetTestJob(t *testing.T, id pgtype.UUID) *db.Job {
+	ctx := context.Background()
+	queries := db.New(TestPool)
+
+	job, err := queries.GetJob(ctx, id)
+	require.NoError(t, err, "Failed to get test job")
+
+	return &job
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/require"
@@ -31,6 +32,68 @@ type HTTPTestResponse struct {
 
 // MakeRequest creates and executes an HTTP test request
 func MakeRequest(t *testing.T, req HTTPTestRequest, handler http.HandlerFunc) *HTTPTestResponse {
+	httpReq := buildRequest(t, req)
+
+	// Execute request
+	recorder := httptest.NewRecorder()
+	handler(recorder, httpReq)
+
+	// Parse response body
+	response := &HTTPTestResponse{
+		ResponseRecorder: recorder,
+		Body:             make(map[string]interface{}),
+	}
+
+	if recorder.Body.Len() > 0 {
+		err := json.NewDecoder(recorder.Body).Decode(&response.Body)
+		if err != nil {
+			t.Logf("Warning: failed to decode response body: %v", err)
+		}
+	}
+
+	return response
+}
+
+// RawRequest executes a request whose response is not JSON (files, archives)
+// and returns the recorder untouched
+func RawRequest(t *testing.T, req HTTPTestRequest, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler(recorder, buildRequest(t, req))
+	return recorder
+}
+
+// StreamRequest runs a streaming handler (e.g. Server-Sent Events) in the background and
+// calls publish every 10ms until the handler returns or timeout elapses, at which point
+// the request is cancelled. The raw response is returned.
+func StreamRequest(t *testing.T, req HTTPTestRequest, handler http.HandlerFunc, publish func(), timeout time.Duration) *httptest.ResponseRecorder {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	httpReq := buildRequest(t, req)
+	httpReq = httpReq.WithContext(ctx)
+
+	recorder := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler(recorder, httpReq)
+	}()
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return recorder
+		case <-ticker.C:
+			if publish != nil && ctx.Err() == nil {
+				publish()
+			}
+		}
+	}
+}
+
+// buildRequest converts a test request into an *http.Request
+func buildRequest(t *testing.T, req HTTPTestRequest) *http.Request {
 	// Prepare request body
 	var bodyReader io.Reader
 	if req.Body != nil {
@@ -76,24 +139,7 @@ func MakeRequest(t *testing.T, req HTTPTestRequest, handler http.HandlerFunc) *H
 		httpReq.URL.RawQuery = q.Encode()
 	}
 
-	// Execute request
-	recorder := httptest.NewRecorder()
-	handler(recorder, httpReq)
-
-	// Parse response body
-	response := &HTTPTestResponse{
-		ResponseRecorder: recorder,
-		Body:             make(map[string]interface{}),
-	}
-
-	if recorder.Body.Len() > 0 {
-		err := json.NewDecoder(recorder.Body).Decode(&response.Body)
-		if err != nil {
-			t.Logf("Warning: failed to decode response body: %v", err)
-		}
-	}
-
-	return response
+	return httpReq
 }
 
 // GET creates a GET request
@@ -207,3 +253,22 @@ func (r *HTTPTestResponse) GetMap(key string) map[string]interface{} {
 	}
 	return nil
 }
+
+// FollowCursor requests a cursor paginated list from req until next_cursor
+// runs out and returns the IDs of every item, in order
+func FollowCursor(t *testing.T, req HTTPTestRequest, handler http.HandlerFunc) []string {
+	ids := []string{}
+	for page := 0; ; page++ {
+		require.Less(t, page, 100, "cursor pagination should end")
+		resp := MakeRequest(t, req, handler)
+		require.Equal(t, http.StatusOK, resp.Code)
+		for _, item := range resp.GetArray("items") {
+			ids = append(ids, item.(map[string]interface{})["id"].(string))
+		}
+		next := resp.GetString("next_cursor")
+		if next == "" {
+			return ids
+		}
+		req = req.WithQueryParam("cursor", next)
+	}
+}
@@ -0,0 +1,156 @@
+package jobs
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"stl-manager/internal/jobs"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitForStatus polls a job until it reaches the wanted status or times out
+func waitForStatus(t *testing.T, id pgtype.UUID, status string) {
+	require.Eventually(t, func() bool {
+		return helpers.GetTestJob(t, id).Status == status
+	}, 5*time.Second, 50*time.Millisecond, "job did not reach status %s", status)
+}
+
+func TestGetJob(t *testing.T) {
+	job, err := manager.Enqueue(context.Background(), quickJobType, map[string]string{"hello": "world"})
+	require.NoError(t, err)
+	defer helpers.DeleteTestJob(t, job.ID)
+	waitForStatus(t, job.ID, jobs.StatusCompleted)
+
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{
+			name:     "get existing job",
+			id:       uuid.UUID(job.ID.Bytes).String(),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid id",
+			id:       "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not found",
+			id:       uuid.New().String(),
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.GET("/jobs/"+tt.id).WithURLParam("id", tt.id)
+			resp := helpers.MakeRequest(t, req, handler.GetJob)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+			if resp.Code == http.StatusOK {
+				assert.Equal(t, jobs.StatusCompleted, resp.GetString("status"))
+				assert.Equal(t, "world", resp.GetMap("payload")["hello"])
+				assert.Equal(t, float64(42), resp.GetMap("result")["answer"])
+			}
+		})
+	}
+}
+
+func TestListJobs(t *testing.T) {
+	job, err := manager.Enqueue(context.Background(), quickJobType, nil)
+	require.NoError(t, err)
+	defer helpers.DeleteTestJob(t, job.ID)
+
+	tests := []struct {
+		name     string
+		params   map[string]string
+		wantCode int
+	}{
+		{
+			name:     "list all jobs",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "filter by type",
+			params:   map[string]string{"type": quickJobType},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid status",
+			params:   map[string]string{"status": "unknown"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid cursor",
+			params:   map[string]string{"cursor": "invalid"},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.GET("/jobs")
+			for key, value := range tt.params {
+				req = req.WithQueryParam(key, value)
+			}
+			resp := helpers.MakeRequest(t, req, handler.ListJobs)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+			if resp.Code == http.StatusOK {
+				helpers.AssertCursorPaginatedResponse(t, resp)
+			}
+		})
+	}
+}
+
+func TestListJobLogs(t *testing.T) {
+	job, err := manager.Enqueue(context.Background(), quickJobType, nil)
+	require.NoError(t, err)
+	defer helpers.DeleteTestJob(t, job.ID)
+	waitForStatus(t, job.ID, jobs.StatusCompleted)
+
+	id := uuid.UUID(job.ID.Bytes).String()
+	req := helpers.GET("/jobs/"+id+"/logs").WithURLParam("id", id)
+	resp := helpers.MakeRequest(t, req, handler.ListJobLogs)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	helpers.AssertCursorPaginatedResponse(t, resp)
+	assert.Len(t, resp.GetArray("items"), 1)
+	assert.Nil(t, resp.Body["next_cursor"])
+}
+
+func TestCancelJob(t *testing.T) {
+	job, err := manager.Enqueue(context.Background(), blockingJobType, nil)
+	require.NoError(t, err)
+	defer helpers.DeleteTestJob(t, job.ID)
+	waitForStatus(t, job.ID, jobs.StatusRunning)
+
+	// Exclusive types allow only one active job
+	_, err = manager.Enqueue(context.Background(), blockingJobType, nil)
+	assert.ErrorIs(t, err, jobs.ErrAlreadyRunning)
+
+	id := uuid.UUID(job.ID.Bytes).String()
+	req := helpers.POST("/jobs/"+id+"/cancel", nil).WithURLParam("id", id)
+
+	resp := helpers.MakeRequest(t, req, handler.CancelJob)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	waitForStatus(t, job.ID, jobs.StatusCancelled)
+
+	// A finished job can't be cancelled again
+	resp = helpers.MakeRequest(t, req, handler.CancelJob)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	// Unknown job
+	missing := uuid.New().String()
+	resp = helpers.MakeRequest(t, helpers.POST("/jobs/"+missing+"/cancel", nil).WithURLParam("id", missing), handler.CancelJob)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	jobshandler "stl-manager/internal/handlers/jobs"
+	"stl-manager/internal/jobs"
+	"stl-manager/tests/integration/helpers"
+)
+
+// Job types used only by these tests
+const (
+	quickJobType    = "test_quick"
+	blockingJobType = "test_blocking"
+)
+
+var (
+	handler *jobshandler.Handler
+	manager *jobs.Manager
+)
+
+func TestMain(m *testing.M) {
+	if err := helpers.SetupTestDatabase(); err != nil {
+		panic(err)
+	}
+
+	manager = jobs.NewManager(helpers.TestPool, helpers.TestLogger)
+	manager.Register(quickJobType, jobs.Definition{
+		Run: func(ctx context.Context, job *jobs.Job) (any, error) {
+			job.Info("quick job ran")
+			return map[string]int{"answer": 42}, nil
+		},
+	})
+	manager.Register(blockingJobType, jobs.Definition{
+		Run: func(ctx context.Context, job *jobs.Job) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		Exclusive: true,
+	})
+	handler = jobshandler.New(helpers.TestPool, manager, helpers.TestLogger)
+
+	code := m.Run()
+	helpers.CleanupTestDatabase()
+	os.Exit(code)
+}
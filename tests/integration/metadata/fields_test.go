@@ -0,0 +1,178 @@
+package metadata
+
+import (
+	"net/http"
+	"testing"
+
+	"stl-manager/internal/handlers/metadata"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFields(t *testing.T) {
+	field := helpers.CreateTestCustomField(t, metadata.TypeSelect, "small", "large")
+	defer helpers.DeleteTestCustomField(t, field.ID)
+
+	resp := helpers.MakeRequest(t, helpers.GET("/custom-fields"), handler.ListFields)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	keys := map[string]interface{}{}
+	for _, item := range resp.GetArray("items") {
+		item := item.(map[string]interface{})
+		keys[item["key"].(string)] = item["options"]
+	}
+	// The fields seeded by the migration
+	assert.Contains(t, keys, "designer")
+	assert.Contains(t, keys, "license")
+	assert.Contains(t, keys, "scale")
+	assert.Contains(t, keys, "supports_needed")
+	assert.Equal(t, []interface{}{"small", "large"}, keys[field.Key])
+}
+
+func TestCreateField(t *testing.T) {
+	key := "test_" + uuid.New().String()[:8]
+
+	tests := []struct {
+		name     string
+		body     interface{}
+		wantCode int
+	}{
+		{
+			name:     "create successfully",
+			body:     metadata.FieldRequest{Key: key, Label: "  Base size  ", Type: metadata.TypeSelect, Options: []string{"25mm", " 32mm ", "25mm", ""}},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "duplicate key fails",
+			body:     metadata.FieldRequest{Key: key, Type: metadata.TypeText},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "invalid key fails",
+			body:     metadata.FieldRequest{Key: "Base Size", Type: metadata.TypeText},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "missing type fails",
+			body:     metadata.FieldRequest{Key: "test_missing_type"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown type fails",
+			body:     metadata.FieldRequest{Key: "test_unknown_type", Type: "date"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "select without options fails",
+			body:     metadata.FieldRequest{Key: "test_no_options", Type: metadata.TypeSelect},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "options on text field fail",
+			body:     metadata.FieldRequest{Key: "test_text_options", Type: metadata.TypeText, Options: []string{"a"}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid json fails",
+			body:     "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := helpers.MakeRequest(t, helpers.POST("/custom-fields", tt.body), handler.CreateField)
+			assert.Equal(t, tt.wantCode, resp.Code)
+
+			if resp.Code == http.StatusCreated {
+				id, err := uuid.Parse(resp.GetString("id"))
+				require.NoError(t, err)
+				t.Cleanup(func() { helpers.DeleteTestCustomField(t, pgtype.UUID{Bytes: id, Valid: true}) })
+
+				assert.Equal(t, key, resp.GetString("key"))
+				assert.Equal(t, "Base size", resp.GetString("label"))
+				assert.Equal(t, []interface{}{"25mm", "32mm"}, resp.GetArray("options"))
+			}
+		})
+	}
+}
+
+func TestUpdateField(t *testing.T) {
+	field := helpers.CreateTestCustomField(t, metadata.TypeSelect, "small")
+	defer helpers.DeleteTestCustomField(t, field.ID)
+	id := uuid.UUID(field.ID.Bytes).String()
+
+	tests := []struct {
+		name     string
+		id       string
+		body     interface{}
+		wantCode int
+	}{
+		{
+			name:     "update label and options",
+			id:       id,
+			body:     map[string]interface{}{"label": "Size", "options": []string{"small", "large"}},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "changing the type fails",
+			id:       id,
+			body:     map[string]interface{}{"type": metadata.TypeText},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "changing the key fails",
+			id:       id,
+			body:     map[string]interface{}{"key": "size"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "removing every option fails",
+			id:       id,
+			body:     map[string]interface{}{"options": []string{}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid id",
+			id:       "invalid",
+			body:     map[string]interface{}{"label": "Size"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not found",
+			id:       uuid.New().String(),
+			body:     map[string]interface{}{"label": "Size"},
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.PUT("/custom-fields/"+tt.id, tt.body).WithURLParam("id", tt.id)
+			resp := helpers.MakeRequest(t, req, handler.UpdateField)
+			assert.Equal(t, tt.wantCode, resp.Code)
+
+			if resp.Code == http.StatusOK {
+				assert.Equal(t, field.Key, resp.GetString("key"))
+				assert.Equal(t, "Size", resp.GetString("label"))
+				assert.Equal(t, []interface{}{"small", "large"}, resp.GetArray("options"))
+			}
+		})
+	}
+}
+
+func TestDeleteField(t *testing.T) {
+	field := helpers.CreateTestCustomField(t, metadata.TypeText)
+	defer helpers.DeleteTestCustomField(t, field.ID)
+	id := uuid.UUID(field.ID.Bytes).String()
+
+	resp := helpers.MakeRequest(t, helpers.DELETE("/custom-fields/"+id).WithURLParam("id", id), handler.DeleteField)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = helpers.MakeRequest(t, helpers.DELETE("/custom-fields/"+id).WithURLParam("id", id), handler.DeleteField)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,23 @@
+package metadata
+
+import (
+	"os"
+	"testing"
+
+	"stl-manager/internal/handlers/metadata"
+	"stl-manager/tests/integration/helpers"
+)
+
+var handler *metadata.Handler
+
+func TestMain(m *testing.M) {
+	if err := helpers.SetupTestDatabase(); err != nil {
+		panic(err)
+	}
+
+	handler = metadata.New(helpers.TestPool, helpers.TestLogger)
+
+	code := m.Run()
+	helpers.CleanupTestDatabase()
+	os.Exit(code)
+}
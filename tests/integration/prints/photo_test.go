@@ -0,0 +1,97 @@
+package prints
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"stl-manager/internal/handlers/prints"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// uploadPhoto sends data as the multipart "image" field
+func uploadPhoto(t *testing.T, fileID, printID string, data []byte) *helpers.HTTPTestResponse {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("image", "print.png")
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	req := helpers.PUT("/files/"+fileID+"/prints/"+printID+"/photo", body.String()).
+		WithURLParam("id", fileID).
+		WithURLParam("printId", printID).
+		WithHeader("Content-Type", mw.FormDataContentType())
+	return helpers.MakeRequest(t, req, handler.UploadPhoto)
+}
+
+func pngImage(t *testing.T) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{G: 255, A: 255})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestPrintPhoto(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "print-photo")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+	file := helpers.CreateTestFile(t, "print-photo", "stl", folder.ID)
+	defer helpers.DeleteTestFile(t, file.ID)
+
+	entry := helpers.CreateTestPrint(t, file.ID, prints.ResultSuccess, time.Now())
+	fileID := uuid.UUID(file.ID.Bytes).String()
+	printID := uuid.UUID(entry.ID.Bytes).String()
+	path := "/files/" + fileID + "/prints/" + printID + "/photo"
+	data := pngImage(t)
+
+	get := func() helpers.HTTPTestRequest {
+		return helpers.GET(path).WithURLParam("id", fileID).WithURLParam("printId", printID)
+	}
+
+	t.Run("no photo yet", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, get(), handler.GetPhoto)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("upload and serve", func(t *testing.T) {
+		resp := uploadPhoto(t, fileID, printID, data)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "/v1"+path, resp.GetString("photo_url"))
+
+		raw := helpers.RawRequest(t, get(), handler.GetPhoto)
+		require.Equal(t, http.StatusOK, raw.Code)
+		assert.Equal(t, "image/png", raw.Header().Get("Content-Type"))
+		assert.Equal(t, data, raw.Body.Bytes())
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		resp := uploadPhoto(t, fileID, printID, []byte("solid cube\nendsolid cube\n"))
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+	})
+
+	t.Run("delete print removes the photo", func(t *testing.T) {
+		stored := filepath.Join(mediaDir, "prints", printID+".png")
+		_, err := os.Stat(stored)
+		require.NoError(t, err)
+
+		req := helpers.DELETE("/files/"+fileID+"/prints/"+printID).WithURLParam("id", fileID).WithURLParam("printId", printID)
+		resp := helpers.MakeRequest(t, req, handler.DeletePrint)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		_, err = os.Stat(stored)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
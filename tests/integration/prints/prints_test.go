@@ -0,0 +1,254 @@
+package prints
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"stl-manager/internal/handlers/prints"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestCreatePrint(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "print-create")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+	file := helpers.CreateTestFile(t, "print-create", "stl", folder.ID)
+	defer helpers.DeleteTestFile(t, file.ID)
+	id := uuid.UUID(file.ID.Bytes).String()
+
+	tests := []struct {
+		name     string
+		id       string
+		body     interface{}
+		wantCode int
+	}{
+		{
+			name: "create successfully",
+			id:   id,
+			body: prints.PrintRequest{
+				PrintedAt:       ptr(time.Date(2026, 10, 1, 18, 0, 0, 0, time.UTC)),
+				Printer:         "Prusa MK4",
+				Material:        "PETG",
+				LayerHeight:     ptr(0.2),
+				Nozzle:          ptr(0.4),
+				DurationSeconds: ptr(int32(5400)),
+				Result:          prints.ResultSuccess,
+				Note:            "  Tree supports  ",
+			},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "missing result fails",
+			id:       id,
+			body:     prints.PrintRequest{Printer: "Prusa MK4"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown result fails",
+			id:       id,
+			body:     prints.PrintRequest{Result: "meh"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "zero layer height fails",
+			id:       id,
+			body:     prints.PrintRequest{Result: prints.ResultFailed, LayerHeight: ptr(0.0)},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "negative duration fails",
+			id:       id,
+			body:     prints.PrintRequest{Result: prints.ResultFailed, DurationSeconds: ptr(int32(-1))},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid json fails",
+			id:       id,
+			body:     "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid file id",
+			id:       "invalid",
+			body:     prints.PrintRequest{Result: prints.ResultSuccess},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "file not found",
+			id:       uuid.New().String(),
+			body:     prints.PrintRequest{Result: prints.ResultSuccess},
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.POST("/files/"+tt.id+"/prints", tt.body).WithURLParam("id", tt.id)
+			resp := helpers.MakeRequest(t, req, handler.CreatePrint)
+			assert.Equal(t, tt.wantCode, resp.Code)
+
+			if resp.Code == http.StatusCreated {
+				assert.Equal(t, id, resp.GetString("file_id"))
+				assert.Equal(t, "Prusa MK4", resp.GetString("printer"))
+				assert.Equal(t, 0.2, resp.GetFloat("layer_height"))
+				assert.Equal(t, float64(5400), resp.GetFloat("duration_seconds"))
+				assert.Equal(t, "Tree supports", resp.GetString("note"))
+				assert.Nil(t, resp.Body["photo_url"])
+			}
+		})
+	}
+}
+
+func TestCreatePrintDefaults(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "print-defaults")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+	file := helpers.CreateTestFile(t, "print-defaults", "stl", folder.ID)
+	defer helpers.DeleteTestFile(t, file.ID)
+	id := uuid.UUID(file.ID.Bytes).String()
+
+	req := helpers.POST("/files/"+id+"/prints", map[string]string{"result": "partial"}).WithURLParam("id", id)
+	resp := helpers.MakeRequest(t, req, handler.CreatePrint)
+	require.Equal(t, http.StatusCreated, resp.Code)
+
+	printedAt, err := time.Parse(time.RFC3339, resp.GetString("printed_at"))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), printedAt, time.Minute)
+	assert.Nil(t, resp.Body["layer_height"])
+	assert.Nil(t, resp.Body["nozzle"])
+	assert.Nil(t, resp.Body["duration_seconds"])
+}
+
+func TestListPrints(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "print-list")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+	file := helpers.CreateTestFile(t, "print-list", "stl", folder.ID)
+	defer helpers.DeleteTestFile(t, file.ID)
+	id := uuid.UUID(file.ID.Bytes).String()
+
+	now := time.Now()
+	oldest := helpers.CreateTestPrint(t, file.ID, prints.ResultFailed, now.Add(-48*time.Hour))
+	latest := helpers.CreateTestPrint(t, file.ID, prints.ResultSuccess, now)
+	middle := helpers.CreateTestPrint(t, file.ID, prints.ResultPartial, now.Add(-24*time.Hour))
+
+	t.Run("most recent first with stats", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, helpers.GET("/files/"+id+"/prints").WithURLParam("id", id), handler.ListPrints)
+		require.Equal(t, http.StatusOK, resp.Code)
+		helpers.AssertPaginatedResponse(t, resp)
+
+		var ids []string
+		for _, item := range resp.GetArray("items") {
+			ids = append(ids, item.(map[string]interface{})["id"].(string))
+		}
+		assert.Equal(t, []string{
+			uuid.UUID(latest.ID.Bytes).String(),
+			uuid.UUID(middle.ID.Bytes).String(),
+			uuid.UUID(oldest.ID.Bytes).String(),
+		}, ids)
+
+		stats := resp.GetMap("stats")
+		assert.Equal(t, float64(3), stats["print_count"])
+		assert.InDelta(t, 1.0/3, stats["success_rate"], 0.0001)
+		lastPrintedAt, err := time.Parse(time.RFC3339, stats["last_printed_at"].(string))
+		require.NoError(t, err)
+		assert.WithinDuration(t, now, lastPrintedAt, time.Second)
+	})
+
+	t.Run("paginated", func(t *testing.T) {
+		req := helpers.GET("/files/"+id+"/prints").WithURLParam("id", id).WithQueryParam("page_size", "2")
+		resp := helpers.MakeRequest(t, req, handler.ListPrints)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Len(t, resp.GetArray("items"), 2)
+		assert.Equal(t, float64(3), resp.GetFloat("total"))
+	})
+
+	t.Run("never printed", func(t *testing.T) {
+		other := helpers.CreateTestFile(t, "print-list-none", "stl", folder.ID)
+		defer helpers.DeleteTestFile(t, other.ID)
+		otherID := uuid.UUID(other.ID.Bytes).String()
+
+		resp := helpers.MakeRequest(t, helpers.GET("/files/"+otherID+"/prints").WithURLParam("id", otherID), handler.ListPrints)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Empty(t, resp.GetArray("items"))
+		stats := resp.GetMap("stats")
+		assert.Equal(t, float64(0), stats["print_count"])
+		assert.Nil(t, stats["last_printed_at"])
+		assert.Nil(t, stats["success_rate"])
+	})
+
+	t.Run("cursor rejected", func(t *testing.T) {
+		req := helpers.GET("/files/"+id+"/prints").WithURLParam("id", id).WithQueryParam("cursor", "abc")
+		resp := helpers.MakeRequest(t, req, handler.ListPrints)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestGetUpdateDeletePrint(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "print-crud")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+	file := helpers.CreateTestFile(t, "print-crud", "stl", folder.ID)
+	defer helpers.DeleteTestFile(t, file.ID)
+	other := helpers.CreateTestFile(t, "print-crud-other", "stl", folder.ID)
+	defer helpers.DeleteTestFile(t, other.ID)
+
+	printedAt := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+	entry := helpers.CreateTestPrint(t, file.ID, prints.ResultFailed, printedAt)
+	fileID := uuid.UUID(file.ID.Bytes).String()
+	printID := uuid.UUID(entry.ID.Bytes).String()
+	path := "/files/" + fileID + "/prints/" + printID
+
+	t.Run("get", func(t *testing.T) {
+		req := helpers.GET(path).WithURLParam("id", fileID).WithURLParam("printId", printID)
+		resp := helpers.MakeRequest(t, req, handler.GetPrint)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "failed", resp.GetString("result"))
+	})
+
+	t.Run("print of another file is not found", func(t *testing.T) {
+		otherID := uuid.UUID(other.ID.Bytes).String()
+		req := helpers.GET("/files/"+otherID+"/prints/"+printID).WithURLParam("id", otherID).WithURLParam("printId", printID)
+		resp := helpers.MakeRequest(t, req, handler.GetPrint)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("invalid print id", func(t *testing.T) {
+		req := helpers.GET("/files/"+fileID+"/prints/invalid").WithURLParam("id", fileID).WithURLParam("printId", "invalid")
+		resp := helpers.MakeRequest(t, req, handler.GetPrint)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("update keeps printed_at when omitted", func(t *testing.T) {
+		body := prints.PrintRequest{Printer: "Bambu X1C", Material: "PLA", Result: prints.ResultSuccess, Note: "Reprinted"}
+		req := helpers.PUT(path, body).WithURLParam("id", fileID).WithURLParam("printId", printID)
+		resp := helpers.MakeRequest(t, req, handler.UpdatePrint)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "success", resp.GetString("result"))
+		assert.Equal(t, "Bambu X1C", resp.GetString("printer"))
+
+		got, err := time.Parse(time.RFC3339, resp.GetString("printed_at"))
+		require.NoError(t, err)
+		assert.True(t, printedAt.Equal(got))
+	})
+
+	t.Run("update validates", func(t *testing.T) {
+		req := helpers.PUT(path, prints.PrintRequest{Result: "maybe"}).WithURLParam("id", fileID).WithURLParam("printId", printID)
+		resp := helpers.MakeRequest(t, req, handler.UpdatePrint)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		req := helpers.DELETE(path).WithURLParam("id", fileID).WithURLParam("printId", printID)
+		resp := helpers.MakeRequest(t, req, handler.DeletePrint)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		resp = helpers.MakeRequest(t, helpers.GET(path).WithURLParam("id", fileID).WithURLParam("printId", printID), handler.GetPrint)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
@@ -0,0 +1,33 @@
+package prints
+
+import (
+	"os"
+	"testing"
+
+	"stl-manager/internal/handlers/prints"
+	"stl-manager/internal/media"
+	"stl-manager/tests/integration/helpers"
+)
+
+var handler *prints.Handler
+
+// mediaDir holds the photos uploaded by the tests
+var mediaDir string
+
+func TestMain(m *testing.M) {
+	if err := helpers.SetupTestDatabase(); err != nil {
+		panic(err)
+	}
+
+	var err error
+	mediaDir, err = os.MkdirTemp("", "prints-media-")
+	if err != nil {
+		panic(err)
+	}
+	handler = prints.New(helpers.TestPool, media.NewStore(mediaDir), helpers.TestLogger)
+
+	code := m.Run()
+	os.RemoveAll(mediaDir)
+	helpers.CleanupTestDatabase()
+	os.Exit(code)
+}
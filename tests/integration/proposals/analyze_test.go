@@ -0,0 +1,19 @@
+package proposals
+
+import (
+	"net/http"
+	"testing"
+
+	"stl-manager/internal/handlers/proposals"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeUncategorized(t *testing.T) {
+	req := helpers.POST("/categories/proposals/analyze", proposals.AnalyzeRequest{SampleSize: 50})
+	resp := helpers.MakeRequest(t, req, handler.AnalyzeUncategorized)
+
+	// OpenAI not enabled in tests
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+}
@@ -0,0 +1,108 @@
+package proposals
+
+import (
+	"net/http"
+	"testing"
+
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListProposals(t *testing.T) {
+	proposal := helpers.CreateTestCategoryProposal(t, "test_list")
+	defer helpers.DeleteTestCategoryProposal(t, proposal.ID)
+
+	tests := []struct {
+		name     string
+		status   string
+		cursor   string
+		wantCode int
+	}{
+		{
+			name:     "list all proposals",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "filter pending",
+			status:   "pending",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid status",
+			status:   "unknown",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid cursor",
+			cursor:   "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.GET("/categories/proposals")
+			if tt.status != "" {
+				req = req.WithQueryParam("status", tt.status)
+			}
+			if tt.cursor != "" {
+				req = req.WithQueryParam("cursor", tt.cursor)
+			}
+			resp := helpers.MakeRequest(t, req, handler.ListProposals)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+			if resp.Code == http.StatusOK {
+				helpers.AssertCursorPaginatedResponse(t, resp)
+			}
+		})
+	}
+}
+
+func TestGetProposal(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "test-folder")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+
+	file := helpers.CreateTestFile(t, "castle-wall", "stl", folder.ID)
+	defer helpers.DeleteTestFile(t, file.ID)
+
+	proposal := helpers.CreateTestCategoryProposal(t, "test_get", file.ID)
+	defer helpers.DeleteTestCategoryProposal(t, proposal.ID)
+
+	tests := []struct {
+		name      string
+		id        string
+		wantCode  int
+		wantFiles int
+	}{
+		{
+			name:      "get existing proposal",
+			id:        uuid.UUID(proposal.ID.Bytes).String(),
+			wantCode:  http.StatusOK,
+			wantFiles: 1,
+		},
+		{
+			name:     "invalid id",
+			id:       "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not found",
+			id:       uuid.New().String(),
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.GET("/categories/proposals/"+tt.id).WithURLParam("id", tt.id)
+			resp := helpers.MakeRequest(t, req, handler.GetProposal)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+			if resp.Code == http.StatusOK {
+				assert.Len(t, resp.GetArray("files"), tt.wantFiles)
+			}
+		})
+	}
+}
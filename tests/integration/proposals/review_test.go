@@ -0,0 +1,89 @@
+package proposals
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/handlers/proposals"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptProposal(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "test-folder")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+
+	file := helpers.CreateTestFile(t, "castle-wall", "stl", folder.ID)
+	defer helpers.DeleteTestFile(t, file.ID)
+
+	proposal := helpers.CreateTestCategoryProposal(t, "test_accept", file.ID)
+	defer helpers.DeleteTestCategoryProposal(t, proposal.ID)
+
+	id := uuid.UUID(proposal.ID.Bytes).String()
+	req := helpers.POST("/categories/proposals/"+id+"/accept", proposals.AcceptRequest{Reclassify: true}).WithURLParam("id", id)
+	resp := helpers.MakeRequest(t, req, handler.AcceptProposal)
+
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "accepted", resp.GetMap("proposal")["status"])
+	assert.Equal(t, float64(1), resp.GetFloat("reclassified"))
+
+	// The category was created and assigned to the proposal's file
+	categoryID, err := uuid.Parse(resp.GetMap("category")["id"].(string))
+	require.NoError(t, err)
+	defer helpers.DeleteTestCategory(t, pgtype.UUID{Bytes: categoryID, Valid: true})
+
+	categories, err := db.New(helpers.TestPool).GetFileCategories(context.Background(), file.ID)
+	require.NoError(t, err)
+	require.Len(t, categories, 1)
+	assert.Equal(t, proposal.Name, categories[0].Name)
+
+	// A proposal can only be reviewed once
+	resp = helpers.MakeRequest(t, req, handler.AcceptProposal)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestRejectProposal(t *testing.T) {
+	proposal := helpers.CreateTestCategoryProposal(t, "test_reject")
+	defer helpers.DeleteTestCategoryProposal(t, proposal.ID)
+
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{
+			name:     "reject pending proposal",
+			id:       uuid.UUID(proposal.ID.Bytes).String(),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "already rejected",
+			id:       uuid.UUID(proposal.ID.Bytes).String(),
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "invalid id",
+			id:       "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not found",
+			id:       uuid.New().String(),
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.POST("/categories/proposals/"+tt.id+"/reject", nil).WithURLParam("id", tt.id)
+			resp := helpers.MakeRequest(t, req, handler.RejectProposal)
+			assert.Equal(t, tt.wantCode, resp.Code)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package proposals
+
+import (
+	"os"
+	"testing"
+
+	"stl-manager/internal/ai"
+	"stl-manager/internal/events"
+	"stl-manager/internal/handlers/proposals"
+	"stl-manager/internal/jobs"
+	"stl-manager/tests/integration/helpers"
+)
+
+var handler *proposals.Handler
+
+func TestMain(m *testing.M) {
+	if err := helpers.SetupTestDatabase(); err != nil {
+		panic(err)
+	}
+
+	classifier := ai.NewOpenAIClassifier("")
+	handler = proposals.New(helpers.TestPool, classifier, jobs.NewManager(helpers.TestPool, helpers.TestLogger), events.NewBroker(), helpers.TestLogger)
+
+	code := m.Run()
+	helpers.CleanupTestDatabase()
+	os.Exit(code)
+}
@@ -0,0 +1,67 @@
+package reclassify
+
+import (
+	"net/http"
+	"testing"
+
+	"stl-manager/internal/handlers/reclassify"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateReclassify(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "test-folder")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+
+	tests := []struct {
+		name     string
+		body     interface{}
+		wantCode int
+	}{
+		{
+			name:     "requires OpenAI",
+			body:     reclassify.ReclassifyRequest{Category: "uncategorized", DryRun: true},
+			wantCode: http.StatusServiceUnavailable, // OpenAI not enabled in tests
+		},
+		{
+			name:     "folder filter requires OpenAI",
+			body:     reclassify.ReclassifyRequest{FolderID: uuid.UUID(folder.ID.Bytes).String()},
+			wantCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:     "queued filter requires OpenAI",
+			body:     reclassify.ReclassifyRequest{Queued: true},
+			wantCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:     "filter is required",
+			body:     reclassify.ReclassifyRequest{DryRun: true},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid folder id",
+			body:     reclassify.ReclassifyRequest{FolderID: "invalid"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "folder not found",
+			body:     reclassify.ReclassifyRequest{FolderID: uuid.New().String()},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "invalid json",
+			body:     "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.POST("/reclassify", tt.body)
+			resp := helpers.MakeRequest(t, req, handler.CreateReclassify)
+			assert.Equal(t, tt.wantCode, resp.Code)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package reclassify
+
+import (
+	"net/http"
+	"testing"
+
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetReclassify(t *testing.T) {
+	run := helpers.CreateTestReclassifyRun(t, true)
+	defer helpers.DeleteTestReclassifyRun(t, run.ID)
+
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{
+			name:     "get existing run",
+			id:       uuid.UUID(run.ID.Bytes).String(),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid id",
+			id:       "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not found",
+			id:       uuid.New().String(),
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.GET("/reclassify/"+tt.id).WithURLParam("id", tt.id)
+			resp := helpers.MakeRequest(t, req, handler.GetReclassify)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+			if resp.Code == http.StatusOK {
+				assert.Equal(t, "uncategorized", resp.GetMap("filter")["category"])
+				assert.NotNil(t, resp.GetArray("report"))
+			}
+		})
+	}
+}
+
+func TestListReclassify(t *testing.T) {
+	run := helpers.CreateTestReclassifyRun(t, false)
+	defer helpers.DeleteTestReclassifyRun(t, run.ID)
+
+	req := helpers.GET("/reclassify").WithQueryParam("page_size", "5")
+	resp := helpers.MakeRequest(t, req, handler.ListReclassify)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	helpers.AssertCursorPaginatedResponse(t, resp)
+}
@@ -0,0 +1,30 @@
+package reclassify
+
+import (
+	"os"
+	"testing"
+
+	"stl-manager/internal/ai"
+	"stl-manager/internal/config"
+	"stl-manager/internal/events"
+	"stl-manager/internal/handlers/reclassify"
+	"stl-manager/internal/jobs"
+	"stl-manager/internal/worker"
+	"stl-manager/tests/integration/helpers"
+)
+
+var handler *reclassify.Handler
+
+func TestMain(m *testing.M) {
+	if err := helpers.SetupTestDatabase(); err != nil {
+		panic(err)
+	}
+
+	cfg := &config.Config{OpenAIAPIKey: ""}
+	classifier := ai.NewOpenAIClassifier("")
+	handler = reclassify.New(helpers.TestPool, classifier, worker.NewPool(20), jobs.NewManager(helpers.TestPool, helpers.TestLogger), events.NewBroker(), cfg, helpers.TestLogger)
+
+	code := m.Run()
+	helpers.CleanupTestDatabase()
+	os.Exit(code)
+}
@@ -0,0 +1,261 @@
+package savedsearches
+
+import (
+	"net/http"
+	"testing"
+
+	"stl-manager/internal/handlers/savedsearches"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSavedSearch(t *testing.T) {
+	existing := helpers.CreateTestSavedSearch(t, "test-taken-"+uuid.New().String()[:8], nil, false)
+	defer helpers.DeleteTestSavedSearch(t, existing.ID)
+
+	tests := []struct {
+		name     string
+		body     interface{}
+		wantCode int
+	}{
+		{
+			name: "create successfully",
+			body: savedsearches.SavedSearchRequest{
+				Name:    "big uncategorized stls " + uuid.New().String()[:8],
+				Filters: map[string]string{"uncategorized": "true", "type": "stl", "min_size": "52428800", "modified_after": "this_month"},
+				Pinned:  true,
+			},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "create without filters",
+			body:     savedsearches.SavedSearchRequest{Name: "everything " + uuid.New().String()[:8]},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "missing name fails",
+			body:     savedsearches.SavedSearchRequest{Filters: map[string]string{"type": "stl"}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown filter fails",
+			body:     savedsearches.SavedSearchRequest{Name: "bad", Filters: map[string]string{"colour": "red"}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid filter value fails",
+			body:     savedsearches.SavedSearchRequest{Name: "bad", Filters: map[string]string{"modified_after": "last_tuesday"}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "paging is not a filter",
+			body:     savedsearches.SavedSearchRequest{Name: "bad", Filters: map[string]string{"page": "2"}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "duplicate name conflicts",
+			body:     savedsearches.SavedSearchRequest{Name: existing.Name},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "invalid json fails",
+			body:     "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.POST("/saved-searches", tt.body)
+			resp := helpers.MakeRequest(t, req, handler.CreateSavedSearch)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+
+			if resp.Code == http.StatusCreated {
+				id, _ := uuid.Parse(resp.GetString("id"))
+				defer helpers.DeleteTestSavedSearch(t, pgtype.UUID{Bytes: id, Valid: true})
+
+				assert.NotNil(t, resp.GetMap("filters"))
+			}
+		})
+	}
+
+	t.Run("empty filters are dropped", func(t *testing.T) {
+		body := savedsearches.SavedSearchRequest{
+			Name:    "trimmed " + uuid.New().String()[:8],
+			Filters: map[string]string{"type": " stl ", "q": ""},
+		}
+		resp := helpers.MakeRequest(t, helpers.POST("/saved-searches", body), handler.CreateSavedSearch)
+		require.Equal(t, http.StatusCreated, resp.Code)
+		id, _ := uuid.Parse(resp.GetString("id"))
+		defer helpers.DeleteTestSavedSearch(t, pgtype.UUID{Bytes: id, Valid: true})
+
+		assert.Equal(t, map[string]interface{}{"type": "stl"}, resp.GetMap("filters"))
+		assert.Equal(t, false, resp.Body["pinned"])
+	})
+}
+
+func TestGetSavedSearch(t *testing.T) {
+	s := helpers.CreateTestSavedSearch(t, "test-get-"+uuid.New().String()[:8], map[string]string{"type": "stl"}, false)
+	defer helpers.DeleteTestSavedSearch(t, s.ID)
+
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{
+			name:     "get existing saved search",
+			id:       uuid.UUID(s.ID.Bytes).String(),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid id",
+			id:       "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not found",
+			id:       uuid.New().String(),
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.GET("/saved-searches/"+tt.id).WithURLParam("id", tt.id)
+			resp := helpers.MakeRequest(t, req, handler.GetSavedSearch)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+			if resp.Code == http.StatusOK {
+				assert.Equal(t, s.Name, resp.GetString("name"))
+				assert.Equal(t, "stl", resp.GetMap("filters")["type"])
+			}
+		})
+	}
+}
+
+func TestListSavedSearches(t *testing.T) {
+	s := helpers.CreateTestSavedSearch(t, "test-list-"+uuid.New().String()[:8], nil, true)
+	defer helpers.DeleteTestSavedSearch(t, s.ID)
+
+	resp := helpers.MakeRequest(t, helpers.GET("/saved-searches"), handler.ListSavedSearches)
+	require.Equal(t, http.StatusOK, resp.Code)
+	helpers.AssertPaginatedResponse(t, resp)
+
+	// Pinned searches come first
+	items := resp.GetArray("items")
+	require.NotEmpty(t, items)
+	assert.Equal(t, true, items[0].(map[string]interface{})["pinned"])
+
+	t.Run("cursor is rejected", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, helpers.GET("/saved-searches").WithQueryParam("cursor", "abc"), handler.ListSavedSearches)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestUpdateSavedSearch(t *testing.T) {
+	s := helpers.CreateTestSavedSearch(t, "test-update-"+uuid.New().String()[:8], map[string]string{"type": "stl"}, false)
+	defer helpers.DeleteTestSavedSearch(t, s.ID)
+	id := uuid.UUID(s.ID.Bytes).String()
+
+	t.Run("update successfully", func(t *testing.T) {
+		body := savedsearches.SavedSearchRequest{Name: s.Name + "-renamed", Filters: map[string]string{"type": "zip"}, Pinned: true}
+		resp := helpers.MakeRequest(t, helpers.PUT("/saved-searches/"+id, body).WithURLParam("id", id), handler.UpdateSavedSearch)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		assert.Equal(t, s.Name+"-renamed", resp.GetString("name"))
+		assert.Equal(t, map[string]interface{}{"type": "zip"}, resp.GetMap("filters"))
+		assert.Equal(t, true, resp.Body["pinned"])
+	})
+
+	t.Run("invalid filters fail", func(t *testing.T) {
+		body := savedsearches.SavedSearchRequest{Name: s.Name, Filters: map[string]string{"sort": "relevance"}}
+		resp := helpers.MakeRequest(t, helpers.PUT("/saved-searches/"+id, body).WithURLParam("id", id), handler.UpdateSavedSearch)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		missing := uuid.New().String()
+		body := savedsearches.SavedSearchRequest{Name: "missing"}
+		resp := helpers.MakeRequest(t, helpers.PUT("/saved-searches/"+missing, body).WithURLParam("id", missing), handler.UpdateSavedSearch)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
+
+func TestDeleteSavedSearch(t *testing.T) {
+	s := helpers.CreateTestSavedSearch(t, "test-delete-"+uuid.New().String()[:8], nil, false)
+	defer helpers.DeleteTestSavedSearch(t, s.ID)
+	id := uuid.UUID(s.ID.Bytes).String()
+
+	resp := helpers.MakeRequest(t, helpers.DELETE("/saved-searches/"+id).WithURLParam("id", id), handler.DeleteSavedSearch)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = helpers.MakeRequest(t, helpers.DELETE("/saved-searches/"+id).WithURLParam("id", id), handler.DeleteSavedSearch)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestListSavedSearchFiles(t *testing.T) {
+	folder := helpers.CreateTestFolder(t, "saved-search-run")
+	defer helpers.DeleteTestFolder(t, folder.ID)
+
+	ids := map[string]bool{}
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		file := helpers.CreateTestFile(t, name, "stl", folder.ID)
+		defer helpers.DeleteTestFile(t, file.ID)
+		ids[uuid.UUID(file.ID.Bytes).String()] = true
+	}
+	other := helpers.CreateTestFile(t, "delta", "zip", folder.ID)
+	defer helpers.DeleteTestFile(t, other.ID)
+
+	s := helpers.CreateTestSavedSearch(t, "test-run-"+uuid.New().String()[:8], map[string]string{
+		"folder_id":      uuid.UUID(folder.ID.Bytes).String(),
+		"type":           "stl",
+		"modified_after": "this_month",
+	}, false)
+	defer helpers.DeleteTestSavedSearch(t, s.ID)
+	id := uuid.UUID(s.ID.Bytes).String()
+
+	run := func() helpers.HTTPTestRequest {
+		return helpers.GET("/saved-searches/"+id+"/files").WithURLParam("id", id)
+	}
+
+	t.Run("runs the saved filters", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, run(), handler.ListSavedSearchFiles)
+		require.Equal(t, http.StatusOK, resp.Code)
+		helpers.AssertCursorPaginatedResponse(t, resp)
+
+		assert.Equal(t, float64(3), resp.GetFloat("total"))
+		for _, item := range resp.GetArray("items") {
+			assert.True(t, ids[item.(map[string]interface{})["id"].(string)])
+		}
+	})
+
+	t.Run("request overrides sort and paging", func(t *testing.T) {
+		req := run().WithQueryParam("sort", "name").WithQueryParam("order", "desc").WithQueryParam("page_size", "1")
+		resp := helpers.MakeRequest(t, req, handler.ListSavedSearchFiles)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		items := resp.GetArray("items")
+		require.Len(t, items, 1)
+		assert.Equal(t, "gamma.stl", items[0].(map[string]interface{})["file_name"])
+		assert.NotNil(t, resp.Body["next_cursor"])
+	})
+
+	t.Run("request cannot change the filters", func(t *testing.T) {
+		resp := helpers.MakeRequest(t, run().WithQueryParam("type", "zip"), handler.ListSavedSearchFiles)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, float64(3), resp.GetFloat("total"))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		missing := uuid.New().String()
+		req := helpers.GET("/saved-searches/"+missing+"/files").WithURLParam("id", missing)
+		resp := helpers.MakeRequest(t, req, handler.ListSavedSearchFiles)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
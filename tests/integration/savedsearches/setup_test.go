@@ -0,0 +1,30 @@
+package savedsearches
+
+import (
+	"os"
+	"testing"
+
+	"stl-manager/internal/ai"
+	"stl-manager/internal/config"
+	"stl-manager/internal/events"
+	"stl-manager/internal/handlers/files"
+	"stl-manager/internal/handlers/savedsearches"
+	"stl-manager/tests/integration/helpers"
+)
+
+var handler *savedsearches.Handler
+
+func TestMain(m *testing.M) {
+	if err := helpers.SetupTestDatabase(); err != nil {
+		panic(err)
+	}
+
+	cfg := &config.Config{OpenAIAPIKey: ""}
+	classifier := ai.NewOpenAIClassifier("")
+	filesHandler := files.New(helpers.TestPool, classifier, nil, events.NewBroker(), cfg, helpers.TestLogger)
+	handler = savedsearches.New(helpers.TestPool, filesHandler.ListFiles, helpers.TestLogger)
+
+	code := m.Run()
+	helpers.CleanupTestDatabase()
+	os.Exit(code)
+}
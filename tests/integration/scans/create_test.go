@@ -4,31 +4,66 @@ import (
 	"net/http"
 	"testing"
 
+	"stl-manager/internal/handlers/scans"
 	"stl-manager/tests/integration/helpers"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestCreateScan(t *testing.T) {
 	tests := []struct {
 		name     string
+		body     interface{}
 		wantCode int
 	}{
 		{
 			name:     "create scan successfully",
 			wantCode: http.StatusAccepted,
 		},
+		{
+			name:     "path outside scan root fails",
+			body:     scans.Options{Path: "../elsewhere"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "missing directory fails",
+			body:     scans.Options{Path: "does/not/exist-" + uuid.New().String()},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "path and folder_id together fail",
+			body:     scans.Options{Path: "minis", FolderID: uuid.New().String()},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid folder_id fails",
+			body:     scans.Options{FolderID: "invalid"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown folder fails",
+			body:     scans.Options{FolderID: uuid.New().String()},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "invalid json fails",
+			body:     "invalid",
+			wantCode: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			req := helpers.POST("/scan", nil)
+			req := helpers.POST("/scan", tt.body)
 			resp := helpers.MakeRequest(t, req, handler.CreateScan)
 			assert.Equal(t, tt.wantCode, resp.Code)
 
 			if tt.wantCode == http.StatusAccepted {
 				scanID := resp.GetString("scan_id")
 				assert.NotEmpty(t, scanID, "scan_id should be returned")
+				assert.NotEmpty(t, resp.GetString("job_id"), "job_id should be returned")
+				assert.NotNil(t, resp.GetMap("options"), "options should be returned")
 
 				// Note: We don't clean up the scan here because the goroutine is running
 				// In a real scenario, scans would be cleaned up by a background job or TTL
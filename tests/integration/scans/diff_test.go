@@ -0,0 +1,142 @@
+package scans
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"stl-manager/internal/ai"
+	"stl-manager/internal/config"
+	"stl-manager/internal/handlers/scans"
+	"stl-manager/internal/jobs"
+	"stl-manager/internal/scanner"
+	"stl-manager/internal/worker"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runTestScan starts a scan with h, waits for it to complete and returns its report
+func runTestScan(t *testing.T, h *scans.Handler, opts scans.Options) *helpers.HTTPTestResponse {
+	// Another test's scan may still be finishing
+	var resp *helpers.HTTPTestResponse
+	require.Eventually(t, func() bool {
+		resp = helpers.MakeRequest(t, helpers.POST("/scan", opts), h.CreateScan)
+		return resp.Code != http.StatusConflict
+	}, 10*time.Second, 100*time.Millisecond)
+	require.Equal(t, http.StatusAccepted, resp.Code)
+
+	scanID := resp.GetString("scan_id")
+	id, _ := uuid.Parse(scanID)
+	t.Cleanup(func() { helpers.DeleteTestScan(t, pgtype.UUID{Bytes: id, Valid: true}) })
+
+	require.Eventually(t, func() bool {
+		req := helpers.GET("/scans/"+scanID).WithURLParam("id", scanID)
+		return helpers.MakeRequest(t, req, h.GetScan).GetString("status") == "completed"
+	}, 10*time.Second, 100*time.Millisecond)
+
+	req := helpers.GET("/scans/"+scanID+"/report").WithURLParam("id", scanID).WithQueryParam("page_size", "100")
+	report := helpers.MakeRequest(t, req, h.GetScanReport)
+	require.Equal(t, http.StatusOK, report.Code)
+	return report
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestScanDiff(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{
+		ScanRootDir:   root,
+		SupportedExts: []string{".stl"},
+	}
+	h := scans.New(helpers.TestPool, ai.NewOpenAIClassifier(""), scanner.New(root, cfg.SupportedExts, helpers.TestLogger),
+		worker.NewPool(4), jobs.NewManager(helpers.TestPool, helpers.TestLogger), broker, cfg, helpers.TestLogger)
+	t.Cleanup(func() {
+		ctx := context.Background()
+		_, _ = helpers.TestPool.Exec(ctx, "DELETE FROM files WHERE starts_with(path, $1)", root)
+		_, _ = helpers.TestPool.Exec(ctx, "DELETE FROM folders WHERE starts_with(path, $1)", root)
+	})
+
+	path := func(parts ...string) string { return filepath.Join(append([]string{root}, parts...)...) }
+	writeTestFile(t, path("a", "keep.stl"), "keep")
+	writeTestFile(t, path("a", "changed.stl"), "v1")
+	writeTestFile(t, path("a", "deleted.stl"), "deleted")
+	writeTestFile(t, path("a", "sub", "nested.stl"), "nested")
+	writeTestFile(t, path("b", "gone.stl"), "gone")
+	writeTestFile(t, path("c", "model.stl"), "model")
+
+	noAI := false
+	opts := scans.Options{Incremental: true, Prune: true, Classify: &noAI}
+
+	first := runTestScan(t, h, opts)
+	assert.Equal(t, float64(6), first.GetMap("summary")["added"])
+
+	var modelID pgtype.UUID
+	require.NoError(t, helpers.TestPool.QueryRow(context.Background(),
+		"SELECT id FROM files WHERE path = $1", path("c", "model.stl")).Scan(&modelID))
+
+	// changed.stl changes size; deleted.stl goes away from a folder the walk
+	// still enters, b/ from the disk altogether; model.stl moves to d/ with the
+	// same name, size and modification time
+	writeTestFile(t, path("a", "changed.stl"), "version 2")
+	require.NoError(t, os.Remove(path("a", "deleted.stl")))
+	require.NoError(t, os.RemoveAll(path("b")))
+	require.NoError(t, os.MkdirAll(path("d"), 0o755))
+	require.NoError(t, os.Rename(path("c", "model.stl"), path("d", "model.stl")))
+
+	second := runTestScan(t, h, opts)
+	summary := second.GetMap("summary")
+	assert.Equal(t, float64(0), summary["added"], "unchanged files are not added again")
+	assert.Equal(t, float64(1), summary["updated"])
+	assert.Equal(t, float64(1), summary["moved"])
+	assert.Equal(t, float64(2), summary["removed"])
+
+	events := map[string]string{}
+	for _, item := range second.GetArray("items") {
+		event := item.(map[string]interface{})
+		events[event["path"].(string)] = event["event"].(string)
+	}
+	assert.Equal(t, map[string]string{
+		path("a", "changed.stl"): "updated",
+		path("d", "model.stl"):   "moved",
+		path("a", "deleted.stl"): "removed",
+		path("b", "gone.stl"):    "removed",
+	}, events, "keep.stl and sub/nested.stl are unchanged")
+
+	// Pruning deleted the removed files and the folders left empty
+	rows, err := helpers.TestPool.Query(context.Background(),
+		"SELECT path FROM files WHERE starts_with(path, $1) ORDER BY path", root)
+	require.NoError(t, err)
+	var paths []string
+	for rows.Next() {
+		var p string
+		require.NoError(t, rows.Scan(&p))
+		paths = append(paths, p)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{
+		path("a", "changed.stl"),
+		path("a", "keep.stl"),
+		path("a", "sub", "nested.stl"),
+		path("d", "model.stl"),
+	}, paths)
+
+	var movedID pgtype.UUID
+	require.NoError(t, helpers.TestPool.QueryRow(context.Background(),
+		"SELECT id FROM files WHERE path = $1", path("d", "model.stl")).Scan(&movedID))
+	assert.Equal(t, modelID, movedID, "moved files keep their row")
+
+	var folders int
+	require.NoError(t, helpers.TestPool.QueryRow(context.Background(),
+		"SELECT COUNT(*) FROM folders WHERE path = ANY($1)", []string{path("b"), path("c")}).Scan(&folders))
+	assert.Zero(t, folders)
+}
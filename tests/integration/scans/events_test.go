@@ -0,0 +1,78 @@
+package scans
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"stl-manager/internal/events"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanEvents(t *testing.T) {
+	completed := helpers.CreateTestScan(t, "completed")
+	defer helpers.DeleteTestScan(t, completed.ID)
+
+	tests := []struct {
+		name       string
+		id         string
+		wantCode   int
+		wantEvents []string
+	}{
+		{
+			name:       "finished scan sends state and closes",
+			id:         uuid.UUID(completed.ID.Bytes).String(),
+			wantCode:   http.StatusOK,
+			wantEvents: []string{"event: status", "event: completed"},
+		},
+		{
+			name:     "invalid id",
+			id:       "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not found",
+			id:       uuid.New().String(),
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.GET("/scans/"+tt.id+"/events").WithURLParam("id", tt.id)
+			resp := helpers.StreamRequest(t, req, handler.ScanEvents, nil, 2*time.Second)
+			assert.Equal(t, tt.wantCode, resp.Code)
+
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, "text/event-stream", resp.Header().Get("Content-Type"))
+				for _, event := range tt.wantEvents {
+					assert.Contains(t, resp.Body.String(), event)
+				}
+			}
+		})
+	}
+}
+
+func TestScanEventsLive(t *testing.T) {
+	scan := helpers.CreateTestScan(t, "running")
+	defer helpers.DeleteTestScan(t, scan.ID)
+
+	scanID := uuid.UUID(scan.ID.Bytes)
+	topic := events.ScanTopic(scanID)
+
+	req := helpers.GET("/scans/"+scanID.String()+"/events").WithURLParam("id", scanID.String())
+	resp := helpers.StreamRequest(t, req, handler.ScanEvents, func() {
+		broker.Publish(topic, "progress", map[string]int{"processed": 1, "total": 2, "progress": 50})
+		broker.Publish(topic, "completed", map[string]int{"processed": 2})
+	}, 2*time.Second)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	body := resp.Body.String()
+	assert.Contains(t, body, "event: status")
+	assert.Contains(t, body, "event: progress")
+	assert.Contains(t, body, `"progress":50`)
+	assert.Contains(t, body, "event: completed", "stream should end with the final event")
+}
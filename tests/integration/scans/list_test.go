@@ -6,7 +6,9 @@ import (
 
 	"stl-manager/tests/integration/helpers"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListScans(t *testing.T) {
@@ -25,13 +27,48 @@ func TestListScans(t *testing.T) {
 			req:  helpers.GET("/scans").WithQueryParam("page", "1").WithQueryParam("page_size", "10"),
 			want: http.StatusOK,
 		},
+		{
+			name: "filter by schedule",
+			req:  helpers.GET("/scans").WithQueryParam("schedule_id", uuid.New().String()),
+			want: http.StatusOK,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			resp := helpers.MakeRequest(t, tt.req, handler.ListScans)
 			assert.Equal(t, tt.want, resp.Code)
-			helpers.AssertPaginatedResponse(t, resp)
+			helpers.AssertCursorPaginatedResponse(t, resp)
 		})
 	}
+
+	t.Run("cursor pages cover every scan once", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			scan := helpers.CreateTestScan(t, "completed")
+			defer helpers.DeleteTestScan(t, scan.ID)
+		}
+
+		resp := helpers.MakeRequest(t, helpers.GET("/scans"), handler.ListScans)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		ids := helpers.FollowCursor(t, helpers.GET("/scans").WithQueryParam("page_size", "2"), handler.ListScans)
+		assert.Len(t, ids, int(resp.GetFloat("total")))
+		seen := map[string]bool{}
+		for _, id := range ids {
+			assert.False(t, seen[id], "scan %s listed twice", id)
+			seen[id] = true
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		req := helpers.GET("/scans").WithQueryParam("cursor", "invalid")
+		resp := helpers.MakeRequest(t, req, handler.ListScans)
+		helpers.AssertErrorResponse(t, resp, http.StatusBadRequest)
+	})
+
+	t.Run("invalid schedule id", func(t *testing.T) {
+		req := helpers.GET("/scans").WithQueryParam("schedule_id", "invalid")
+		resp := helpers.MakeRequest(t, req, handler.ListScans)
+		helpers.AssertErrorResponse(t, resp, http.StatusBadRequest)
+	})
 }
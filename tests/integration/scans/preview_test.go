@@ -0,0 +1,53 @@
+package scans
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"stl-manager/internal/handlers/scans"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunScanPreview(t *testing.T) {
+	// Another test's scan may still be finishing
+	var resp *helpers.HTTPTestResponse
+	require.Eventually(t, func() bool {
+		resp = helpers.MakeRequest(t, helpers.POST("/scan", scans.Options{DryRun: true}), handler.CreateScan)
+		return resp.Code != http.StatusConflict
+	}, 10*time.Second, 100*time.Millisecond)
+	require.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, true, resp.GetMap("options")["dry_run"])
+
+	scanID := resp.GetString("scan_id")
+	id, _ := uuid.Parse(scanID)
+	defer helpers.DeleteTestScan(t, pgtype.UUID{Bytes: id, Valid: true})
+
+	var scan *helpers.HTTPTestResponse
+	require.Eventually(t, func() bool {
+		req := helpers.GET("/scans/"+scanID).WithURLParam("id", scanID)
+		scan = helpers.MakeRequest(t, req, handler.GetScan)
+		return scan.GetString("status") == "completed"
+	}, 10*time.Second, 100*time.Millisecond)
+
+	preview := scan.GetMap("preview")
+	require.NotNil(t, preview, "dry-run scans store a preview")
+	for _, key := range []string{"new", "changed", "moved", "missing", "new_folders"} {
+		group, ok := preview[key].(map[string]interface{})
+		require.True(t, ok, "preview should have %s", key)
+		helpers.AssertHasFields(t, group, "count", "samples")
+	}
+	helpers.AssertHasFields(t, preview, "unchanged", "ignored", "ai")
+	helpers.AssertHasFields(t, preview["ai"].(map[string]interface{}), "enabled", "calls", "estimated_cost_usd", "scan_budget_usd")
+
+	// Previews are left out of scan lists
+	list := helpers.MakeRequest(t, helpers.GET("/scans").WithQueryParam("page_size", "100"), handler.ListScans)
+	for _, item := range list.GetArray("items") {
+		assert.NotContains(t, item.(map[string]interface{}), "preview")
+	}
+}
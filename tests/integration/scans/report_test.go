@@ -0,0 +1,114 @@
+package scans
+
+import (
+	"net/http"
+	"testing"
+
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetScanReport(t *testing.T) {
+	scan := helpers.CreateTestScan(t, "completed")
+	defer helpers.DeleteTestScan(t, scan.ID)
+
+	helpers.CreateTestScanEvent(t, scan.ID, "added", "/library/a.stl", "")
+	helpers.CreateTestScanEvent(t, scan.ID, "added", "/library/b.stl", "")
+	helpers.CreateTestScanEvent(t, scan.ID, "failed", "/library/c.stl", "duplicate key")
+
+	scanID := uuid.UUID(scan.ID.Bytes).String()
+
+	tests := []struct {
+		name      string
+		id        string
+		event     string
+		wantCode  int
+		wantTotal float64
+	}{
+		{
+			name:      "all events",
+			id:        scanID,
+			wantCode:  http.StatusOK,
+			wantTotal: 3,
+		},
+		{
+			name:      "filter by event",
+			id:        scanID,
+			event:     "failed",
+			wantCode:  http.StatusOK,
+			wantTotal: 1,
+		},
+		{
+			name:     "invalid event",
+			id:       scanID,
+			event:    "renamed",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid id",
+			id:       "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not found",
+			id:       uuid.New().String(),
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.GET("/scans/"+tt.id+"/report").WithURLParam("id", tt.id)
+			if tt.event != "" {
+				req = req.WithQueryParam("event", tt.event)
+			}
+			resp := helpers.MakeRequest(t, req, handler.GetScanReport)
+			assert.Equal(t, tt.wantCode, resp.Code)
+
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, tt.wantTotal, resp.GetFloat("total"))
+				assert.Len(t, resp.GetArray("items"), int(tt.wantTotal))
+
+				summary := resp.GetMap("summary")
+				assert.Equal(t, float64(2), summary["added"])
+				assert.Equal(t, float64(1), summary["failed"])
+				assert.Equal(t, float64(0), summary["removed"])
+				assert.Equal(t, scanID, resp.GetMap("scan")["id"])
+
+				if tt.event == "failed" {
+					item := resp.GetArray("items")[0].(map[string]interface{})
+					assert.Equal(t, "duplicate key", item["error"])
+				}
+			}
+		})
+	}
+
+	t.Run("cursor pages cover every event once", func(t *testing.T) {
+		req := helpers.GET("/scans/"+scanID+"/report").WithURLParam("id", scanID).WithQueryParam("page_size", "2")
+		ids := []float64{}
+		for page := 0; page < 3; page++ {
+			resp := helpers.MakeRequest(t, req, handler.GetScanReport)
+			require.Equal(t, http.StatusOK, resp.Code)
+			helpers.AssertCursorPaginatedResponse(t, resp)
+			for _, item := range resp.GetArray("items") {
+				ids = append(ids, item.(map[string]interface{})["id"].(float64))
+			}
+			next := resp.GetString("next_cursor")
+			if next == "" {
+				break
+			}
+			req = req.WithQueryParam("cursor", next)
+		}
+		assert.Len(t, ids, 3)
+		assert.IsIncreasing(t, ids)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		req := helpers.GET("/scans/"+scanID+"/report").WithURLParam("id", scanID).WithQueryParam("cursor", "invalid")
+		resp := helpers.MakeRequest(t, req, handler.GetScanReport)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
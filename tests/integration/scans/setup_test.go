@@ -6,12 +6,18 @@ import (
 
 	"stl-manager/internal/ai"
 	"stl-manager/internal/config"
+	"stl-manager/internal/events"
 	"stl-manager/internal/handlers/scans"
+	"stl-manager/internal/jobs"
 	"stl-manager/internal/scanner"
+	"stl-manager/internal/worker"
 	"stl-manager/tests/integration/helpers"
 )
 
-var handler *scans.Handler
+var (
+	handler *scans.Handler
+	broker  *events.Broker
+)
 
 func TestMain(m *testing.M) {
 	if err := helpers.SetupTestDatabase(); err != nil {
@@ -25,7 +31,8 @@ func TestMain(m *testing.M) {
 	}
 	classifier := ai.NewOpenAIClassifier("")
 	fileScanner := scanner.New(cfg.ScanRootDir, cfg.SupportedExts, helpers.TestLogger)
-	handler = scans.New(helpers.TestPool, classifier, fileScanner, cfg, helpers.TestLogger)
+	broker = events.NewBroker()
+	handler = scans.New(helpers.TestPool, classifier, fileScanner, worker.NewPool(20), jobs.NewManager(helpers.TestPool, helpers.TestLogger), broker, cfg, helpers.TestLogger)
 
 	code := m.Run()
 	helpers.CleanupTestDatabase()
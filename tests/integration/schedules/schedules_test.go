@@ -0,0 +1,315 @@
+package schedules
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"stl-manager/internal/db"
+	"stl-manager/internal/handlers/scans"
+	"stl-manager/internal/handlers/schedules"
+	"stl-manager/internal/jobs"
+	"stl-manager/internal/schedule"
+	"stl-manager/tests/integration/helpers"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSchedule(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name        string
+		body        interface{}
+		wantCode    int
+		wantNextRun bool
+	}{
+		{
+			name:        "create successfully",
+			body:        schedules.ScheduleRequest{Name: "nightly", Cron: "0 3 * * *", Timezone: "Europe/Madrid"},
+			wantCode:    http.StatusCreated,
+			wantNextRun: true,
+		},
+		{
+			name:     "create disabled with options",
+			body:     schedules.ScheduleRequest{Name: "minis", Cron: "@weekly", Enabled: &disabled, Options: scans.Options{Path: "minis/", Incremental: true}},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "missing name fails",
+			body:     schedules.ScheduleRequest{Cron: "0 3 * * *"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid cron fails",
+			body:     schedules.ScheduleRequest{Name: "bad", Cron: "61 * * * *"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown timezone fails",
+			body:     schedules.ScheduleRequest{Name: "bad", Cron: "@daily", Timezone: "Mars/Olympus"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "path outside scan root fails",
+			body:     schedules.ScheduleRequest{Name: "bad", Cron: "@daily", Options: scans.Options{Path: "../other"}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid json fails",
+			body:     "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.POST("/schedules", tt.body)
+			resp := helpers.MakeRequest(t, req, handler.CreateSchedule)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+
+			if resp.Code == http.StatusCreated {
+				id, _ := uuid.Parse(resp.GetString("id"))
+				defer helpers.DeleteTestScanSchedule(t, pgtype.UUID{Bytes: id, Valid: true})
+
+				assert.NotNil(t, resp.GetMap("options"))
+				if tt.wantNextRun {
+					assert.NotEmpty(t, resp.GetString("next_run"))
+				} else {
+					assert.Nil(t, resp.Body["next_run"])
+				}
+			}
+		})
+	}
+
+	t.Run("options are normalized", func(t *testing.T) {
+		body := schedules.ScheduleRequest{Name: "norm", Cron: "@daily", Options: scans.Options{Path: "./minis/../terrain/"}}
+		resp := helpers.MakeRequest(t, helpers.POST("/schedules", body), handler.CreateSchedule)
+		require.Equal(t, http.StatusCreated, resp.Code)
+		id, _ := uuid.Parse(resp.GetString("id"))
+		defer helpers.DeleteTestScanSchedule(t, pgtype.UUID{Bytes: id, Valid: true})
+
+		assert.Equal(t, "terrain", resp.GetMap("options")["path"])
+		assert.Equal(t, "UTC", resp.GetString("timezone"))
+	})
+}
+
+func TestGetSchedule(t *testing.T) {
+	s := helpers.CreateTestScanSchedule(t, "test-get-"+uuid.New().String()[:8], "@hourly")
+	defer helpers.DeleteTestScanSchedule(t, s.ID)
+
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{
+			name:     "get existing schedule",
+			id:       uuid.UUID(s.ID.Bytes).String(),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid id",
+			id:       "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not found",
+			id:       uuid.New().String(),
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.GET("/schedules/"+tt.id).WithURLParam("id", tt.id)
+			resp := helpers.MakeRequest(t, req, handler.GetSchedule)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+			if resp.Code == http.StatusOK {
+				assert.Equal(t, s.Name, resp.GetString("name"))
+				assert.Equal(t, "@hourly", resp.GetString("cron"))
+			}
+		})
+	}
+}
+
+func TestListSchedules(t *testing.T) {
+	s := helpers.CreateTestScanSchedule(t, "test-list-"+uuid.New().String()[:8], "@daily")
+	defer helpers.DeleteTestScanSchedule(t, s.ID)
+
+	req := helpers.GET("/schedules").WithQueryParam("page_size", "100")
+	resp := helpers.MakeRequest(t, req, handler.ListSchedules)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	helpers.AssertCursorPaginatedResponse(t, resp)
+	assert.GreaterOrEqual(t, resp.GetFloat("total"), float64(1))
+}
+
+func TestUpdateSchedule(t *testing.T) {
+	s := helpers.CreateTestScanSchedule(t, "test-update-"+uuid.New().String()[:8], "@daily")
+	defer helpers.DeleteTestScanSchedule(t, s.ID)
+	id := uuid.UUID(s.ID.Bytes).String()
+	disabled := false
+
+	tests := []struct {
+		name        string
+		id          string
+		body        interface{}
+		wantCode    int
+		wantNextRun bool
+	}{
+		{
+			name:        "update cron",
+			id:          id,
+			body:        schedules.ScheduleRequest{Name: "renamed", Cron: "*/15 * * * *"},
+			wantCode:    http.StatusOK,
+			wantNextRun: true,
+		},
+		{
+			name:     "disable clears next run",
+			id:       id,
+			body:     schedules.ScheduleRequest{Name: "renamed", Cron: "*/15 * * * *", Enabled: &disabled},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid cron fails",
+			id:       id,
+			body:     schedules.ScheduleRequest{Name: "renamed", Cron: "every day"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid id",
+			id:       "invalid",
+			body:     schedules.ScheduleRequest{Name: "renamed", Cron: "@daily"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not found",
+			id:       uuid.New().String(),
+			body:     schedules.ScheduleRequest{Name: "renamed", Cron: "@daily"},
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.PUT("/schedules/"+tt.id, tt.body).WithURLParam("id", tt.id)
+			resp := helpers.MakeRequest(t, req, handler.UpdateSchedule)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+			if resp.Code == http.StatusOK {
+				assert.Equal(t, "renamed", resp.GetString("name"))
+				if tt.wantNextRun {
+					assert.NotEmpty(t, resp.GetString("next_run"))
+				} else {
+					assert.Nil(t, resp.Body["next_run"])
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteSchedule(t *testing.T) {
+	s := helpers.CreateTestScanSchedule(t, "test-delete-"+uuid.New().String()[:8], "@daily")
+	defer helpers.DeleteTestScanSchedule(t, s.ID)
+
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{
+			name:     "delete existing schedule",
+			id:       uuid.UUID(s.ID.Bytes).String(),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "already deleted",
+			id:       uuid.UUID(s.ID.Bytes).String(),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "invalid id",
+			id:       "invalid",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := helpers.DELETE("/schedules/"+tt.id).WithURLParam("id", tt.id)
+			resp := helpers.MakeRequest(t, req, handler.DeleteSchedule)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+		})
+	}
+}
+
+func TestSchedulerRunDue(t *testing.T) {
+	ctx := context.Background()
+	queries := db.New(helpers.TestPool)
+
+	// makeDue moves a schedule's next run into the past
+	makeDue := func(s *db.ScanSchedule) {
+		_, err := queries.UpdateScanSchedule(ctx, db.UpdateScanScheduleParams{
+			ID:       s.ID,
+			Name:     s.Name,
+			Cron:     s.Cron,
+			Timezone: s.Timezone,
+			Options:  s.Options,
+			Enabled:  true,
+			NextRun:  pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true},
+		})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name       string
+		startErr   error
+		wantStatus string
+	}{
+		{
+			name:       "due schedule starts a scan",
+			wantStatus: schedule.StatusStarted,
+		},
+		{
+			name:       "running scan skips the run",
+			startErr:   jobs.ErrAlreadyRunning,
+			wantStatus: schedule.StatusSkipped,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := helpers.CreateTestScanSchedule(t, "test-due-"+uuid.New().String()[:8], "0 * * * *")
+			defer helpers.DeleteTestScanSchedule(t, s.ID)
+			makeDue(s)
+
+			started := 0
+			scheduler := schedule.NewScheduler(helpers.TestPool, func(ctx context.Context, due db.ScanSchedule) (db.Scan, error) {
+				if due.ID == s.ID {
+					started++
+				}
+				return db.Scan{}, tt.startErr
+			}, helpers.TestLogger)
+
+			scheduler.RunDue(ctx)
+			// The schedule was claimed, so a second pass does not start it again
+			scheduler.RunDue(ctx)
+
+			assert.Equal(t, 1, started)
+
+			got, err := queries.GetScanSchedule(ctx, s.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, got.LastStatus.String)
+			assert.True(t, got.LastRunAt.Valid)
+			assert.True(t, got.NextRun.Time.After(time.Now()))
+		})
+	}
+}
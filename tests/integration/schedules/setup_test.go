@@ -0,0 +1,23 @@
+package schedules
+
+import (
+	"os"
+	"testing"
+
+	"stl-manager/internal/handlers/schedules"
+	"stl-manager/tests/integration/helpers"
+)
+
+var handler *schedules.Handler
+
+func TestMain(m *testing.M) {
+	if err := helpers.SetupTestDatabase(); err != nil {
+		panic(err)
+	}
+
+	handler = schedules.New(helpers.TestPool, helpers.TestLogger)
+
+	code := m.Run()
+	helpers.CleanupTestDatabase()
+	os.Exit(code)
+}